@@ -0,0 +1,469 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"mmbot/internal/config"
+	"mmbot/internal/service/backtest"
+	"mmbot/internal/service/montecarlo"
+	"mmbot/internal/service/risk"
+	"mmbot/internal/service/schedule"
+	"mmbot/internal/service/strategy"
+	"mmbot/internal/store/postgres"
+)
+
+func main() {
+	if err := config.LoadDotEnv(".env"); err != nil {
+		log.Printf("failed to load .env: %v", err)
+	}
+	cfg := config.Load()
+
+	csvPath := flag.String("csv", "", "candle CSV file (time,open,high,low,close[,volume]); omit to read the postgres candle store")
+	symbol := flag.String("symbol", "EURUSD", "symbol to backtest")
+	timeframe := flag.String("timeframe", "M15", "candle timeframe (and the candle store series to read)")
+	htfTimeframe := flag.String("htf-timeframe", cfg.StrategyHTFTimeframe, "higher timeframe resampled from the candles to confirm signals (empty disables)")
+	fromRaw := flag.String("from", "", "start time (RFC3339 or YYYY-MM-DD)")
+	toRaw := flag.String("to", "", "end time (RFC3339 or YYYY-MM-DD)")
+	equity := flag.Float64("equity", 10000, "initial account equity")
+	spread := flag.Float64("spread", 1.0, "spread in pips applied to entries and short exits")
+	slippage := flag.Float64("slippage", 0.2, "slippage in pips applied against every fill")
+	commission := flag.Float64("commission", 7.0, "round-turn commission per 1.0 lot")
+	pipValue := flag.Float64("pip-value", 10.0, "account currency value of one pip on 1.0 lot")
+	pipSize := flag.Float64("pip-size", 0, "price size of one pip (0 guesses from the symbol name; see GET /admin/symbols)")
+	riskPct := flag.Float64("risk-pct", cfg.DefaultRiskPct, "percent of equity risked per trade (0 uses -volume)")
+	volume := flag.Float64("volume", 0.01, "fixed lot size when -risk-pct is 0")
+	lookback := flag.Int("lookback", cfg.StrategyMaxCandles, "candles passed to the strategy on each bar")
+	outDir := flag.String("out", "backtest-out", "directory for result.json, trades.csv, equity.csv and summary.csv")
+	ensemble := flag.String("ensemble", cfg.StrategyEnsemble, "ensemble members name[:weight],... (e.g. trend:2,breakout); empty runs the trend engine alone; backtest and montecarlo modes")
+	ensembleMode := flag.String("ensemble-mode", cfg.StrategyEnsembleMode, "ensemble combination: majority, weighted or veto")
+	mode := flag.String("mode", "backtest", "backtest, sweep, walkforward or montecarlo")
+	var params paramFlags
+	flag.Var(&params, "param", "sweep range name=min:max[:step], repeatable (e.g. fast_ema=10:40:5)")
+	search := flag.String("search", backtest.SearchGrid, "sweep search: grid or random")
+	samples := flag.Int("samples", 100, "parameter sets drawn by random search")
+	seed := flag.Int64("seed", 1, "random search seed")
+	objectiveName := flag.String("objective", "return_dd", "ranking objective: net_profit, profit_factor, expectancy, expectancy_r, win_rate, max_drawdown, return_dd")
+	minTrades := flag.Int("min-trades", 20, "minimum trades for a parameter set to be ranked")
+	workers := flag.Int("workers", 0, "parallel workers (default: number of CPUs)")
+	top := flag.Int("top", 20, "sweep results printed to stdout")
+	isBars := flag.Int("is-bars", 2000, "walk-forward in-sample window in bars")
+	oosBars := flag.Int("oos-bars", 500, "walk-forward out-of-sample window in bars")
+	anchored := flag.Bool("anchored", false, "walk-forward with an expanding in-sample window")
+	tradesPath := flag.String("trades", "", "montecarlo input: result.json or trades.csv from an earlier run (omit to backtest first)")
+	mcRisk := flag.String("mc-risk", "0.25,0.5,1,1.5,2", "montecarlo risk-per-trade levels in percent, comma separated")
+	mcIterations := flag.Int("mc-iterations", 5000, "montecarlo runs per risk level")
+	mcMethod := flag.String("mc-method", montecarlo.MethodShuffle, "montecarlo resampling: shuffle or bootstrap")
+	mcHorizon := flag.Int("mc-horizon", 0, "trades per montecarlo run (default: input length)")
+	mcTradesPerDay := flag.Int("mc-trades-per-day", 0, "fixed trades per simulated day (default: replay observed days)")
+	dailyLimit := flag.Float64("daily-limit", cfg.MaxDailyLossPct, "daily loss percent that halts a simulated day (0 disables)")
+	ruinDD := flag.Float64("ruin-dd", 30, "drawdown percent counted as ruin")
+	maxRuin := flag.Float64("max-ruin", 0.01, "ruin probability tolerated by the recommendation")
+	flag.Parse()
+
+	mcCfg := montecarlo.Config{
+		Iterations:         *mcIterations,
+		Method:             *mcMethod,
+		Seed:               *seed,
+		Horizon:            *mcHorizon,
+		TradesPerDay:       *mcTradesPerDay,
+		DailyLossLimitPct:  *dailyLimit,
+		RuinDrawdownPct:    *ruinDD,
+		MaxRuinProbability: *maxRuin,
+	}
+	if *mode == "montecarlo" {
+		levels, err := parseFloatList(*mcRisk)
+		if err != nil {
+			log.Fatalf("invalid -mc-risk: %v", err)
+		}
+		mcCfg.RiskLevels = levels
+	}
+	if *mode == "montecarlo" && *tradesPath != "" {
+		outcomes, err := loadOutcomes(*tradesPath)
+		if err != nil {
+			log.Fatalf("load trades: %v", err)
+		}
+		runMonteCarlo(outcomes, mcCfg, *outDir)
+		return
+	}
+
+	candles, err := loadCandles(cfg, *csvPath, *symbol, *timeframe, *fromRaw, *toRaw)
+	if err != nil {
+		log.Fatalf("load candles: %v", err)
+	}
+	barLen, err := strategy.ParseTimeframe(*timeframe)
+	if err != nil {
+		log.Fatalf("invalid -timeframe: %v", err)
+	}
+	candles, issues, err := strategy.NormalizeCandles(candles, strategy.NormalizeOptions{Timeframe: barLen})
+	if err != nil {
+		for _, is := range issues {
+			log.Printf("%s %s: %s", is.Time.Format(time.RFC3339), is.Code, is.Message)
+		}
+		log.Fatalf("invalid candles: %v", err)
+	}
+	logCandleIssues(issues)
+	log.Printf("loaded %d candles for %s", len(candles), *symbol)
+
+	// Sessions, weekends and holidays apply to simulated bar times; the news
+	// calendar lives in the server store and is not replayed.
+	gate, err := schedule.New(schedule.Config{
+		Timezone:      cfg.TradingTimezone,
+		Sessions:      cfg.TradingSessions,
+		Holidays:      cfg.TradingHolidays,
+		BlockWeekends: cfg.TradingBlockWeekends,
+	}, nil)
+	if err != nil {
+		log.Fatalf("invalid trading schedule: %v", err)
+	}
+	tradingDays, err := risk.ParseTradingDays(cfg.TradingDayBoundary, cfg.TradingDayAccounts)
+	if err != nil {
+		log.Fatalf("invalid trading day boundary: %v", err)
+	}
+	riskEngine := risk.NewEngine(
+		cfg.MaxOpenPositions,
+		cfg.MaxDailyLossPct,
+		cfg.AIMinConfidence,
+		cfg.MaxSpreadPips,
+	).WithExposureLimits(risk.ExposureLimits{
+		MaxPositionsPerSymbol:  cfg.MaxPositionsPerSymbol,
+		MaxLotsPerSymbol:       cfg.MaxLotsPerSymbol,
+		MaxCurrencyLots:        cfg.MaxCurrencyExposureLots,
+		MaxCorrelatedPositions: cfg.MaxCorrelatedPositions,
+	}).WithStreakLimits(risk.StreakLimits{
+		MaxConsecutiveLosses: cfg.MaxConsecutiveLosses,
+		LossCooldown:         cfg.LossStreakCooldown,
+		MaxTradesPerDay:      cfg.MaxTradesPerDay,
+	}).WithTimeGate(gate).WithTradingDays(tradingDays)
+	if cfg.RiskPolicyFile != "" {
+		policy, err := risk.LoadPolicy(cfg.RiskPolicyFile)
+		if err != nil {
+			log.Fatalf("invalid risk policy: %v", err)
+		}
+		riskEngine.WithPolicy(policy)
+	}
+	trend := strategy.NewTrendEngine().Override(strategy.TrendOverride{
+		FastEMA:        cfg.StrategyFastEMA,
+		SlowEMA:        cfg.StrategySlowEMA,
+		ATRLen:         cfg.StrategyATRLen,
+		SLATRMult:      cfg.StrategySLATRMult,
+		TPRiskReward:   cfg.StrategyTPRiskReward,
+		MinSLPips:      cfg.StrategyMinSLPips,
+		MinTrendGapPct: cfg.StrategyMinTrendGapPct,
+		MinSlopePct:    cfg.StrategyMinSlopePct,
+	})
+	if err := trend.Validate(); err != nil {
+		log.Fatalf("invalid strategy parameters: %v", err)
+	}
+	btCfg := backtest.Config{
+		Symbol:           *symbol,
+		Timeframe:        *timeframe,
+		HTFTimeframe:     *htfTimeframe,
+		InitialEquity:    *equity,
+		SpreadPips:       *spread,
+		SlippagePips:     *slippage,
+		CommissionPerLot: *commission,
+		PipValuePerLot:   *pipValue,
+		PipSize:          *pipSize,
+		RiskPct:          *riskPct,
+		Volume:           *volume,
+		Lookback:         *lookback,
+	}
+
+	if *mode == "sweep" || *mode == "walkforward" {
+		ranges := make([]backtest.ParamRange, 0, len(params))
+		for _, raw := range params {
+			pr, err := backtest.ParseParamRange(raw)
+			if err != nil {
+				log.Fatalf("%v", err)
+			}
+			ranges = append(ranges, pr)
+		}
+		sc := backtest.SweepConfig{
+			Base:      *trend,
+			Ranges:    ranges,
+			Search:    *search,
+			Samples:   *samples,
+			Seed:      *seed,
+			Objective: *objectiveName,
+			MinTrades: *minTrades,
+			Workers:   *workers,
+		}
+		switch *mode {
+		case "sweep":
+			runSweep(candles, btCfg, riskEngine, sc, *outDir, *top)
+		case "walkforward":
+			runWalkForward(candles, btCfg, riskEngine, sc, backtest.WalkForwardConfig{
+				InSampleBars:  *isBars,
+				OutSampleBars: *oosBars,
+				Anchored:      *anchored,
+			}, *outDir)
+		}
+		return
+	}
+	if *mode != "backtest" && *mode != "montecarlo" {
+		log.Fatalf("unknown -mode %q", *mode)
+	}
+
+	var strat backtest.Strategy = trend
+	if *ensemble != "" {
+		members, err := strategy.NewRegistry(trend, strategy.NewBreakoutEngine(), strategy.NewRangeEngine()).ParseMembers(*ensemble)
+		if err != nil {
+			log.Fatalf("invalid -ensemble: %v", err)
+		}
+		ens, err := strategy.NewEnsemble(*ensembleMode, members...)
+		if err != nil {
+			log.Fatalf("invalid -ensemble-mode: %v", err)
+		}
+		strat = ens
+	}
+	res, err := backtest.NewEngine(strat, riskEngine, btCfg).Run(candles)
+	if err != nil {
+		log.Fatalf("backtest failed: %v", err)
+	}
+	if err := writeOutputs(*outDir, res); err != nil {
+		log.Fatalf("write outputs: %v", err)
+	}
+
+	st := res.Stats
+	fmt.Printf("trades=%d win_rate=%.2f%% profit_factor=%.2f expectancy=%.2f (%.2fR) max_drawdown=%.2f (%.2f%%) net=%.2f\n",
+		st.Trades, st.WinRate*100, st.ProfitFactor, st.Expectancy, st.ExpectancyR, st.MaxDrawdown, st.MaxDrawdownPct, st.NetProfit)
+	if len(res.RiskDenials) > 0 {
+		fmt.Printf("risk denials: %v\n", res.RiskDenials)
+	}
+	if *mode == "montecarlo" {
+		runMonteCarlo(montecarlo.FromTrades(res.Trades), mcCfg, *outDir)
+		return
+	}
+	fmt.Printf("results written to %s\n", *outDir)
+}
+
+type paramFlags []string
+
+func (p *paramFlags) String() string { return strings.Join(*p, ",") }
+
+func (p *paramFlags) Set(v string) error {
+	*p = append(*p, v)
+	return nil
+}
+
+func runSweep(candles []strategy.Candle, btCfg backtest.Config, riskEngine *risk.Engine, sc backtest.SweepConfig, outDir string, top int) {
+	results, err := backtest.Sweep(candles, btCfg, riskEngine, sc)
+	if err != nil {
+		log.Fatalf("sweep failed: %v", err)
+	}
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		log.Fatalf("create output dir: %v", err)
+	}
+	if err := writeFile(filepath.Join(outDir, "sweep.json"), func(f *os.File) error { return backtest.WriteJSON(f, results) }); err != nil {
+		log.Fatalf("write sweep.json: %v", err)
+	}
+	fmt.Printf("%d parameter sets ranked by %s\n", len(results), sc.Objective)
+	for i, r := range results {
+		if i >= top {
+			break
+		}
+		if r.Error != "" {
+			fmt.Printf("%3d  %v  error=%s\n", i+1, r.Params, r.Error)
+			continue
+		}
+		fmt.Printf("%3d  score=%.4f eligible=%t trades=%d win_rate=%.2f%% pf=%.2f dd=%.2f%%  %v\n",
+			i+1, r.Score, r.Eligible, r.Stats.Trades, r.Stats.WinRate*100, r.Stats.ProfitFactor, r.Stats.MaxDrawdownPct, r.Params)
+	}
+	fmt.Printf("results written to %s\n", outDir)
+}
+
+func runWalkForward(candles []strategy.Candle, btCfg backtest.Config, riskEngine *risk.Engine, sc backtest.SweepConfig, wf backtest.WalkForwardConfig, outDir string) {
+	report, err := backtest.WalkForward(candles, btCfg, riskEngine, sc, wf)
+	if err != nil {
+		log.Fatalf("walk-forward failed: %v", err)
+	}
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		log.Fatalf("create output dir: %v", err)
+	}
+	if err := writeFile(filepath.Join(outDir, "walkforward.json"), func(f *os.File) error { return backtest.WriteJSON(f, report) }); err != nil {
+		log.Fatalf("write walkforward.json: %v", err)
+	}
+	for _, f := range report.Folds {
+		if f.Error != "" {
+			fmt.Printf("fold %d  %s..%s  %s\n", f.Index, f.OutSampleFrom.Format("2006-01-02"), f.OutSampleTo.Format("2006-01-02"), f.Error)
+			continue
+		}
+		fmt.Printf("fold %d  %s..%s  in-sample=%.4f out-of-sample=%.4f  %v\n",
+			f.Index, f.OutSampleFrom.Format("2006-01-02"), f.OutSampleTo.Format("2006-01-02"), f.InSampleScore, f.OutSampleScore, f.Params)
+	}
+	oos := report.OutOfSample
+	fmt.Printf("out-of-sample: trades=%d win_rate=%.2f%% pf=%.2f net=%.2f dd=%.2f%%\n",
+		oos.Trades, oos.WinRate*100, oos.ProfitFactor, oos.NetProfit, oos.MaxDrawdownPct)
+	fmt.Printf("mean %s in-sample=%.4f out-of-sample=%.4f efficiency=%.2f\n",
+		report.Objective, report.MeanInSampleScore, report.MeanOutSampleScore, report.Efficiency)
+	fmt.Printf("results written to %s\n", outDir)
+}
+
+func runMonteCarlo(outcomes []montecarlo.Outcome, mcCfg montecarlo.Config, outDir string) {
+	report, err := montecarlo.Run(outcomes, mcCfg)
+	if err != nil {
+		log.Fatalf("monte carlo failed: %v", err)
+	}
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		log.Fatalf("create output dir: %v", err)
+	}
+	if err := writeFile(filepath.Join(outDir, "montecarlo.json"), func(f *os.File) error { return backtest.WriteJSON(f, report) }); err != nil {
+		log.Fatalf("write montecarlo.json: %v", err)
+	}
+	fmt.Printf("%d trades over %d days, expectancy %.3fR, %d %s runs per level\n",
+		report.Trades, report.Days, report.ExpectancyR, report.Config.Iterations, report.Config.Method)
+	for _, l := range report.Levels {
+		fmt.Printf("risk=%.2f%%  dd p50=%.1f%% p95=%.1f%% p99=%.1f%%  worst-day p95=%.2f%%  return p50=%.1f%% p95=%.1f%%  ruin=%.2f%%  daily-limit-hit=%.1f%%\n",
+			l.RiskPct, l.MaxDrawdownPct.P50, l.MaxDrawdownPct.P95, l.MaxDrawdownPct.P99, l.WorstDayLossPct.P95,
+			l.ReturnPct.P50, l.ReturnPct.P95, l.RuinProbability*100, l.DailyLimitHitProbability*100)
+	}
+	if rec := report.Recommendation; rec != nil {
+		if rec.RiskPct > 0 {
+			fmt.Printf("recommendation: DEFAULT_RISK_PCT=%.2f MAX_DAILY_LOSS_PCT=%.1f (%s)\n", rec.RiskPct, rec.DailyLossPct, rec.Reason)
+		} else {
+			fmt.Printf("recommendation: %s\n", rec.Reason)
+		}
+	}
+	fmt.Printf("results written to %s\n", outDir)
+}
+
+func loadOutcomes(path string) ([]montecarlo.Outcome, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if strings.HasSuffix(strings.ToLower(path), ".json") {
+		var res backtest.Result
+		if err := json.NewDecoder(f).Decode(&res); err != nil {
+			return nil, err
+		}
+		return montecarlo.FromTrades(res.Trades), nil
+	}
+	return montecarlo.ReadOutcomesCSV(f)
+}
+
+func parseFloatList(raw string) ([]float64, error) {
+	out := make([]float64, 0, 8)
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// logCandleIssues prints a count per issue code plus the first few gaps, which
+// are usually the ones worth checking against the data source.
+func logCandleIssues(issues []strategy.CandleIssue) {
+	counts := make(map[string]int)
+	gaps := 0
+	for _, is := range issues {
+		counts[is.Code]++
+		if is.Code == strategy.IssueGap && gaps < 5 {
+			log.Printf("gap before %s: %s", is.Time.Format(time.RFC3339), is.Message)
+			gaps++
+		}
+	}
+	if len(counts) > 0 {
+		log.Printf("candle issues: %v", counts)
+	}
+}
+
+func loadCandles(cfg config.Config, csvPath, symbol, timeframe, fromRaw, toRaw string) ([]strategy.Candle, error) {
+	from, err := parseTimeFlag(fromRaw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -from: %w", err)
+	}
+	to, err := parseTimeFlag(toRaw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -to: %w", err)
+	}
+
+	if csvPath != "" {
+		f, err := os.Open(csvPath)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		all, err := backtest.ReadCandlesCSV(f)
+		if err != nil {
+			return nil, err
+		}
+		out := all[:0]
+		for _, c := range all {
+			if (!from.IsZero() && c.Time.Before(from)) || (!to.IsZero() && c.Time.After(to)) {
+				continue
+			}
+			out = append(out, c)
+		}
+		return out, nil
+	}
+
+	if cfg.DatabaseURL == "" {
+		return nil, fmt.Errorf("no -csv given and DATABASE_URL is not set")
+	}
+	st, err := postgres.NewStore(cfg.DatabaseURL, cfg.EATokenTTL, cfg.OAuthEncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	return st.ListCandles(symbol, timeframe, from, to, 0), nil
+}
+
+func parseTimeFlag(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if ts, err := time.Parse(time.RFC3339, raw); err == nil {
+		return ts.UTC(), nil
+	}
+	return time.Parse("2006-01-02", raw)
+}
+
+func writeOutputs(dir string, res backtest.Result) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	files := []struct {
+		name  string
+		write func(f *os.File) error
+	}{
+		{"result.json", func(f *os.File) error { return backtest.WriteJSON(f, res) }},
+		{"trades.csv", func(f *os.File) error { return backtest.WriteTradesCSV(f, res.Trades) }},
+		{"equity.csv", func(f *os.File) error { return backtest.WriteEquityCSV(f, res.Equity) }},
+		{"summary.csv", func(f *os.File) error { return backtest.WriteStatsCSV(f, res.Stats) }},
+	}
+	for _, item := range files {
+		if err := writeFile(filepath.Join(dir, item.name), item.write); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFile(path string, write func(f *os.File) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	werr := write(f)
+	cerr := f.Close()
+	if werr != nil {
+		return werr
+	}
+	return cerr
+}
@@ -14,6 +14,7 @@ import (
 	"mmbot/internal/integrations/openclaw"
 	"mmbot/internal/integrations/telegram"
 	"mmbot/internal/service/risk"
+	"mmbot/internal/service/schedule"
 	storepkg "mmbot/internal/store"
 	"mmbot/internal/store/memory"
 	"mmbot/internal/store/postgres"
@@ -36,12 +37,41 @@ func main() {
 	} else {
 		st = memory.NewStore(cfg.EATokenTTL)
 	}
+	gate, err := schedule.New(tradingSchedule(cfg), st)
+	if err != nil {
+		log.Fatalf("invalid trading schedule: %v", err)
+	}
+	tradingDays, err := risk.ParseTradingDays(cfg.TradingDayBoundary, cfg.TradingDayAccounts)
+	if err != nil {
+		log.Fatalf("invalid trading day boundary: %v", err)
+	}
 	riskEngine := risk.NewEngine(
 		cfg.MaxOpenPositions,
 		cfg.MaxDailyLossPct,
 		cfg.AIMinConfidence,
 		cfg.MaxSpreadPips,
-	)
+	).WithExposureLimits(risk.ExposureLimits{
+		MaxPositionsPerSymbol:  cfg.MaxPositionsPerSymbol,
+		MaxLotsPerSymbol:       cfg.MaxLotsPerSymbol,
+		MaxCurrencyLots:        cfg.MaxCurrencyExposureLots,
+		MaxCorrelatedPositions: cfg.MaxCorrelatedPositions,
+	}).WithStreakLimits(risk.StreakLimits{
+		MaxConsecutiveLosses: cfg.MaxConsecutiveLosses,
+		LossCooldown:         cfg.LossStreakCooldown,
+		MaxTradesPerDay:      cfg.MaxTradesPerDay,
+	}).WithSlippageLimits(risk.SlippageLimits{
+		MaxAvgPips: cfg.MaxAvgSlippagePips,
+		Window:     cfg.SlippageWindow,
+		MinFills:   cfg.SlippageMinFills,
+		PauseFor:   cfg.SlippagePause,
+	}).WithMarginFloor(cfg.MinMarginLevelPct).WithTimeGate(gate).WithTradingDays(tradingDays)
+	if cfg.RiskPolicyFile != "" {
+		policy, err := risk.LoadPolicy(cfg.RiskPolicyFile)
+		if err != nil {
+			log.Fatalf("invalid risk policy: %v", err)
+		}
+		riskEngine.WithPolicy(policy)
+	}
 	notifier := telegram.NewNotifier(cfg.TelegramBotToken, cfg.TelegramChatID)
 	openClawClient := openclaw.NewClient(
 		cfg.OpenClawWebhookURL,
@@ -68,9 +98,27 @@ func main() {
 		}
 	}()
 
+	paperCtx, stopPaper := context.WithCancel(context.Background())
+	defer stopPaper()
+	go srv.RunPaper(paperCtx)
+
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			changes, err := srv.ReloadRiskPolicy("sighup")
+			if err != nil {
+				log.Printf("risk policy reload failed: %v", err)
+				continue
+			}
+			log.Printf("risk policy reloaded: %d change(s)", len(changes))
+		}
+	}()
+
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 	<-stop
+	stopPaper()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -78,3 +126,15 @@ func main() {
 		log.Printf("graceful shutdown failed: %v", err)
 	}
 }
+
+func tradingSchedule(cfg config.Config) schedule.Config {
+	return schedule.Config{
+		Timezone:      cfg.TradingTimezone,
+		Sessions:      cfg.TradingSessions,
+		Holidays:      cfg.TradingHolidays,
+		BlockWeekends: cfg.TradingBlockWeekends,
+		Before:        cfg.NewsBlackoutBefore,
+		After:         cfg.NewsBlackoutAfter,
+		MinImpact:     cfg.BlackoutMinImpact,
+	}
+}
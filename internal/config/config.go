@@ -21,11 +21,66 @@ type Config struct {
 	MaxOpenPositions        int
 	MaxSpreadPips           float64
 	DefaultRiskPct          float64
+	MaxPositionsPerSymbol   int
+	MaxLotsPerSymbol        float64
+	MaxCurrencyExposureLots float64
+	MaxCorrelatedPositions  int
+	MaxDrawdownPct          float64
+	MaxWeeklyLossPct        float64
+	MaxMonthlyLossPct       float64
+	MaxConsecutiveLosses    int
+	LossStreakCooldown      time.Duration
+	MaxTradesPerDay         int
+	TradingTimezone         string
+	TradingSessions         string
+	TradingHolidays         string
+	TradingBlockWeekends    bool
+	TradingDayBoundary      string
+	TradingDayAccounts      string
+	DailyBreakerAutoResume  bool
+	NewsBlackoutBefore      time.Duration
+	NewsBlackoutAfter       time.Duration
+	BlackoutMinImpact       string
+	RiskPolicyFile          string
+	MinMarginLevelPct       float64
+	MaxAvgSlippagePips      float64
+	SlippageWindow          int
+	SlippageMinFills        int
+	SlippagePause           time.Duration
+	EquitySampleInterval    time.Duration
+	EquityRawRetention      time.Duration
+	EquityMinuteRetention   time.Duration
+	EquityHourRetention     time.Duration
+	ReconcileGrace          time.Duration
+	BaseCurrency            string
+	FXRates                 string
+	FXQuoteMaxAge           time.Duration
+	PaperAccounts           string
+	PaperBalance            float64
+	PaperCurrency           string
+	PaperSpreadPips         float64
+	PaperSlippagePips       float64
+	PaperTimeframe          string
+	PaperInterval           time.Duration
 	StrategyRateLimitPerMin int
 	StrategyMinInterval     time.Duration
 	StrategyDedupTTL        time.Duration
 	StrategyDailyBudget     int
 	StrategyMaxCandles      int
+	StrategyFastEMA         *int
+	StrategySlowEMA         *int
+	StrategyATRLen          *int
+	StrategySLATRMult       *float64
+	StrategyTPRiskReward    *float64
+	StrategyMinSLPips       *float64
+	StrategyMinTrendGapPct  *float64
+	StrategyMinSlopePct     *float64
+	StrategyHTFTimeframe    string
+	StrategyDropFormingBar  bool
+	StrategyEnsemble        string
+	StrategyEnsembleMode    string
+	StrategyRegimeRouting   bool
+	StrategyRegimeRoutes    string
 	TelegramBotToken        string
 	TelegramChatID          string
 	TelegramAllowedChatIDs  string
@@ -61,11 +116,66 @@ func Load() Config {
 		MaxOpenPositions:        getInt("MAX_OPEN_POSITIONS", 3),
 		MaxSpreadPips:           getFloat("MAX_SPREAD_PIPS", 2.0),
 		DefaultRiskPct:          getFloat("DEFAULT_RISK_PCT", 1.0),
+		MaxPositionsPerSymbol:   getInt("MAX_POSITIONS_PER_SYMBOL", 2),
+		MaxLotsPerSymbol:        getFloat("MAX_LOTS_PER_SYMBOL", 0),
+		MaxCurrencyExposureLots: getFloat("MAX_CURRENCY_EXPOSURE_LOTS", 0),
+		MaxCorrelatedPositions:  getInt("MAX_CORRELATED_POSITIONS", 2),
+		MaxDrawdownPct:          getFloat("MAX_DRAWDOWN_PCT", 10.0),
+		MaxWeeklyLossPct:        getFloat("MAX_WEEKLY_LOSS_PCT", 5.0),
+		MaxMonthlyLossPct:       getFloat("MAX_MONTHLY_LOSS_PCT", 8.0),
+		MaxConsecutiveLosses:    getInt("MAX_CONSECUTIVE_LOSSES", 0),
+		LossStreakCooldown:      getDuration("LOSS_STREAK_COOLDOWN", 0),
+		MaxTradesPerDay:         getInt("MAX_TRADES_PER_DAY", 0),
+		TradingTimezone:         getEnv("TRADING_TIMEZONE", "UTC"),
+		TradingSessions:         getEnv("TRADING_SESSIONS", ""),
+		TradingHolidays:         getEnv("TRADING_HOLIDAYS", ""),
+		TradingBlockWeekends:    getBool("TRADING_BLOCK_WEEKENDS", false),
+		TradingDayBoundary:      getEnv("TRADING_DAY_BOUNDARY", ""),
+		TradingDayAccounts:      getEnv("TRADING_DAY_ACCOUNTS", ""),
+		DailyBreakerAutoResume:  getBool("DAILY_BREAKER_AUTO_RESUME", false),
+		NewsBlackoutBefore:      getDuration("NEWS_BLACKOUT_BEFORE", 15*time.Minute),
+		NewsBlackoutAfter:       getDuration("NEWS_BLACKOUT_AFTER", 15*time.Minute),
+		BlackoutMinImpact:       getEnv("BLACKOUT_MIN_IMPACT", ""),
+		RiskPolicyFile:          getEnv("RISK_POLICY_FILE", ""),
+		MinMarginLevelPct:       getFloat("MIN_MARGIN_LEVEL_PCT", 0),
+		MaxAvgSlippagePips:      getFloat("MAX_AVG_SLIPPAGE_PIPS", 0),
+		SlippageWindow:          getInt("SLIPPAGE_WINDOW", 20),
+		SlippageMinFills:        getInt("SLIPPAGE_MIN_FILLS", 5),
+		SlippagePause:           getDuration("SLIPPAGE_PAUSE", time.Hour),
+		EquitySampleInterval:    getDuration("EQUITY_SAMPLE_INTERVAL", 10*time.Second),
+		EquityRawRetention:      getDuration("EQUITY_RAW_RETENTION", 24*time.Hour),
+		EquityMinuteRetention:   getDuration("EQUITY_MINUTE_RETENTION", 7*24*time.Hour),
+		EquityHourRetention:     getDuration("EQUITY_HOUR_RETENTION", 0),
+		ReconcileGrace:          getDuration("RECONCILE_GRACE", 2*time.Minute),
+		BaseCurrency:            getEnv("BASE_CURRENCY", "USD"),
+		FXRates:                 getEnv("FX_RATES", ""),
+		FXQuoteMaxAge:           getDuration("FX_QUOTE_MAX_AGE", time.Hour),
+		PaperAccounts:           getEnv("PAPER_ACCOUNTS", ""),
+		PaperBalance:            getFloat("PAPER_BALANCE", 10000),
+		PaperCurrency:           getEnv("PAPER_CURRENCY", "USD"),
+		PaperSpreadPips:         getFloat("PAPER_SPREAD_PIPS", 1.0),
+		PaperSlippagePips:       getFloat("PAPER_SLIPPAGE_PIPS", 0.2),
+		PaperTimeframe:          getEnv("PAPER_TIMEFRAME", "M15"),
+		PaperInterval:           getDuration("PAPER_INTERVAL", 5*time.Second),
 		StrategyRateLimitPerMin: getInt("STRATEGY_RATE_LIMIT_PER_MIN", 30),
 		StrategyMinInterval:     getDuration("STRATEGY_MIN_INTERVAL", 2*time.Second),
 		StrategyDedupTTL:        getDuration("STRATEGY_DEDUP_TTL", 30*time.Second),
 		StrategyDailyBudget:     getInt("STRATEGY_DAILY_BUDGET", 500),
 		StrategyMaxCandles:      getInt("STRATEGY_MAX_CANDLES", 300),
+		StrategyFastEMA:         getOptionalInt("STRATEGY_FAST_EMA"),
+		StrategySlowEMA:         getOptionalInt("STRATEGY_SLOW_EMA"),
+		StrategyATRLen:          getOptionalInt("STRATEGY_ATR_LEN"),
+		StrategySLATRMult:       getOptionalFloat("STRATEGY_SL_ATR_MULT"),
+		StrategyTPRiskReward:    getOptionalFloat("STRATEGY_TP_RISK_REWARD"),
+		StrategyMinSLPips:       getOptionalFloat("STRATEGY_MIN_SL_PIPS"),
+		StrategyMinTrendGapPct:  getOptionalFloat("STRATEGY_MIN_TREND_GAP_PCT"),
+		StrategyMinSlopePct:     getOptionalFloat("STRATEGY_MIN_SLOPE_PCT"),
+		StrategyHTFTimeframe:    getEnv("STRATEGY_HTF_TIMEFRAME", ""),
+		StrategyDropFormingBar:  getBool("STRATEGY_DROP_FORMING_BAR", true),
+		StrategyEnsemble:        getEnv("STRATEGY_ENSEMBLE", ""),
+		StrategyEnsembleMode:    getEnv("STRATEGY_ENSEMBLE_MODE", "majority"),
+		StrategyRegimeRouting:   getBool("STRATEGY_REGIME_ROUTING", false),
+		StrategyRegimeRoutes:    getEnv("STRATEGY_REGIME_ROUTES", "trending=trend,ranging=range,volatile=breakout,quiet=none"),
 		TelegramBotToken:        getEnv("TELEGRAM_BOT_TOKEN", ""),
 		TelegramChatID:          getEnv("TELEGRAM_CHAT_ID", ""),
 		TelegramAllowedChatIDs:  getEnv("TELEGRAM_ALLOWED_CHAT_IDS", ""),
@@ -118,6 +228,45 @@ func getFloat(key string, fallback float64) float64 {
 	return n
 }
 
+// getOptionalInt is nil when key is unset or not an integer, so the caller
+// keeps its own default.
+func getOptionalInt(key string) *int {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return nil
+	}
+	return &n
+}
+
+// getOptionalFloat is nil when key is unset or not a number.
+func getOptionalFloat(key string) *float64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil
+	}
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return nil
+	}
+	return &n
+}
+
+func getBool(key string, fallback bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fallback
+	}
+	return b
+}
+
 func getDuration(key string, fallback time.Duration) time.Duration {
 	v := os.Getenv(key)
 	if v == "" {
@@ -32,6 +32,16 @@ const (
 	EventRiskTriggered          EventType = "RiskTriggered"
 	EventBotPaused              EventType = "BotPaused"
 	EventOpenClawDeliveryFailed EventType = "OpenClawDeliveryFailed"
+	EventRegimeChanged          EventType = "RegimeChanged"
+	EventLossStreakCooldown     EventType = "LossStreakCooldown"
+	EventDailyTradeLimit        EventType = "DailyTradeLimitReached"
+	EventRiskPolicyChanged      EventType = "RiskPolicyChanged"
+	EventSymbolPaused           EventType = "SymbolPaused"
+	EventSymbolResumed          EventType = "SymbolResumed"
+	EventTradeClosed            EventType = "TradeClosed"
+	EventTradingDayClosed       EventType = "TradingDayClosed"
+	EventReportGenerated        EventType = "ReportGenerated"
+	EventReconcileIssue         EventType = "ReconcileIssue"
 )
 
 type Command struct {
@@ -45,9 +55,16 @@ type Command struct {
 	SL        float64       `json:"sl,omitempty"`
 	TP        float64       `json:"tp,omitempty"`
 	Reason    string        `json:"reason,omitempty"`
+	Strategy  string        `json:"strategy,omitempty"`
 	Status    CommandStatus `json:"status"`
 	ExpiresAt time.Time     `json:"expires_at"`
 	CreatedAt time.Time     `json:"created_at"`
+	// Fill quality, set from a successful result that reported prices.
+	RequestedPrice float64   `json:"requested_price,omitempty"`
+	FillPrice      float64   `json:"fill_price,omitempty"`
+	SlippagePips   float64   `json:"slippage_pips,omitempty"`
+	LatencyMS      int64     `json:"latency_ms,omitempty"`
+	FilledAt       time.Time `json:"filled_at,omitempty"`
 }
 
 type CommandResult struct {
@@ -57,6 +74,18 @@ type CommandResult struct {
 	ErrorCode    string `json:"error_code,omitempty"`
 	ErrorMessage string `json:"error_message,omitempty"`
 	ExecutedAt   string `json:"executed_at,omitempty"`
+	// RequestedPrice is the quote the EA sent the order at and FillPrice the
+	// broker's deal price; LatencyMS is the order round trip.
+	RequestedPrice float64 `json:"requested_price,omitempty"`
+	FillPrice      float64 `json:"fill_price,omitempty"`
+	// SlippagePips is computed by the server from the prices when the EA
+	// leaves it out. Positive is against the trade.
+	SlippagePips float64 `json:"slippage_pips,omitempty"`
+	LatencyMS    int64   `json:"latency_ms,omitempty"`
+	// AccountID and ReceivedAt are set by the store when it records the
+	// result.
+	AccountID  string    `json:"account_id,omitempty"`
+	ReceivedAt time.Time `json:"received_at,omitempty"`
 }
 
 type Event struct {
@@ -68,6 +97,16 @@ type Event struct {
 	CreatedAt time.Time              `json:"created_at"`
 }
 
+// Account is a broker account registered through the EA handshake. Currency
+// is the ISO code its balance, equity and PnL are denominated in; it is
+// reported in the EA snapshot or set by an admin, and empty until known.
+type Account struct {
+	ID        string    `json:"id"`
+	Currency  string    `json:"currency"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 type EASession struct {
 	Token     string    `json:"token"`
 	AccountID string    `json:"account_id"`
@@ -100,15 +139,450 @@ type SignalInput struct {
 	SpreadPips     float64 `json:"spread_pips"`
 	StopLossPips   float64 `json:"stop_loss_pips"`
 	TakeProfitPips float64 `json:"take_profit_pips"`
+	// Volume is the lot size the signal would open; exposure limits count it.
+	Volume float64 `json:"volume,omitempty"`
+	// Strategy names the engine (or "ensemble") that produced the signal;
+	// Votes records each ensemble member's view.
+	Strategy string         `json:"strategy,omitempty"`
+	Votes    []StrategyVote `json:"votes,omitempty"`
+	// Regime is the market regime that routed the signal, when routing is on.
+	Regime string `json:"regime,omitempty"`
+}
+
+// StrategyVote is one ensemble member's output for the bar being evaluated.
+type StrategyVote struct {
+	Strategy       string  `json:"strategy"`
+	Weight         float64 `json:"weight"`
+	HasSignal      bool    `json:"has_signal"`
+	Side           string  `json:"side,omitempty"`
+	Confidence     float64 `json:"confidence,omitempty"`
+	StopLossPips   float64 `json:"stop_loss_pips,omitempty"`
+	TakeProfitPips float64 `json:"take_profit_pips,omitempty"`
+	Reason         string  `json:"reason,omitempty"`
+	Error          string  `json:"error,omitempty"`
+}
+
+type Candle struct {
+	Time   time.Time `json:"time"`
+	Open   float64   `json:"open"`
+	High   float64   `json:"high"`
+	Low    float64   `json:"low"`
+	Close  float64   `json:"close"`
+	Volume float64   `json:"volume,omitempty"`
+}
+
+const (
+	SymbolSpecSourceEA    = "ea"
+	SymbolSpecSourceAdmin = "admin"
+)
+
+// SymbolSpec is the broker contract for a symbol as reported by the EA
+// (Source "ea") or entered by an admin (Source "admin"). Admin entries are
+// overrides: their non-zero fields win over the EA report.
+type SymbolSpec struct {
+	Symbol string  `json:"symbol"`
+	Digits int     `json:"digits,omitempty"`
+	Point  float64 `json:"point,omitempty"`
+	// PipSize is normally derived from Digits/Point; set it to override.
+	PipSize      float64 `json:"pip_size,omitempty"`
+	TickSize     float64 `json:"tick_size,omitempty"`
+	TickValue    float64 `json:"tick_value,omitempty"`
+	ContractSize float64 `json:"contract_size,omitempty"`
+	VolumeMin    float64 `json:"volume_min,omitempty"`
+	VolumeMax    float64 `json:"volume_max,omitempty"`
+	VolumeStep   float64 `json:"volume_step,omitempty"`
+	StopsLevel   int     `json:"stops_level,omitempty"`
+	// MarginPerLot is the initial margin one lot needs, in account currency,
+	// as the broker computed it when the spec was reported.
+	MarginPerLot   float64          `json:"margin_per_lot,omitempty"`
+	BaseCurrency   string           `json:"base_currency,omitempty"`
+	ProfitCurrency string           `json:"profit_currency,omitempty"`
+	Sessions       []TradingSession `json:"sessions,omitempty"`
+	Source         string           `json:"source"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+}
+
+// TradingSession is one trading window in broker server time. Day follows
+// time.Weekday (0 = Sunday), matching MQL5's ENUM_DAY_OF_WEEK.
+type TradingSession struct {
+	Day  int    `json:"day"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Blackout is a news window during which new opens are blocked for symbols
+// trading Currency (a currency code or a symbol name; empty means all).
+type Blackout struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Currency  string    `json:"currency,omitempty"`
+	Impact    string    `json:"impact,omitempty"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Source    string    `json:"source"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type StrategyState struct {
-	Paused        bool
+	Paused bool
+	// AccountPaused is set while the account's drawdown breaker holds.
+	AccountPaused bool
+	// SymbolPaused is set while the symbol's slippage pause holds.
+	SymbolPaused  bool
 	OpenPositions int
 	DailyLossPct  float64
+	// Spec is the resolved symbol contract, nil when the symbol is unknown.
+	Spec *SymbolSpec
+	// Positions are the open positions from the latest EA snapshot.
+	Positions []Position
+	// Margin is the account's margin from the latest EA snapshot, nil when
+	// the EA did not report it.
+	Margin *MarginState
+	// TradesToday counts executed OPENs since the start of the trading day.
+	TradesToday       int
+	LossCooldownUntil time.Time
+	// Now is the evaluation time; zero means the wall clock.
+	Now time.Time
+}
+
+// MarginState is an account's margin usage in account currency; Level is
+// equity / margin in percent (zero without open positions).
+type MarginState struct {
+	Equity     float64 `json:"equity"`
+	Margin     float64 `json:"margin"`
+	FreeMargin float64 `json:"free_margin"`
+	Level      float64 `json:"margin_level"`
+}
+
+// Position is one open position as reported in an EA sync snapshot. The
+// currencies are filled from the symbol registry when known.
+type Position struct {
+	Ticket         string  `json:"ticket"`
+	Symbol         string  `json:"symbol"`
+	Side           string  `json:"side"`
+	Volume         float64 `json:"volume"`
+	PriceOpen      float64 `json:"price_open,omitempty"`
+	PriceCurrent   float64 `json:"price_current,omitempty"`
+	SL             float64 `json:"sl,omitempty"`
+	TP             float64 `json:"tp,omitempty"`
+	Profit         float64 `json:"profit,omitempty"`
+	Swap           float64 `json:"swap,omitempty"`
+	Commission     float64 `json:"commission,omitempty"`
+	BaseCurrency   string  `json:"base_currency,omitempty"`
+	ProfitCurrency string  `json:"profit_currency,omitempty"`
+}
+
+type TradeStatus string
+
+const (
+	TradeStatusOpen   TradeStatus = "OPEN"
+	TradeStatusClosed TradeStatus = "CLOSED"
+)
+
+// Trade is the journal record of one position, from the OPEN command that
+// created it to the sync that no longer lists its ticket. StopLossPips and
+// TakeProfitPips are the distances requested at open; SL and TP are the
+// current absolute prices. CloseReason is how the broker closed the position
+// (SL, TP, SO, CLIENT, EXPERT, ...), taken from its closing deal.
+type Trade struct {
+	ID              string              `json:"id"`
+	AccountID       string              `json:"account_id"`
+	Symbol          string              `json:"symbol"`
+	Side            string              `json:"side"`
+	Volume          float64             `json:"volume"`
+	Status          TradeStatus         `json:"status"`
+	Ticket          string              `json:"ticket"`
+	OpenCommandID   string              `json:"open_command_id"`
+	CloseCommandID  string              `json:"close_command_id,omitempty"`
+	Strategy        string              `json:"strategy,omitempty"`
+	Reason          string              `json:"reason,omitempty"`
+	CloseReason     string              `json:"close_reason,omitempty"`
+	EntryPrice      float64             `json:"entry_price,omitempty"`
+	ExitPrice       float64             `json:"exit_price,omitempty"`
+	StopLossPips    float64             `json:"stop_loss_pips,omitempty"`
+	TakeProfitPips  float64             `json:"take_profit_pips,omitempty"`
+	SL              float64             `json:"sl,omitempty"`
+	TP              float64             `json:"tp,omitempty"`
+	Profit          float64             `json:"profit"`
+	RMultiple       float64             `json:"r_multiple"`
+	DurationSeconds int64               `json:"duration_seconds,omitempty"`
+	Modifications   []TradeModification `json:"modifications"`
+	OpenedAt        time.Time           `json:"opened_at"`
+	ClosedAt        time.Time           `json:"closed_at,omitempty"`
+	UpdatedAt       time.Time           `json:"updated_at"`
+}
+
+// TradeModification is one SL or TP change. Source is "command" for a
+// MOVE_SL/SET_TP result and "sync" for a change first seen in a snapshot.
+type TradeModification struct {
+	Field     string    `json:"field"`
+	Before    float64   `json:"before"`
+	After     float64   `json:"after"`
+	Source    string    `json:"source"`
+	CommandID string    `json:"command_id,omitempty"`
+	At        time.Time `json:"at"`
 }
 
+// TradeQuery filters journal listings. Zero fields match everything; From
+// and To bound the open time, ClosedFrom and ClosedTo the close time, which
+// only closed trades have.
+type TradeQuery struct {
+	AccountID  string
+	Symbol     string
+	Ticket     string
+	Strategy   string
+	Status     TradeStatus
+	From       time.Time
+	To         time.Time
+	ClosedFrom time.Time
+	ClosedTo   time.Time
+	Limit      int
+}
+
+// Deal is one broker deal from the EA's history sync. Entry is IN, OUT,
+// INOUT or OUT_BY and Reason how the deal was initiated (CLIENT, EXPERT, SL,
+// TP, SO, ...). Order is the order that produced the deal and PositionID the
+// ticket of the position it opened or closed. Time is broker server time.
+type Deal struct {
+	AccountID  string    `json:"account_id"`
+	Ticket     string    `json:"ticket"`
+	Order      string    `json:"order,omitempty"`
+	PositionID string    `json:"position_id,omitempty"`
+	Symbol     string    `json:"symbol"`
+	Side       string    `json:"side"`
+	Entry      string    `json:"entry"`
+	Reason     string    `json:"reason,omitempty"`
+	Volume     float64   `json:"volume"`
+	Price      float64   `json:"price"`
+	Profit     float64   `json:"profit"`
+	Swap       float64   `json:"swap,omitempty"`
+	Commission float64   `json:"commission,omitempty"`
+	Magic      int64     `json:"magic,omitempty"`
+	Comment    string    `json:"comment,omitempty"`
+	Time       time.Time `json:"time"`
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+// Opens reports whether the deal opened (or reversed into) a position.
+func (d Deal) Opens() bool {
+	return d.Entry == "IN" || d.Entry == "INOUT"
+}
+
+// Closes reports whether the deal closed some or all of a position.
+func (d Deal) Closes() bool {
+	return d.Entry == "OUT" || d.Entry == "OUT_BY" || d.Entry == "INOUT"
+}
+
+// DealQuery filters stored deals. Zero fields match everything; From and To
+// bound the deal time.
+type DealQuery struct {
+	AccountID string
+	Symbol    string
+	From      time.Time
+	To        time.Time
+	Limit     int
+}
+
+type ReconcileIssueKind string
+
+const (
+	ReconcileOrphanedPosition   ReconcileIssueKind = "orphaned_position"
+	ReconcileMissingFill        ReconcileIssueKind = "missing_fill"
+	ReconcileDuplicateExecution ReconcileIssueKind = "duplicate_execution"
+)
+
+// ReconcileIssue is a disagreement between the backend's commands and the
+// broker's deals and positions. ID is derived from the kind and the ticket
+// or command it concerns, so a repeated finding updates the same issue. An
+// issue is open until ResolvedAt is set.
+type ReconcileIssue struct {
+	ID         string             `json:"id"`
+	AccountID  string             `json:"account_id"`
+	Kind       ReconcileIssueKind `json:"kind"`
+	Ticket     string             `json:"ticket,omitempty"`
+	CommandID  string             `json:"command_id,omitempty"`
+	Symbol     string             `json:"symbol,omitempty"`
+	Detail     string             `json:"detail"`
+	DetectedAt time.Time          `json:"detected_at"`
+	ResolvedAt time.Time          `json:"resolved_at,omitempty"`
+}
+
+// Open reports whether the issue is still unresolved.
+func (i ReconcileIssue) Open() bool {
+	return i.ResolvedAt.IsZero()
+}
+
+// ReconcileQuery filters reconciliation issues. Status is "open",
+// "resolved" or empty for both.
+type ReconcileQuery struct {
+	AccountID string
+	Kind      ReconcileIssueKind
+	Status    string
+	Limit     int
+}
+
+type ReportKind string
+
+const (
+	ReportDaily  ReportKind = "daily"
+	ReportWeekly ReportKind = "weekly"
+)
+
+// Report summarizes an account's trading day or week. Period is the trading
+// day key for a daily report and the ISO week ("2026-W03") for a weekly one.
+// Trade figures cover journal trades closed in [From, To). SignalsDenied
+// counts proposed signals the risk engine denied; their reasons are also in
+// RiskTriggers, which additionally counts denials raised before a signal is
+// proposed. Money figures are in Currency, the account's currency when the
+// report was generated.
+type Report struct {
+	ID               string         `json:"id"`
+	AccountID        string         `json:"account_id"`
+	Kind             ReportKind     `json:"kind"`
+	Period           string         `json:"period"`
+	From             time.Time      `json:"from"`
+	To               time.Time      `json:"to"`
+	TradesOpened     int            `json:"trades_opened"`
+	TradesClosed     int            `json:"trades_closed"`
+	Wins             int            `json:"wins"`
+	Losses           int            `json:"losses"`
+	WinRate          float64        `json:"win_rate"`
+	NetProfit        float64        `json:"net_profit"`
+	ProfitFactor     float64        `json:"profit_factor"`
+	StartEquity      float64        `json:"start_equity"`
+	EndEquity        float64        `json:"end_equity"`
+	ReturnPct        float64        `json:"return_pct"`
+	MaxDrawdown      float64        `json:"max_drawdown"`
+	MaxDrawdownPct   float64        `json:"max_drawdown_pct"`
+	RiskTriggers     map[string]int `json:"risk_triggers"`
+	SignalsProposed  int            `json:"signals_proposed"`
+	SignalsDenied    int            `json:"signals_denied"`
+	DeliveryFailures int            `json:"delivery_failures"`
+	Currency         string         `json:"currency"`
+	CreatedAt        time.Time      `json:"created_at"`
+}
+
+// ReportQuery filters archived reports. Zero fields match everything; From
+// and To bound the period start.
+type ReportQuery struct {
+	AccountID string
+	Kind      ReportKind
+	From      time.Time
+	To        time.Time
+	Limit     int
+}
+
+// ExportQuery selects records to export. An empty AccountID matches every
+// account and a zero bound is open. The range is [From, To) on the record's
+// creation time: commands when queued, results when received, events when
+// emitted and trades when opened.
+type ExportQuery struct {
+	AccountID string
+	From      time.Time
+	To        time.Time
+}
+
+// Matches reports whether a record of the account created at t is selected.
+func (q ExportQuery) Matches(accountID string, t time.Time) bool {
+	if q.AccountID != "" && accountID != q.AccountID {
+		return false
+	}
+	return (q.From.IsZero() || !t.Before(q.From)) && (q.To.IsZero() || t.Before(q.To))
+}
+
+// EquityPoint is one sample of an account's equity curve. A raw point is a
+// single sync. A rollup point covers the bucket starting at Time: it holds
+// the bucket's last values, its equity range and the number of syncs.
+type EquityPoint struct {
+	AccountID     string    `json:"account_id"`
+	Resolution    string    `json:"resolution"`
+	Time          time.Time `json:"time"`
+	Equity        float64   `json:"equity"`
+	Balance       float64   `json:"balance"`
+	FloatingPnL   float64   `json:"floating_pnl"`
+	EquityHigh    float64   `json:"equity_high"`
+	EquityLow     float64   `json:"equity_low"`
+	OpenPositions int       `json:"open_positions"`
+	Samples       int       `json:"samples"`
+}
+
+// AccountRiskState is the equity history behind the per-account drawdown,
+// weekly and monthly loss breakers. A breaker pause stays until an admin
+// resumes the account.
+type AccountRiskState struct {
+	AccountID        string    `json:"account_id"`
+	PeakEquity       float64   `json:"peak_equity"`
+	PeakAt           time.Time `json:"peak_at"`
+	WeekStart        time.Time `json:"week_start"`
+	WeekStartEquity  float64   `json:"week_start_equity"`
+	MonthStart       time.Time `json:"month_start"`
+	MonthStartEquity float64   `json:"month_start_equity"`
+	LastEquity       float64   `json:"last_equity"`
+	Paused           bool      `json:"paused"`
+	PauseReason      string    `json:"pause_reason,omitempty"`
+	PausedAt         time.Time `json:"paused_at,omitempty"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// SymbolPause blocks new opens on a symbol after its fills slipped too far.
+// A zero Until means the pause holds until an admin resumes it. Fill
+// statistics for the slippage rule only count fills after ResumedAt.
+type SymbolPause struct {
+	Symbol          string    `json:"symbol"`
+	Paused          bool      `json:"paused"`
+	Reason          string    `json:"reason,omitempty"`
+	AvgSlippagePips float64   `json:"avg_slippage_pips,omitempty"`
+	Fills           int       `json:"fills,omitempty"`
+	PausedAt        time.Time `json:"paused_at,omitempty"`
+	Until           time.Time `json:"until,omitempty"`
+	ResumedAt       time.Time `json:"resumed_at,omitempty"`
+}
+
+// Active reports whether the pause still blocks opens at now.
+func (p SymbolPause) Active(now time.Time) bool {
+	return p.Paused && (p.Until.IsZero() || now.Before(p.Until))
+}
+
+// PauseOwner records what set the global pause, so an automatic resume
+// only lifts a pause it set. AccountID is empty for an admin pause.
+type PauseOwner struct {
+	Source    string    `json:"source"`
+	AccountID string    `json:"account_id,omitempty"`
+	At        time.Time `json:"at"`
+}
+
+// TradeActivity counts an account's executed OPENs for the current trading
+// day and its run of losing closes, for the streak and trades-per-day rules.
+type TradeActivity struct {
+	AccountID     string    `json:"account_id"`
+	Day           string    `json:"day"`
+	TradesToday   int       `json:"trades_today"`
+	LossStreak    int       `json:"loss_streak"`
+	CooldownUntil time.Time `json:"cooldown_until,omitempty"`
+	LastCloseAt   time.Time `json:"last_close_at,omitempty"`
+	// DayStartEquity is the first equity synced in the trading day.
+	DayStartEquity float64 `json:"day_start_equity,omitempty"`
+	// DailyBreakerAt is when the daily loss breaker paused the bot during
+	// the trading day.
+	DailyBreakerAt time.Time `json:"daily_breaker_at,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// RiskDecision is the outcome of one evaluation. DenyReason is the reason of
+// the first failed check; Checks lists every rule that was evaluated.
 type RiskDecision struct {
-	Allowed    bool   `json:"allowed"`
-	DenyReason string `json:"deny_reason,omitempty"`
+	Allowed    bool        `json:"allowed"`
+	DenyReason string      `json:"deny_reason,omitempty"`
+	Checks     []RiskCheck `json:"checks,omitempty"`
+}
+
+// RiskCheck is one rule's result. Observed and Threshold are numbers for
+// limit rules and flags, times or reasons for the others; Reason is the deny
+// reason the rule reports when it fails.
+type RiskCheck struct {
+	Rule      string      `json:"rule"`
+	Passed    bool        `json:"passed"`
+	Reason    string      `json:"reason,omitempty"`
+	Observed  interface{} `json:"observed,omitempty"`
+	Threshold interface{} `json:"threshold,omitempty"`
 }
@@ -2,18 +2,25 @@ package http
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"mmbot/internal/config"
+	"mmbot/internal/domain"
 	"mmbot/internal/integrations/openclaw"
 	"mmbot/internal/integrations/telegram"
 	"mmbot/internal/service/risk"
+	"mmbot/internal/service/schedule"
 	"mmbot/internal/store/memory"
 )
 
@@ -489,6 +496,2269 @@ func TestE2E_StrategyGuard_MaxCandles(t *testing.T) {
 	}
 }
 
+func TestE2E_StrategyCandleValidation(t *testing.T) {
+	cfg := config.Config{
+		AdminUsername:           "admin",
+		AdminPassword:           "pw",
+		JWTSecret:               "jwt-secret",
+		EAConnectCode:           "MMBOT-ONE-TIME-CODE",
+		EATokenTTL:              24 * time.Hour,
+		AIMinConfidence:         0.70,
+		MaxDailyLossPct:         2.0,
+		MaxOpenPositions:        3,
+		MaxSpreadPips:           2.0,
+		OpenAIAPIKey:            "sk-test",
+		OpenClawTimeout:         1 * time.Second,
+		StrategyRateLimitPerMin: 100,
+		StrategyDailyBudget:     100,
+		StrategyMaxCandles:      300,
+	}
+	store := memory.NewStore(24 * time.Hour)
+	srv := NewServer(
+		cfg,
+		store,
+		risk.NewEngine(cfg.MaxOpenPositions, cfg.MaxDailyLossPct, cfg.AIMinConfidence, cfg.MaxSpreadPips),
+		telegram.NewNotifier("", ""),
+		openclaw.NewClient("", time.Second, 0, 100*time.Millisecond, time.Second),
+	)
+	api := httptest.NewServer(srv.Router())
+	defer api.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	adminLoginResp := postJSON(t, client, api.URL+"/admin/login", map[string]string{
+		"username": "admin",
+		"password": "pw",
+	}, "")
+	adminToken := strField(t, adminLoginResp, "token")
+
+	candles := uptrendCandles(120)
+	candles[50]["high"] = candles[50]["low"].(float64) - 0.0001
+	status, body := postJSONStatus(t, client, api.URL+"/admin/strategy/evaluate", map[string]interface{}{
+		"account_id":  "paper-1",
+		"symbol":      "EURUSD",
+		"spread_pips": 1.0,
+		"candles":     candles,
+	}, adminToken)
+	if status != http.StatusBadRequest {
+		t.Fatalf("expected 400 for high below low, got %d body=%#v", status, body)
+	}
+	if issues, ok := body["candle_issues"].([]interface{}); !ok || len(issues) != 1 {
+		t.Fatalf("expected one reported candle issue, got %#v", body)
+	}
+
+	// Reversed and duplicated input is repaired and reported, not rejected.
+	candles = uptrendCandles(120)
+	reversed := make([]map[string]interface{}, 0, len(candles)+1)
+	for i := len(candles) - 1; i >= 0; i-- {
+		reversed = append(reversed, candles[i])
+	}
+	reversed = append(reversed, candles[60])
+	resp := postJSON(t, client, api.URL+"/admin/strategy/evaluate", map[string]interface{}{
+		"account_id":  "paper-1",
+		"symbol":      "EURUSD",
+		"spread_pips": 1.0,
+		"candles":     reversed,
+	}, adminToken)
+	if !boolField(resp, "allowed") {
+		t.Fatalf("expected normalised series to produce an allowed signal, got %#v", resp)
+	}
+	if issues, ok := resp["candle_issues"].([]interface{}); !ok || len(issues) != 2 {
+		t.Fatalf("expected unsorted and duplicate issues, got %#v", resp["candle_issues"])
+	}
+}
+
+func TestE2E_StrategyHigherTimeframeConfirmation(t *testing.T) {
+	cfg := config.Config{
+		AdminUsername:           "admin",
+		AdminPassword:           "pw",
+		JWTSecret:               "jwt-secret",
+		EAConnectCode:           "MMBOT-ONE-TIME-CODE",
+		EATokenTTL:              24 * time.Hour,
+		AIMinConfidence:         0.70,
+		MaxDailyLossPct:         2.0,
+		MaxOpenPositions:        3,
+		MaxSpreadPips:           2.0,
+		OpenAIAPIKey:            "sk-test",
+		OpenClawTimeout:         1 * time.Second,
+		StrategyRateLimitPerMin: 100,
+		StrategyDailyBudget:     100,
+		StrategyMaxCandles:      300,
+		StrategyHTFTimeframe:    "H4",
+	}
+	store := memory.NewStore(24 * time.Hour)
+	srv := NewServer(
+		cfg,
+		store,
+		risk.NewEngine(cfg.MaxOpenPositions, cfg.MaxDailyLossPct, cfg.AIMinConfidence, cfg.MaxSpreadPips),
+		telegram.NewNotifier("", ""),
+		openclaw.NewClient("", time.Second, 0, 100*time.Millisecond, time.Second),
+	)
+	api := httptest.NewServer(srv.Router())
+	defer api.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	adminLoginResp := postJSON(t, client, api.URL+"/admin/login", map[string]string{
+		"username": "admin",
+		"password": "pw",
+	}, "")
+	adminToken := strField(t, adminLoginResp, "token")
+	payload := map[string]interface{}{
+		"account_id":  "paper-1",
+		"symbol":      "EURUSD",
+		"spread_pips": 1.0,
+		"candles":     uptrendCandles(120),
+	}
+
+	// No stored H4 series yet: without confirmation no signal is taken.
+	status, body := postJSONStatus(t, client, api.URL+"/admin/strategy/evaluate", payload, adminToken)
+	if status != http.StatusOK || boolField(body, "has_signal") || !strings.HasPrefix(fmt.Sprint(body["reason"]), "insufficient HTF history") {
+		t.Fatalf("expected no signal for insufficient H4 history, got %d body=%#v", status, body)
+	}
+	// A short series the client sends is still rejected.
+	short := map[string]interface{}{
+		"account_id":    "paper-1",
+		"symbol":        "EURUSD",
+		"spread_pips":   1.0,
+		"candles":       uptrendCandles(120),
+		"htf_timeframe": "H4",
+		"htf_candles":   uptrendCandles(10),
+	}
+	if status, body := postJSONStatus(t, client, api.URL+"/admin/strategy/evaluate", short, adminToken); status != http.StatusBadRequest || !strings.Contains(fmt.Sprint(body["error"]), "51 H4 candles") {
+		t.Fatalf("expected 400 for short htf_candles, got %d body=%#v", status, body)
+	}
+
+	// A stored H4 downtrend vetoes the M15 long.
+	h4 := make([]domain.Candle, 0, 80)
+	for i := 0; i < 80; i++ {
+		close := 1.1500 - float64(i)*0.0010
+		h4 = append(h4, domain.Candle{
+			Time:  time.Unix(1700000000, 0).UTC().Add(time.Duration(i-80) * 4 * time.Hour),
+			Open:  close + 0.0005,
+			High:  close + 0.0010,
+			Low:   close - 0.0010,
+			Close: close,
+		})
+	}
+	store.SaveCandles("EURUSD", "H4", h4)
+	vetoed := postJSON(t, client, api.URL+"/admin/strategy/evaluate", payload, adminToken)
+	if boolField(vetoed, "has_signal") {
+		t.Fatalf("expected H4 downtrend to veto the long, got %#v", vetoed)
+	}
+	alignment, ok := vetoed["alignment"].(map[string]interface{})
+	if !ok || alignment["regime"] != "bearish" {
+		t.Fatalf("expected bearish alignment in response, got %#v", vetoed)
+	}
+}
+
+func TestE2E_SymbolRegistrySizingAndOverride(t *testing.T) {
+	cfg := config.Config{
+		AdminUsername:           "admin",
+		AdminPassword:           "pw",
+		JWTSecret:               "jwt-secret",
+		EAConnectCode:           "MMBOT-ONE-TIME-CODE",
+		EATokenTTL:              24 * time.Hour,
+		AIMinConfidence:         0.70,
+		MaxDailyLossPct:         2.0,
+		MaxOpenPositions:        3,
+		MaxSpreadPips:           2.0,
+		DefaultRiskPct:          1.0,
+		OpenAIAPIKey:            "sk-test",
+		OpenClawTimeout:         1 * time.Second,
+		StrategyRateLimitPerMin: 100,
+		StrategyDailyBudget:     100,
+	}
+	store := memory.NewStore(24 * time.Hour)
+	srv := NewServer(
+		cfg,
+		store,
+		risk.NewEngine(cfg.MaxOpenPositions, cfg.MaxDailyLossPct, cfg.AIMinConfidence, cfg.MaxSpreadPips),
+		telegram.NewNotifier("", ""),
+		openclaw.NewClient("", time.Second, 0, 100*time.Millisecond, time.Second),
+	)
+	api := httptest.NewServer(srv.Router())
+	defer api.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	adminToken := strField(t, postJSON(t, client, api.URL+"/admin/login", map[string]string{
+		"username": "admin",
+		"password": "pw",
+	}, ""), "token")
+	eaToken := strField(t, postJSON(t, client, api.URL+"/ea/register", map[string]string{
+		"connect_code": "MMBOT-ONE-TIME-CODE",
+		"account_id":   "paper-1",
+		"device_id":    "dev-1",
+	}, ""), "token")
+
+	specResp := postJSON(t, client, api.URL+"/ea/symbols", map[string]interface{}{
+		"symbols": []map[string]interface{}{
+			{
+				"symbol":        "EURUSD",
+				"digits":        5,
+				"point":         0.00001,
+				"tick_size":     0.00001,
+				"tick_value":    1.0,
+				"contract_size": 100000,
+				"volume_min":    0.01,
+				"volume_max":    50,
+				"volume_step":   0.01,
+				"stops_level":   10,
+				"sessions":      []map[string]interface{}{{"day": 1, "from": "00:00", "to": "24:00"}},
+			},
+			{"symbol": "BROKEN"},
+		},
+	}, eaToken)
+	if saved, _ := numField(specResp, "saved"); saved != 1 {
+		t.Fatalf("expected one spec saved and one rejected, got %#v", specResp)
+	}
+	entry := getJSON(t, client, api.URL+"/admin/symbols/eurusd", adminToken)
+	effective, _ := entry["effective"].(map[string]interface{})
+	if pip, _ := numField(effective, "pip_size"); pip != 0.0001 {
+		t.Fatalf("expected derived pip size 0.0001, got %#v", entry)
+	}
+
+	_ = postJSON(t, client, api.URL+"/ea/sync", map[string]interface{}{
+		"equity":             10000.0,
+		"balance":            10000.0,
+		"realized_pnl_today": 0.0,
+		"positions":          []interface{}{},
+	}, eaToken)
+
+	evalResp := postJSON(t, client, api.URL+"/admin/strategy/evaluate", map[string]interface{}{
+		"account_id":  "paper-1",
+		"symbol":      "EURUSD",
+		"spread_pips": 1.0,
+		"candles":     uptrendCandles(120),
+	}, adminToken)
+	cmd, ok := evalResp["command"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected queued command, got %#v", evalResp)
+	}
+	sl, _ := numField(cmd, "sl")
+	volume, _ := numField(cmd, "volume")
+	// 1% of 10000 over SL pips at $10 per pip per lot, rounded down to 0.01.
+	want := math.Floor(100/(sl*10)*100) / 100
+	if sl <= 0 || math.Abs(volume-want) > 1e-9 {
+		t.Fatalf("expected risk-sized volume %.2f for %.1f pip stop, got %v", want, sl, volume)
+	}
+
+	execResp := postJSON(t, client, api.URL+"/ea/execute", map[string]interface{}{}, eaToken)
+	if pip, _ := numField(execResp, "pip_size"); pip != 0.0001 {
+		t.Fatalf("expected pip_size in execute payload, got %#v", execResp)
+	}
+
+	status, overridden := requestJSONStatus(t, client, http.MethodPut, api.URL+"/admin/symbols/EURUSD", map[string]interface{}{
+		"volume_step": 0.1,
+	}, adminToken)
+	if status != http.StatusOK {
+		t.Fatalf("expected override to be accepted, got %d body=%#v", status, overridden)
+	}
+	effective, _ = overridden["effective"].(map[string]interface{})
+	if step, _ := numField(effective, "volume_step"); step != 0.1 || effective["source"] != domain.SymbolSpecSourceAdmin {
+		t.Fatalf("expected admin override to apply, got %#v", overridden)
+	}
+	if digits, _ := numField(effective, "digits"); digits != 5 {
+		t.Fatalf("expected reported fields to survive the override, got %#v", overridden)
+	}
+
+	status, _ = requestJSONStatus(t, client, http.MethodPut, api.URL+"/admin/symbols/US500", map[string]interface{}{
+		"volume_step": 0.1,
+	}, adminToken)
+	if status != http.StatusBadRequest {
+		t.Fatalf("expected incomplete override of an unreported symbol to be rejected, got %d", status)
+	}
+
+	if status, _ = requestJSONStatus(t, client, http.MethodDelete, api.URL+"/admin/symbols/EURUSD", nil, adminToken); status != http.StatusOK {
+		t.Fatalf("expected override delete to succeed, got %d", status)
+	}
+	if status, _ = requestJSONStatus(t, client, http.MethodDelete, api.URL+"/admin/symbols/EURUSD", nil, adminToken); status != http.StatusNotFound {
+		t.Fatalf("expected second delete to 404, got %d", status)
+	}
+	list := getJSON(t, client, api.URL+"/admin/symbols", adminToken)
+	if count, _ := numField(list, "count"); count != 1 {
+		t.Fatalf("expected one registered symbol, got %#v", list)
+	}
+}
+
+func TestE2E_StrategyEnsembleRecordsVotes(t *testing.T) {
+	cfg := config.Config{
+		AdminUsername:           "admin",
+		AdminPassword:           "pw",
+		JWTSecret:               "jwt-secret",
+		EAConnectCode:           "MMBOT-ONE-TIME-CODE",
+		EATokenTTL:              24 * time.Hour,
+		AIMinConfidence:         0.70,
+		MaxDailyLossPct:         2.0,
+		MaxOpenPositions:        3,
+		MaxSpreadPips:           2.0,
+		OpenAIAPIKey:            "sk-test",
+		OpenClawTimeout:         1 * time.Second,
+		StrategyRateLimitPerMin: 100,
+		StrategyDailyBudget:     100,
+		StrategyEnsemble:        "trend,breakout",
+		StrategyEnsembleMode:    "veto",
+	}
+	store := memory.NewStore(24 * time.Hour)
+	srv := NewServer(
+		cfg,
+		store,
+		risk.NewEngine(cfg.MaxOpenPositions, cfg.MaxDailyLossPct, cfg.AIMinConfidence, cfg.MaxSpreadPips),
+		telegram.NewNotifier("", ""),
+		openclaw.NewClient("", time.Second, 0, 100*time.Millisecond, time.Second),
+	)
+	api := httptest.NewServer(srv.Router())
+	defer api.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	adminToken := strField(t, postJSON(t, client, api.URL+"/admin/login", map[string]string{
+		"username": "admin",
+		"password": "pw",
+	}, ""), "token")
+
+	// The steady uptrend never closes above the prior 20-bar high, so the
+	// breakout member abstains and the trend member's long is not vetoed.
+	evalResp := postJSON(t, client, api.URL+"/admin/strategy/evaluate", map[string]interface{}{
+		"account_id":  "paper-1",
+		"symbol":      "EURUSD",
+		"spread_pips": 1.0,
+		"candles":     uptrendCandles(120),
+	}, adminToken)
+	if !boolField(evalResp, "allowed") {
+		t.Fatalf("expected ensemble long to be allowed, got %#v", evalResp)
+	}
+
+	var proposed *domain.Event
+	for _, evt := range store.ListEvents(20) {
+		if evt.Type == domain.EventSignalProposed {
+			evt := evt
+			proposed = &evt
+			break
+		}
+	}
+	if proposed == nil {
+		t.Fatal("expected a SignalProposed event")
+	}
+	votes, ok := proposed.Payload["votes"].([]domain.StrategyVote)
+	if !ok || len(votes) != 2 || proposed.Payload["strategy"] != "ensemble" {
+		t.Fatalf("expected two recorded votes from the ensemble, got %#v", proposed.Payload)
+	}
+	if !votes[0].HasSignal || votes[0].Strategy != "trend" || votes[1].HasSignal {
+		t.Fatalf("unexpected votes %+v", votes)
+	}
+}
+
+func TestE2E_RegimeRoutingEmitsChanges(t *testing.T) {
+	cfg := config.Config{
+		AdminUsername:           "admin",
+		AdminPassword:           "pw",
+		JWTSecret:               "jwt-secret",
+		EAConnectCode:           "MMBOT-ONE-TIME-CODE",
+		EATokenTTL:              24 * time.Hour,
+		AIMinConfidence:         0.70,
+		MaxDailyLossPct:         2.0,
+		MaxOpenPositions:        3,
+		MaxSpreadPips:           2.0,
+		OpenAIAPIKey:            "sk-test",
+		OpenClawTimeout:         1 * time.Second,
+		StrategyRateLimitPerMin: 100,
+		StrategyDailyBudget:     100,
+		StrategyMaxCandles:      300,
+		StrategyRegimeRouting:   true,
+		StrategyRegimeRoutes:    "trending=trend,ranging=range,volatile=breakout,quiet=none",
+	}
+	store := memory.NewStore(24 * time.Hour)
+	srv := NewServer(
+		cfg,
+		store,
+		risk.NewEngine(cfg.MaxOpenPositions, cfg.MaxDailyLossPct, cfg.AIMinConfidence, cfg.MaxSpreadPips),
+		telegram.NewNotifier("", ""),
+		openclaw.NewClient("", time.Second, 0, 100*time.Millisecond, time.Second),
+	)
+	api := httptest.NewServer(srv.Router())
+	defer api.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	adminToken := strField(t, postJSON(t, client, api.URL+"/admin/login", map[string]string{
+		"username": "admin",
+		"password": "pw",
+	}, ""), "token")
+
+	trendResp := postJSON(t, client, api.URL+"/admin/strategy/evaluate", map[string]interface{}{
+		"account_id":  "paper-1",
+		"symbol":      "EURUSD",
+		"spread_pips": 1.0,
+		"candles":     uptrendCandles(120),
+	}, adminToken)
+	reg, _ := trendResp["regime"].(map[string]interface{})
+	if reg["regime"] != "trending" || !boolField(trendResp, "allowed") {
+		t.Fatalf("expected the trending regime to route to the trend engine, got %#v", trendResp)
+	}
+
+	flat := make([]map[string]interface{}, 0, 80)
+	for i := 0; i < 80; i++ {
+		close := 1.1000 + 0.0005*float64(i%2*2-1)
+		flat = append(flat, map[string]interface{}{
+			"time":  time.Unix(int64(1700000000+(200+i)*900), 0).UTC().Format(time.RFC3339),
+			"open":  close,
+			"high":  close + 0.0003,
+			"low":   close - 0.0008,
+			"close": close,
+		})
+	}
+	rangeResp := postJSON(t, client, api.URL+"/admin/strategy/evaluate", map[string]interface{}{
+		"account_id":  "paper-1",
+		"symbol":      "EURUSD",
+		"spread_pips": 1.0,
+		"candles":     flat,
+	}, adminToken)
+	reg, _ = rangeResp["regime"].(map[string]interface{})
+	if reg["regime"] != "ranging" || boolField(rangeResp, "has_signal") {
+		t.Fatalf("expected a ranging regime without a fade setup, got %#v", rangeResp)
+	}
+
+	changed := false
+	for _, evt := range store.ListEvents(20) {
+		if evt.Type == domain.EventRegimeChanged && evt.Payload["from"] == "trending" && evt.Payload["to"] == "ranging" {
+			changed = true
+		}
+	}
+	if !changed {
+		t.Fatalf("expected a RegimeChanged event, got %#v", store.ListEvents(20))
+	}
+
+	current := getJSON(t, client, api.URL+"/admin/regime?symbol=EURUSD", adminToken)
+	if current["strategy"] != "range" {
+		t.Fatalf("expected stored series to route to range, got %#v", current)
+	}
+	list := getJSON(t, client, api.URL+"/admin/regime", adminToken)
+	if count, _ := numField(list, "count"); count != 1 {
+		t.Fatalf("expected one tracked series, got %#v", list)
+	}
+}
+
+func TestE2E_CorrelatedExposureLimit(t *testing.T) {
+	cfg := config.Config{
+		AdminUsername:    "admin",
+		AdminPassword:    "pw",
+		JWTSecret:        "jwt-secret",
+		EAConnectCode:    "MMBOT-ONE-TIME-CODE",
+		EATokenTTL:       24 * time.Hour,
+		MaxDailyLossPct:  2.0,
+		MaxOpenPositions: 5,
+		MaxSpreadPips:    2.0,
+		OpenAIAPIKey:     "sk-test",
+		OpenClawTimeout:  time.Second,
+	}
+	store := memory.NewStore(24 * time.Hour)
+	srv := NewServer(
+		cfg,
+		store,
+		risk.NewEngine(cfg.MaxOpenPositions, cfg.MaxDailyLossPct, 0.70, cfg.MaxSpreadPips).
+			WithExposureLimits(risk.ExposureLimits{MaxPositionsPerSymbol: 2, MaxCorrelatedPositions: 2}),
+		telegram.NewNotifier("", ""),
+		openclaw.NewClient("", time.Second, 0, 100*time.Millisecond, time.Second),
+	)
+	api := httptest.NewServer(srv.Router())
+	defer api.Close()
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	adminToken := strField(t, postJSON(t, client, api.URL+"/admin/login", map[string]string{
+		"username": "admin",
+		"password": "pw",
+	}, ""), "token")
+	eaToken := strField(t, postJSON(t, client, api.URL+"/ea/register", map[string]string{
+		"connect_code": "MMBOT-ONE-TIME-CODE",
+		"account_id":   "paper-1",
+		"device_id":    "dev-1",
+	}, ""), "token")
+	_ = postJSON(t, client, api.URL+"/ea/sync", map[string]interface{}{
+		"equity":    10000.0,
+		"daily_pnl": 0.0,
+		"positions": []interface{}{
+			map[string]interface{}{"ticket": 1, "symbol": "EURUSD", "side": "BUY", "volume": 0.1},
+			map[string]interface{}{"ticket": 2, "symbol": "EURJPY", "side": "BUY", "volume": 0.1},
+		},
+	}, eaToken)
+
+	signal := func(side string) map[string]interface{} {
+		return postJSON(t, client, api.URL+"/admin/signals/evaluate", map[string]interface{}{
+			"account_id":       "paper-1",
+			"symbol":           "EURGBP",
+			"side":             side,
+			"confidence":       0.9,
+			"spread_pips":      1.0,
+			"stop_loss_pips":   10,
+			"take_profit_pips": 20,
+		}, adminToken)
+	}
+	if resp := signal("BUY"); boolField(resp, "allowed") || resp["deny_reason"] != "correlated_positions_limit_reached" {
+		t.Fatalf("expected third EUR long to be denied as correlated, got %#v", resp)
+	}
+	if resp := signal("SELL"); !boolField(resp, "allowed") {
+		t.Fatalf("expected EUR short to pass the exposure rules, got %#v", resp)
+	}
+}
+
+func TestE2E_DrawdownBreakerRequiresManualResume(t *testing.T) {
+	cfg := config.Config{
+		AdminUsername:    "admin",
+		AdminPassword:    "pw",
+		JWTSecret:        "jwt-secret",
+		EAConnectCode:    "MMBOT-ONE-TIME-CODE",
+		EATokenTTL:       24 * time.Hour,
+		MaxDailyLossPct:  2.0,
+		MaxOpenPositions: 3,
+		MaxSpreadPips:    2.0,
+		MaxDrawdownPct:   10.0,
+		OpenAIAPIKey:     "sk-test",
+		OpenClawTimeout:  time.Second,
+	}
+	store := memory.NewStore(24 * time.Hour)
+	srv := NewServer(
+		cfg,
+		store,
+		risk.NewEngine(cfg.MaxOpenPositions, cfg.MaxDailyLossPct, 0.70, cfg.MaxSpreadPips),
+		telegram.NewNotifier("", ""),
+		openclaw.NewClient("", time.Second, 0, 100*time.Millisecond, time.Second),
+	)
+	api := httptest.NewServer(srv.Router())
+	defer api.Close()
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	adminToken := strField(t, postJSON(t, client, api.URL+"/admin/login", map[string]string{
+		"username": "admin",
+		"password": "pw",
+	}, ""), "token")
+	eaToken := strField(t, postJSON(t, client, api.URL+"/ea/register", map[string]string{
+		"connect_code": "MMBOT-ONE-TIME-CODE",
+		"account_id":   "paper-1",
+		"device_id":    "dev-1",
+	}, ""), "token")
+	sync := func(equity float64) map[string]interface{} {
+		return postJSON(t, client, api.URL+"/ea/sync", map[string]interface{}{
+			"equity":    equity,
+			"daily_pnl": 0.0,
+			"positions": []interface{}{},
+		}, eaToken)
+	}
+	signal := func() map[string]interface{} {
+		return postJSON(t, client, api.URL+"/admin/signals/evaluate", map[string]interface{}{
+			"account_id":     "paper-1",
+			"symbol":         "EURUSD",
+			"side":           "BUY",
+			"confidence":     0.9,
+			"spread_pips":    1.0,
+			"stop_loss_pips": 10,
+		}, adminToken)
+	}
+
+	_ = sync(10000)
+	_ = sync(11000)
+	if resp := sync(10000); boolField(resp, "triggered_circuit_breaker") {
+		t.Fatalf("expected 9.1%% drawdown to stay under the limit, got %#v", resp)
+	}
+	resp := sync(9800)
+	if !boolField(resp, "triggered_circuit_breaker") || !boolField(resp, "account_paused") {
+		t.Fatalf("expected drawdown breaker to pause the account, got %#v", resp)
+	}
+	if dd, _ := numField(resp, "drawdown_pct"); dd < 10.9 || dd > 10.91 {
+		t.Fatalf("expected ~10.9%% drawdown, got %#v", resp)
+	}
+	if heartbeat := postJSON(t, client, api.URL+"/ea/heartbeat", map[string]interface{}{}, eaToken); !boolField(heartbeat, "paused") {
+		t.Fatalf("expected heartbeat to report the account pause, got %#v", heartbeat)
+	}
+
+	// Recovering equity does not lift the pause by itself.
+	_ = sync(10600)
+	if resp := signal(); boolField(resp, "allowed") || resp["deny_reason"] != "account_paused" {
+		t.Fatalf("expected account_paused until manual resume, got %#v", resp)
+	}
+
+	events := getJSON(t, client, api.URL+"/events?limit=50", adminToken)
+	found := false
+	var pauseSource interface{}
+	for _, raw := range events["events"].([]interface{}) {
+		evt := raw.(map[string]interface{})
+		payload, _ := evt["payload"].(map[string]interface{})
+		if evt["event_type"] == string(domain.EventRiskTriggered) && payload["reason"] == "max_drawdown_limit_hit" {
+			found = true
+		}
+		if evt["event_type"] == string(domain.EventBotPaused) && payload["reason"] == "max_drawdown_limit_hit" {
+			pauseSource = payload["source"]
+		}
+	}
+	if !found {
+		t.Fatalf("expected RiskTriggered max_drawdown_limit_hit event, got %#v", events)
+	}
+	if pauseSource != "account_breaker" {
+		t.Fatalf("expected the account breaker's own pause source, got %v", pauseSource)
+	}
+
+	_ = sync(9700)
+	status, resumed := requestJSONStatus(t, client, http.MethodPost, api.URL+"/admin/accounts/paper-1/resume", nil, adminToken)
+	if status != http.StatusOK {
+		t.Fatalf("expected resume to succeed, got %d body=%#v", status, resumed)
+	}
+	state, _ := resumed["state"].(map[string]interface{})
+	if peak, _ := numField(state, "peak_equity"); peak != 9700 {
+		t.Fatalf("expected tripped peak to rebase to last equity, got %#v", resumed)
+	}
+	if resp := sync(9650); boolField(resp, "triggered_circuit_breaker") {
+		t.Fatalf("expected no re-trigger after resume, got %#v", resp)
+	}
+	if resp := signal(); !boolField(resp, "allowed") {
+		t.Fatalf("expected trading to resume, got %#v", resp)
+	}
+	if status, _ := requestJSONStatus(t, client, http.MethodPost, api.URL+"/admin/accounts/paper-1/resume", nil, adminToken); status != http.StatusConflict {
+		t.Fatalf("expected resuming an unpaused account to conflict, got %d", status)
+	}
+}
+
+func TestE2E_LossStreakCooldownAndTradesPerDay(t *testing.T) {
+	cfg := config.Config{
+		AdminUsername:    "admin",
+		AdminPassword:    "pw",
+		JWTSecret:        "jwt-secret",
+		EAConnectCode:    "MMBOT-ONE-TIME-CODE",
+		EATokenTTL:       24 * time.Hour,
+		MaxDailyLossPct:  5.0,
+		MaxOpenPositions: 5,
+		MaxSpreadPips:    2.0,
+		OpenAIAPIKey:     "sk-test",
+		OpenClawTimeout:  time.Second,
+	}
+	newAPI := func(limits risk.StreakLimits) *httptest.Server {
+		srv := NewServer(
+			cfg,
+			memory.NewStore(24*time.Hour),
+			risk.NewEngine(cfg.MaxOpenPositions, cfg.MaxDailyLossPct, 0.70, cfg.MaxSpreadPips).WithStreakLimits(limits),
+			telegram.NewNotifier("", ""),
+			openclaw.NewClient("", time.Second, 0, 100*time.Millisecond, time.Second),
+		)
+		return httptest.NewServer(srv.Router())
+	}
+	client := &http.Client{Timeout: 5 * time.Second}
+	login := func(api *httptest.Server) (string, string) {
+		adminToken := strField(t, postJSON(t, client, api.URL+"/admin/login", map[string]string{
+			"username": "admin",
+			"password": "pw",
+		}, ""), "token")
+		eaToken := strField(t, postJSON(t, client, api.URL+"/ea/register", map[string]string{
+			"connect_code": "MMBOT-ONE-TIME-CODE",
+			"account_id":   "paper-1",
+			"device_id":    "dev-1",
+		}, ""), "token")
+		return adminToken, eaToken
+	}
+	signal := func(api *httptest.Server, adminToken string) map[string]interface{} {
+		return postJSON(t, client, api.URL+"/admin/signals/evaluate", map[string]interface{}{
+			"account_id":     "paper-1",
+			"symbol":         "EURUSD",
+			"side":           "BUY",
+			"confidence":     0.9,
+			"spread_pips":    1.0,
+			"stop_loss_pips": 10,
+		}, adminToken)
+	}
+	hasEvent := func(api *httptest.Server, adminToken string, eventType domain.EventType) bool {
+		events := getJSON(t, client, api.URL+"/events?limit=50", adminToken)
+		for _, raw := range events["events"].([]interface{}) {
+			if raw.(map[string]interface{})["event_type"] == string(eventType) {
+				return true
+			}
+		}
+		return false
+	}
+
+	t.Run("loss streak", func(t *testing.T) {
+		api := newAPI(risk.StreakLimits{MaxConsecutiveLosses: 2, LossCooldown: time.Hour})
+		defer api.Close()
+		adminToken, eaToken := login(api)
+		sync := func(positions ...map[string]interface{}) {
+			_ = postJSON(t, client, api.URL+"/ea/sync", map[string]interface{}{
+				"equity":    10000.0,
+				"daily_pnl": 0.0,
+				"positions": append([]map[string]interface{}{}, positions...),
+			}, eaToken)
+		}
+		sync(
+			map[string]interface{}{"ticket": 11, "symbol": "EURUSD", "side": "BUY", "volume": 0.1, "profit": -8.0},
+			map[string]interface{}{"ticket": 12, "symbol": "GBPUSD", "side": "BUY", "volume": 0.1, "profit": -6.0},
+		)
+		sync(map[string]interface{}{"ticket": 12, "symbol": "GBPUSD", "side": "BUY", "volume": 0.1, "profit": -7.0})
+		if resp := signal(api, adminToken); !boolField(resp, "allowed") {
+			t.Fatalf("expected one loss to leave trading open, got %#v", resp)
+		}
+		sync()
+		if resp := signal(api, adminToken); boolField(resp, "allowed") || resp["deny_reason"] != "loss_streak_cooldown_active" {
+			t.Fatalf("expected loss_streak_cooldown_active after two losses, got %#v", resp)
+		}
+		if !hasEvent(api, adminToken, domain.EventLossStreakCooldown) {
+			t.Fatal("expected LossStreakCooldown event")
+		}
+	})
+
+	t.Run("trades per day", func(t *testing.T) {
+		api := newAPI(risk.StreakLimits{MaxTradesPerDay: 1})
+		defer api.Close()
+		adminToken, eaToken := login(api)
+		resp := signal(api, adminToken)
+		cmd, ok := resp["command"].(map[string]interface{})
+		if !ok {
+			t.Fatalf("expected first signal to queue a command, got %#v", resp)
+		}
+		// A queued but unexecuted OPEN does not count.
+		if resp := signal(api, adminToken); !boolField(resp, "allowed") {
+			t.Fatalf("expected second signal to pass before any fill, got %#v", resp)
+		}
+		_ = postJSON(t, client, api.URL+"/ea/result", map[string]interface{}{
+			"command_id":    strField(t, cmd, "command_id"),
+			"status":        "SUCCESS",
+			"broker_ticket": "501",
+		}, eaToken)
+		if resp := signal(api, adminToken); boolField(resp, "allowed") || resp["deny_reason"] != "max_trades_per_day_reached" {
+			t.Fatalf("expected max_trades_per_day_reached after one fill, got %#v", resp)
+		}
+		if !hasEvent(api, adminToken, domain.EventDailyTradeLimit) {
+			t.Fatal("expected DailyTradeLimitReached event")
+		}
+	})
+}
+
+func TestE2E_NewsBlackoutCalendar(t *testing.T) {
+	cfg := config.Config{
+		AdminUsername:    "admin",
+		AdminPassword:    "pw",
+		JWTSecret:        "jwt-secret",
+		EAConnectCode:    "MMBOT-ONE-TIME-CODE",
+		EATokenTTL:       24 * time.Hour,
+		MaxDailyLossPct:  2.0,
+		MaxOpenPositions: 5,
+		MaxSpreadPips:    2.0,
+		OpenAIAPIKey:     "sk-test",
+		OpenClawTimeout:  time.Second,
+	}
+	store := memory.NewStore(24 * time.Hour)
+	gate := &schedule.Gate{Before: 15 * time.Minute, After: 15 * time.Minute, Blackouts: store}
+	srv := NewServer(
+		cfg,
+		store,
+		risk.NewEngine(cfg.MaxOpenPositions, cfg.MaxDailyLossPct, 0.70, cfg.MaxSpreadPips).WithTimeGate(gate),
+		telegram.NewNotifier("", ""),
+		openclaw.NewClient("", time.Second, 0, 100*time.Millisecond, time.Second),
+	)
+	api := httptest.NewServer(srv.Router())
+	defer api.Close()
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	adminToken := strField(t, postJSON(t, client, api.URL+"/admin/login", map[string]string{
+		"username": "admin",
+		"password": "pw",
+	}, ""), "token")
+	signal := func(symbol string) map[string]interface{} {
+		return postJSON(t, client, api.URL+"/admin/signals/evaluate", map[string]interface{}{
+			"account_id":     "paper-1",
+			"symbol":         symbol,
+			"side":           "BUY",
+			"confidence":     0.9,
+			"spread_pips":    1.0,
+			"stop_loss_pips": 10,
+		}, adminToken)
+	}
+
+	now := time.Now().UTC()
+	created := postJSON(t, client, api.URL+"/admin/blackouts", map[string]interface{}{
+		"title":    "CPI",
+		"currency": "usd",
+		"impact":   "high",
+		"start":    now.Add(10 * time.Minute).Format(time.RFC3339),
+	}, adminToken)
+	if resp := signal("EURUSD"); boolField(resp, "allowed") || resp["deny_reason"] != "news_blackout" {
+		t.Fatalf("expected EURUSD inside the padded USD blackout to be denied, got %#v", resp)
+	}
+	if resp := signal("EURGBP"); !boolField(resp, "allowed") {
+		t.Fatalf("expected EURGBP to be unaffected by a USD blackout, got %#v", resp)
+	}
+
+	// Importing the same feed twice keeps one entry per UID.
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"UID:boe-now",
+		"DTSTART:" + now.Add(-5*time.Minute).Format("20060102T150405Z"),
+		"DTEND:" + now.Add(5*time.Minute).Format("20060102T150405Z"),
+		"SUMMARY:BoE rate decision",
+		"CATEGORIES:GBP",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequest(http.MethodPost, api.URL+"/admin/blackouts/import", strings.NewReader(ics))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		req.Header.Set("Content-Type", "text/calendar")
+		res, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expected ICS import to succeed, got %d", res.StatusCode)
+		}
+	}
+	listed := getJSON(t, client, api.URL+"/admin/blackouts", adminToken)
+	if count, _ := numField(listed, "count"); count != 2 {
+		t.Fatalf("expected 2 blackouts after re-import, got %#v", listed)
+	}
+	if resp := signal("EURGBP"); boolField(resp, "allowed") || resp["deny_reason"] != "news_blackout" {
+		t.Fatalf("expected EURGBP to be denied during the imported GBP event, got %#v", resp)
+	}
+
+	status, _ := requestJSONStatus(t, client, http.MethodDelete, api.URL+"/admin/blackouts/"+strField(t, created, "id"), nil, adminToken)
+	if status != http.StatusOK {
+		t.Fatalf("expected delete to succeed, got %d", status)
+	}
+	if resp := signal("EURUSD"); !boolField(resp, "allowed") {
+		t.Fatalf("expected EURUSD to trade after the blackout was removed, got %#v", resp)
+	}
+}
+
+func TestE2E_RiskDecisionListsEveryCheck(t *testing.T) {
+	cfg := config.Config{
+		AdminUsername:    "admin",
+		AdminPassword:    "pw",
+		JWTSecret:        "jwt-secret",
+		EAConnectCode:    "MMBOT-ONE-TIME-CODE",
+		EATokenTTL:       24 * time.Hour,
+		MaxDailyLossPct:  2.0,
+		MaxOpenPositions: 3,
+		MaxSpreadPips:    2.0,
+		OpenAIAPIKey:     "sk-test",
+		OpenClawTimeout:  time.Second,
+	}
+	srv := NewServer(
+		cfg,
+		memory.NewStore(24*time.Hour),
+		risk.NewEngine(cfg.MaxOpenPositions, cfg.MaxDailyLossPct, 0.70, cfg.MaxSpreadPips),
+		telegram.NewNotifier("", ""),
+		openclaw.NewClient("", time.Second, 0, 100*time.Millisecond, time.Second),
+	)
+	api := httptest.NewServer(srv.Router())
+	defer api.Close()
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	adminToken := strField(t, postJSON(t, client, api.URL+"/admin/login", map[string]string{
+		"username": "admin",
+		"password": "pw",
+	}, ""), "token")
+	resp := postJSON(t, client, api.URL+"/admin/signals/evaluate", map[string]interface{}{
+		"account_id":     "paper-1",
+		"symbol":         "EURUSD",
+		"side":           "BUY",
+		"confidence":     0.4,
+		"spread_pips":    5.0,
+		"stop_loss_pips": 10,
+	}, adminToken)
+	if boolField(resp, "allowed") || resp["deny_reason"] != "spread_too_high" {
+		t.Fatalf("expected spread_too_high to stay the deny reason, got %#v", resp)
+	}
+	failed, _ := resp["failed_reasons"].([]interface{})
+	if len(failed) != 2 || failed[0] != "spread_too_high" || failed[1] != "ai_confidence_too_low" {
+		t.Fatalf("expected both failures, got %#v", resp["failed_reasons"])
+	}
+	checks, _ := resp["checks"].([]interface{})
+	var spread map[string]interface{}
+	for _, raw := range checks {
+		if c := raw.(map[string]interface{}); c["rule"] == "spread" {
+			spread = c
+		}
+	}
+	if spread == nil || boolField(spread, "passed") || spread["observed"] != 5.0 || spread["threshold"] != 2.0 {
+		t.Fatalf("expected spread check with observed value and threshold, got %#v", checks)
+	}
+
+	events := getJSON(t, client, api.URL+"/events?limit=20", adminToken)
+	found := false
+	for _, raw := range events["events"].([]interface{}) {
+		evt := raw.(map[string]interface{})
+		if evt["event_type"] != string(domain.EventRiskTriggered) {
+			continue
+		}
+		payload, _ := evt["payload"].(map[string]interface{})
+		if got, _ := payload["checks"].([]interface{}); len(got) == len(checks) {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("expected RiskTriggered to carry the full check list")
+	}
+
+	allowed := postJSON(t, client, api.URL+"/admin/signals/evaluate", map[string]interface{}{
+		"account_id":     "paper-1",
+		"symbol":         "EURUSD",
+		"side":           "BUY",
+		"confidence":     0.9,
+		"spread_pips":    1.0,
+		"stop_loss_pips": 10,
+	}, adminToken)
+	if !boolField(allowed, "allowed") {
+		t.Fatalf("expected valid signal to pass, got %#v", allowed)
+	}
+	if got, _ := allowed["checks"].([]interface{}); len(got) == 0 {
+		t.Fatalf("expected allowed decisions to list their checks, got %#v", allowed)
+	}
+}
+
+func TestE2E_RiskPolicyReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "risk_policy.json")
+	writePolicy := func(raw string) {
+		if err := os.WriteFile(path, []byte(raw), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writePolicy(`{"defaults": {"max_spread_pips": 2.0}}`)
+	cfg := config.Config{
+		AdminUsername:    "admin",
+		AdminPassword:    "pw",
+		JWTSecret:        "jwt-secret",
+		EAConnectCode:    "MMBOT-ONE-TIME-CODE",
+		EATokenTTL:       24 * time.Hour,
+		MaxDailyLossPct:  2.0,
+		MaxOpenPositions: 3,
+		MaxSpreadPips:    2.0,
+		OpenAIAPIKey:     "sk-test",
+		OpenClawTimeout:  time.Second,
+		RiskPolicyFile:   path,
+	}
+	policy, err := risk.LoadPolicy(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := NewServer(
+		cfg,
+		memory.NewStore(24*time.Hour),
+		risk.NewEngine(cfg.MaxOpenPositions, cfg.MaxDailyLossPct, 0.70, cfg.MaxSpreadPips).WithPolicy(policy),
+		telegram.NewNotifier("", ""),
+		openclaw.NewClient("", time.Second, 0, 100*time.Millisecond, time.Second),
+	)
+	api := httptest.NewServer(srv.Router())
+	defer api.Close()
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	adminToken := strField(t, postJSON(t, client, api.URL+"/admin/login", map[string]string{
+		"username": "admin",
+		"password": "pw",
+	}, ""), "token")
+	signal := func(accountID string) map[string]interface{} {
+		return postJSON(t, client, api.URL+"/admin/signals/evaluate", map[string]interface{}{
+			"account_id":     accountID,
+			"symbol":         "EURUSD",
+			"side":           "BUY",
+			"confidence":     0.9,
+			"spread_pips":    1.5,
+			"stop_loss_pips": 10,
+		}, adminToken)
+	}
+	if resp := signal("live-1"); !boolField(resp, "allowed") {
+		t.Fatalf("expected signal to pass before the reload, got %#v", resp)
+	}
+
+	writePolicy(`{"defaults": {"max_spread_pips": 2.0}, "accounts": {"live-1": {"max_spread_pips": 1.0}}}`)
+	status, reloaded := postJSONStatus(t, client, api.URL+"/admin/risk/policy/reload", map[string]interface{}{}, adminToken)
+	if status != http.StatusOK {
+		t.Fatalf("expected reload to succeed, got %d %#v", status, reloaded)
+	}
+	if changes, _ := reloaded["changes"].([]interface{}); len(changes) != 1 {
+		t.Fatalf("expected one change, got %#v", reloaded)
+	}
+	if resp := signal("live-1"); boolField(resp, "allowed") || resp["deny_reason"] != "spread_too_high" {
+		t.Fatalf("expected the account override to deny, got %#v", resp)
+	}
+	if resp := signal("paper-1"); !boolField(resp, "allowed") {
+		t.Fatalf("expected other accounts to keep the default, got %#v", resp)
+	}
+	effective := getJSON(t, client, api.URL+"/admin/risk/policy?account_id=live-1&symbol=EURUSD", adminToken)
+	if eff, _ := effective["effective"].(map[string]interface{}); eff["max_spread_pips"] != 1.0 {
+		t.Fatalf("expected effective thresholds for live-1, got %#v", effective)
+	}
+
+	events := getJSON(t, client, api.URL+"/events?limit=50", adminToken)
+	var audit map[string]interface{}
+	for _, raw := range events["events"].([]interface{}) {
+		if evt := raw.(map[string]interface{}); evt["event_type"] == string(domain.EventRiskPolicyChanged) {
+			audit, _ = evt["payload"].(map[string]interface{})
+		}
+	}
+	changes, _ := audit["changes"].([]interface{})
+	if audit["source"] != "admin" || len(changes) != 1 {
+		t.Fatalf("expected RiskPolicyChanged with the diff, got %#v", audit)
+	}
+	if change := changes[0].(map[string]interface{}); change["scope"] != "accounts.live-1" || change["before"] != nil || change["after"] != 1.0 {
+		t.Fatalf("unexpected diff entry %#v", change)
+	}
+
+	// An invalid file is rejected and the loaded policy stays in force.
+	writePolicy(`{"defaults": {"max_spread_pips": -1}}`)
+	if status, _ := postJSONStatus(t, client, api.URL+"/admin/risk/policy/reload", map[string]interface{}{}, adminToken); status != http.StatusBadRequest {
+		t.Fatalf("expected invalid policy to be rejected, got %d", status)
+	}
+	if resp := signal("live-1"); boolField(resp, "allowed") {
+		t.Fatalf("expected previous policy to remain after a failed reload, got %#v", resp)
+	}
+
+	// The /ea/sync daily loss breaker uses the account's effective limit.
+	writePolicy(`{"accounts": {"live-1": {"max_daily_loss_pct": 5.0}}}`)
+	if status, _ := postJSONStatus(t, client, api.URL+"/admin/risk/policy/reload", map[string]interface{}{}, adminToken); status != http.StatusOK {
+		t.Fatalf("expected reload to succeed, got %d", status)
+	}
+	eaToken := strField(t, postJSON(t, client, api.URL+"/ea/register", map[string]string{
+		"connect_code": "MMBOT-ONE-TIME-CODE",
+		"account_id":   "live-1",
+		"device_id":    "dev-1",
+	}, ""), "token")
+	synced := postJSON(t, client, api.URL+"/ea/sync", map[string]interface{}{
+		"equity":    9700.0,
+		"daily_pnl": -300.0,
+	}, eaToken)
+	if loss, _ := numField(synced, "daily_loss_pct"); loss < cfg.MaxDailyLossPct || boolField(synced, "triggered_circuit_breaker") {
+		t.Fatalf("expected the account limit to keep the breaker off, got %#v", synced)
+	}
+}
+
+func TestE2E_MarginLevelFloor(t *testing.T) {
+	cfg := config.Config{
+		AdminUsername:    "admin",
+		AdminPassword:    "pw",
+		JWTSecret:        "jwt-secret",
+		EAConnectCode:    "MMBOT-ONE-TIME-CODE",
+		EATokenTTL:       24 * time.Hour,
+		MaxDailyLossPct:  2.0,
+		MaxOpenPositions: 5,
+		MaxSpreadPips:    2.0,
+		OpenAIAPIKey:     "sk-test",
+		OpenClawTimeout:  time.Second,
+	}
+	srv := NewServer(
+		cfg,
+		memory.NewStore(24*time.Hour),
+		risk.NewEngine(cfg.MaxOpenPositions, cfg.MaxDailyLossPct, 0.70, cfg.MaxSpreadPips).WithMarginFloor(200),
+		telegram.NewNotifier("", ""),
+		openclaw.NewClient("", time.Second, 0, 100*time.Millisecond, time.Second),
+	)
+	api := httptest.NewServer(srv.Router())
+	defer api.Close()
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	adminToken := strField(t, postJSON(t, client, api.URL+"/admin/login", map[string]string{
+		"username": "admin",
+		"password": "pw",
+	}, ""), "token")
+	eaToken := strField(t, postJSON(t, client, api.URL+"/ea/register", map[string]string{
+		"connect_code": "MMBOT-ONE-TIME-CODE",
+		"account_id":   "paper-1",
+		"device_id":    "dev-1",
+	}, ""), "token")
+	_ = postJSON(t, client, api.URL+"/ea/symbols", map[string]interface{}{
+		"symbols": []map[string]interface{}{{
+			"symbol":         "EURUSD",
+			"digits":         5,
+			"point":          0.00001,
+			"tick_size":      0.00001,
+			"tick_value":     1.0,
+			"contract_size":  100000,
+			"volume_min":     0.01,
+			"volume_max":     50,
+			"volume_step":    0.01,
+			"margin_per_lot": 1000.0,
+		}},
+	}, eaToken)
+	synced := postJSON(t, client, api.URL+"/ea/sync", map[string]interface{}{
+		"equity":    10000.0,
+		"daily_pnl": 0.0,
+		"margin":    3000.0,
+		"positions": []interface{}{
+			map[string]interface{}{"ticket": 1, "symbol": "EURUSD", "side": "BUY", "volume": 3.0},
+		},
+	}, eaToken)
+	if level, _ := numField(synced, "margin_level"); math.Abs(level-333.333) > 0.01 {
+		t.Fatalf("expected derived margin level in the sync response, got %#v", synced)
+	}
+
+	signal := func(volume float64) map[string]interface{} {
+		return postJSON(t, client, api.URL+"/admin/signals/evaluate", map[string]interface{}{
+			"account_id":     "paper-1",
+			"symbol":         "EURUSD",
+			"side":           "BUY",
+			"confidence":     0.9,
+			"spread_pips":    1.0,
+			"stop_loss_pips": 10,
+			"volume":         volume,
+		}, adminToken)
+	}
+	if resp := signal(1.0); !boolField(resp, "allowed") {
+		t.Fatalf("expected one lot to keep margin level above the floor, got %#v", resp)
+	}
+	if resp := signal(2.5); boolField(resp, "allowed") || resp["deny_reason"] != "margin_level_below_floor" {
+		t.Fatalf("expected margin_level_below_floor, got %#v", resp)
+	}
+}
+
+func TestE2E_SlippagePausesSymbol(t *testing.T) {
+	cfg := config.Config{
+		AdminUsername:    "admin",
+		AdminPassword:    "pw",
+		JWTSecret:        "jwt-secret",
+		EAConnectCode:    "MMBOT-ONE-TIME-CODE",
+		EATokenTTL:       24 * time.Hour,
+		MaxDailyLossPct:  2.0,
+		MaxOpenPositions: 5,
+		MaxSpreadPips:    2.0,
+		OpenAIAPIKey:     "sk-test",
+		OpenClawTimeout:  time.Second,
+	}
+	srv := NewServer(
+		cfg,
+		memory.NewStore(24*time.Hour),
+		risk.NewEngine(cfg.MaxOpenPositions, cfg.MaxDailyLossPct, 0.70, cfg.MaxSpreadPips).WithSlippageLimits(risk.SlippageLimits{
+			MaxAvgPips: 1.5,
+			Window:     10,
+			MinFills:   2,
+		}),
+		telegram.NewNotifier("", ""),
+		openclaw.NewClient("", time.Second, 0, 100*time.Millisecond, time.Second),
+	)
+	api := httptest.NewServer(srv.Router())
+	defer api.Close()
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	adminToken := strField(t, postJSON(t, client, api.URL+"/admin/login", map[string]string{
+		"username": "admin",
+		"password": "pw",
+	}, ""), "token")
+	eaToken := strField(t, postJSON(t, client, api.URL+"/ea/register", map[string]string{
+		"connect_code": "MMBOT-ONE-TIME-CODE",
+		"account_id":   "paper-1",
+		"device_id":    "dev-1",
+	}, ""), "token")
+	_ = postJSON(t, client, api.URL+"/ea/symbols", map[string]interface{}{
+		"symbols": []map[string]interface{}{{
+			"symbol":        "EURUSD",
+			"digits":        5,
+			"point":         0.00001,
+			"tick_size":     0.00001,
+			"tick_value":    1.0,
+			"contract_size": 100000,
+			"volume_min":    0.01,
+			"volume_max":    50,
+			"volume_step":   0.01,
+		}},
+	}, eaToken)
+
+	signal := func() map[string]interface{} {
+		return postJSON(t, client, api.URL+"/admin/signals/evaluate", map[string]interface{}{
+			"account_id":     "paper-1",
+			"symbol":         "EURUSD",
+			"side":           "BUY",
+			"confidence":     0.9,
+			"spread_pips":    1.0,
+			"stop_loss_pips": 10,
+			"volume":         0.1,
+		}, adminToken)
+	}
+	// fill opens a BUY and reports it filled 3 pips above the quote.
+	fill := func() {
+		t.Helper()
+		if resp := signal(); !boolField(resp, "allowed") {
+			t.Fatalf("expected signal to be allowed, got %#v", resp)
+		}
+		cmdID := strField(t, postJSON(t, client, api.URL+"/ea/execute", map[string]interface{}{}, eaToken), "command_id")
+		_ = postJSON(t, client, api.URL+"/ea/result", map[string]interface{}{
+			"command_id":      cmdID,
+			"status":          "SUCCESS",
+			"broker_ticket":   "701",
+			"requested_price": 1.10000,
+			"fill_price":      1.10030,
+			"latency_ms":      120,
+		}, eaToken)
+	}
+
+	fill()
+	stats := getJSON(t, client, api.URL+"/admin/fills/stats?symbol=EURUSD", adminToken)
+	rows, _ := stats["stats"].([]interface{})
+	if len(rows) != 1 {
+		t.Fatalf("expected EURUSD fill stats, got %#v", stats)
+	}
+	row := rows[0].(map[string]interface{})
+	if avg, _ := numField(row, "avg_slippage_pips"); math.Abs(avg-3) > 1e-6 {
+		t.Fatalf("expected 3 pips of slippage, got %#v", row)
+	}
+	if latency, _ := numField(row, "avg_latency_ms"); latency != 120 {
+		t.Fatalf("expected 120ms latency, got %#v", row)
+	}
+	if resp := signal(); !boolField(resp, "allowed") {
+		t.Fatalf("expected no pause below the minimum fill count, got %#v", resp)
+	}
+	_ = postJSON(t, client, api.URL+"/ea/execute", map[string]interface{}{}, eaToken)
+
+	fill()
+	if resp := signal(); boolField(resp, "allowed") || resp["deny_reason"] != "symbol_slippage_paused" {
+		t.Fatalf("expected symbol_slippage_paused, got %#v", resp)
+	}
+	events := getJSON(t, client, api.URL+"/events?limit=50", adminToken)
+	found := false
+	for _, raw := range events["events"].([]interface{}) {
+		evt := raw.(map[string]interface{})
+		payload, _ := evt["payload"].(map[string]interface{})
+		if evt["event_type"] == string(domain.EventSymbolPaused) && payload["symbol"] == "EURUSD" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected SymbolPaused event, got %#v", events)
+	}
+
+	if status, _ := requestJSONStatus(t, client, http.MethodPost, api.URL+"/admin/symbols/EURUSD/resume", nil, adminToken); status != http.StatusOK {
+		t.Fatalf("expected resume to succeed, got %d", status)
+	}
+	if status, _ := requestJSONStatus(t, client, http.MethodPost, api.URL+"/admin/symbols/EURUSD/resume", nil, adminToken); status != http.StatusConflict {
+		t.Fatalf("expected a second resume to conflict, got %d", status)
+	}
+	// Fills before the resume no longer count, so one more bad fill does
+	// not pause the symbol again.
+	fill()
+	if resp := signal(); !boolField(resp, "allowed") {
+		t.Fatalf("expected symbol to trade after resume, got %#v", resp)
+	}
+}
+
+func TestE2E_TradeJournal(t *testing.T) {
+	cfg := config.Config{
+		AdminUsername:    "admin",
+		AdminPassword:    "pw",
+		JWTSecret:        "jwt-secret",
+		EAConnectCode:    "MMBOT-ONE-TIME-CODE",
+		EATokenTTL:       24 * time.Hour,
+		MaxDailyLossPct:  2.0,
+		MaxOpenPositions: 5,
+		MaxSpreadPips:    2.0,
+		OpenAIAPIKey:     "sk-test",
+		OpenClawTimeout:  time.Second,
+	}
+	srv := NewServer(
+		cfg,
+		memory.NewStore(24*time.Hour),
+		risk.NewEngine(cfg.MaxOpenPositions, cfg.MaxDailyLossPct, 0.70, cfg.MaxSpreadPips),
+		telegram.NewNotifier("", ""),
+		openclaw.NewClient("", time.Second, 0, 100*time.Millisecond, time.Second),
+	)
+	api := httptest.NewServer(srv.Router())
+	defer api.Close()
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	adminToken := strField(t, postJSON(t, client, api.URL+"/admin/login", map[string]string{
+		"username": "admin",
+		"password": "pw",
+	}, ""), "token")
+	eaToken := strField(t, postJSON(t, client, api.URL+"/ea/register", map[string]string{
+		"connect_code": "MMBOT-ONE-TIME-CODE",
+		"account_id":   "paper-1",
+		"device_id":    "dev-1",
+	}, ""), "token")
+
+	resp := postJSON(t, client, api.URL+"/admin/signals/evaluate", map[string]interface{}{
+		"account_id":     "paper-1",
+		"symbol":         "EURUSD",
+		"side":           "BUY",
+		"confidence":     0.9,
+		"spread_pips":    1.0,
+		"stop_loss_pips": 10,
+		"volume":         0.2,
+		"reason":         "breakout above range",
+		"strategy":       "breakout",
+	}, adminToken)
+	if !boolField(resp, "allowed") {
+		t.Fatalf("expected signal to be allowed, got %#v", resp)
+	}
+	cmdID := strField(t, postJSON(t, client, api.URL+"/ea/execute", map[string]interface{}{}, eaToken), "command_id")
+	_ = postJSON(t, client, api.URL+"/ea/result", map[string]interface{}{
+		"command_id":      cmdID,
+		"status":          "SUCCESS",
+		"broker_ticket":   "9001",
+		"requested_price": 1.10000,
+		"fill_price":      1.10000,
+	}, eaToken)
+
+	position := func(sl, current, profit float64) map[string]interface{} {
+		return map[string]interface{}{
+			"ticket": 9001, "symbol": "EURUSD", "side": "BUY", "volume": 0.2,
+			"price_open": 1.10000, "price_current": current, "sl": sl, "tp": 1.10300, "profit": profit,
+		}
+	}
+	_ = postJSON(t, client, api.URL+"/ea/sync", map[string]interface{}{
+		"equity": 10000.0, "positions": []interface{}{position(1.09900, 1.10050, 10)},
+	}, eaToken)
+	_ = postJSON(t, client, api.URL+"/ea/sync", map[string]interface{}{
+		"equity": 10020.0, "positions": []interface{}{position(1.10000, 1.10150, 30)},
+	}, eaToken)
+
+	open := getJSON(t, client, api.URL+"/admin/trades?status=open", adminToken)
+	trades, _ := open["trades"].([]interface{})
+	if len(trades) != 1 {
+		t.Fatalf("expected one open trade, got %#v", open)
+	}
+	trade := trades[0].(map[string]interface{})
+	if trade["ticket"] != "9001" || trade["open_command_id"] != cmdID || trade["strategy"] != "breakout" || trade["reason"] != "breakout above range" {
+		t.Fatalf("expected the trade to link the OPEN command, got %#v", trade)
+	}
+	if mods, _ := trade["modifications"].([]interface{}); len(mods) != 1 || mods[0].(map[string]interface{})["source"] != "sync" {
+		t.Fatalf("expected the SL move to breakeven as a modification, got %#v", trade["modifications"])
+	}
+
+	_ = postJSON(t, client, api.URL+"/ea/sync", map[string]interface{}{
+		"equity": 10030.0, "positions": []interface{}{},
+	}, eaToken)
+	closed := getJSON(t, client, api.URL+"/admin/trades/"+strField(t, trade, "id"), adminToken)
+	if closed["status"] != "CLOSED" {
+		t.Fatalf("expected the trade to close when its ticket left the sync, got %#v", closed)
+	}
+	if exit, _ := numField(closed, "exit_price"); math.Abs(exit-1.10150) > 1e-9 {
+		t.Fatalf("expected exit at the last synced price, got %#v", closed)
+	}
+	if r, _ := numField(closed, "r_multiple"); r != 1.5 {
+		t.Fatalf("expected 1.5R, got %#v", closed)
+	}
+	if profit, _ := numField(closed, "profit"); profit != 30 {
+		t.Fatalf("expected realised profit 30, got %#v", closed)
+	}
+	if filtered := getJSON(t, client, api.URL+"/admin/trades?strategy=trend", adminToken); filtered["count"] != 0.0 {
+		t.Fatalf("expected the strategy filter to exclude the trade, got %#v", filtered)
+	}
+	if status, _ := requestJSONStatus(t, client, http.MethodGet, api.URL+"/admin/trades?status=pending", nil, adminToken); status != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown status, got %d", status)
+	}
+
+	perf := getJSON(t, client, api.URL+"/analytics/performance?account_id=paper-1", adminToken)
+	if trades, _ := numField(perf, "trades"); trades != 1 {
+		t.Fatalf("expected one closed trade in the analytics, got %#v", perf)
+	}
+	if initial, _ := numField(perf, "initial_equity"); initial != 10000 {
+		t.Fatalf("expected initial equity from the last sync less the trade profit, got %#v", perf)
+	}
+	byStrategy, _ := perf["by_strategy"].(map[string]interface{})
+	if breakout, _ := byStrategy["breakout"].(map[string]interface{}); breakout == nil || breakout["net_profit"] != 30.0 {
+		t.Fatalf("expected the profit attributed to breakout, got %#v", perf["by_strategy"])
+	}
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	if perf := getJSON(t, client, api.URL+"/analytics/performance?from="+future, adminToken); perf["trades"] != 0.0 {
+		t.Fatalf("expected the date filter to exclude the trade, got %#v", perf)
+	}
+}
+
+func TestE2E_EquityCurve(t *testing.T) {
+	cfg := config.Config{
+		AdminUsername:    "admin",
+		AdminPassword:    "pw",
+		JWTSecret:        "jwt-secret",
+		EAConnectCode:    "MMBOT-ONE-TIME-CODE",
+		EATokenTTL:       24 * time.Hour,
+		MaxDailyLossPct:  50.0,
+		MaxOpenPositions: 5,
+		MaxSpreadPips:    2.0,
+		OpenAIAPIKey:     "sk-test",
+		OpenClawTimeout:  time.Second,
+	}
+	srv := NewServer(
+		cfg,
+		memory.NewStore(24*time.Hour),
+		risk.NewEngine(cfg.MaxOpenPositions, cfg.MaxDailyLossPct, 0.70, cfg.MaxSpreadPips),
+		telegram.NewNotifier("", ""),
+		openclaw.NewClient("", time.Second, 0, 100*time.Millisecond, time.Second),
+	)
+	api := httptest.NewServer(srv.Router())
+	defer api.Close()
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	adminToken := strField(t, postJSON(t, client, api.URL+"/admin/login", map[string]string{
+		"username": "admin",
+		"password": "pw",
+	}, ""), "token")
+	eaToken := strField(t, postJSON(t, client, api.URL+"/ea/register", map[string]string{
+		"connect_code": "MMBOT-ONE-TIME-CODE",
+		"account_id":   "paper-1",
+		"device_id":    "dev-1",
+	}, ""), "token")
+	for _, eq := range []float64{10000, 10200, 9690, 9800} {
+		_ = postJSON(t, client, api.URL+"/ea/sync", map[string]interface{}{
+			"equity":    eq,
+			"balance":   10000.0,
+			"positions": []interface{}{},
+		}, eaToken)
+	}
+
+	raw := getJSON(t, client, api.URL+"/analytics/equity?account_id=paper-1&resolution=raw", adminToken)
+	points, _ := raw["points"].([]interface{})
+	if len(points) != 4 {
+		t.Fatalf("expected every sync as a raw point, got %#v", raw)
+	}
+	if floating, _ := numField(points[2].(map[string]interface{}), "floating_pnl"); floating != -310 {
+		t.Fatalf("expected floating PnL from equity less balance, got %#v", points[2])
+	}
+	if dd, _ := numField(raw, "max_drawdown"); dd != 510 {
+		t.Fatalf("expected a 510 drawdown from the 10200 peak, got %#v", raw)
+	}
+
+	hourly := getJSON(t, client, api.URL+"/analytics/equity?account_id=paper-1", adminToken)
+	if hourly["resolution"] != "1h" {
+		t.Fatalf("expected auto resolution 1h for the whole history, got %#v", hourly)
+	}
+	buckets, _ := hourly["points"].([]interface{})
+	total := 0.0
+	for _, b := range buckets {
+		n, _ := numField(b.(map[string]interface{}), "samples")
+		total += n
+	}
+	if total != 4 {
+		t.Fatalf("expected the hourly rollup to fold all four syncs, got %#v", hourly)
+	}
+	if status, _ := requestJSONStatus(t, client, http.MethodGet, api.URL+"/analytics/equity?account_id=paper-1&resolution=5m", nil, adminToken); status != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown resolution, got %d", status)
+	}
+}
+
+func TestE2E_TradingDayRollover(t *testing.T) {
+	cfg := config.Config{
+		AdminUsername:          "admin",
+		AdminPassword:          "pw",
+		JWTSecret:              "jwt-secret",
+		EAConnectCode:          "MMBOT-ONE-TIME-CODE",
+		EATokenTTL:             24 * time.Hour,
+		MaxDailyLossPct:        2.0,
+		MaxOpenPositions:       5,
+		MaxSpreadPips:          2.0,
+		OpenAIAPIKey:           "sk-test",
+		OpenClawTimeout:        time.Second,
+		DailyBreakerAutoResume: true,
+	}
+	days, err := risk.ParseTradingDays("17:00@America/New_York", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := memory.NewStore(24 * time.Hour)
+	srv := NewServer(
+		cfg,
+		st,
+		risk.NewEngine(cfg.MaxOpenPositions, cfg.MaxDailyLossPct, 0.70, cfg.MaxSpreadPips).WithTradingDays(days),
+		telegram.NewNotifier("", ""),
+		openclaw.NewClient("", time.Second, 0, 100*time.Millisecond, time.Second),
+	)
+	api := httptest.NewServer(srv.Router())
+	defer api.Close()
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	adminToken := strField(t, postJSON(t, client, api.URL+"/admin/login", map[string]string{
+		"username": "admin",
+		"password": "pw",
+	}, ""), "token")
+	eaToken := strField(t, postJSON(t, client, api.URL+"/ea/register", map[string]string{
+		"connect_code": "MMBOT-ONE-TIME-CODE",
+		"account_id":   "paper-1",
+		"device_id":    "dev-1",
+	}, ""), "token")
+
+	// The daily loss breaker paused the bot on last week's Monday. The
+	// account has not been active since, so every day up to today closes.
+	day, _ := days.Lookup("paper-1")
+	today := day.Key(time.Now())
+	thisMonday, err := time.Parse("2006-01-02", today)
+	if err != nil {
+		t.Fatal(err)
+	}
+	thisMonday = thisMonday.AddDate(0, 0, -((int(thisMonday.Weekday()) + 6) % 7))
+	lastMonday := thisMonday.AddDate(0, 0, -7).Format("2006-01-02")
+	dayStart, _, err := day.Bounds(lastMonday)
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedDays := 0
+	for key := lastMonday; key < today; closedDays++ {
+		if key, err = day.Next(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	st.SaveTradeActivity(domain.TradeActivity{
+		AccountID:      "paper-1",
+		Day:            lastMonday,
+		TradesToday:    3,
+		DayStartEquity: 10000,
+		DailyBreakerAt: dayStart.Add(20 * time.Hour),
+	})
+	st.SetDailyLoss("paper-1", 2.5)
+	st.SetPausedBy(domain.PauseOwner{Source: "risk_circuit_breaker", AccountID: "paper-1", At: dayStart.Add(20 * time.Hour)})
+	st.SaveTrade(domain.Trade{
+		AccountID: "paper-1",
+		Symbol:    "EURUSD",
+		Side:      "BUY",
+		Volume:    0.1,
+		Status:    domain.TradeStatusClosed,
+		Profit:    -40,
+		OpenedAt:  dayStart.Add(15 * time.Hour),
+		ClosedAt:  dayStart.Add(17 * time.Hour),
+	})
+	// A trade carried into last week counts in the week it closed, and the
+	// weekly report opens at the week's tracked starting equity.
+	st.SaveTrade(domain.Trade{
+		AccountID: "paper-1",
+		Symbol:    "GBPUSD",
+		Side:      "SELL",
+		Volume:    0.1,
+		Status:    domain.TradeStatusClosed,
+		Profit:    25,
+		OpenedAt:  dayStart.AddDate(0, 0, -10),
+		ClosedAt:  dayStart.AddDate(0, 0, 2),
+	})
+	st.SaveAccountRiskState(domain.AccountRiskState{
+		AccountID:       "paper-1",
+		PeakEquity:      10200,
+		WeekStart:       dayStart.UTC(),
+		WeekStartEquity: 10200,
+		LastEquity:      10000,
+	})
+
+	synced := postJSON(t, client, api.URL+"/ea/sync", map[string]interface{}{
+		"equity":    10000.0,
+		"balance":   10000.0,
+		"positions": []interface{}{},
+	}, eaToken)
+	if loss, _ := numField(synced, "daily_loss_pct"); loss != 0 || boolField(synced, "triggered_circuit_breaker") {
+		t.Fatalf("expected a fresh day without loss, got %#v", synced)
+	}
+	if st.IsPaused() {
+		t.Fatal("expected the daily breaker pause to be lifted at the boundary")
+	}
+
+	events := getJSON(t, client, api.URL+"/events?limit=200", adminToken)
+	var summary map[string]interface{}
+	closedSummaries := 0
+	for _, raw := range events["events"].([]interface{}) {
+		evt := raw.(map[string]interface{})
+		if evt["event_type"] != string(domain.EventTradingDayClosed) {
+			continue
+		}
+		closedSummaries++
+		if payload, _ := evt["payload"].(map[string]interface{}); payload["day"] == lastMonday {
+			summary = payload
+		}
+	}
+	if summary == nil || summary["boundary"] != "17:00@America/New_York" {
+		t.Fatalf("expected a TradingDayClosed summary, got %#v", events)
+	}
+	if closedSummaries != closedDays {
+		t.Fatalf("expected every day since %s to close, got %d of %d", lastMonday, closedSummaries, closedDays)
+	}
+	if trades, _ := numField(summary, "trades_opened"); trades != 3 || !boolField(summary, "breaker_resumed") {
+		t.Fatalf("unexpected day summary %#v", summary)
+	}
+	if loss, _ := numField(summary, "daily_loss_pct"); loss != 2.5 {
+		t.Fatalf("expected the closed day's loss in the summary, got %#v", summary)
+	}
+	if closed, _ := numField(summary, "trades_closed"); closed != 1 {
+		t.Fatalf("expected the journal close in the summary, got %#v", summary)
+	}
+
+	// Each missed day gets its daily report, and last week its weekly one.
+	reports := getJSON(t, client, api.URL+"/reports?account_id=paper-1", adminToken)
+	if count, _ := numField(reports, "count"); int(count) != closedDays+1 {
+		t.Fatalf("expected %d daily and one weekly report, got %#v", closedDays, reports)
+	}
+	daily := getJSON(t, client, api.URL+"/reports/"+strField(t, summary, "report_id"), adminToken)
+	if daily["kind"] != "daily" || daily["period"] != lastMonday || daily["from"] != dayStart.UTC().Format(time.RFC3339) {
+		t.Fatalf("unexpected daily report %#v", daily)
+	}
+	if net, _ := numField(daily, "net_profit"); net != -40 {
+		t.Fatalf("expected the day's net profit in the report, got %#v", daily)
+	}
+	if start, _ := numField(daily, "start_equity"); start != 10000 {
+		t.Fatalf("expected the day's starting equity in the report, got %#v", daily)
+	}
+	year, week := thisMonday.AddDate(0, 0, -7).ISOWeek()
+	weekly := getJSON(t, client, api.URL+"/reports?kind=weekly", adminToken)
+	rows, _ := weekly["reports"].([]interface{})
+	if len(rows) != 1 || rows[0].(map[string]interface{})["period"] != fmt.Sprintf("%d-W%02d", year, week) {
+		t.Fatalf("expected one weekly report for last week, got %#v", weekly)
+	}
+	lastWeek := rows[0].(map[string]interface{})
+	if start, _ := numField(lastWeek, "start_equity"); start != 10200 || lastWeek["trades_closed"] != 2.0 || lastWeek["net_profit"] != -15.0 {
+		t.Fatalf("expected the weekly report to open at the week's start equity with both closes, got %#v", lastWeek)
+	}
+	if status, _ := requestJSONStatus(t, client, http.MethodGet, api.URL+"/reports?kind=monthly", nil, adminToken); status != http.StatusBadRequest {
+		t.Fatalf("expected an unknown kind to be rejected, got %d", status)
+	}
+	if status, _ := requestJSONStatus(t, client, http.MethodGet, api.URL+"/reports/missing", nil, adminToken); status != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown report, got %d", status)
+	}
+	generated := 0
+	for _, raw := range getJSON(t, client, api.URL+"/events?limit=200", adminToken)["events"].([]interface{}) {
+		if raw.(map[string]interface{})["event_type"] == string(domain.EventReportGenerated) {
+			generated++
+		}
+	}
+	if generated != closedDays+1 {
+		t.Fatalf("expected a ReportGenerated event per report, got %d", generated)
+	}
+
+	// The new day's loss is measured from its first synced equity.
+	synced = postJSON(t, client, api.URL+"/ea/sync", map[string]interface{}{
+		"equity":    9750.0,
+		"balance":   10000.0,
+		"positions": []interface{}{},
+	}, eaToken)
+	if loss, _ := numField(synced, "daily_loss_pct"); loss != 2.5 || !boolField(synced, "triggered_circuit_breaker") {
+		t.Fatalf("expected the breaker to trip on the new day's loss, got %#v", synced)
+	}
+	activity, _ := st.TradeActivity("paper-1")
+	if activity.DailyBreakerAt.IsZero() {
+		t.Fatalf("expected the breaker trip to be recorded on the day, got %+v", activity)
+	}
+
+	// An admin pause taken over the breaker's pause survives the boundary.
+	_ = postJSON(t, client, api.URL+"/bot/pause", map[string]interface{}{}, adminToken)
+	previous, err := time.Parse("2006-01-02", activity.Day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	activity.Day = previous.AddDate(0, 0, -1).Format("2006-01-02")
+	st.SaveTradeActivity(activity)
+	_ = postJSON(t, client, api.URL+"/ea/sync", map[string]interface{}{
+		"equity":    9750.0,
+		"balance":   10000.0,
+		"positions": []interface{}{},
+	}, eaToken)
+	if !st.IsPaused() {
+		t.Fatal("expected the admin pause to outlive the trading day")
+	}
+	if owner, _ := st.PauseOwner(); owner.Source != "admin" {
+		t.Fatalf("expected the admin to own the pause, got %+v", owner)
+	}
+}
+
+func TestE2E_Export(t *testing.T) {
+	cfg := config.Config{
+		AdminUsername:    "admin",
+		AdminPassword:    "pw",
+		JWTSecret:        "jwt-secret",
+		EAConnectCode:    "MMBOT-ONE-TIME-CODE",
+		EATokenTTL:       24 * time.Hour,
+		MaxDailyLossPct:  2.0,
+		MaxOpenPositions: 5,
+		MaxSpreadPips:    2.0,
+		OpenAIAPIKey:     "sk-test",
+		OpenClawTimeout:  time.Second,
+	}
+	srv := NewServer(
+		cfg,
+		memory.NewStore(24*time.Hour),
+		risk.NewEngine(cfg.MaxOpenPositions, cfg.MaxDailyLossPct, 0.70, cfg.MaxSpreadPips),
+		telegram.NewNotifier("", ""),
+		openclaw.NewClient("", time.Second, 0, 100*time.Millisecond, time.Second),
+	)
+	api := httptest.NewServer(srv.Router())
+	defer api.Close()
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	adminToken := strField(t, postJSON(t, client, api.URL+"/admin/login", map[string]string{
+		"username": "admin",
+		"password": "pw",
+	}, ""), "token")
+	eaToken := strField(t, postJSON(t, client, api.URL+"/ea/register", map[string]string{
+		"connect_code": "MMBOT-ONE-TIME-CODE",
+		"account_id":   "paper-1",
+		"device_id":    "dev-1",
+	}, ""), "token")
+
+	for _, side := range []string{"BUY", "SELL"} {
+		resp := postJSON(t, client, api.URL+"/admin/signals/evaluate", map[string]interface{}{
+			"account_id":     "paper-1",
+			"symbol":         "EURUSD",
+			"side":           side,
+			"confidence":     0.9,
+			"spread_pips":    1.0,
+			"stop_loss_pips": 10,
+			"volume":         0.1,
+		}, adminToken)
+		if !boolField(resp, "allowed") {
+			t.Fatalf("expected signal to be allowed, got %#v", resp)
+		}
+	}
+	cmdID := strField(t, postJSON(t, client, api.URL+"/ea/execute", map[string]interface{}{}, eaToken), "command_id")
+	_ = postJSON(t, client, api.URL+"/ea/result", map[string]interface{}{
+		"command_id":    cmdID,
+		"status":        "FAILED",
+		"error_code":    "10019",
+		"error_message": "not enough money",
+	}, eaToken)
+
+	download := func(path string) (*http.Response, string) {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, api.URL+path, nil)
+		if err != nil {
+			t.Fatalf("new request: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("do request: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	resp, body := download("/admin/export/commands?account_id=paper-1")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/csv; charset=utf-8" {
+		t.Fatalf("expected a CSV export, got %d %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	rows, err := csv.NewReader(strings.NewReader(body)).ReadAll()
+	if err != nil || len(rows) != 3 || rows[0][0] != "command_id" || rows[1][0] != cmdID {
+		t.Fatalf("expected a header and both commands oldest first, got %v (%v)", rows, err)
+	}
+
+	_, body = download("/admin/export/results?format=ndjson")
+	var result map[string]interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(body)), &result); err != nil {
+		t.Fatalf("expected one NDJSON result line, got %q", body)
+	}
+	if result["command_id"] != cmdID || result["error_message"] != "not enough money" || result["account_id"] != "paper-1" {
+		t.Fatalf("unexpected result record %#v", result)
+	}
+
+	_, body = download("/admin/export/events?format=ndjson&account_id=paper-1")
+	if lines := strings.Split(strings.TrimSpace(body), "\n"); len(lines) < 2 {
+		t.Fatalf("expected the signal events, got %q", body)
+	}
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	if _, body = download("/admin/export/events?from=" + future); strings.Count(body, "\n") != 1 {
+		t.Fatalf("expected only the header for an empty range, got %q", body)
+	}
+	if _, body = download("/admin/export/trades?account_id=other&format=ndjson"); body != "" {
+		t.Fatalf("expected no trades for another account, got %q", body)
+	}
+
+	for path, want := range map[string]int{
+		"/admin/export/positions":         http.StatusNotFound,
+		"/admin/export/trades?format=xml": http.StatusBadRequest,
+		"/admin/export/events?to=today":   http.StatusBadRequest,
+	} {
+		if resp, _ := download(path); resp.StatusCode != want {
+			t.Fatalf("%s: expected %d, got %d", path, want, resp.StatusCode)
+		}
+	}
+}
+
+func TestE2E_DealReconciliation(t *testing.T) {
+	cfg := config.Config{
+		AdminUsername:    "admin",
+		AdminPassword:    "pw",
+		JWTSecret:        "jwt-secret",
+		EAConnectCode:    "MMBOT-ONE-TIME-CODE",
+		EATokenTTL:       24 * time.Hour,
+		MaxDailyLossPct:  2.0,
+		MaxOpenPositions: 5,
+		MaxSpreadPips:    2.0,
+		OpenAIAPIKey:     "sk-test",
+		OpenClawTimeout:  time.Second,
+	}
+	srv := NewServer(
+		cfg,
+		memory.NewStore(24*time.Hour),
+		risk.NewEngine(cfg.MaxOpenPositions, cfg.MaxDailyLossPct, 0.70, cfg.MaxSpreadPips),
+		telegram.NewNotifier("", ""),
+		openclaw.NewClient("", time.Second, 0, 100*time.Millisecond, time.Second),
+	)
+	api := httptest.NewServer(srv.Router())
+	defer api.Close()
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	adminToken := strField(t, postJSON(t, client, api.URL+"/admin/login", map[string]string{
+		"username": "admin",
+		"password": "pw",
+	}, ""), "token")
+	eaToken := strField(t, postJSON(t, client, api.URL+"/ea/register", map[string]string{
+		"connect_code": "MMBOT-ONE-TIME-CODE",
+		"account_id":   "paper-1",
+		"device_id":    "dev-1",
+	}, ""), "token")
+
+	tickets := map[string]string{"EURUSD": "100", "GBPUSD": "200"}
+	commands := make(map[string]string)
+	for _, symbol := range []string{"EURUSD", "GBPUSD"} {
+		resp := postJSON(t, client, api.URL+"/admin/signals/evaluate", map[string]interface{}{
+			"account_id":     "paper-1",
+			"symbol":         symbol,
+			"side":           "BUY",
+			"confidence":     0.9,
+			"spread_pips":    1.0,
+			"stop_loss_pips": 10,
+			"volume":         0.1,
+		}, adminToken)
+		if !boolField(resp, "allowed") {
+			t.Fatalf("expected signal to be allowed, got %#v", resp)
+		}
+		cmdID := strField(t, postJSON(t, client, api.URL+"/ea/execute", map[string]interface{}{}, eaToken), "command_id")
+		commands[symbol] = cmdID
+		_ = postJSON(t, client, api.URL+"/ea/result", map[string]interface{}{
+			"command_id":    cmdID,
+			"status":        "SUCCESS",
+			"broker_ticket": tickets[symbol],
+		}, eaToken)
+	}
+
+	// The GBPUSD fill never reached the broker and XAUUSD was opened by hand.
+	_ = postJSON(t, client, api.URL+"/ea/sync", map[string]interface{}{
+		"equity": 10000.0,
+		"positions": []interface{}{
+			map[string]interface{}{"ticket": 100, "symbol": "EURUSD", "side": "BUY", "volume": 0.1, "price_open": 1.10000, "price_current": 1.10100, "profit": 10},
+			map[string]interface{}{"ticket": 500, "symbol": "XAUUSD", "side": "BUY", "volume": 1.0, "price_open": 2400.0, "price_current": 2401.0, "profit": 100},
+		},
+	}, eaToken)
+	inDeals := []interface{}{
+		map[string]interface{}{"ticket": "1", "order": "100", "position_id": "100", "symbol": "EURUSD", "side": "BUY", "entry": "IN", "reason": "EXPERT", "volume": 0.1, "price": 1.10000, "time": "2026-03-02T10:00:00Z"},
+		map[string]interface{}{"ticket": "2", "order": "500", "position_id": "500", "symbol": "XAUUSD", "side": "BUY", "entry": "in", "reason": "client", "volume": 1.0, "price": 2400.0, "time": "2026-03-02T10:05:00Z"},
+	}
+	resp := postJSON(t, client, api.URL+"/ea/deals", map[string]interface{}{"history_seconds": 86400, "deals": inDeals}, eaToken)
+	if resp["saved"] != 2.0 {
+		t.Fatalf("expected both deals saved, got %#v", resp)
+	}
+	found, _ := resp["issues"].([]interface{})
+	if len(found) != 2 {
+		t.Fatalf("expected a missing fill and an orphaned position, got %#v", resp)
+	}
+	issues := getJSON(t, client, api.URL+"/admin/reconcile/issues?status=open&account_id=paper-1", adminToken)
+	kinds := make(map[string]map[string]interface{})
+	list, _ := issues["issues"].([]interface{})
+	for _, raw := range list {
+		issue := raw.(map[string]interface{})
+		kinds[strField(t, issue, "kind")] = issue
+	}
+	if missing := kinds["missing_fill"]; missing == nil || missing["command_id"] != commands["GBPUSD"] || missing["ticket"] != "200" {
+		t.Fatalf("expected the GBPUSD OPEN to miss its fill, got %#v", issues)
+	}
+	orphan := kinds["orphaned_position"]
+	if orphan == nil || orphan["ticket"] != "500" || orphan["symbol"] != "XAUUSD" {
+		t.Fatalf("expected the manual XAUUSD position to be orphaned, got %#v", issues)
+	}
+
+	// Syncing the same history again raises nothing new.
+	_ = postJSON(t, client, api.URL+"/ea/deals", map[string]interface{}{"history_seconds": 86400, "deals": inDeals}, eaToken)
+	events := getJSON(t, client, api.URL+"/events?limit=100", adminToken)
+	raised := 0
+	eventList, _ := events["events"].([]interface{})
+	for _, raw := range eventList {
+		event := raw.(map[string]interface{})
+		if event["event_type"] == string(domain.EventReconcileIssue) {
+			raised++
+		}
+	}
+	if raised != 2 {
+		t.Fatalf("expected one ReconcileIssue event per issue, got %d", raised)
+	}
+
+	// EURUSD hits its TP, the XAUUSD position is closed by hand and the
+	// GBPUSD deal shows up late.
+	_ = postJSON(t, client, api.URL+"/ea/sync", map[string]interface{}{
+		"equity": 10130.0, "positions": []interface{}{},
+	}, eaToken)
+	deals := append(inDeals,
+		map[string]interface{}{"ticket": "3", "order": "101", "position_id": "100", "symbol": "EURUSD", "side": "SELL", "entry": "OUT", "reason": "TP", "volume": 0.1, "price": 1.10300, "profit": 30, "time": "2026-03-02T11:00:00Z"},
+		map[string]interface{}{"ticket": "4", "order": "501", "position_id": "500", "symbol": "XAUUSD", "side": "SELL", "entry": "OUT", "reason": "CLIENT", "volume": 1.0, "price": 2401.0, "profit": 100, "time": "2026-03-02T11:05:00Z"},
+		map[string]interface{}{"ticket": "5", "order": "200", "position_id": "200", "symbol": "GBPUSD", "side": "BUY", "entry": "IN", "reason": "EXPERT", "volume": 0.1, "price": 1.27000, "time": "2026-03-02T10:01:00Z"},
+	)
+	resp = postJSON(t, client, api.URL+"/ea/deals", map[string]interface{}{"history_seconds": 86400, "deals": deals}, eaToken)
+	if resp["resolved"] != 2.0 || resp["attributed"] != 1.0 {
+		t.Fatalf("expected both issues resolved and the EURUSD close attributed, got %#v", resp)
+	}
+	if open := getJSON(t, client, api.URL+"/admin/reconcile/issues?status=open", adminToken); open["count"] != 0.0 {
+		t.Fatalf("expected no open issues, got %#v", open)
+	}
+	closed := getJSON(t, client, api.URL+"/admin/trades?status=closed&symbol=EURUSD", adminToken)
+	trades, _ := closed["trades"].([]interface{})
+	if len(trades) != 1 {
+		t.Fatalf("expected the EURUSD trade closed, got %#v", closed)
+	}
+	trade := trades[0].(map[string]interface{})
+	if trade["close_reason"] != "TP" || trade["profit"] != 30.0 || trade["exit_price"] != 1.103 {
+		t.Fatalf("expected the TP deal to complete the trade, got %#v", trade)
+	}
+	if listed := getJSON(t, client, api.URL+"/admin/deals?account_id=paper-1&symbol=xauusd", adminToken); listed["count"] != 2.0 {
+		t.Fatalf("expected both XAUUSD deals, got %#v", listed)
+	}
+
+	resolveURL := api.URL + "/admin/accounts/paper-1/reconcile/issues/" + strField(t, orphan, "id") + "/resolve"
+	if status, _ := requestJSONStatus(t, client, http.MethodPost, resolveURL, nil, adminToken); status != http.StatusConflict {
+		t.Fatalf("expected resolving a resolved issue to conflict, got %d", status)
+	}
+	if status, _ := requestJSONStatus(t, client, http.MethodPost, api.URL+"/admin/accounts/paper-1/reconcile/issues/missing_fill:nope/resolve", nil, adminToken); status != http.StatusNotFound {
+		t.Fatalf("expected an unknown issue to be 404, got %d", status)
+	}
+	if status, _ := requestJSONStatus(t, client, http.MethodGet, api.URL+"/admin/reconcile/issues?kind=ghost", nil, adminToken); status != http.StatusBadRequest {
+		t.Fatalf("expected an unknown kind to be rejected, got %d", status)
+	}
+
+	// A retried USDJPY OPEN filled twice. Once an admin resolves the
+	// duplicate, the same deals no longer raise it.
+	resp = postJSON(t, client, api.URL+"/admin/signals/evaluate", map[string]interface{}{
+		"account_id":     "paper-1",
+		"symbol":         "USDJPY",
+		"side":           "BUY",
+		"confidence":     0.9,
+		"spread_pips":    1.0,
+		"stop_loss_pips": 10,
+		"volume":         0.1,
+	}, adminToken)
+	if !boolField(resp, "allowed") {
+		t.Fatalf("expected signal to be allowed, got %#v", resp)
+	}
+	retried := strField(t, postJSON(t, client, api.URL+"/ea/execute", map[string]interface{}{}, eaToken), "command_id")
+	_ = postJSON(t, client, api.URL+"/ea/result", map[string]interface{}{
+		"command_id":    retried,
+		"status":        "SUCCESS",
+		"broker_ticket": "300",
+	}, eaToken)
+	deals = append(deals,
+		map[string]interface{}{"ticket": "6", "order": "300", "position_id": "300", "symbol": "USDJPY", "side": "BUY", "entry": "IN", "reason": "EXPERT", "volume": 0.1, "price": 150.0, "comment": "MMBot " + retried[:8], "time": "2026-03-02T12:00:00Z"},
+		map[string]interface{}{"ticket": "7", "order": "301", "position_id": "301", "symbol": "USDJPY", "side": "BUY", "entry": "IN", "reason": "EXPERT", "volume": 0.1, "price": 150.0, "comment": "MMBot " + retried[:8], "time": "2026-03-02T12:00:05Z"},
+	)
+	reconcileEvents := func() int {
+		n := 0
+		for _, raw := range getJSON(t, client, api.URL+"/events?limit=200", adminToken)["events"].([]interface{}) {
+			if raw.(map[string]interface{})["event_type"] == string(domain.EventReconcileIssue) {
+				n++
+			}
+		}
+		return n
+	}
+	before := reconcileEvents()
+	_ = postJSON(t, client, api.URL+"/ea/deals", map[string]interface{}{"history_seconds": 86400, "deals": deals}, eaToken)
+	duplicateID := "duplicate_execution:" + retried
+	if reconcileEvents() != before+1 {
+		t.Fatalf("expected the duplicate execution to be raised once")
+	}
+	dupURL := api.URL + "/admin/accounts/paper-1/reconcile/issues/" + duplicateID + "/resolve"
+	if status, _ := requestJSONStatus(t, client, http.MethodPost, dupURL, nil, adminToken); status != http.StatusOK {
+		t.Fatalf("expected the admin to resolve the duplicate, got %d", status)
+	}
+	before = reconcileEvents()
+	_ = postJSON(t, client, api.URL+"/ea/deals", map[string]interface{}{"history_seconds": 86400, "deals": deals}, eaToken)
+	if after := reconcileEvents(); after != before {
+		t.Fatalf("expected a resolved duplicate not to be raised again, got %d new events", after-before)
+	}
+	if open := getJSON(t, client, api.URL+"/admin/reconcile/issues?status=open&kind=duplicate_execution", adminToken); open["count"] != 0.0 {
+		t.Fatalf("expected the duplicate to stay resolved, got %#v", open)
+	}
+}
+
+func TestE2E_MultiCurrencyAnalytics(t *testing.T) {
+	cfg := config.Config{
+		AdminUsername:    "admin",
+		AdminPassword:    "pw",
+		JWTSecret:        "jwt-secret",
+		EAConnectCode:    "MMBOT-ONE-TIME-CODE",
+		EATokenTTL:       24 * time.Hour,
+		MaxDailyLossPct:  2.0,
+		MaxOpenPositions: 5,
+		MaxSpreadPips:    2.0,
+		OpenAIAPIKey:     "sk-test",
+		OpenClawTimeout:  time.Second,
+		BaseCurrency:     "usd",
+		FXRates:          "EURUSD=1.10",
+		FXQuoteMaxAge:    time.Hour,
+	}
+	srv := NewServer(
+		cfg,
+		memory.NewStore(24*time.Hour),
+		risk.NewEngine(cfg.MaxOpenPositions, cfg.MaxDailyLossPct, 0.70, cfg.MaxSpreadPips),
+		telegram.NewNotifier("", ""),
+		openclaw.NewClient("", time.Second, 0, 100*time.Millisecond, time.Second),
+	)
+	api := httptest.NewServer(srv.Router())
+	defer api.Close()
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	adminToken := strField(t, postJSON(t, client, api.URL+"/admin/login", map[string]string{
+		"username": "admin",
+		"password": "pw",
+	}, ""), "token")
+
+	// An account that does not report its currency and a EUR account that
+	// does; each opens and closes one EURUSD trade.
+	trade := func(accountID, currency string, ticket int, profit float64) string {
+		t.Helper()
+		eaToken := strField(t, postJSON(t, client, api.URL+"/ea/register", map[string]string{
+			"connect_code": "MMBOT-ONE-TIME-CODE",
+			"account_id":   accountID,
+			"device_id":    "dev-" + accountID,
+		}, ""), "token")
+		resp := postJSON(t, client, api.URL+"/admin/signals/evaluate", map[string]interface{}{
+			"account_id":     accountID,
+			"symbol":         "EURUSD",
+			"side":           "BUY",
+			"confidence":     0.9,
+			"spread_pips":    1.0,
+			"stop_loss_pips": 10,
+			"volume":         0.1,
+		}, adminToken)
+		if !boolField(resp, "allowed") {
+			t.Fatalf("expected signal to be allowed, got %#v", resp)
+		}
+		cmdID := strField(t, postJSON(t, client, api.URL+"/ea/execute", map[string]interface{}{}, eaToken), "command_id")
+		_ = postJSON(t, client, api.URL+"/ea/result", map[string]interface{}{
+			"command_id":    cmdID,
+			"status":        "SUCCESS",
+			"broker_ticket": fmt.Sprint(ticket),
+		}, eaToken)
+		snapshot := map[string]interface{}{
+			"equity":  1000.0,
+			"balance": 1000.0,
+			"positions": []interface{}{
+				map[string]interface{}{"ticket": ticket, "symbol": "EURUSD", "side": "BUY", "volume": 0.1, "profit": profit},
+			},
+		}
+		if currency != "" {
+			snapshot["currency"] = currency
+		}
+		_ = postJSON(t, client, api.URL+"/ea/sync", snapshot, eaToken)
+		snapshot["positions"] = []interface{}{}
+		_ = postJSON(t, client, api.URL+"/ea/sync", snapshot, eaToken)
+		return eaToken
+	}
+	_ = trade("acct-usd", "", 301, -10)
+	eurToken := trade("acct-eur", "eur", 302, 50)
+
+	accounts := getJSON(t, client, api.URL+"/admin/accounts", adminToken)
+	currencies := make(map[string]string)
+	list, _ := accounts["accounts"].([]interface{})
+	for _, raw := range list {
+		account := raw.(map[string]interface{})
+		currencies[strField(t, account, "id")] = strField(t, account, "currency")
+	}
+	if accounts["base_currency"] != "USD" || currencies["acct-eur"] != "EUR" || currencies["acct-usd"] != "" {
+		t.Fatalf("expected the EUR account to be registered with its currency, got %#v", accounts)
+	}
+
+	near := func(got interface{}, want float64) bool {
+		v, ok := got.(float64)
+		return ok && v > want-1e-6 && v < want+1e-6
+	}
+
+	// Until the first account's currency is known it cannot be converted.
+	if status, body := requestJSONStatus(t, client, http.MethodGet, api.URL+"/analytics/performance", nil, adminToken); status != http.StatusUnprocessableEntity || !strings.Contains(fmt.Sprint(body["error"]), "acct-usd") {
+		t.Fatalf("expected 422 for the account without a currency, got %d %#v", status, body)
+	}
+	portfolio := getJSON(t, client, api.URL+"/analytics/portfolio", adminToken)
+	if unconverted, _ := portfolio["unconverted"].([]interface{}); len(unconverted) != 1 || unconverted[0] != "acct-usd" || !near(portfolio["total_equity"], 1000*1.1) {
+		t.Fatalf("expected the account without a currency to be unconverted, got %#v", portfolio)
+	}
+	if perf := getJSON(t, client, api.URL+"/analytics/performance?account_id=acct-usd", adminToken); perf["currency"] != "" || !near(perf["net_profit"], -10) {
+		t.Fatalf("expected one account's performance unconverted, got %#v", perf)
+	}
+	if status, _ := requestJSONStatus(t, client, http.MethodPut, api.URL+"/admin/accounts/acct-usd", map[string]string{"currency": "usd"}, adminToken); status != http.StatusOK {
+		t.Fatalf("expected the admin to set the currency, got %d", status)
+	}
+
+	perf := getJSON(t, client, api.URL+"/analytics/performance", adminToken)
+	if perf["currency"] != "USD" || !near(perf["net_profit"], 50*1.10-10) {
+		t.Fatalf("expected the EUR profit converted at the table rate, got %#v", perf)
+	}
+	perf = getJSON(t, client, api.URL+"/analytics/performance?account_id=acct-eur", adminToken)
+	if perf["currency"] != "EUR" || !near(perf["net_profit"], 50) {
+		t.Fatalf("expected one account's performance in its own currency, got %#v", perf)
+	}
+	if status, body := requestJSONStatus(t, client, http.MethodGet, api.URL+"/analytics/performance?currency=GBP", nil, adminToken); status != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 without a GBP rate, got %d %#v", status, body)
+	}
+
+	// A fresh EA quote replaces the table rate.
+	_ = postJSON(t, client, api.URL+"/ea/sync", map[string]interface{}{
+		"currency":  "EUR",
+		"equity":    1000.0,
+		"balance":   1000.0,
+		"positions": []interface{}{},
+		"quotes": []interface{}{
+			map[string]interface{}{"symbol": "EURUSD", "bid": 1.1999, "ask": 1.2001},
+			map[string]interface{}{"symbol": "GBPUSD", "bid": 1.2999, "ask": 1.3001},
+		},
+	}, eurToken)
+	rates := getJSON(t, client, api.URL+"/admin/fx/rates", adminToken)
+	if rates["count"] != 2.0 {
+		t.Fatalf("expected the EURUSD and GBPUSD quotes, got %#v", rates)
+	}
+	portfolio = getJSON(t, client, api.URL+"/analytics/portfolio", adminToken)
+	if portfolio["currency"] != "USD" || portfolio["count"] != 2.0 || !near(portfolio["total_equity"], 1000+1000*1.2) {
+		t.Fatalf("expected the EUR equity converted at the EA quote, got %#v", portfolio)
+	}
+	portfolio = getJSON(t, client, api.URL+"/analytics/portfolio?currency=gbp", adminToken)
+	if !near(portfolio["total_equity"], 1000/1.3+1000*1.2/1.3) {
+		t.Fatalf("expected a GBP total crossed through USD, got %#v", portfolio)
+	}
+	portfolio = getJSON(t, client, api.URL+"/analytics/portfolio?currency=JPY", adminToken)
+	if unconverted, _ := portfolio["unconverted"].([]interface{}); len(unconverted) != 2 || portfolio["total_equity"] != 0.0 {
+		t.Fatalf("expected both accounts unconverted without a JPY rate, got %#v", portfolio)
+	}
+
+	if status, _ := requestJSONStatus(t, client, http.MethodPut, api.URL+"/admin/accounts/acct-usd", map[string]string{"currency": "dollars"}, adminToken); status != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid currency, got %d", status)
+	}
+	if status, _ := requestJSONStatus(t, client, http.MethodPut, api.URL+"/admin/accounts/nope", map[string]string{"currency": "USD"}, adminToken); status != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown account, got %d", status)
+	}
+	status, account := requestJSONStatus(t, client, http.MethodPut, api.URL+"/admin/accounts/acct-usd", map[string]string{"currency": "gbp"}, adminToken)
+	if status != http.StatusOK || account["currency"] != "GBP" {
+		t.Fatalf("expected the admin to set the currency, got %d %#v", status, account)
+	}
+	portfolio = getJSON(t, client, api.URL+"/analytics/portfolio", adminToken)
+	if !near(portfolio["total_equity"], 1000*1.3+1000*1.2) {
+		t.Fatalf("expected the GBP account converted to USD, got %#v", portfolio)
+	}
+}
+
+func TestE2E_PaperBroker(t *testing.T) {
+	cfg := config.Config{
+		AdminUsername:     "admin",
+		AdminPassword:     "pw",
+		JWTSecret:         "jwt-secret",
+		EAConnectCode:     "MMBOT-ONE-TIME-CODE",
+		EATokenTTL:        24 * time.Hour,
+		MaxDailyLossPct:   2.0,
+		MaxOpenPositions:  5,
+		MaxSpreadPips:     2.0,
+		OpenAIAPIKey:      "sk-test",
+		OpenClawTimeout:   time.Second,
+		PaperAccounts:     "sim-1",
+		PaperBalance:      10000,
+		PaperCurrency:     "USD",
+		PaperSpreadPips:   1,
+		PaperSlippagePips: 0,
+		PaperTimeframe:    "M15",
+	}
+	st := memory.NewStore(24 * time.Hour)
+	srv := NewServer(
+		cfg,
+		st,
+		risk.NewEngine(cfg.MaxOpenPositions, cfg.MaxDailyLossPct, 0.70, cfg.MaxSpreadPips),
+		telegram.NewNotifier("", ""),
+		openclaw.NewClient("", time.Second, 0, 100*time.Millisecond, time.Second),
+	)
+	api := httptest.NewServer(srv.Router())
+	defer api.Close()
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	// Paper accounts are registered at startup, before their first sync.
+	if account, ok := st.Account("sim-1"); !ok || account.Currency != "USD" {
+		t.Fatalf("expected sim-1 registered in USD, got %+v (%v)", account, ok)
+	}
+
+	adminToken := strField(t, postJSON(t, client, api.URL+"/admin/login", map[string]string{
+		"username": "admin",
+		"password": "pw",
+	}, ""), "token")
+
+	now := time.Now().UTC()
+	quote := func(bid float64, at time.Time) map[string]interface{} {
+		return map[string]interface{}{"symbol": "EURUSD", "bid": bid, "time": at.Format(time.RFC3339Nano)}
+	}
+	if resp := postJSON(t, client, api.URL+"/admin/paper/quotes", map[string]interface{}{
+		"quotes": []interface{}{quote(1.1000, now.Add(-3*time.Minute))},
+	}, adminToken); resp["accepted"] != 1.0 {
+		t.Fatalf("expected the quote accepted, got %#v", resp)
+	}
+	resp := postJSON(t, client, api.URL+"/admin/signals/evaluate", map[string]interface{}{
+		"account_id":       "sim-1",
+		"symbol":           "EURUSD",
+		"side":             "BUY",
+		"confidence":       0.9,
+		"spread_pips":      1.0,
+		"stop_loss_pips":   10,
+		"take_profit_pips": 20,
+		"volume":           0.1,
+	}, adminToken)
+	if !boolField(resp, "allowed") {
+		t.Fatalf("expected signal to be allowed, got %#v", resp)
+	}
+
+	// The simulator picks up the queued OPEN and fills it at the ask.
+	step := postJSON(t, client, api.URL+"/admin/paper/step", nil, adminToken)
+	steps, _ := step["accounts"].([]interface{})
+	if len(steps) != 1 || steps[0].(map[string]interface{})["executed"] != 1.0 {
+		t.Fatalf("expected one command executed, got %#v", step)
+	}
+	open := getJSON(t, client, api.URL+"/admin/trades?status=open&account_id=sim-1", adminToken)
+	trades, _ := open["trades"].([]interface{})
+	if len(trades) != 1 || trades[0].(map[string]interface{})["ticket"] != "1" {
+		t.Fatalf("expected the paper fill journaled, got %#v", open)
+	}
+
+	// The market trades through the target between steps.
+	_ = postJSON(t, client, api.URL+"/admin/paper/quotes", map[string]interface{}{
+		"quotes": []interface{}{quote(1.1025, now.Add(-2*time.Minute)), quote(1.1015, now.Add(-time.Minute))},
+	}, adminToken)
+	step = postJSON(t, client, api.URL+"/admin/paper/step", nil, adminToken)
+	if steps, _ := step["accounts"].([]interface{}); steps[0].(map[string]interface{})["closed"] != 1.0 {
+		t.Fatalf("expected the take profit hit, got %#v", step)
+	}
+	closed := getJSON(t, client, api.URL+"/admin/trades?status=closed&account_id=sim-1", adminToken)
+	trades, _ = closed["trades"].([]interface{})
+	if len(trades) != 1 {
+		t.Fatalf("expected the trade closed, got %#v", closed)
+	}
+	trade := trades[0].(map[string]interface{})
+	if exit, _ := numField(trade, "exit_price"); trade["close_reason"] != "TP" || trade["profit"] != 20.0 || math.Abs(exit-1.1021) > 1e-9 {
+		t.Fatalf("expected the TP deal to complete the trade, got %#v", trade)
+	}
+	if deals := getJSON(t, client, api.URL+"/admin/deals?account_id=sim-1", adminToken); deals["count"] != 2.0 {
+		t.Fatalf("expected the opening and closing deals, got %#v", deals)
+	}
+	if issues := getJSON(t, client, api.URL+"/admin/reconcile/issues?status=open", adminToken); issues["count"] != 0.0 {
+		t.Fatalf("expected the simulated history to reconcile, got %#v", issues)
+	}
+	accounts := getJSON(t, client, api.URL+"/admin/paper/accounts", adminToken)
+	list, _ := accounts["accounts"].([]interface{})
+	if len(list) != 1 || list[0].(map[string]interface{})["balance"] != 10020.0 {
+		t.Fatalf("expected the profit booked to the paper balance, got %#v", accounts)
+	}
+
+	// Without a JPY rate a USDJPY fill cannot be valued in USD, so it is
+	// rejected rather than booked at a rate of 1.
+	_ = postJSON(t, client, api.URL+"/admin/paper/quotes", map[string]interface{}{
+		"quotes": []interface{}{map[string]interface{}{"symbol": "USDJPY", "bid": 150.0, "time": time.Now().UTC().Format(time.RFC3339Nano)}},
+	}, adminToken)
+	resp = postJSON(t, client, api.URL+"/admin/signals/evaluate", map[string]interface{}{
+		"account_id":       "sim-1",
+		"symbol":           "USDJPY",
+		"side":             "BUY",
+		"confidence":       0.9,
+		"spread_pips":      1.0,
+		"stop_loss_pips":   10,
+		"take_profit_pips": 20,
+		"volume":           0.1,
+	}, adminToken)
+	if !boolField(resp, "allowed") {
+		t.Fatalf("expected the USDJPY signal to be allowed, got %#v", resp)
+	}
+	_ = postJSON(t, client, api.URL+"/admin/paper/step", nil, adminToken)
+	accounts = getJSON(t, client, api.URL+"/admin/paper/accounts", adminToken)
+	list, _ = accounts["accounts"].([]interface{})
+	if positions, _ := list[0].(map[string]interface{})["positions"].([]interface{}); len(positions) != 0 || list[0].(map[string]interface{})["balance"] != 10020.0 {
+		t.Fatalf("expected the unconvertible USDJPY fill rejected, got %#v", accounts)
+	}
+	if _, err := (paperPrices{server: srv, currency: "USD", now: time.Now()}).Contract("USDJPY"); err == nil {
+		t.Fatal("expected no USDJPY contract without a JPY rate")
+	}
+
+	if status, _ := postJSONStatus(t, client, api.URL+"/admin/paper/quotes", map[string]interface{}{"quotes": []interface{}{}}, adminToken); status != http.StatusBadRequest {
+		t.Fatalf("expected an empty quote batch to be rejected, got %d", status)
+	}
+}
+
 func uptrendCandles(n int) []map[string]interface{} {
 	out := make([]map[string]interface{}, 0, n)
 	base := 1.0800
@@ -569,6 +2839,36 @@ func postJSONStatus(t *testing.T, client *http.Client, url string, body interfac
 	return resp.StatusCode, out
 }
 
+func requestJSONStatus(t *testing.T, client *http.Client, method, url string, body interface{}, bearerToken string) (int, map[string]interface{}) {
+	t.Helper()
+	var reader *bytes.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("marshal request: %v", err)
+		}
+		reader = bytes.NewReader(raw)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+bearerToken)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("do request: %v", err)
+	}
+	defer resp.Body.Close()
+	var out map[string]interface{}
+	_ = json.NewDecoder(resp.Body).Decode(&out)
+	return resp.StatusCode, out
+}
+
 func getJSON(t *testing.T, client *http.Client, url string, bearerToken string) map[string]interface{} {
 	t.Helper()
 	req, err := http.NewRequest(http.MethodGet, url, nil)
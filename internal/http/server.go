@@ -8,6 +8,9 @@ import (
 	"hash/fnv"
 	"log"
 	"net/http"
+	"net/url"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -22,9 +25,20 @@ import (
 	"mmbot/internal/domain"
 	"mmbot/internal/integrations/openclaw"
 	"mmbot/internal/integrations/telegram"
+	"mmbot/internal/service/analytics"
+	"mmbot/internal/service/equity"
+	"mmbot/internal/service/export"
+	"mmbot/internal/service/fx"
+	"mmbot/internal/service/journal"
 	"mmbot/internal/service/oauth"
+	"mmbot/internal/service/paper"
+	"mmbot/internal/service/reconcile"
+	"mmbot/internal/service/regime"
+	"mmbot/internal/service/report"
 	"mmbot/internal/service/risk"
+	"mmbot/internal/service/schedule"
 	"mmbot/internal/service/strategy"
+	"mmbot/internal/service/symbols"
 	storepkg "mmbot/internal/store"
 )
 
@@ -35,6 +49,15 @@ const (
 	contextKeyEASession    contextKey = "ea_session"
 )
 
+const (
+	// dailyBreakerSource is the pause source of the /ea/sync daily loss
+	// breaker, which may resume itself at the next trading day boundary.
+	dailyBreakerSource = "risk_circuit_breaker"
+	// accountBreakerSource is the pause source of the drawdown, weekly and
+	// monthly loss breakers, which only an admin resume lifts.
+	accountBreakerSource = "account_breaker"
+)
+
 type Server struct {
 	cfg                  config.Config
 	store                storepkg.Store
@@ -42,10 +65,21 @@ type Server struct {
 	notifier             *telegram.Notifier
 	openClaw             *openclaw.Client
 	openAIOAuth          *oauth.OpenAIClient
-	trendEngine          *strategy.TrendEngine
+	signalStrategy       strategy.Strategy
+	regimes              *regime.Service
+	regimeRoutes         regime.Routes
+	symbols              *symbols.Registry
+	tradingLocation      *time.Location
+	rates                *fx.Converter
+	paper                map[string]*paper.Account
+	market               *paper.Market
 	allowedTelegramChats map[string]bool
 	strategyUsageMu      sync.Mutex
 	strategyUsage        map[string]*strategyUsageState
+	policyMu             sync.Mutex
+	tradingDayMu         sync.Mutex
+	riskStateMu          sync.Mutex
+	paperMu              sync.Mutex
 }
 
 type strategyUsageState struct {
@@ -72,6 +106,8 @@ func NewServer(
 	if strings.TrimSpace(cfg.TelegramChatID) != "" {
 		allowedChats[strings.TrimSpace(cfg.TelegramChatID)] = true
 	}
+	strategies := strategy.NewRegistry(newTrendEngine(cfg), strategy.NewBreakoutEngine(), strategy.NewRangeEngine())
+	signalStrategy := newSignalStrategy(cfg, strategies)
 	return &Server{
 		cfg:        cfg,
 		store:      store,
@@ -86,7 +122,14 @@ func NewServer(
 			RedirectURI:  cfg.OpenAIRedirectURI,
 			Scopes:       parseScopes(cfg.OpenAIScopes),
 		},
-		trendEngine:          strategy.NewTrendEngine(),
+		signalStrategy:       signalStrategy,
+		regimes:              regime.NewService(store, regime.NewClassifier(), cfg.StrategyMaxCandles),
+		regimeRoutes:         newRegimeRoutes(cfg, strategies),
+		symbols:              symbols.NewRegistry(store),
+		tradingLocation:      newTradingLocation(cfg),
+		rates:                newConverter(cfg),
+		paper:                newPaperAccounts(cfg, store),
+		market:               paper.NewMarket(store, cfg.PaperTimeframe),
 		allowedTelegramChats: allowedChats,
 		strategyUsage:        make(map[string]*strategyUsageState),
 	}
@@ -111,11 +154,43 @@ func (s *Server) Router() http.Handler {
 		protected.Post("/bot/pause", s.handlePause)
 		protected.Post("/bot/resume", s.handleResume)
 		protected.Get("/dashboard/summary", s.handleDashboardSummary)
+		protected.Get("/analytics/performance", s.handlePerformance)
+		protected.Get("/analytics/equity", s.handleEquityCurve)
+		protected.Get("/analytics/portfolio", s.handlePortfolio)
+		protected.Get("/reports", s.handleListReports)
+		protected.Get("/reports/{id}", s.handleGetReport)
 		protected.Get("/events", s.handleListEvents)
 		protected.Get("/oauth/openai/status", s.handleOpenAIStatus)
 		protected.Post("/oauth/openai/disconnect", s.handleOpenAIDisconnect)
 		protected.Post("/admin/signals/evaluate", s.handleEvaluateSignal)
 		protected.Post("/admin/strategy/evaluate", s.handleTrendEvaluate)
+		protected.Get("/admin/regime", s.handleRegime)
+		protected.Get("/admin/symbols", s.handleListSymbols)
+		protected.Get("/admin/symbols/{symbol}", s.handleGetSymbol)
+		protected.Put("/admin/symbols/{symbol}", s.handlePutSymbolOverride)
+		protected.Delete("/admin/symbols/{symbol}", s.handleDeleteSymbolOverride)
+		protected.Post("/admin/symbols/{symbol}/resume", s.handleSymbolResume)
+		protected.Get("/admin/fills/stats", s.handleFillStats)
+		protected.Get("/admin/trades", s.handleListTrades)
+		protected.Get("/admin/trades/{id}", s.handleGetTrade)
+		protected.Get("/admin/export/{dataset}", s.handleExport)
+		protected.Get("/admin/deals", s.handleListDeals)
+		protected.Get("/admin/reconcile/issues", s.handleListReconcileIssues)
+		protected.Get("/admin/blackouts", s.handleListBlackouts)
+		protected.Post("/admin/blackouts", s.handleCreateBlackout)
+		protected.Post("/admin/blackouts/import", s.handleImportBlackouts)
+		protected.Delete("/admin/blackouts/{id}", s.handleDeleteBlackout)
+		protected.Get("/admin/accounts", s.handleListAccounts)
+		protected.Put("/admin/accounts/{account_id}", s.handlePutAccount)
+		protected.Get("/admin/fx/rates", s.handleFXRates)
+		protected.Get("/admin/paper/accounts", s.handleListPaperAccounts)
+		protected.Post("/admin/paper/quotes", s.handlePushPaperQuotes)
+		protected.Post("/admin/paper/step", s.handlePaperStep)
+		protected.Get("/admin/accounts/{account_id}/risk", s.handleAccountRisk)
+		protected.Post("/admin/accounts/{account_id}/resume", s.handleAccountResume)
+		protected.Post("/admin/accounts/{account_id}/reconcile/issues/{id}/resolve", s.handleResolveReconcileIssue)
+		protected.Get("/admin/risk/policy", s.handleRiskPolicy)
+		protected.Post("/admin/risk/policy/reload", s.handleReloadRiskPolicy)
 	})
 
 	r.Group(func(ea chi.Router) {
@@ -124,6 +199,8 @@ func (s *Server) Router() http.Handler {
 		ea.Post("/ea/sync", s.handleEASync)
 		ea.Post("/ea/execute", s.handleEAExecute)
 		ea.Post("/ea/result", s.handleEAResult)
+		ea.Post("/ea/symbols", s.handleEASymbols)
+		ea.Post("/ea/deals", s.handleEADeals)
 	})
 
 	return r
@@ -285,7 +362,7 @@ func (s *Server) handleEAHeartbeat(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"ok":          true,
 		"server_time": time.Now().UTC().Format(time.RFC3339),
-		"paused":      s.store.IsPaused(),
+		"paused":      s.store.IsPaused() || s.accountPaused(session.AccountID),
 	})
 }
 
@@ -300,40 +377,557 @@ func (s *Server) handleEASync(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	s.store.SavePositionSnapshot(session.AccountID, payload)
+	writeJSON(w, http.StatusOK, s.applySync(r.Context(), session.AccountID, payload))
+}
+
+// applySync records a sync snapshot for accountID: closed trades, the
+// journal, equity, daily loss and drawdown. It returns the sync response.
+func (s *Server) applySync(ctx context.Context, accountID string, payload map[string]interface{}) map[string]interface{} {
+	s.tradeActivity(ctx, accountID, time.Now())
+	prevSnapshot, hadSnapshot := s.store.PositionSnapshot(accountID)
+	s.store.SavePositionSnapshot(accountID, payload)
+	s.observeAccount(accountID, payload, time.Now())
+	var closed []domain.Position
+	if hadSnapshot {
+		closed = risk.ClosedPositions(prevSnapshot, payload)
+		s.recordClosedTrades(ctx, accountID, closed)
+	}
+	s.updateJournal(accountID, risk.SnapshotPositions(payload), closed, time.Now())
+	s.recordEquity(accountID, payload, time.Now())
 
 	metrics := risk.DeriveSnapshotMetrics(payload)
-	s.store.SetOpenPositions(session.AccountID, metrics.OpenPositions)
-	s.store.SetDailyLoss(session.AccountID, metrics.DailyLossPct)
+	if _, ok := s.riskEngine.TradingDay(accountID); ok {
+		metrics.DailyLossPct = s.tradingDayLoss(ctx, accountID, risk.SnapshotEquity(payload), time.Now())
+	}
+	s.store.SetOpenPositions(accountID, metrics.OpenPositions)
+	s.store.SetDailyLoss(accountID, metrics.DailyLossPct)
 
 	triggeredCircuitBreaker := false
-	if metrics.DailyLossPct >= s.cfg.MaxDailyLossPct && !s.store.IsPaused() {
+	maxDailyLossPct := s.riskEngine.Thresholds(accountID, "").MaxDailyLossPct
+	if metrics.DailyLossPct >= maxDailyLossPct && !s.store.IsPaused() {
 		triggeredCircuitBreaker = true
-		s.store.SetPaused(true)
-		s.emitEvent(domain.EventRiskTriggered, session.AccountID, map[string]interface{}{
+		now := time.Now().UTC()
+		s.store.SetPausedBy(domain.PauseOwner{Source: dailyBreakerSource, AccountID: accountID, At: now})
+		s.updateTradeActivity(ctx, accountID, now, func(a domain.TradeActivity) domain.TradeActivity {
+			a.DailyBreakerAt = now
+			return a
+		})
+		s.emitEvent(domain.EventRiskTriggered, accountID, map[string]interface{}{
 			"reason":         "daily_loss_limit_hit_sync",
 			"daily_loss_pct": metrics.DailyLossPct,
-			"threshold_pct":  s.cfg.MaxDailyLossPct,
+			"threshold_pct":  maxDailyLossPct,
 			"net_pnl":        metrics.NetPnL,
 			"equity":         metrics.Equity,
 		})
-		s.emitEvent(domain.EventBotPaused, session.AccountID, map[string]interface{}{
+		s.emitEvent(domain.EventBotPaused, accountID, map[string]interface{}{
 			"paused": true,
-			"source": "risk_circuit_breaker",
+			"source": dailyBreakerSource,
 		})
-		_ = s.notifier.Notify(r.Context(), fmt.Sprintf(
+		_ = s.notifier.Notify(ctx, fmt.Sprintf(
 			"Daily loss circuit breaker triggered: %.2f%% >= %.2f%%. Bot paused.",
 			metrics.DailyLossPct,
-			s.cfg.MaxDailyLossPct,
+			maxDailyLossPct,
 		))
 	}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
+	accountRisk, breached := s.trackAccountRisk(ctx, accountID, risk.SnapshotEquity(payload))
+	if breached {
+		triggeredCircuitBreaker = true
+	}
+
+	return map[string]interface{}{
 		"ok":                        true,
 		"open_positions":            metrics.OpenPositions,
 		"daily_loss_pct":            metrics.DailyLossPct,
+		"drawdown_pct":              risk.DrawdownPct(accountRisk),
+		"margin_level":              metrics.MarginLevel,
+		"account_paused":            accountRisk.Paused,
 		"triggered_circuit_breaker": triggeredCircuitBreaker,
+	}
+}
+
+// publishReport builds and archives an account report for [from, to) and
+// delivers it to Telegram and, as a ReportGenerated event, to OpenClaw. It
+// runs as the period closes, so the last synced equity ends it.
+func (s *Server) publishReport(ctx context.Context, accountID string, kind domain.ReportKind, period string, from, to time.Time, startEquity float64) domain.Report {
+	points := s.store.ListEquityPoints(accountID, equity.Minute, from, to)
+	if len(points) == 0 {
+		points = s.store.ListEquityPoints(accountID, equity.Raw, from, to)
+	}
+	var endEquity float64
+	if state, ok := s.store.AccountRiskState(accountID); ok {
+		endEquity = state.LastEquity
+	}
+	built := report.Build(report.Input{
+		AccountID:   accountID,
+		Kind:        kind,
+		Period:      period,
+		From:        from,
+		To:          to,
+		Trades:      s.reportTrades(accountID, from, to),
+		Points:      points,
+		Events:      s.store.ListAccountEvents(accountID, from, to),
+		StartEquity: startEquity,
+		EndEquity:   endEquity,
+	}, time.Now())
+	built.Currency, _ = s.accountCurrency(accountID)
+	rep := s.store.SaveReport(built)
+	s.emitEvent(domain.EventReportGenerated, accountID, map[string]interface{}{
+		"report_id": rep.ID,
+		"kind":      rep.Kind,
+		"period":    rep.Period,
+		"report":    rep,
+	})
+	_ = s.notifier.Notify(ctx, report.Text(rep))
+	return rep
+}
+
+// reportTrades loads the trades a report over [from, to) counts: those
+// opened in the period and those closed in it.
+func (s *Server) reportTrades(accountID string, from, to time.Time) []domain.Trade {
+	trades := s.store.ListTrades(domain.TradeQuery{AccountID: accountID, From: from, To: to})
+	seen := make(map[string]bool, len(trades))
+	for _, t := range trades {
+		seen[t.ID] = true
+	}
+	closed := s.store.ListTrades(domain.TradeQuery{AccountID: accountID, Status: domain.TradeStatusClosed, ClosedFrom: from, ClosedTo: to})
+	for _, t := range closed {
+		if !seen[t.ID] {
+			trades = append(trades, t)
+		}
+	}
+	return trades
+}
+
+// observeAccount records the account currency and the FX quotes a sync
+// snapshot reports.
+func (s *Server) observeAccount(accountID string, snapshot map[string]interface{}, now time.Time) {
+	if currency := risk.SnapshotCurrency(snapshot); fx.ValidCode(currency) {
+		if account, ok := s.store.Account(accountID); !ok || account.Currency != currency {
+			s.store.SaveAccount(domain.Account{ID: accountID, Currency: currency, UpdatedAt: now.UTC()})
+		}
+	}
+	for symbol, mid := range risk.SnapshotQuotes(snapshot) {
+		if base, quote, ok := fx.SplitPair(symbol, nil); ok {
+			s.rates.Observe(base, quote, mid, now)
+		}
+	}
+}
+
+// baseCurrency is BASE_CURRENCY, or USD when it is not a currency code.
+func (s *Server) baseCurrency() string {
+	if currency := fx.Normalize(s.cfg.BaseCurrency); fx.ValidCode(currency) {
+		return currency
+	}
+	return "USD"
+}
+
+// accountCurrency is the account's currency and whether it is known. An
+// account whose currency is unknown cannot be converted.
+func (s *Server) accountCurrency(accountID string) (string, bool) {
+	if account, ok := s.store.Account(accountID); ok && account.Currency != "" {
+		return account.Currency, true
+	}
+	return "", false
+}
+
+// accountRate is the rate from the account's currency to currency. Without a
+// target currency the figures stay in the account's own, known or not.
+func (s *Server) accountRate(accountID, currency string, now time.Time) (float64, error) {
+	native, ok := s.accountCurrency(accountID)
+	if !ok {
+		if currency == "" {
+			return 1, nil
+		}
+		return 0, fmt.Errorf("currency of account %s is unknown", accountID)
+	}
+	return s.rates.Rate(native, currency, now)
+}
+
+// currencyParam reads the currency query parameter, defaulting to fallback.
+func currencyParam(query url.Values, fallback string) (string, error) {
+	raw := query.Get("currency")
+	if raw == "" {
+		return fallback, nil
+	}
+	currency := fx.Normalize(raw)
+	if !fx.ValidCode(currency) {
+		return "", errors.New("currency must be a three-letter code")
+	}
+	return currency, nil
+}
+
+// convertReport restates a report's money figures in currency at the rate
+// of now, not the rate when the report was built. A report built before its
+// account's currency was known is taken to be in the account's currency now.
+func (s *Server) convertReport(rep domain.Report, currency string, now time.Time) (domain.Report, error) {
+	var rate float64
+	var err error
+	if rep.Currency != "" {
+		rate, err = s.rates.Rate(rep.Currency, currency, now)
+	} else {
+		rate, err = s.accountRate(rep.AccountID, currency, now)
+	}
+	if err != nil {
+		return rep, err
+	}
+	rep.NetProfit *= rate
+	rep.StartEquity *= rate
+	rep.EndEquity *= rate
+	rep.MaxDrawdown *= rate
+	rep.Currency = currency
+	return rep, nil
+}
+
+// tradingDayLoss measures the daily loss from the equity at the start of the
+// account's trading day instead of the EA's server-midnight figure. The
+// first sync of a day sets its starting equity.
+func (s *Server) tradingDayLoss(ctx context.Context, accountID string, equity float64, now time.Time) float64 {
+	activity := s.tradeActivity(ctx, accountID, now)
+	if activity.DayStartEquity <= 0 && equity > 0 {
+		activity = s.updateTradeActivity(ctx, accountID, now, func(a domain.TradeActivity) domain.TradeActivity {
+			if a.DayStartEquity <= 0 {
+				a.DayStartEquity = equity
+			}
+			return a
+		})
+	}
+	return risk.DayLossPct(activity, equity)
+}
+
+// trackAccountRisk updates the account's equity high-water mark and period
+// anchors, and pauses the account when a drawdown breaker trips. It reports
+// true only on the sync that trips the breaker.
+func (s *Server) trackAccountRisk(ctx context.Context, accountID string, equity float64) (domain.AccountRiskState, bool) {
+	s.riskStateMu.Lock()
+	state, ok := s.store.AccountRiskState(accountID)
+	if !ok {
+		state = domain.AccountRiskState{AccountID: accountID}
+	}
+	day, _ := s.riskEngine.TradingDay(accountID)
+	state = risk.TrackEquity(state, equity, time.Now(), day)
+	breach, tripped := s.drawdownLimits().Check(state)
+	if !tripped || state.Paused {
+		s.store.SaveAccountRiskState(state)
+		s.riskStateMu.Unlock()
+		return state, false
+	}
+	state.Paused = true
+	state.PauseReason = breach.Reason
+	state.PausedAt = time.Now().UTC()
+	s.store.SaveAccountRiskState(state)
+	s.riskStateMu.Unlock()
+
+	s.emitEvent(domain.EventRiskTriggered, accountID, map[string]interface{}{
+		"reason":        breach.Reason,
+		"loss_pct":      breach.LossPct,
+		"threshold_pct": breach.ThresholdPct,
+		"equity":        state.LastEquity,
+		"peak_equity":   state.PeakEquity,
+	})
+	s.emitEvent(domain.EventBotPaused, accountID, map[string]interface{}{
+		"paused": true,
+		"source": accountBreakerSource,
+		"reason": breach.Reason,
+	})
+	_ = s.notifier.Notify(ctx, fmt.Sprintf(
+		"Account %s breaker triggered (%s): %.2f%% >= %.2f%%. Account paused until resumed.",
+		accountID, breach.Reason, breach.LossPct, breach.ThresholdPct,
+	))
+	return state, true
+}
+
+// recordClosedTrades feeds positions that vanished since the previous sync
+// into the loss streak and emits LossStreakCooldown when a cooldown starts.
+func (s *Server) recordClosedTrades(ctx context.Context, accountID string, closed []domain.Position) {
+	if len(closed) == 0 {
+		return
+	}
+	type cooldown struct {
+		position domain.Position
+		until    time.Time
+	}
+	var started []cooldown
+	s.updateTradeActivity(ctx, accountID, time.Now(), func(a domain.TradeActivity) domain.TradeActivity {
+		for _, p := range closed {
+			var ok bool
+			if a, ok = s.riskEngine.RecordClose(a, p.Profit+p.Swap+p.Commission, time.Now()); ok {
+				started = append(started, cooldown{position: p, until: a.CooldownUntil})
+			}
+		}
+		return a
+	})
+	for _, c := range started {
+		s.emitEvent(domain.EventLossStreakCooldown, accountID, map[string]interface{}{
+			"last_ticket":    c.position.Ticket,
+			"last_symbol":    c.position.Symbol,
+			"cooldown_until": c.until.Format(time.RFC3339),
+		})
+		_ = s.notifier.Notify(ctx, fmt.Sprintf(
+			"Loss streak on %s: new opens paused until %s.",
+			accountID, c.until.Format(time.RFC3339),
+		))
+	}
+}
+
+func (s *Server) equityPolicy() equity.Policy {
+	return equity.Policy{
+		SampleInterval:  s.cfg.EquitySampleInterval,
+		RawRetention:    s.cfg.EquityRawRetention,
+		MinuteRetention: s.cfg.EquityMinuteRetention,
+		HourRetention:   s.cfg.EquityHourRetention,
+	}
+}
+
+// recordEquity adds a sync to the account's equity curve. Every sync is
+// folded into the current 1m and 1h buckets; a raw point is kept only once
+// per sample interval. Opening a new bucket prunes points past retention.
+func (s *Server) recordEquity(accountID string, snapshot map[string]interface{}, now time.Time) {
+	point := equity.Sample(accountID, snapshot, now)
+	if point.Equity <= 0 {
+		return
+	}
+	policy := s.equityPolicy()
+	if last, ok := s.store.LatestEquityPoint(accountID, equity.Raw); !ok || point.Time.Sub(last.Time) >= policy.SampleInterval {
+		s.store.SaveEquityPoint(point)
+	}
+	for i, res := range equity.Rollups {
+		bucket := equity.Bucket(point, res)
+		if current, ok := s.store.LatestEquityPoint(accountID, res); ok && current.Time.Equal(bucket.Time) {
+			bucket = current
+		} else {
+			finer := equity.Raw
+			if i > 0 {
+				finer = equity.Rollups[i-1]
+			}
+			for _, r := range []string{finer, res} {
+				if keep := policy.Retention(r); keep > 0 {
+					s.store.PruneEquityPoints(accountID, r, now.Add(-keep))
+				}
+			}
+		}
+		s.store.SaveEquityPoint(equity.Fold(bucket, point))
+	}
+}
+
+// updateJournal folds a sync into the trade journal: open trades pick up
+// their synced entry price and SL/TP changes, and trades whose tickets
+// closed are finished with their last reported state. A position no trade
+// tracks is matched by symbol and side to a trade opened without a ticket.
+func (s *Server) updateJournal(accountID string, positions, closed []domain.Position, now time.Time) {
+	for _, p := range positions {
+		if p.Ticket == "" {
+			continue
+		}
+		trade, ok := s.store.OpenTradeByTicket(accountID, p.Ticket)
+		adopted := false
+		if !ok {
+			open := s.store.ListTrades(domain.TradeQuery{AccountID: accountID, Symbol: p.Symbol, Status: domain.TradeStatusOpen})
+			if trade, ok = journal.Adopt(open, p); !ok {
+				continue
+			}
+			adopted = true
+		}
+		trade, changed := journal.ApplySnapshot(trade, p, now)
+		if adopted && !changed {
+			trade.UpdatedAt = now.UTC()
+		}
+		if adopted || changed {
+			s.store.SaveTrade(trade)
+		}
+	}
+	for _, p := range closed {
+		if p.Ticket == "" {
+			continue
+		}
+		trade, ok := s.store.OpenTradeByTicket(accountID, p.Ticket)
+		if !ok {
+			continue
+		}
+		trade = s.store.SaveTrade(journal.Close(trade, p, s.symbols.PipSize(trade.Symbol), now))
+		s.emitEvent(domain.EventTradeClosed, accountID, map[string]interface{}{
+			"trade_id":         trade.ID,
+			"ticket":           trade.Ticket,
+			"symbol":           trade.Symbol,
+			"side":             trade.Side,
+			"profit":           trade.Profit,
+			"r_multiple":       trade.RMultiple,
+			"duration_seconds": trade.DurationSeconds,
+		})
+	}
+}
+
+// journalCommand records a successful command against the account's open
+// trades on its symbol: an OPEN starts a trade, MOVE_SL and SET_TP record a
+// modification and CLOSE links the closing command.
+func (s *Server) journalCommand(cmd domain.Command, ticket string, now time.Time) {
+	if cmd.Type == domain.CommandOpen {
+		s.store.SaveTrade(journal.Open(cmd, ticket, now))
+		return
+	}
+	if cmd.Type != domain.CommandMoveSL && cmd.Type != domain.CommandSetTP && cmd.Type != domain.CommandClose {
+		return
+	}
+	open := s.store.ListTrades(domain.TradeQuery{AccountID: cmd.AccountID, Symbol: cmd.Symbol, Status: domain.TradeStatusOpen})
+	for _, trade := range open {
+		switch cmd.Type {
+		case domain.CommandMoveSL:
+			trade = journal.Modify(trade, "sl", cmd.SL, cmd.ID, now)
+		case domain.CommandSetTP:
+			trade = journal.Modify(trade, "tp", cmd.TP, cmd.ID, now)
+		case domain.CommandClose:
+			trade.CloseCommandID = cmd.ID
+		}
+		s.store.SaveTrade(trade)
+	}
+}
+
+// tradeActivity loads the account's activity rolled over to now's trading
+// day. The first call past a boundary closes out the previous day.
+func (s *Server) tradeActivity(ctx context.Context, accountID string, now time.Time) domain.TradeActivity {
+	return s.updateTradeActivity(ctx, accountID, now, nil)
+}
+
+// updateTradeActivity is tradeActivity followed by change and a save. The
+// load, change and save happen under tradingDayMu so concurrent results and
+// syncs do not lose each other's counts. The days a rollover closes are
+// published after the lock is released.
+func (s *Server) updateTradeActivity(ctx context.Context, accountID string, now time.Time, change func(domain.TradeActivity) domain.TradeActivity) domain.TradeActivity {
+	activity, closed := s.rollTradeActivity(accountID, now, change)
+	for _, c := range closed {
+		s.closeTradingDay(ctx, c)
+	}
+	return activity
+}
+
+// closedDay is a trading day a rollover ended. It is collected under
+// tradingDayMu and published by closeTradingDay once the lock is released.
+type closedDay struct {
+	accountID string
+	day       risk.TradingDay
+	activity  domain.TradeActivity
+	// next is the key of the day that follows.
+	next         string
+	dailyLossPct float64
+	// weekStartEquity opens the weekly report when this day ends a week;
+	// zero falls back to the week's first equity point.
+	weekStartEquity float64
+}
+
+// rollTradeActivity is the locked part of updateTradeActivity. A rollover
+// closes the activity's day and every later day the account was not active
+// on, oldest first, and resets the daily loss.
+func (s *Server) rollTradeActivity(accountID string, now time.Time, change func(domain.TradeActivity) domain.TradeActivity) (domain.TradeActivity, []closedDay) {
+	s.tradingDayMu.Lock()
+	defer s.tradingDayMu.Unlock()
+	activity, ok := s.store.TradeActivity(accountID)
+	if !ok {
+		activity = domain.TradeActivity{AccountID: accountID}
+	}
+	day, _ := s.riskEngine.TradingDay(accountID)
+	today := day.Key(now)
+	var closed []closedDay
+	if activity.Day != "" && activity.Day != today {
+		closed = append(closed, closedDay{accountID: accountID, day: day, activity: activity, dailyLossPct: s.store.DailyLoss(accountID)})
+		for {
+			next, err := day.Next(closed[len(closed)-1].activity.Day)
+			if err != nil {
+				next = today
+			}
+			closed[len(closed)-1].next = next
+			if next >= today {
+				break
+			}
+			closed = append(closed, closedDay{accountID: accountID, day: day, activity: domain.TradeActivity{AccountID: accountID, Day: next}})
+		}
+		s.store.SetDailyLoss(accountID, 0)
+		// The risk state's week has not rolled yet when the week's last
+		// day closes; only a matching week start is the report's.
+		if state, ok := s.store.AccountRiskState(accountID); ok {
+			for i := range closed {
+				weekStart, _, err := day.Bounds(report.WeekStart(closed[i].activity.Day))
+				if err == nil && weekStart.UTC().Equal(state.WeekStart) {
+					closed[i].weekStartEquity = state.WeekStartEquity
+				}
+			}
+		}
+	}
+	activity = day.Roll(activity, now)
+	if change != nil {
+		activity = change(activity)
+	}
+	if len(closed) > 0 || change != nil {
+		activity.UpdatedAt = now.UTC()
+		s.store.SaveTradeActivity(activity)
+	}
+	return activity, closed
+}
+
+// closeTradingDay publishes the daily report of a closed day, and the
+// weekly one when the next day starts a new week. It then emits the day
+// summary and, with DAILY_BREAKER_AUTO_RESUME, lifts a pause the daily loss
+// breaker set that day.
+func (s *Server) closeTradingDay(ctx context.Context, c closedDay) {
+	accountID, day, activity := c.accountID, c.day, c.activity
+	summary := map[string]interface{}{
+		"day":            activity.Day,
+		"boundary":       day.String(),
+		"trades_opened":  activity.TradesToday,
+		"daily_loss_pct": c.dailyLossPct,
+	}
+	if start, end, err := day.Bounds(activity.Day); err == nil {
+		daily := s.publishReport(ctx, accountID, domain.ReportDaily, activity.Day, start, end, activity.DayStartEquity)
+		summary["start"] = start.UTC().Format(time.RFC3339)
+		summary["end"] = end.UTC().Format(time.RFC3339)
+		summary["trades_closed"] = daily.TradesClosed
+		summary["wins"] = daily.Wins
+		summary["net_profit"] = daily.NetProfit
+		summary["report_id"] = daily.ID
+		if week := report.WeekKey(activity.Day); week != report.WeekKey(c.next) {
+			if weekStart, _, err := day.Bounds(report.WeekStart(activity.Day)); err == nil {
+				s.publishReport(ctx, accountID, domain.ReportWeekly, week, weekStart, end, c.weekStartEquity)
+			}
+		}
+	}
+	if activity.DayStartEquity > 0 {
+		summary["start_equity"] = activity.DayStartEquity
+	}
+	if state, ok := s.store.AccountRiskState(accountID); ok && state.LastEquity > 0 {
+		summary["end_equity"] = state.LastEquity
+	}
+
+	// Only a pause this account's daily breaker still owns is lifted; an
+	// admin pause or another account's breaker stays in force.
+	owner, owned := s.store.PauseOwner()
+	resume := s.cfg.DailyBreakerAutoResume && !activity.DailyBreakerAt.IsZero() &&
+		owned && owner.Source == dailyBreakerSource && owner.AccountID == accountID
+	summary["breaker_resumed"] = resume
+	s.emitEvent(domain.EventTradingDayClosed, accountID, summary)
+	if !resume {
+		return
+	}
+	s.store.SetPaused(false)
+	s.emitEvent(domain.EventBotPaused, accountID, map[string]interface{}{
+		"paused": false,
+		"source": "trading_day_rollover",
 	})
+	_ = s.notifier.Notify(ctx, fmt.Sprintf(
+		"Trading day %s closed for %s. Daily loss breaker lifted; bot resumed.",
+		activity.Day, accountID,
+	))
+}
+
+func (s *Server) drawdownLimits() risk.DrawdownLimits {
+	return risk.DrawdownLimits{
+		MaxDrawdownPct:    s.cfg.MaxDrawdownPct,
+		MaxWeeklyLossPct:  s.cfg.MaxWeeklyLossPct,
+		MaxMonthlyLossPct: s.cfg.MaxMonthlyLossPct,
+	}
+}
+
+func (s *Server) accountPaused(accountID string) bool {
+	state, ok := s.store.AccountRiskState(accountID)
+	return ok && state.Paused
 }
 
 func (s *Server) handleEAExecute(w http.ResponseWriter, r *http.Request) {
@@ -351,7 +945,7 @@ func (s *Server) handleEAExecute(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]interface{}{
+	resp := map[string]interface{}{
 		"command_id": cmd.ID,
 		"type":       cmd.Type,
 		"symbol":     cmd.Symbol,
@@ -361,7 +955,13 @@ func (s *Server) handleEAExecute(w http.ResponseWriter, r *http.Request) {
 		"tp":         cmd.TP,
 		"reason":     cmd.Reason,
 		"expires_at": cmd.ExpiresAt.Format(time.RFC3339),
-	})
+	}
+	// SL/TP are in pips; tell the EA what a pip is when the registry knows,
+	// otherwise it keeps its own digits-based conversion.
+	if spec, ok := s.symbols.Get(cmd.Symbol); ok && spec.PipSize > 0 {
+		resp["pip_size"] = spec.PipSize
+	}
+	writeJSON(w, http.StatusOK, resp)
 }
 
 func (s *Server) handleEAResult(w http.ResponseWriter, r *http.Request) {
@@ -375,59 +975,1116 @@ func (s *Server) handleEAResult(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	cmd, err := s.store.MarkCommandResult(req)
+	event, err := s.applyResult(r.Context(), session.AccountID, req)
 	if err != nil {
+		log.Printf("ea result for command %s not recorded: %v", req.CommandID, err)
 		writeError(w, http.StatusNotFound, "command not found")
 		return
 	}
-	success := strings.EqualFold(req.Status, "SUCCESS")
-	if success {
-		if cmd.Type == domain.CommandOpen {
-			s.store.AdjustOpenPositions(session.AccountID, 1)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"ok":       true,
+		"event_id": event.ID,
+	})
+}
+
+// applyResult records a command's execution result for accountID and emits
+// its trade event. It fails when the command is unknown.
+func (s *Server) applyResult(ctx context.Context, accountID string, req domain.CommandResult) (domain.Event, error) {
+	success := strings.EqualFold(req.Status, "SUCCESS")
+	if success && req.SlippagePips == 0 && req.RequestedPrice > 0 && req.FillPrice > 0 {
+		if pending, ok := s.store.Command(req.CommandID); ok {
+			req.SlippagePips = risk.SlippagePips(pending.Type, pending.Side, req.RequestedPrice, req.FillPrice, s.symbols.PipSize(pending.Symbol))
+		}
+	}
+	cmd, err := s.store.MarkCommandResult(req)
+	if err != nil {
+		return domain.Event{}, err
+	}
+	if success {
+		if cmd.Type == domain.CommandOpen {
+			s.store.AdjustOpenPositions(accountID, 1)
+			var reached bool
+			activity := s.updateTradeActivity(ctx, accountID, time.Now(), func(a domain.TradeActivity) domain.TradeActivity {
+				a, reached = s.riskEngine.RecordOpen(a, time.Now())
+				return a
+			})
+			if reached {
+				s.emitEvent(domain.EventDailyTradeLimit, accountID, map[string]interface{}{
+					"day":          activity.Day,
+					"trades_today": activity.TradesToday,
+				})
+			}
+		}
+		if cmd.Type == domain.CommandClose {
+			s.store.AdjustOpenPositions(accountID, -1)
+		}
+		if req.FillPrice > 0 {
+			s.checkFillQuality(ctx, cmd.Symbol, time.Now())
+		}
+		s.journalCommand(cmd, req.BrokerTicket, time.Now())
+	}
+
+	eventType := domain.EventTradeExecuted
+	if cmd.Type == domain.CommandMoveSL || cmd.Type == domain.CommandSetTP {
+		eventType = domain.EventTradeModified
+	}
+	payload := map[string]interface{}{
+		"command_id":    req.CommandID,
+		"status":        req.Status,
+		"broker_ticket": req.BrokerTicket,
+		"error_code":    req.ErrorCode,
+		"error_message": req.ErrorMessage,
+		"command_type":  cmd.Type,
+		"symbol":        cmd.Symbol,
+	}
+	if req.FillPrice > 0 {
+		payload["requested_price"] = req.RequestedPrice
+		payload["fill_price"] = req.FillPrice
+		payload["slippage_pips"] = req.SlippagePips
+		payload["latency_ms"] = req.LatencyMS
+	}
+	event := s.emitEvent(eventType, accountID, payload)
+	if success {
+		_ = s.notifier.Notify(ctx, fmt.Sprintf("[%s] %s %s %.2f", cmd.Type, cmd.Side, cmd.Symbol, cmd.Volume))
+	}
+	return event, nil
+}
+
+// checkFillQuality pauses a symbol whose recent fills slipped more than the
+// limit on average. Fills before the last resume or expired pause are not
+// counted, so they cannot pause the symbol again.
+func (s *Server) checkFillQuality(ctx context.Context, symbol string, now time.Time) {
+	prior, _ := s.store.SymbolPause(symbol)
+	if prior.Active(now) {
+		return
+	}
+	since := prior.ResumedAt
+	if prior.Paused && prior.Until.After(since) {
+		since = prior.Until
+	}
+	fills := s.store.ListFills(symbol, since, s.riskEngine.SlippageLimits().Window)
+	stats, ok := risk.ComputeFillStats(fills)[symbol]
+	if !ok {
+		return
+	}
+	pause, breached := s.riskEngine.SlippageBreach(stats, now)
+	if !breached {
+		return
+	}
+	pause.ResumedAt = prior.ResumedAt
+	s.store.SaveSymbolPause(pause)
+	s.emitEvent(domain.EventSymbolPaused, "", map[string]interface{}{
+		"symbol":            pause.Symbol,
+		"reason":            pause.Reason,
+		"avg_slippage_pips": pause.AvgSlippagePips,
+		"fills":             pause.Fills,
+		"until":             pause.Until,
+	})
+	_ = s.notifier.Notify(ctx, fmt.Sprintf("Symbol %s paused: average slippage %.2f pips over %d fills.", pause.Symbol, pause.AvgSlippagePips, pause.Fills))
+}
+
+func (s *Server) symbolPaused(symbol string, now time.Time) bool {
+	pause, ok := s.store.SymbolPause(symbol)
+	return ok && pause.Active(now)
+}
+
+func (s *Server) handleEASymbols(w http.ResponseWriter, r *http.Request) {
+	session, err := sessionFromContext(r.Context())
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "missing ea session")
+		return
+	}
+	var req struct {
+		Symbols []domain.SymbolSpec `json:"symbols"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	type rejection struct {
+		Symbol string `json:"symbol"`
+		Error  string `json:"error"`
+	}
+	saved := 0
+	rejected := make([]rejection, 0)
+	for _, spec := range req.Symbols {
+		spec.Source = domain.SymbolSpecSourceEA
+		if err := symbols.Validate(spec); err != nil {
+			rejected = append(rejected, rejection{Symbol: spec.Symbol, Error: err.Error()})
+			continue
+		}
+		s.store.SaveSymbolSpec(spec)
+		saved++
+	}
+	s.store.TouchDevice(session.DeviceID)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"ok":       true,
+		"saved":    saved,
+		"rejected": rejected,
+	})
+}
+
+// defaultDealHistory is the window assumed when the EA does not say how far
+// back its deal history goes.
+const defaultDealHistory = 24 * time.Hour
+
+// handleEADeals stores the EA's deal history and reconciles it against the
+// backend's commands and the latest position snapshot.
+func (s *Server) handleEADeals(w http.ResponseWriter, r *http.Request) {
+	session, err := sessionFromContext(r.Context())
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "missing ea session")
+		return
+	}
+	var req struct {
+		HistorySeconds int64         `json:"history_seconds"`
+		Deals          []domain.Deal `json:"deals"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	now := time.Now()
+	deals := make([]domain.Deal, 0, len(req.Deals))
+	for _, d := range req.Deals {
+		d.Ticket = strings.TrimSpace(d.Ticket)
+		if d.Ticket == "" {
+			continue
+		}
+		d.AccountID = session.AccountID
+		d.Symbol = strings.ToUpper(strings.TrimSpace(d.Symbol))
+		d.Side = strings.ToUpper(strings.TrimSpace(d.Side))
+		d.Entry = strings.ToUpper(strings.TrimSpace(d.Entry))
+		d.Reason = strings.ToUpper(strings.TrimSpace(d.Reason))
+		d.ReceivedAt = now.UTC()
+		deals = append(deals, d)
+	}
+	s.store.SaveDeals(deals)
+	s.store.TouchDevice(session.DeviceID)
+
+	history := time.Duration(req.HistorySeconds) * time.Second
+	if history <= 0 {
+		history = defaultDealHistory
+	}
+	found, resolved, attributed := s.reconcileAccount(r.Context(), session.AccountID, deals, history, now)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"ok":         true,
+		"saved":      len(deals),
+		"issues":     found,
+		"resolved":   resolved,
+		"attributed": attributed,
+	})
+}
+
+// reconcileAccount compares the account's OPEN results over the history
+// window with the synced deals and the latest snapshot. New issues, and
+// resolved ones whose evidence changed, are stored, emitted as
+// ReconcileIssue events and sent to Telegram;
+// open issues that no longer hold are marked resolved. Journal trades the
+// deals closed pick up their close reason, exit price and net profit.
+func (s *Server) reconcileAccount(ctx context.Context, accountID string, deals []domain.Deal, history time.Duration, now time.Time) ([]domain.ReconcileIssue, int, int) {
+	opens := make([]reconcile.Open, 0)
+	_ = s.store.StreamCommandResults(domain.ExportQuery{AccountID: accountID, From: now.Add(-history)}, func(res domain.CommandResult) error {
+		if !strings.EqualFold(res.Status, "SUCCESS") {
+			return nil
+		}
+		if cmd, ok := s.store.Command(res.CommandID); ok && cmd.Type == domain.CommandOpen {
+			opens = append(opens, reconcile.Open{
+				CommandID: cmd.ID,
+				Ticket:    strings.TrimSpace(res.BrokerTicket),
+				Symbol:    cmd.Symbol,
+				At:        res.ReceivedAt,
+			})
+		}
+		return nil
+	})
+	in := reconcile.Input{
+		AccountID: accountID,
+		Opens:     opens,
+		Deals:     deals,
+		Positions: s.snapshotPositions(accountID),
+		Journaled: func(ticket string) bool {
+			_, ok := s.store.OpenTradeByTicket(accountID, ticket)
+			return ok
+		},
+		From:  now.Add(-history),
+		Until: now.Add(-s.cfg.ReconcileGrace),
+	}
+
+	found := reconcile.Check(in, now)
+	current := make(map[string]bool, len(found))
+	for i, issue := range found {
+		current[issue.ID] = true
+		if prior, ok := s.store.ReconcileIssue(accountID, issue.ID); ok && (prior.Open() || !reconcile.Changed(prior, issue)) {
+			found[i] = prior
+			continue
+		}
+		s.store.SaveReconcileIssue(issue)
+		s.emitEvent(domain.EventReconcileIssue, accountID, reconcileIssuePayload(issue))
+		_ = s.notifier.Notify(ctx, fmt.Sprintf("Reconciliation on %s: %s. %s", accountID, issue.Kind, issue.Detail))
+	}
+	resolved := 0
+	for _, issue := range s.store.ListReconcileIssues(domain.ReconcileQuery{AccountID: accountID, Status: "open"}) {
+		if current[issue.ID] || !reconcile.Resolved(issue, in) {
+			continue
+		}
+		issue.ResolvedAt = now.UTC()
+		s.store.SaveReconcileIssue(issue)
+		s.emitEvent(domain.EventReconcileIssue, accountID, reconcileIssuePayload(issue))
+		resolved++
+	}
+
+	attributed := 0
+	for _, position := range reconcile.ClosedPositions(deals) {
+		for _, trade := range s.store.ListTrades(domain.TradeQuery{AccountID: accountID, Ticket: position, Status: domain.TradeStatusClosed}) {
+			if trade, ok := reconcile.Attribute(trade, deals, s.symbols.PipSize(trade.Symbol), now); ok {
+				s.store.SaveTrade(trade)
+				attributed++
+			}
+		}
+	}
+	return found, resolved, attributed
+}
+
+func reconcileIssuePayload(issue domain.ReconcileIssue) map[string]interface{} {
+	status := "open"
+	if !issue.Open() {
+		status = "resolved"
+	}
+	return map[string]interface{}{
+		"issue_id":   issue.ID,
+		"kind":       issue.Kind,
+		"status":     status,
+		"ticket":     issue.Ticket,
+		"command_id": issue.CommandID,
+		"symbol":     issue.Symbol,
+		"detail":     issue.Detail,
+	}
+}
+
+func (s *Server) handleListSymbols(w http.ResponseWriter, r *http.Request) {
+	entries := s.symbols.List()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"symbols": entries,
+		"count":   len(entries),
+	})
+}
+
+func (s *Server) handleGetSymbol(w http.ResponseWriter, r *http.Request) {
+	entry, ok := s.symbols.Entry(chi.URLParam(r, "symbol"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "symbol not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, entry)
+}
+
+// handlePutSymbolOverride stores an admin layer on top of the EA report. Zero
+// fields keep the reported value; a symbol the EA has not reported yet needs a
+// complete spec.
+func (s *Server) handlePutSymbolOverride(w http.ResponseWriter, r *http.Request) {
+	var spec domain.SymbolSpec
+	if err := decodeJSON(r, &spec); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	spec.Symbol = strings.ToUpper(strings.TrimSpace(chi.URLParam(r, "symbol")))
+	spec.Source = domain.SymbolSpecSourceAdmin
+	if spec.Digits < 0 || spec.Point < 0 || spec.PipSize < 0 || spec.TickSize < 0 || spec.TickValue < 0 ||
+		spec.ContractSize < 0 || spec.VolumeMin < 0 || spec.VolumeMax < 0 || spec.VolumeStep < 0 || spec.StopsLevel < 0 ||
+		spec.MarginPerLot < 0 {
+		writeError(w, http.StatusBadRequest, "symbol spec values must not be negative")
+		return
+	}
+	if existing, ok := s.symbols.Entry(spec.Symbol); !ok || existing.Reported == nil {
+		if err := symbols.Validate(spec); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	s.store.SaveSymbolSpec(spec)
+	entry, _ := s.symbols.Entry(spec.Symbol)
+	writeJSON(w, http.StatusOK, entry)
+}
+
+func (s *Server) handleDeleteSymbolOverride(w http.ResponseWriter, r *http.Request) {
+	if !s.store.DeleteSymbolSpec(chi.URLParam(r, "symbol"), domain.SymbolSpecSourceAdmin) {
+		writeError(w, http.StatusNotFound, "symbol override not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]bool{"ok": true})
+}
+
+// handleFillStats reports execution quality per symbol from stored fills,
+// with the current slippage pauses.
+func (s *Server) handleFillStats(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if raw := r.URL.Query().Get("since"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "since must be RFC3339")
+			return
+		}
+		since = t
+	}
+	bySymbol := risk.ComputeFillStats(s.store.ListFills(r.URL.Query().Get("symbol"), since, 0))
+	stats := make([]risk.FillStats, 0, len(bySymbol))
+	for _, st := range bySymbol {
+		stats = append(stats, st)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Symbol < stats[j].Symbol })
+	limits := s.riskEngine.SlippageLimits()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"stats":  stats,
+		"pauses": s.store.ListSymbolPauses(),
+		"limits": map[string]interface{}{
+			"max_avg_slippage_pips": limits.MaxAvgPips,
+			"window":                limits.Window,
+			"min_fills":             limits.MinFills,
+			"pause_seconds":         int64(limits.PauseFor.Seconds()),
+		},
+	})
+}
+
+// handleListTrades lists journal records, newest opened first.
+func (s *Server) handleListTrades(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := domain.TradeQuery{
+		AccountID: query.Get("account_id"),
+		Symbol:    query.Get("symbol"),
+		Strategy:  query.Get("strategy"),
+		Status:    domain.TradeStatus(strings.ToUpper(query.Get("status"))),
+		Limit:     parseInt(query.Get("limit"), 100),
+	}
+	if q.Status != "" && q.Status != domain.TradeStatusOpen && q.Status != domain.TradeStatusClosed {
+		writeError(w, http.StatusBadRequest, "status must be OPEN or CLOSED")
+		return
+	}
+	for key, target := range map[string]*time.Time{"from": &q.From, "to": &q.To} {
+		raw := query.Get(key)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, key+" must be RFC3339")
+			return
+		}
+		*target = t
+	}
+	trades := s.store.ListTrades(q)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"trades": trades,
+		"count":  len(trades),
+	})
+}
+
+func (s *Server) handleListReports(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := domain.ReportQuery{
+		AccountID: query.Get("account_id"),
+		Kind:      domain.ReportKind(strings.ToLower(query.Get("kind"))),
+		Limit:     parseInt(query.Get("limit"), 50),
+	}
+	if q.Kind != "" && q.Kind != domain.ReportDaily && q.Kind != domain.ReportWeekly {
+		writeError(w, http.StatusBadRequest, "kind must be daily or weekly")
+		return
+	}
+	for key, target := range map[string]*time.Time{"from": &q.From, "to": &q.To} {
+		raw := query.Get(key)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, key+" must be RFC3339")
+			return
+		}
+		*target = t
+	}
+	currency, err := currencyParam(query, "")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	reports := s.store.ListReports(q)
+	if currency != "" {
+		now := time.Now()
+		for i, rep := range reports {
+			if reports[i], err = s.convertReport(rep, currency, now); err != nil {
+				writeError(w, http.StatusUnprocessableEntity, err.Error())
+				return
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"reports": reports,
+		"count":   len(reports),
+	})
+}
+
+func (s *Server) handleGetReport(w http.ResponseWriter, r *http.Request) {
+	rep, ok := s.store.Report(chi.URLParam(r, "id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "report not found")
+		return
+	}
+	currency, err := currencyParam(r.URL.Query(), "")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if currency != "" {
+		if rep, err = s.convertReport(rep, currency, time.Now()); err != nil {
+			writeError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, rep)
+}
+
+// exportFlushEvery is how many rows an export buffers before flushing them
+// to the client.
+const exportFlushEvery = 500
+
+// handleExport streams a dataset as CSV or NDJSON straight from the store.
+// The server write timeout does not apply, so long exports are not cut off.
+// Once the first byte is sent, errors can only be logged.
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	dataset := chi.URLParam(r, "dataset")
+	if !slices.Contains(export.Datasets, dataset) {
+		writeError(w, http.StatusNotFound, "unknown export dataset")
+		return
+	}
+	query := r.URL.Query()
+	format := strings.ToLower(query.Get("format"))
+	if format == "" {
+		format = export.CSV
+	}
+	q := domain.ExportQuery{AccountID: query.Get("account_id")}
+	for key, target := range map[string]*time.Time{"from": &q.From, "to": &q.To} {
+		raw := query.Get(key)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, key+" must be RFC3339")
+			return
+		}
+		*target = t
+	}
+	out, err := export.NewWriter(w, format, dataset)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", out.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, dataset, format))
+	w.WriteHeader(http.StatusOK)
+
+	rows := 0
+	written := func(err error) error {
+		if err != nil {
+			return err
+		}
+		if rows++; rows%exportFlushEvery == 0 {
+			if err := out.Flush(); err != nil {
+				return err
+			}
+			_ = rc.Flush()
+		}
+		return r.Context().Err()
+	}
+	switch dataset {
+	case "commands":
+		err = s.store.StreamCommands(q, func(c domain.Command) error { return written(out.Command(c)) })
+	case "results":
+		err = s.store.StreamCommandResults(q, func(res domain.CommandResult) error { return written(out.Result(res)) })
+	case "events":
+		err = s.store.StreamEvents(q, func(e domain.Event) error { return written(out.Event(e)) })
+	case "trades":
+		err = s.store.StreamTrades(q, func(t domain.Trade) error { return written(out.Trade(t)) })
+	}
+	if err == nil {
+		err = out.Header()
+	}
+	if flushErr := out.Flush(); err == nil {
+		err = flushErr
+	}
+	if err != nil {
+		log.Printf("export %s stopped after %d rows: %v", dataset, rows, err)
+	}
+}
+
+func (s *Server) handleGetTrade(w http.ResponseWriter, r *http.Request) {
+	trade, ok := s.store.Trade(chi.URLParam(r, "id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "trade not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, trade)
+}
+
+// handleListDeals lists synced broker deals, newest first.
+func (s *Server) handleListDeals(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := domain.DealQuery{
+		AccountID: query.Get("account_id"),
+		Symbol:    query.Get("symbol"),
+		Limit:     parseInt(query.Get("limit"), 100),
+	}
+	for key, target := range map[string]*time.Time{"from": &q.From, "to": &q.To} {
+		raw := query.Get(key)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, key+" must be RFC3339")
+			return
+		}
+		*target = t
+	}
+	deals := s.store.ListDeals(q)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"deals": deals,
+		"count": len(deals),
+	})
+}
+
+func (s *Server) handleListReconcileIssues(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := domain.ReconcileQuery{
+		AccountID: query.Get("account_id"),
+		Kind:      domain.ReconcileIssueKind(strings.ToLower(query.Get("kind"))),
+		Status:    strings.ToLower(query.Get("status")),
+		Limit:     parseInt(query.Get("limit"), 100),
+	}
+	switch q.Kind {
+	case "", domain.ReconcileOrphanedPosition, domain.ReconcileMissingFill, domain.ReconcileDuplicateExecution:
+	default:
+		writeError(w, http.StatusBadRequest, "kind must be orphaned_position, missing_fill or duplicate_execution")
+		return
+	}
+	if q.Status != "" && q.Status != "open" && q.Status != "resolved" {
+		writeError(w, http.StatusBadRequest, "status must be open or resolved")
+		return
+	}
+	issues := s.store.ListReconcileIssues(q)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issues": issues,
+		"count":  len(issues),
+	})
+}
+
+// handleResolveReconcileIssue closes an issue by hand, for duplicate
+// executions and anything an admin has dealt with on the broker.
+func (s *Server) handleResolveReconcileIssue(w http.ResponseWriter, r *http.Request) {
+	accountID := chi.URLParam(r, "account_id")
+	issue, ok := s.store.ReconcileIssue(accountID, chi.URLParam(r, "id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "reconcile issue not found")
+		return
+	}
+	if !issue.Open() {
+		writeError(w, http.StatusConflict, "reconcile issue is already resolved")
+		return
+	}
+	issue.ResolvedAt = time.Now().UTC()
+	s.store.SaveReconcileIssue(issue)
+	payload := reconcileIssuePayload(issue)
+	payload["source"] = "admin"
+	event := s.emitEvent(domain.EventReconcileIssue, accountID, payload)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"ok":       true,
+		"event_id": event.ID,
+		"issue":    issue,
+	})
+}
+
+// handleSymbolResume lifts a slippage pause. Fills before now no longer count
+// toward the symbol's average.
+func (s *Server) handleSymbolResume(w http.ResponseWriter, r *http.Request) {
+	pause, ok := s.store.SymbolPause(chi.URLParam(r, "symbol"))
+	if !ok || !pause.Active(time.Now()) {
+		writeError(w, http.StatusConflict, "symbol is not paused")
+		return
+	}
+	previous := pause.Reason
+	pause.Paused = false
+	pause.Reason = ""
+	pause.ResumedAt = time.Now().UTC()
+	s.store.SaveSymbolPause(pause)
+	event := s.emitEvent(domain.EventSymbolResumed, "", map[string]interface{}{
+		"symbol":         pause.Symbol,
+		"source":         "admin",
+		"cleared_reason": previous,
+	})
+	_ = s.notifier.Notify(r.Context(), fmt.Sprintf("Symbol %s resumed.", pause.Symbol))
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"ok":       true,
+		"event_id": event.ID,
+		"pause":    pause,
+	})
+}
+
+func (s *Server) handlePause(w http.ResponseWriter, r *http.Request) {
+	event := s.setPausedState(r.Context(), true, "admin")
+	_ = s.notifier.Notify(r.Context(), "MMBot paused: new OPEN commands are blocked.")
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"ok":       true,
+		"event_id": event.ID,
+	})
+}
+
+func (s *Server) handleResume(w http.ResponseWriter, r *http.Request) {
+	event := s.setPausedState(r.Context(), false, "admin")
+	_ = s.notifier.Notify(r.Context(), "MMBot resumed: OPEN commands are allowed again.")
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"ok":       true,
+		"event_id": event.ID,
+	})
+}
+
+func (s *Server) handleListBlackouts(w http.ResponseWriter, r *http.Request) {
+	var from, to time.Time
+	for key, target := range map[string]*time.Time{"from": &from, "to": &to} {
+		raw := r.URL.Query().Get(key)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, key+" must be RFC3339")
+			return
+		}
+		*target = t
+	}
+	blackouts := s.store.ListBlackouts(from, to)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"blackouts": blackouts,
+		"count":     len(blackouts),
+	})
+}
+
+func (s *Server) handleCreateBlackout(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Title    string    `json:"title"`
+		Currency string    `json:"currency"`
+		Impact   string    `json:"impact"`
+		Start    time.Time `json:"start"`
+		End      time.Time `json:"end"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Start.IsZero() {
+		writeError(w, http.StatusBadRequest, "start is required")
+		return
+	}
+	if req.End.IsZero() {
+		req.End = req.Start
+	}
+	if req.End.Before(req.Start) {
+		writeError(w, http.StatusBadRequest, "end must not be before start")
+		return
+	}
+	b := s.store.SaveBlackout(domain.Blackout{
+		Title:    strings.TrimSpace(req.Title),
+		Currency: strings.ToUpper(strings.TrimSpace(req.Currency)),
+		Impact:   strings.ToLower(strings.TrimSpace(req.Impact)),
+		Start:    req.Start.UTC(),
+		End:      req.End.UTC(),
+		Source:   schedule.SourceAdmin,
+	})
+	writeJSON(w, http.StatusOK, b)
+}
+
+// handleImportBlackouts loads an ICS or CSV calendar from the request body.
+// Entries keep stable IDs, so importing the same feed again updates them.
+func (s *Server) handleImportBlackouts(w http.ResponseWriter, r *http.Request) {
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		switch ct := r.Header.Get("Content-Type"); {
+		case strings.Contains(ct, "text/calendar"):
+			format = "ics"
+		case strings.Contains(ct, "text/csv"):
+			format = "csv"
+		}
+	}
+	body := http.MaxBytesReader(w, r.Body, 4<<20)
+	var (
+		blackouts []domain.Blackout
+		err       error
+	)
+	switch format {
+	case "ics":
+		blackouts, err = schedule.ParseICS(body, s.tradingLocation)
+	case "csv":
+		blackouts, err = schedule.ParseCSV(body, s.tradingLocation)
+	default:
+		writeError(w, http.StatusBadRequest, "format must be ics or csv")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	for _, b := range blackouts {
+		s.store.SaveBlackout(b)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"ok":       true,
+		"imported": len(blackouts),
+	})
+}
+
+func (s *Server) handleDeleteBlackout(w http.ResponseWriter, r *http.Request) {
+	if !s.store.DeleteBlackout(chi.URLParam(r, "id")) {
+		writeError(w, http.StatusNotFound, "blackout not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"ok": true})
+}
+
+// ReloadRiskPolicy re-reads RISK_POLICY_FILE and swaps it into the risk
+// engine. An invalid file leaves the current policy in place. Any change is
+// recorded as a RiskPolicyChanged event with a per-field diff.
+func (s *Server) ReloadRiskPolicy(source string) ([]risk.PolicyChange, error) {
+	path := strings.TrimSpace(s.cfg.RiskPolicyFile)
+	if path == "" {
+		return nil, errors.New("RISK_POLICY_FILE is not set")
+	}
+	policy, err := risk.LoadPolicy(path)
+	if err != nil {
+		return nil, err
+	}
+	s.policyMu.Lock()
+	defer s.policyMu.Unlock()
+	prev := s.riskEngine.SetPolicy(policy)
+	changes := risk.DiffPolicies(prev, policy)
+	if len(changes) > 0 {
+		s.emitEvent(domain.EventRiskPolicyChanged, "", map[string]interface{}{
+			"source":  source,
+			"path":    path,
+			"changes": changes,
+		})
+	}
+	return changes, nil
+}
+
+func (s *Server) handleRiskPolicy(w http.ResponseWriter, r *http.Request) {
+	resp := map[string]interface{}{
+		"path":   s.cfg.RiskPolicyFile,
+		"policy": s.riskEngine.Policy(),
+	}
+	accountID, symbol := r.URL.Query().Get("account_id"), r.URL.Query().Get("symbol")
+	if accountID != "" || symbol != "" {
+		resp["effective"] = s.riskEngine.Thresholds(accountID, symbol)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleReloadRiskPolicy(w http.ResponseWriter, r *http.Request) {
+	changes, err := s.ReloadRiskPolicy("admin")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"ok":      true,
+		"changes": append([]risk.PolicyChange{}, changes...),
+	})
+}
+
+func (s *Server) handleListAccounts(w http.ResponseWriter, r *http.Request) {
+	accounts := s.store.ListAccounts()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"accounts":      accounts,
+		"count":         len(accounts),
+		"base_currency": s.baseCurrency(),
+	})
+}
+
+// handlePutAccount sets the currency of an account whose EA does not report
+// one. A currency the EA reports replaces it on the next sync.
+func (s *Server) handlePutAccount(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Currency string `json:"currency"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	currency := fx.Normalize(req.Currency)
+	if !fx.ValidCode(currency) {
+		writeError(w, http.StatusBadRequest, "currency must be a three-letter code")
+		return
+	}
+	accountID := chi.URLParam(r, "account_id")
+	if _, ok := s.store.Account(accountID); !ok {
+		writeError(w, http.StatusNotFound, "account not found")
+		return
+	}
+	account := s.store.SaveAccount(domain.Account{ID: accountID, Currency: currency, UpdatedAt: time.Now().UTC()})
+	writeJSON(w, http.StatusOK, account)
+}
+
+// handleFXRates lists the FX rates conversions can use now: the FX_RATES
+// table overlaid with fresh EA quotes.
+func (s *Server) handleFXRates(w http.ResponseWriter, r *http.Request) {
+	rates := s.rates.Rates(time.Now())
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"base_currency": s.baseCurrency(),
+		"rates":         rates,
+		"count":         len(rates),
+	})
+}
+
+func (s *Server) handleListPaperAccounts(w http.ResponseWriter, r *http.Request) {
+	accounts := make([]map[string]interface{}, 0, len(s.paper))
+	for _, id := range s.paperAccountIDs() {
+		accounts = append(accounts, s.paper[id].Snapshot())
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"accounts": accounts,
+		"count":    len(accounts),
+	})
+}
+
+// handlePushPaperQuotes feeds bids to the paper market. Quotes without a
+// time are stamped now; quotes older than the last step are dropped.
+func (s *Server) handlePushPaperQuotes(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Quotes []paper.Quote `json:"quotes"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(req.Quotes) == 0 {
+		writeError(w, http.StatusBadRequest, "quotes are required")
+		return
+	}
+	now := time.Now()
+	accepted := 0
+	for _, q := range req.Quotes {
+		if q.At.IsZero() {
+			q.At = now
+		}
+		if s.market.Push(q) {
+			accepted++
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"ok":       true,
+		"accepted": accepted,
+		"dropped":  len(req.Quotes) - accepted,
+	})
+}
+
+func (s *Server) handlePaperStep(w http.ResponseWriter, r *http.Request) {
+	if len(s.paper) == 0 {
+		writeError(w, http.StatusConflict, "no paper accounts configured")
+		return
+	}
+	steps := s.stepPaper(r.Context(), time.Now())
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"accounts": steps,
+		"count":    len(steps),
+	})
+}
+
+// RunPaper steps the paper accounts every PAPER_INTERVAL until ctx is done.
+// It returns at once when PAPER_ACCOUNTS is empty.
+func (s *Server) RunPaper(ctx context.Context) {
+	if len(s.paper) == 0 {
+		return
+	}
+	interval := s.cfg.PaperInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	log.Printf("paper broker simulating %d account(s) every %s", len(s.paper), interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.stepPaper(ctx, now)
 		}
-		if cmd.Type == domain.CommandClose {
-			s.store.AdjustOpenPositions(session.AccountID, -1)
+	}
+}
+
+// stepPaper runs one simulator round. Each paper account is marked to the
+// prices since the last round, closing positions whose stop or target was
+// crossed, then executes its queued commands. Results, the sync snapshot
+// and new deals go through the same paths as the EA's.
+func (s *Server) stepPaper(ctx context.Context, now time.Time) []map[string]interface{} {
+	s.paperMu.Lock()
+	defer s.paperMu.Unlock()
+	ids := s.paperAccountIDs()
+	ticks := make(map[string]paper.Tick)
+	for _, id := range ids {
+		for _, symbol := range s.paper[id].Symbols() {
+			if _, done := ticks[symbol]; done {
+				continue
+			}
+			if tick, ok := s.market.Advance(symbol); ok {
+				ticks[symbol] = tick
+				// Held pairs double as FX quotes, as the EA's quote symbols do.
+				if base, quote, ok := fx.SplitPair(symbol, nil); ok {
+					s.rates.Observe(base, quote, tick.Bid, now)
+				}
+			}
 		}
 	}
 
-	eventType := domain.EventTradeExecuted
-	if cmd.Type == domain.CommandMoveSL || cmd.Type == domain.CommandSetTP {
-		eventType = domain.EventTradeModified
+	out := make([]map[string]interface{}, 0, len(ids))
+	for _, id := range ids {
+		acct := s.paper[id]
+		dealsBefore := len(acct.Deals(time.Time{}))
+		closed := acct.Step(ticks, now)
+		prices := paperPrices{server: s, currency: acct.Currency(), now: now}
+		executed := 0
+		for {
+			cmd, err := s.store.NextQueuedCommand(id)
+			if err != nil {
+				break
+			}
+			if _, err := s.applyResult(ctx, id, acct.Execute(cmd, prices, now)); err == nil {
+				executed++
+			}
+		}
+		day, _ := s.riskEngine.TradingDay(id)
+		acct.RollDay(day.Key(now))
+		synced := s.applySync(ctx, id, acct.Snapshot())
+		if len(acct.Deals(time.Time{})) != dealsBefore {
+			deals := acct.Deals(now.Add(-defaultDealHistory))
+			for i := range deals {
+				deals[i].ReceivedAt = now.UTC()
+			}
+			s.store.SaveDeals(deals)
+			s.reconcileAccount(ctx, id, deals, defaultDealHistory, now)
+		}
+		out = append(out, map[string]interface{}{
+			"account_id": id,
+			"executed":   executed,
+			"closed":     len(closed),
+			"sync":       synced,
+		})
 	}
-	event := s.emitEvent(eventType, session.AccountID, map[string]interface{}{
-		"command_id":    req.CommandID,
-		"status":        req.Status,
-		"broker_ticket": req.BrokerTicket,
-		"error_code":    req.ErrorCode,
-		"error_message": req.ErrorMessage,
-		"command_type":  cmd.Type,
-		"symbol":        cmd.Symbol,
-	})
-	if success {
-		_ = s.notifier.Notify(r.Context(), fmt.Sprintf("[%s] %s %s %.2f", cmd.Type, cmd.Side, cmd.Symbol, cmd.Volume))
+	return out
+}
+
+func (s *Server) paperAccountIDs() []string {
+	ids := make([]string, 0, len(s.paper))
+	for id := range s.paper {
+		ids = append(ids, id)
 	}
+	sort.Strings(ids)
+	return ids
+}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"ok":       true,
-		"event_id": event.ID,
-	})
+// paperPrices prices paper fills from the paper market and the symbol
+// registry. Profits are converted from the symbol's profit currency to the
+// account currency; a symbol whose profit currency or rate is unknown cannot
+// be traded.
+type paperPrices struct {
+	server   *Server
+	currency string
+	now      time.Time
 }
 
-func (s *Server) handlePause(w http.ResponseWriter, r *http.Request) {
-	event := s.setPausedState(r.Context(), true, "admin")
-	_ = s.notifier.Notify(r.Context(), "MMBot paused: new OPEN commands are blocked.")
+func (p paperPrices) Bid(symbol string) (float64, bool) {
+	return p.server.market.Bid(symbol)
+}
+
+func (p paperPrices) Contract(symbol string) (paper.Contract, error) {
+	contract := paper.Contract{PipSize: p.server.symbols.PipSize(symbol)}
+	var spec *domain.SymbolSpec
+	if s, ok := p.server.symbols.Get(symbol); ok {
+		contract.ContractSize = s.ContractSize
+		spec = &s
+	}
+	profitCurrency := ""
+	if spec != nil {
+		profitCurrency = fx.Normalize(spec.ProfitCurrency)
+	}
+	if profitCurrency == "" {
+		_, profitCurrency, _ = fx.SplitPair(symbol, spec)
+	}
+	if profitCurrency == "" {
+		return paper.Contract{}, fmt.Errorf("profit currency of %s is unknown", symbol)
+	}
+	rate, err := p.server.rates.Rate(profitCurrency, p.currency, p.now)
+	if err != nil {
+		return paper.Contract{}, fmt.Errorf("convert %s profit to %s: %w", symbol, p.currency, err)
+	}
+	contract.Rate = rate
+	return contract, nil
+}
+
+func (s *Server) handleAccountRisk(w http.ResponseWriter, r *http.Request) {
+	accountID := chi.URLParam(r, "account_id")
+	state, ok := s.store.AccountRiskState(accountID)
+	if !ok {
+		writeError(w, http.StatusNotFound, "no equity synced for account")
+		return
+	}
+	limits := s.drawdownLimits()
 	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"ok":       true,
-		"event_id": event.ID,
+		"state":        state,
+		"drawdown_pct": risk.DrawdownPct(state),
+		"limits": map[string]float64{
+			"max_drawdown_pct":     limits.MaxDrawdownPct,
+			"max_weekly_loss_pct":  limits.MaxWeeklyLossPct,
+			"max_monthly_loss_pct": limits.MaxMonthlyLossPct,
+		},
 	})
 }
 
-func (s *Server) handleResume(w http.ResponseWriter, r *http.Request) {
-	event := s.setPausedState(r.Context(), false, "admin")
-	_ = s.notifier.Notify(r.Context(), "MMBot resumed: OPEN commands are allowed again.")
+// handleAccountResume lifts a breaker pause. The references the breaker
+// tripped on are rebased to the last equity so the next sync does not pause
+// the account again for the same loss.
+func (s *Server) handleAccountResume(w http.ResponseWriter, r *http.Request) {
+	accountID := chi.URLParam(r, "account_id")
+	s.riskStateMu.Lock()
+	state, ok := s.store.AccountRiskState(accountID)
+	if !ok {
+		s.riskStateMu.Unlock()
+		writeError(w, http.StatusNotFound, "no equity synced for account")
+		return
+	}
+	if !state.Paused {
+		s.riskStateMu.Unlock()
+		writeError(w, http.StatusConflict, "account is not paused")
+		return
+	}
+	previous := state.PauseReason
+	state = s.drawdownLimits().Rebase(state)
+	state.Paused = false
+	state.PauseReason = ""
+	state.PausedAt = time.Time{}
+	s.store.SaveAccountRiskState(state)
+	s.riskStateMu.Unlock()
+	event := s.emitEvent(domain.EventBotPaused, accountID, map[string]interface{}{
+		"paused":         false,
+		"source":         "admin",
+		"cleared_reason": previous,
+	})
+	_ = s.notifier.Notify(r.Context(), fmt.Sprintf("Account %s resumed after %s.", accountID, previous))
 	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"ok":       true,
 		"event_id": event.ID,
+		"state":    state,
 	})
 }
 
@@ -448,8 +2105,13 @@ func (s *Server) handleTrendEvaluate(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		AccountID  string            `json:"account_id"`
 		Symbol     string            `json:"symbol"`
+		Timeframe  string            `json:"timeframe"`
 		SpreadPips float64           `json:"spread_pips"`
 		Candles    []strategy.Candle `json:"candles"`
+		// Higher-timeframe confirmation series; loaded from the candle store
+		// when only htf_timeframe (or STRATEGY_HTF_TIMEFRAME) is given.
+		HTFTimeframe string            `json:"htf_timeframe"`
+		HTFCandles   []strategy.Candle `json:"htf_candles"`
 	}
 	if err := decodeJSON(r, &req); err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
@@ -458,25 +2120,137 @@ func (s *Server) handleTrendEvaluate(w http.ResponseWriter, r *http.Request) {
 	if req.AccountID == "" {
 		req.AccountID = "paper-1"
 	}
-	if s.cfg.StrategyMaxCandles > 0 && len(req.Candles) > s.cfg.StrategyMaxCandles {
+	if strings.TrimSpace(req.Timeframe) == "" {
+		req.Timeframe = "M15"
+	}
+	if s.cfg.StrategyMaxCandles > 0 && (len(req.Candles) > s.cfg.StrategyMaxCandles || len(req.HTFCandles) > s.cfg.StrategyMaxCandles) {
 		writeError(w, http.StatusBadRequest, fmt.Sprintf("too many candles: max %d", s.cfg.StrategyMaxCandles))
 		return
 	}
+	barLen, err := strategy.ParseTimeframe(req.Timeframe)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.HTFTimeframe == "" && len(req.HTFCandles) == 0 {
+		req.HTFTimeframe = s.cfg.StrategyHTFTimeframe
+	}
+	htfLen := time.Duration(0)
+	if req.HTFTimeframe != "" {
+		if htfLen, err = strategy.ParseTimeframe(req.HTFTimeframe); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	now := time.Now().UTC()
+	candles, issues, err := strategy.NormalizeCandles(req.Candles, strategy.NormalizeOptions{
+		Timeframe:      barLen,
+		DropFormingBar: s.cfg.StrategyDropFormingBar,
+		Now:            now,
+	})
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error":         "invalid candles: " + err.Error(),
+			"candle_issues": issues,
+		})
+		return
+	}
+	req.Candles = candles
+	var htfIssues []strategy.CandleIssue
+	if len(req.HTFCandles) > 0 {
+		htfCandles, found, err := strategy.NormalizeCandles(req.HTFCandles, strategy.NormalizeOptions{
+			Timeframe:      htfLen,
+			DropFormingBar: s.cfg.StrategyDropFormingBar,
+			Now:            now,
+		})
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+				"error":             "invalid htf_candles: " + err.Error(),
+				"htf_candle_issues": found,
+			})
+			return
+		}
+		req.HTFCandles = htfCandles
+		htfIssues = found
+	}
+	loadedHTF := false
+	if req.HTFTimeframe != "" && len(req.HTFCandles) == 0 && len(req.Candles) > 0 {
+		req.HTFCandles = s.closedHTFCandles(req.Symbol, req.Timeframe, req.HTFTimeframe, req.Candles[len(req.Candles)-1].Time)
+		loadedHTF = true
+	}
+
+	strat := s.signalStrategy
+	var current *regime.Result
+	if s.regimeRoutes != nil {
+		res, err := s.observeRegime(req.AccountID, req.Symbol, req.Timeframe, req.Candles)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		current = &res
+		strat = s.regimeRoutes[res.Regime]
+		if strat == nil {
+			s.store.SaveCandles(req.Symbol, req.Timeframe, req.Candles)
+			resp := map[string]interface{}{
+				"has_signal": false,
+				"reason":     fmt.Sprintf("%s regime: no strategy routed", res.Regime),
+				"regime":     res,
+			}
+			addCandleIssues(resp, issues, htfIssues)
+			writeJSON(w, http.StatusOK, resp)
+			return
+		}
+	}
 
-	sig, err := s.trendEngine.Evaluate(strategy.TrendInput{
-		Symbol:     req.Symbol,
-		Candles:    req.Candles,
-		SpreadPips: req.SpreadPips,
+	sig, err := strat.Evaluate(strategy.TrendInput{
+		Symbol:       req.Symbol,
+		Timeframe:    req.Timeframe,
+		Candles:      req.Candles,
+		SpreadPips:   req.SpreadPips,
+		PipSize:      s.symbols.PipSize(req.Symbol),
+		HTFTimeframe: req.HTFTimeframe,
+		HTFCandles:   req.HTFCandles,
 	})
+	if err != nil && loadedHTF && errors.Is(err, strategy.ErrInsufficientHTF) {
+		// The stored higher timeframe is still filling up; without its
+		// confirmation no signal is taken, as in a backtest.
+		s.store.SaveCandles(req.Symbol, req.Timeframe, req.Candles)
+		resp := map[string]interface{}{
+			"has_signal": false,
+			"reason":     err.Error(),
+		}
+		if current != nil {
+			resp["regime"] = current
+		}
+		addCandleIssues(resp, issues, htfIssues)
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	// Keep every evaluated series so backtests can replay what the engine saw live.
+	s.store.SaveCandles(req.Symbol, req.Timeframe, req.Candles)
+	if req.HTFTimeframe != "" && !loadedHTF {
+		s.store.SaveCandles(req.Symbol, req.HTFTimeframe, req.HTFCandles)
+	}
 	if !sig.HasSignal {
-		writeJSON(w, http.StatusOK, map[string]interface{}{
+		resp := map[string]interface{}{
 			"has_signal": false,
 			"reason":     sig.Reason,
-		})
+		}
+		if sig.Alignment != nil {
+			resp["alignment"] = sig.Alignment
+		}
+		if len(sig.Votes) > 0 {
+			resp["votes"] = sig.Votes
+		}
+		if current != nil {
+			resp["regime"] = current
+		}
+		addCandleIssues(resp, issues, htfIssues)
+		writeJSON(w, http.StatusOK, resp)
 		return
 	}
 
@@ -489,13 +2263,108 @@ func (s *Server) handleTrendEvaluate(w http.ResponseWriter, r *http.Request) {
 		SpreadPips:     req.SpreadPips,
 		StopLossPips:   sig.StopLossPips,
 		TakeProfitPips: sig.TakeProfitPips,
+		Strategy:       strat.Name(),
+		Votes:          sig.Votes,
+	}
+	if current != nil {
+		input.Regime = current.Regime
 	}
 	result := s.evaluateAndQueue(r.Context(), input, fingerprintTrendRequest(req.AccountID, req.Symbol, req.SpreadPips, req.Candles))
 	result["has_signal"] = true
 	result["strategy_signal"] = sig
+	if current != nil {
+		result["regime"] = current
+	}
+	addCandleIssues(result, issues, htfIssues)
 	writeJSON(w, http.StatusOK, result)
 }
 
+// addCandleIssues reports normalisation findings (sorting, duplicates, gaps,
+// a dropped forming bar) alongside the evaluation result.
+func addCandleIssues(resp map[string]interface{}, issues, htfIssues []strategy.CandleIssue) {
+	if len(issues) > 0 {
+		resp["candle_issues"] = issues
+	}
+	if len(htfIssues) > 0 {
+		resp["htf_candle_issues"] = htfIssues
+	}
+}
+
+// observeRegime classifies the series and emits RegimeChanged when the
+// symbol's regime differs from the last observation.
+func (s *Server) observeRegime(accountID, symbol, timeframe string, candles []strategy.Candle) (regime.Result, error) {
+	res, prev, changed, err := s.regimes.Observe(symbol, timeframe, candles)
+	if err != nil {
+		return regime.Result{}, err
+	}
+	if changed {
+		s.emitEvent(domain.EventRegimeChanged, accountID, map[string]interface{}{
+			"symbol":         res.Symbol,
+			"timeframe":      res.Timeframe,
+			"from":           prev,
+			"to":             res.Regime,
+			"adx":            res.ADX,
+			"atr_percentile": res.ATRPercentile,
+			"ema_slope_pct":  res.EMASlopePct,
+			"bar_time":       res.BarTime.Format(time.RFC3339),
+		})
+	}
+	return res, nil
+}
+
+func (s *Server) handleRegime(w http.ResponseWriter, r *http.Request) {
+	symbol := strings.TrimSpace(r.URL.Query().Get("symbol"))
+	if symbol == "" {
+		results := s.regimes.List()
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"regimes": results,
+			"count":   len(results),
+		})
+		return
+	}
+	timeframe := r.URL.Query().Get("timeframe")
+	if timeframe == "" {
+		timeframe = "M15"
+	}
+	if _, err := strategy.ParseTimeframe(timeframe); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// A lookup classifies without recording, so it never emits RegimeChanged.
+	res, err := s.regimes.Classify(symbol, timeframe, nil)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp := map[string]interface{}{"regime": res}
+	if s.regimeRoutes != nil {
+		routed := "none"
+		if strat := s.regimeRoutes[res.Regime]; strat != nil {
+			routed = strat.Name()
+		}
+		resp["strategy"] = routed
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// closedHTFCandles loads the stored higher-timeframe series up to the signal
+// bar, leaving out a higher-timeframe bar that was still forming at its close.
+func (s *Server) closedHTFCandles(symbol, timeframe, htfTimeframe string, lastBar time.Time) []strategy.Candle {
+	candles := s.store.ListCandles(symbol, htfTimeframe, time.Time{}, lastBar, s.cfg.StrategyMaxCandles)
+	barLen, err := strategy.ParseTimeframe(timeframe)
+	if err != nil {
+		return candles
+	}
+	htfLen, err := strategy.ParseTimeframe(htfTimeframe)
+	if err != nil {
+		return candles
+	}
+	if n := len(candles); n > 0 && candles[n-1].Time.Add(htfLen).After(lastBar.Add(barLen)) {
+		candles = candles[:n-1]
+	}
+	return candles
+}
+
 func (s *Server) evaluateAndQueue(ctx context.Context, input domain.SignalInput, fingerprint string) map[string]interface{} {
 	if denied, reason := s.enforceStrategyRunPolicy(input.AccountID, fingerprint, time.Now().UTC()); denied {
 		s.emitEvent(domain.EventRiskTriggered, input.AccountID, map[string]interface{}{
@@ -522,32 +2391,63 @@ func (s *Server) evaluateAndQueue(ctx context.Context, input domain.SignalInput,
 		}
 	}
 
+	now := time.Now().UTC()
+	activity := s.tradeActivity(ctx, input.AccountID, now)
 	state := domain.StrategyState{
-		Paused:        s.store.IsPaused(),
-		OpenPositions: s.store.OpenPositions(input.AccountID),
-		DailyLossPct:  s.store.DailyLoss(input.AccountID),
+		Paused:            s.store.IsPaused(),
+		AccountPaused:     s.accountPaused(input.AccountID),
+		SymbolPaused:      s.symbolPaused(input.Symbol, now),
+		OpenPositions:     s.store.OpenPositions(input.AccountID),
+		DailyLossPct:      s.store.DailyLoss(input.AccountID),
+		TradesToday:       activity.TradesToday,
+		LossCooldownUntil: activity.CooldownUntil,
+		Now:               now,
+	}
+	spec, knownSymbol := s.symbols.Get(input.Symbol)
+	if knownSymbol {
+		state.Spec = &spec
+	}
+	state.Positions = s.snapshotPositions(input.AccountID)
+	state.Margin = s.snapshotMargin(input.AccountID)
+	if input.Volume <= 0 {
+		input.Volume = s.calculateVolume(input.AccountID, input.StopLossPips, spec, knownSymbol)
 	}
 	decision := s.riskEngine.Evaluate(input, state)
 
-	s.emitEvent(domain.EventSignalProposed, input.AccountID, map[string]interface{}{
+	proposed := map[string]interface{}{
 		"symbol":     input.Symbol,
 		"side":       input.Side,
 		"confidence": input.Confidence,
 		"allowed":    decision.Allowed,
 		"reason":     input.Reason,
 		"source":     "strategy",
-	})
+	}
+	if input.Strategy != "" {
+		proposed["strategy"] = input.Strategy
+	}
+	if len(input.Votes) > 0 {
+		proposed["votes"] = input.Votes
+	}
+	if input.Regime != "" {
+		proposed["regime"] = input.Regime
+	}
+	s.emitEvent(domain.EventSignalProposed, input.AccountID, proposed)
 
 	if !decision.Allowed {
+		failed := risk.FailedReasons(decision)
 		s.emitEvent(domain.EventRiskTriggered, input.AccountID, map[string]interface{}{
-			"reason": decision.DenyReason,
-			"symbol": input.Symbol,
-			"side":   input.Side,
+			"reason":         decision.DenyReason,
+			"failed_reasons": failed,
+			"checks":         decision.Checks,
+			"symbol":         input.Symbol,
+			"side":           input.Side,
 		})
-		_ = s.notifier.Notify(ctx, fmt.Sprintf("Risk trigger: %s (%s %s)", decision.DenyReason, input.Side, input.Symbol))
+		_ = s.notifier.Notify(ctx, fmt.Sprintf("Risk trigger: %s (%s %s)", strings.Join(failed, ", "), input.Side, input.Symbol))
 		return map[string]interface{}{
-			"allowed":     false,
-			"deny_reason": decision.DenyReason,
+			"allowed":        false,
+			"deny_reason":    decision.DenyReason,
+			"failed_reasons": failed,
+			"checks":         decision.Checks,
 		}
 	}
 
@@ -556,15 +2456,17 @@ func (s *Server) evaluateAndQueue(ctx context.Context, input domain.SignalInput,
 		Type:      domain.CommandOpen,
 		Symbol:    input.Symbol,
 		Side:      strings.ToUpper(input.Side),
-		Volume:    s.calculateVolume(),
+		Volume:    input.Volume,
 		SL:        input.StopLossPips,
 		TP:        input.TakeProfitPips,
 		Reason:    input.Reason,
+		Strategy:  input.Strategy,
 		ExpiresAt: time.Now().UTC().Add(30 * time.Second),
 	})
 	return map[string]interface{}{
 		"allowed": decision.Allowed,
 		"command": cmd,
+		"checks":  decision.Checks,
 	}
 }
 
@@ -578,6 +2480,7 @@ func (s *Server) handleDashboardSummary(w http.ResponseWriter, r *http.Request)
 		"account_id":            accountID,
 		"mode":                  "paper",
 		"paused":                s.store.IsPaused(),
+		"account_paused":        s.accountPaused(accountID),
 		"open_positions":        s.store.OpenPositions(accountID),
 		"daily_loss_pct":        s.store.DailyLoss(accountID),
 		"ai_provider_connected": connected,
@@ -585,6 +2488,193 @@ func (s *Server) handleDashboardSummary(w http.ResponseWriter, r *http.Request)
 	})
 }
 
+// handlePerformance computes statistics over the journal's closed trades,
+// filtered by account, symbol, strategy and close time. Percentages need an
+// initial equity: the initial_equity parameter, or for one account its last
+// synced equity less the filtered trades' profit. Profits are converted to
+// the currency parameter, which defaults to the account's currency for one
+// account and to BASE_CURRENCY across accounts.
+func (s *Server) handlePerformance(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var from, to time.Time
+	for key, target := range map[string]*time.Time{"from": &from, "to": &to} {
+		raw := query.Get(key)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, key+" must be RFC3339")
+			return
+		}
+		*target = t
+	}
+	initialEquity := 0.0
+	if raw := query.Get("initial_equity"); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v < 0 {
+			writeError(w, http.StatusBadRequest, "initial_equity must be a non-negative number")
+			return
+		}
+		initialEquity = v
+	}
+	accountID := query.Get("account_id")
+	fallback := s.baseCurrency()
+	if accountID != "" {
+		// Empty while unknown, which keeps the account's own figures.
+		fallback, _ = s.accountCurrency(accountID)
+	}
+	currency, err := currencyParam(query, fallback)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	trades := s.store.ListTrades(domain.TradeQuery{
+		AccountID: accountID,
+		Symbol:    query.Get("symbol"),
+		Strategy:  query.Get("strategy"),
+		Status:    domain.TradeStatusClosed,
+	})
+	closed := trades[:0]
+	for _, t := range trades {
+		if (!from.IsZero() && t.ClosedAt.Before(from)) || (!to.IsZero() && t.ClosedAt.After(to)) {
+			continue
+		}
+		closed = append(closed, t)
+	}
+	now := time.Now()
+	rates := make(map[string]float64)
+	for i, t := range closed {
+		rate, ok := rates[t.AccountID]
+		if !ok {
+			if rate, err = s.accountRate(t.AccountID, currency, now); err != nil {
+				writeError(w, http.StatusUnprocessableEntity, err.Error())
+				return
+			}
+			rates[t.AccountID] = rate
+		}
+		closed[i].Profit = t.Profit * rate
+	}
+	if initialEquity == 0 && accountID != "" {
+		if state, ok := s.store.AccountRiskState(accountID); ok && state.LastEquity > 0 {
+			rate, err := s.accountRate(accountID, currency, now)
+			if err != nil {
+				writeError(w, http.StatusUnprocessableEntity, err.Error())
+				return
+			}
+			lastEquity := state.LastEquity * rate
+			net := 0.0
+			for _, t := range closed {
+				net += t.Profit
+			}
+			initialEquity = max(lastEquity-net, 0)
+		}
+	}
+	writeJSON(w, http.StatusOK, struct {
+		analytics.Report
+		Currency string `json:"currency"`
+	}{analytics.Compute(closed, initialEquity), currency})
+}
+
+// handleEquityCurve returns an account's equity curve at one resolution
+// with its maximum drawdown. resolution defaults to auto, the finest one
+// still retained back to from.
+func (s *Server) handleEquityCurve(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	accountID := query.Get("account_id")
+	if accountID == "" {
+		writeError(w, http.StatusBadRequest, "account_id is required")
+		return
+	}
+	var from, to time.Time
+	for key, target := range map[string]*time.Time{"from": &from, "to": &to} {
+		raw := query.Get(key)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, key+" must be RFC3339")
+			return
+		}
+		*target = t
+	}
+	resolution := query.Get("resolution")
+	if resolution == "" || resolution == "auto" {
+		resolution = s.equityPolicy().Auto(from, time.Now())
+	}
+	if !equity.ValidResolution(resolution) {
+		writeError(w, http.StatusBadRequest, "resolution must be auto, raw, 1m or 1h")
+		return
+	}
+	points := s.store.ListEquityPoints(accountID, resolution, from, to)
+	maxDD, maxDDPct := equity.Drawdown(points)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"account_id":       accountID,
+		"resolution":       resolution,
+		"points":           points,
+		"count":            len(points),
+		"max_drawdown":     maxDD,
+		"max_drawdown_pct": maxDDPct,
+	})
+}
+
+// handlePortfolio totals the last synced equity, balance and daily PnL of
+// every account in one currency: the currency parameter or BASE_CURRENCY.
+// Accounts whose currency is unknown or has no rate to it are listed as
+// unconverted and left out of the totals.
+func (s *Server) handlePortfolio(w http.ResponseWriter, r *http.Request) {
+	currency, err := currencyParam(r.URL.Query(), s.baseCurrency())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	now := time.Now()
+	accounts := make([]map[string]interface{}, 0)
+	unconverted := make([]string, 0)
+	var totalEquity, totalBalance, totalPnL float64
+	for _, account := range s.store.ListAccounts() {
+		snapshot, ok := s.store.PositionSnapshot(account.ID)
+		if !ok {
+			continue
+		}
+		native, _ := s.accountCurrency(account.ID)
+		metrics := risk.DeriveSnapshotMetrics(snapshot)
+		accountEquity, balance := risk.SnapshotEquity(snapshot), risk.SnapshotBalance(snapshot)
+		entry := map[string]interface{}{
+			"account_id": account.ID,
+			"currency":   native,
+			"equity":     accountEquity,
+			"balance":    balance,
+			"net_pnl":    metrics.NetPnL,
+			"converted":  false,
+		}
+		if rate, err := s.accountRate(account.ID, currency, now); err != nil {
+			unconverted = append(unconverted, account.ID)
+		} else {
+			entry["converted"] = true
+			entry["rate"] = rate
+			entry["converted_equity"] = accountEquity * rate
+			entry["converted_balance"] = balance * rate
+			entry["converted_net_pnl"] = metrics.NetPnL * rate
+			totalEquity += accountEquity * rate
+			totalBalance += balance * rate
+			totalPnL += metrics.NetPnL * rate
+		}
+		accounts = append(accounts, entry)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"currency":      currency,
+		"accounts":      accounts,
+		"count":         len(accounts),
+		"total_equity":  totalEquity,
+		"total_balance": totalBalance,
+		"total_net_pnl": totalPnL,
+		"unconverted":   unconverted,
+		"rates":         s.rates.Rates(now),
+	})
+}
+
 func (s *Server) handleListEvents(w http.ResponseWriter, r *http.Request) {
 	limit := parseInt(r.URL.Query().Get("limit"), 20)
 	writeJSON(w, http.StatusOK, map[string]interface{}{
@@ -799,7 +2889,8 @@ func (s *Server) enforceStrategyRunPolicy(accountID, fingerprint string, now tim
 	}
 	u.MinuteCount++
 
-	dayKey := now.Format("2006-01-02")
+	day, _ := s.riskEngine.TradingDay(key)
+	dayKey := day.Key(now)
 	if u.DayKey != dayKey {
 		u.DayKey = dayKey
 		u.DayCount = 0
@@ -871,9 +2962,157 @@ func hashPayload(payload interface{}) string {
 	return fmt.Sprintf("%x", h.Sum64())
 }
 
-func (s *Server) calculateVolume() float64 {
-	// MVP fixed volume sizing for paper mode while risk % wiring is completed.
-	return 0.01
+// newTrendEngine applies the STRATEGY_* tuning knobs; unset values keep the
+// engine defaults, and an invalid combination falls back to them entirely.
+func newTrendEngine(cfg config.Config) *strategy.TrendEngine {
+	engine := strategy.NewTrendEngine().Override(strategy.TrendOverride{
+		FastEMA:        cfg.StrategyFastEMA,
+		SlowEMA:        cfg.StrategySlowEMA,
+		ATRLen:         cfg.StrategyATRLen,
+		SLATRMult:      cfg.StrategySLATRMult,
+		TPRiskReward:   cfg.StrategyTPRiskReward,
+		MinSLPips:      cfg.StrategyMinSLPips,
+		MinTrendGapPct: cfg.StrategyMinTrendGapPct,
+		MinSlopePct:    cfg.StrategyMinSlopePct,
+	})
+	if err := engine.Validate(); err != nil {
+		log.Printf("invalid strategy parameters, using defaults: %v", err)
+		return strategy.NewTrendEngine()
+	}
+	return engine
+}
+
+// snapshotPositions returns the open positions from the account's latest
+// sync, tagged with registered currencies for the exposure rules. Positions
+// opened since that sync are not included.
+func (s *Server) snapshotPositions(accountID string) []domain.Position {
+	snapshot, ok := s.store.PositionSnapshot(accountID)
+	if !ok {
+		return nil
+	}
+	positions := risk.SnapshotPositions(snapshot)
+	for i, p := range positions {
+		if spec, ok := s.symbols.Get(p.Symbol); ok {
+			positions[i].BaseCurrency = spec.BaseCurrency
+			positions[i].ProfitCurrency = spec.ProfitCurrency
+		}
+	}
+	return positions
+}
+
+// snapshotMargin returns the margin reported in the account's latest sync,
+// or nil when the EA did not send any.
+func (s *Server) snapshotMargin(accountID string) *domain.MarginState {
+	snapshot, ok := s.store.PositionSnapshot(accountID)
+	if !ok {
+		return nil
+	}
+	m, ok := risk.SnapshotMargin(snapshot)
+	if !ok {
+		return nil
+	}
+	return &m
+}
+
+// newTradingLocation resolves TRADING_TIMEZONE for calendar imports with
+// floating times. It uses the same parser as the trading schedule, so an
+// unknown zone stops the server like an invalid schedule does.
+func newTradingLocation(cfg config.Config) *time.Location {
+	loc, err := schedule.ParseLocation(cfg.TradingTimezone)
+	if err != nil {
+		log.Fatalf("invalid trading schedule: %v", err)
+	}
+	return loc
+}
+
+// newConverter builds the FX converter from FX_RATES; an invalid table is
+// logged and only EA quotes are used.
+func newConverter(cfg config.Config) *fx.Converter {
+	table, err := fx.ParseRates(cfg.FXRates)
+	if err != nil {
+		log.Printf("invalid FX_RATES, only EA quotes are used: %v", err)
+		table = nil
+	}
+	return fx.NewConverter(table, cfg.FXQuoteMaxAge)
+}
+
+// newPaperAccounts builds the PAPER_ACCOUNTS and registers them in the store
+// so their syncs, trades and deals have an account to belong to.
+func newPaperAccounts(cfg config.Config, store storepkg.Store) map[string]*paper.Account {
+	accounts := make(map[string]*paper.Account)
+	currency := fx.Normalize(cfg.PaperCurrency)
+	if !fx.ValidCode(currency) {
+		log.Printf("invalid PAPER_CURRENCY %q, using USD", cfg.PaperCurrency)
+		currency = "USD"
+	}
+	for _, id := range parseCSVList(cfg.PaperAccounts) {
+		accounts[id] = paper.NewAccount(id, paper.Config{
+			Balance:      cfg.PaperBalance,
+			Currency:     currency,
+			SpreadPips:   cfg.PaperSpreadPips,
+			SlippagePips: cfg.PaperSlippagePips,
+		})
+		store.SaveAccount(domain.Account{ID: id, Currency: currency, UpdatedAt: time.Now().UTC()})
+	}
+	return accounts
+}
+
+// newRegimeRoutes returns nil (no routing) unless STRATEGY_REGIME_ROUTING is
+// on; invalid routes are logged and routing stays off.
+func newRegimeRoutes(cfg config.Config, strategies *strategy.Registry) regime.Routes {
+	if !cfg.StrategyRegimeRouting {
+		return nil
+	}
+	routes, err := regime.ParseRoutes(cfg.StrategyRegimeRoutes, strategies)
+	if err != nil {
+		log.Printf("invalid STRATEGY_REGIME_ROUTES, regime routing disabled: %v", err)
+		return nil
+	}
+	return routes
+}
+
+// calculateVolume sizes the position so hitting the stop loses DefaultRiskPct
+// of equity. Without a registered spec, a synced equity or a stop distance it
+// falls back to the 0.01-lot paper size.
+func (s *Server) calculateVolume(accountID string, stopLossPips float64, spec domain.SymbolSpec, known bool) float64 {
+	const fallback = 0.01
+	if !known {
+		return fallback
+	}
+	snapshot, ok := s.store.PositionSnapshot(accountID)
+	if !ok {
+		return symbols.NormalizeVolume(spec, fallback)
+	}
+	equity := risk.DeriveSnapshotMetrics(snapshot).Equity
+	pipValue := symbols.PipValuePerLot(spec)
+	if equity <= 0 || pipValue <= 0 || stopLossPips <= 0 || s.cfg.DefaultRiskPct <= 0 {
+		return symbols.NormalizeVolume(spec, fallback)
+	}
+	lots := equity * s.cfg.DefaultRiskPct / 100 / (stopLossPips * pipValue)
+	return symbols.NormalizeVolume(spec, lots)
+}
+
+// newSignalStrategy returns the trend engine, or an ensemble of registered
+// strategies when STRATEGY_ENSEMBLE lists members (e.g. "trend:2,breakout").
+// The ensemble is registered too so regime routes can target it. A bad
+// ensemble configuration is logged and the trend engine used alone.
+func newSignalStrategy(cfg config.Config, strategies *strategy.Registry) strategy.Strategy {
+	trend, _ := strategies.Get("trend")
+	if strings.TrimSpace(cfg.StrategyEnsemble) == "" {
+		return trend
+	}
+	members, err := strategies.ParseMembers(cfg.StrategyEnsemble)
+	if err != nil {
+		log.Printf("invalid STRATEGY_ENSEMBLE, using trend engine only: %v", err)
+		return trend
+	}
+	ensemble, err := strategy.NewEnsemble(cfg.StrategyEnsembleMode, members...)
+	if err != nil {
+		log.Printf("invalid STRATEGY_ENSEMBLE_MODE, using trend engine only: %v", err)
+		return trend
+	}
+	strategies.Register(ensemble)
+	return ensemble
 }
 
 func (s *Server) emitEvent(eventType domain.EventType, accountID string, payload map[string]interface{}) domain.Event {
@@ -1041,7 +3280,11 @@ func (s *Server) isAllowedTelegramChat(chatID string) bool {
 
 func (s *Server) setPausedState(ctx context.Context, paused bool, source string) domain.Event {
 	_ = ctx
-	s.store.SetPaused(paused)
+	if paused {
+		s.store.SetPausedBy(domain.PauseOwner{Source: source, At: time.Now().UTC()})
+	} else {
+		s.store.SetPaused(false)
+	}
 	return s.emitEvent(domain.EventBotPaused, "", map[string]interface{}{
 		"paused": paused,
 		"source": source,
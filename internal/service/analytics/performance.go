@@ -0,0 +1,197 @@
+// Package analytics summarizes closed journal trades into performance
+// statistics, overall and per strategy and symbol.
+package analytics
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"mmbot/internal/domain"
+)
+
+// profitFactorCap stands in for an infinite profit factor (no losing trades)
+// so results stay JSON-encodable.
+const profitFactorCap = 999.0
+
+// tradingDaysPerYear annualizes the daily Sharpe and Sortino ratios.
+const tradingDaysPerYear = 252
+
+// Performance summarizes a set of closed trades. A trade with zero or
+// negative profit counts as a loss. Sharpe and Sortino are annualized from
+// the days with at least one close. Without an initial equity they use daily
+// profit instead of daily returns and MaxDrawdownPct stays zero.
+type Performance struct {
+	Trades               int     `json:"trades"`
+	Wins                 int     `json:"wins"`
+	Losses               int     `json:"losses"`
+	WinRate              float64 `json:"win_rate"`
+	GrossProfit          float64 `json:"gross_profit"`
+	GrossLoss            float64 `json:"gross_loss"`
+	NetProfit            float64 `json:"net_profit"`
+	ProfitFactor         float64 `json:"profit_factor"`
+	Expectancy           float64 `json:"expectancy"`
+	AvgWin               float64 `json:"avg_win"`
+	AvgLoss              float64 `json:"avg_loss"`
+	AvgR                 float64 `json:"avg_r"`
+	Sharpe               float64 `json:"sharpe"`
+	Sortino              float64 `json:"sortino"`
+	MaxDrawdown          float64 `json:"max_drawdown"`
+	MaxDrawdownPct       float64 `json:"max_drawdown_pct"`
+	MaxConsecutiveWins   int     `json:"max_consecutive_wins"`
+	MaxConsecutiveLosses int     `json:"max_consecutive_losses"`
+	// CurrentStreak is positive for a run of wins and negative for losses.
+	CurrentStreak int       `json:"current_streak"`
+	FirstCloseAt  time.Time `json:"first_close_at,omitempty"`
+	LastCloseAt   time.Time `json:"last_close_at,omitempty"`
+}
+
+// Report is the overall performance with its attribution by strategy and
+// symbol. Trades without a strategy are grouped under "manual".
+type Report struct {
+	Performance
+	InitialEquity float64                `json:"initial_equity,omitempty"`
+	ByStrategy    map[string]Performance `json:"by_strategy"`
+	BySymbol      map[string]Performance `json:"by_symbol"`
+}
+
+// Compute builds the report for closed trades. initialEquity is the account
+// equity before the first close; zero leaves percentages out.
+func Compute(trades []domain.Trade, initialEquity float64) Report {
+	closed := make([]domain.Trade, 0, len(trades))
+	for _, t := range trades {
+		if t.Status == domain.TradeStatusClosed {
+			closed = append(closed, t)
+		}
+	}
+	sort.SliceStable(closed, func(i, j int) bool { return closed[i].ClosedAt.Before(closed[j].ClosedAt) })
+
+	report := Report{
+		Performance:   summarize(closed, initialEquity),
+		InitialEquity: initialEquity,
+		ByStrategy:    make(map[string]Performance),
+		BySymbol:      make(map[string]Performance),
+	}
+	byStrategy := make(map[string][]domain.Trade)
+	bySymbol := make(map[string][]domain.Trade)
+	for _, t := range closed {
+		strategy := t.Strategy
+		if strategy == "" {
+			strategy = "manual"
+		}
+		byStrategy[strategy] = append(byStrategy[strategy], t)
+		bySymbol[t.Symbol] = append(bySymbol[t.Symbol], t)
+	}
+	for k, ts := range byStrategy {
+		report.ByStrategy[k] = summarize(ts, initialEquity)
+	}
+	for k, ts := range bySymbol {
+		report.BySymbol[k] = summarize(ts, initialEquity)
+	}
+	return report
+}
+
+// summarize expects trades sorted by close time.
+func summarize(trades []domain.Trade, initialEquity float64) Performance {
+	p := Performance{Trades: len(trades)}
+	if len(trades) == 0 {
+		return p
+	}
+	p.FirstCloseAt = trades[0].ClosedAt
+	p.LastCloseAt = trades[len(trades)-1].ClosedAt
+
+	sumR := 0.0
+	equity, peak := initialEquity, initialEquity
+	var days []float64
+	day, dayStart, dayPnL := "", initialEquity, 0.0
+	flushDay := func() {
+		if day == "" {
+			return
+		}
+		if initialEquity > 0 && dayStart > 0 {
+			days = append(days, dayPnL/dayStart)
+		} else {
+			days = append(days, dayPnL)
+		}
+	}
+	for _, t := range trades {
+		p.NetProfit += t.Profit
+		sumR += t.RMultiple
+		if t.Profit > 0 {
+			p.Wins++
+			p.GrossProfit += t.Profit
+			p.CurrentStreak = max(p.CurrentStreak, 0) + 1
+			p.MaxConsecutiveWins = max(p.MaxConsecutiveWins, p.CurrentStreak)
+		} else {
+			p.Losses++
+			p.GrossLoss += -t.Profit
+			p.CurrentStreak = min(p.CurrentStreak, 0) - 1
+			p.MaxConsecutiveLosses = max(p.MaxConsecutiveLosses, -p.CurrentStreak)
+		}
+
+		if d := t.ClosedAt.UTC().Format("2006-01-02"); d != day {
+			flushDay()
+			day, dayStart, dayPnL = d, equity, 0
+		}
+		dayPnL += t.Profit
+		equity += t.Profit
+		peak = max(peak, equity)
+		if dd := peak - equity; dd > p.MaxDrawdown {
+			p.MaxDrawdown = dd
+			if initialEquity > 0 && peak > 0 {
+				p.MaxDrawdownPct = dd / peak * 100
+			}
+		}
+	}
+	flushDay()
+
+	p.WinRate = float64(p.Wins) / float64(p.Trades)
+	p.Expectancy = p.NetProfit / float64(p.Trades)
+	p.AvgR = sumR / float64(p.Trades)
+	if p.Wins > 0 {
+		p.AvgWin = p.GrossProfit / float64(p.Wins)
+	}
+	if p.Losses > 0 {
+		p.AvgLoss = p.GrossLoss / float64(p.Losses)
+	}
+	switch {
+	case p.GrossLoss > 0:
+		p.ProfitFactor = p.GrossProfit / p.GrossLoss
+	case p.GrossProfit > 0:
+		p.ProfitFactor = profitFactorCap
+	}
+	p.Sharpe, p.Sortino = ratios(days)
+	return p
+}
+
+// ratios returns the annualized Sharpe and Sortino ratios of daily returns,
+// with a zero risk-free rate. Both are zero below two days or without
+// variation.
+func ratios(returns []float64) (float64, float64) {
+	n := float64(len(returns))
+	if n < 2 {
+		return 0, 0
+	}
+	mean, downside := 0.0, 0.0
+	for _, r := range returns {
+		mean += r
+		if r < 0 {
+			downside += r * r
+		}
+	}
+	mean /= n
+	variance := 0.0
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	std := math.Sqrt(variance / (n - 1))
+	annual := math.Sqrt(tradingDaysPerYear)
+	var sharpe, sortino float64
+	if std > 0 {
+		sharpe = mean / std * annual
+	}
+	if dd := math.Sqrt(downside / n); dd > 0 {
+		sortino = mean / dd * annual
+	}
+	return sharpe, sortino
+}
@@ -0,0 +1,60 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"mmbot/internal/domain"
+)
+
+func closedTrade(strategy, symbol string, profit, r float64, at time.Time) domain.Trade {
+	return domain.Trade{Status: domain.TradeStatusClosed, Strategy: strategy, Symbol: symbol, Profit: profit, RMultiple: r, ClosedAt: at}
+}
+
+func TestCompute_StatsAndAttribution(t *testing.T) {
+	day := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	trades := []domain.Trade{
+		closedTrade("trend", "EURUSD", 100, 2, day),
+		closedTrade("trend", "EURUSD", -50, -1, day.Add(time.Hour)),
+		closedTrade("", "GBPUSD", -50, -1, day.Add(24*time.Hour)),
+		closedTrade("breakout", "GBPUSD", 200, 3, day.Add(48*time.Hour)),
+		{Status: domain.TradeStatusOpen, Strategy: "trend", Profit: 1000},
+	}
+	report := Compute(trades, 1000)
+
+	if report.Trades != 4 || report.Wins != 2 || report.Losses != 2 || report.WinRate != 0.5 {
+		t.Fatalf("unexpected counts %+v", report.Performance)
+	}
+	if report.NetProfit != 200 || report.ProfitFactor != 3 || report.Expectancy != 50 || report.AvgR != 0.75 {
+		t.Fatalf("unexpected profit stats %+v", report.Performance)
+	}
+	// Equity 1000 -> 1100 -> 1050 -> 1000 -> 1200: 100 below the 1100 peak.
+	if report.MaxDrawdown != 100 || math.Abs(report.MaxDrawdownPct-100.0/1100*100) > 1e-9 {
+		t.Fatalf("unexpected drawdown %+v", report.Performance)
+	}
+	if report.MaxConsecutiveLosses != 2 || report.MaxConsecutiveWins != 1 || report.CurrentStreak != 1 {
+		t.Fatalf("unexpected streaks %+v", report.Performance)
+	}
+	// Daily returns: +5%, -4.76%, +20%.
+	if report.Sharpe <= 0 || report.Sortino <= report.Sharpe {
+		t.Fatalf("expected positive Sharpe below Sortino, got %v / %v", report.Sharpe, report.Sortino)
+	}
+
+	if got := report.ByStrategy["trend"]; got.Trades != 2 || got.NetProfit != 50 {
+		t.Fatalf("unexpected trend attribution %+v", got)
+	}
+	if got := report.ByStrategy["manual"]; got.Trades != 1 || got.NetProfit != -50 {
+		t.Fatalf("expected untagged trades under manual, got %+v", got)
+	}
+	if got := report.BySymbol["GBPUSD"]; got.Trades != 2 || got.NetProfit != 150 {
+		t.Fatalf("unexpected GBPUSD attribution %+v", got)
+	}
+}
+
+func TestCompute_NoTrades(t *testing.T) {
+	report := Compute(nil, 0)
+	if report.Trades != 0 || report.ProfitFactor != 0 || report.Sharpe != 0 || len(report.ByStrategy) != 0 {
+		t.Fatalf("expected an empty report, got %+v", report)
+	}
+}
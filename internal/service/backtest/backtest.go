@@ -0,0 +1,400 @@
+package backtest
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"mmbot/internal/domain"
+	"mmbot/internal/service/risk"
+	"mmbot/internal/service/strategy"
+)
+
+// Strategy is the subset of a strategy engine the backtester drives bar by bar.
+type Strategy interface {
+	Evaluate(input strategy.TrendInput) (strategy.TrendSignal, error)
+}
+
+type Config struct {
+	Symbol    string `json:"symbol"`
+	Timeframe string `json:"timeframe"`
+	// HTFTimeframe resamples the candles into a higher timeframe whose EMA
+	// regime must confirm each signal, as in live evaluation.
+	HTFTimeframe     string  `json:"htf_timeframe,omitempty"`
+	InitialEquity    float64 `json:"initial_equity"`
+	SpreadPips       float64 `json:"spread_pips"`
+	SlippagePips     float64 `json:"slippage_pips"`
+	CommissionPerLot float64 `json:"commission_per_lot"`
+	PipSize          float64 `json:"pip_size"`
+	PipValuePerLot   float64 `json:"pip_value_per_lot"`
+	RiskPct          float64 `json:"risk_pct"`
+	Volume           float64 `json:"volume"`
+	Lookback         int     `json:"lookback"`
+	// WarmupBars are fed to the strategy as history but never traded, so an
+	// out-of-sample window can start with fully formed indicators.
+	WarmupBars int `json:"warmup_bars,omitempty"`
+}
+
+type Trade struct {
+	ID           int       `json:"id"`
+	Symbol       string    `json:"symbol"`
+	Side         string    `json:"side"`
+	Volume       float64   `json:"volume"`
+	SignalTime   time.Time `json:"signal_time"`
+	EntryTime    time.Time `json:"entry_time"`
+	EntryPrice   float64   `json:"entry_price"`
+	StopLoss     float64   `json:"stop_loss"`
+	TakeProfit   float64   `json:"take_profit"`
+	ExitTime     time.Time `json:"exit_time"`
+	ExitPrice    float64   `json:"exit_price"`
+	ExitReason   string    `json:"exit_reason"`
+	Pips         float64   `json:"pips"`
+	Commission   float64   `json:"commission"`
+	PnL          float64   `json:"pnl"`
+	RMultiple    float64   `json:"r_multiple"`
+	Confidence   float64   `json:"confidence"`
+	SignalReason string    `json:"signal_reason"`
+}
+
+type EquityPoint struct {
+	Time        time.Time `json:"time"`
+	Balance     float64   `json:"balance"`
+	Equity      float64   `json:"equity"`
+	DrawdownPct float64   `json:"drawdown_pct"`
+}
+
+type Result struct {
+	Config      Config         `json:"config"`
+	Stats       Stats          `json:"stats"`
+	Trades      []Trade        `json:"trades"`
+	Equity      []EquityPoint  `json:"equity"`
+	RiskDenials map[string]int `json:"risk_denials"`
+	Signals     int            `json:"signals"`
+	EvalErrors  int            `json:"eval_errors"`
+}
+
+const (
+	exitStopLoss   = "stop_loss"
+	exitTakeProfit = "take_profit"
+	exitEndOfData  = "end_of_data"
+)
+
+type Engine struct {
+	strategy Strategy
+	risk     *risk.Engine
+	cfg      Config
+}
+
+func NewEngine(strat Strategy, riskEngine *risk.Engine, cfg Config) *Engine {
+	return &Engine{strategy: strat, risk: riskEngine, cfg: cfg.withDefaults()}
+}
+
+func (cfg Config) withDefaults() Config {
+	if cfg.Timeframe == "" {
+		cfg.Timeframe = "M15"
+	}
+	if cfg.InitialEquity <= 0 {
+		cfg.InitialEquity = 10000
+	}
+	if cfg.PipSize <= 0 {
+		cfg.PipSize = strategy.PipSize(cfg.Symbol)
+	}
+	if cfg.PipValuePerLot <= 0 {
+		cfg.PipValuePerLot = 10
+	}
+	if cfg.Volume <= 0 {
+		cfg.Volume = 0.01
+	}
+	if cfg.Lookback <= 0 {
+		cfg.Lookback = 300
+	}
+	return cfg
+}
+
+type position struct {
+	trade    Trade
+	dir      float64
+	riskCash float64
+}
+
+type pendingEntry struct {
+	signal     strategy.TrendSignal
+	signalTime time.Time
+	volume     float64
+}
+
+// Run replays candles in time order. A signal on bar i's close is filled at
+// bar i+1's open, and SL/TP are checked against each bar's high/low with the
+// stop assumed to trigger first when both fall inside the same bar.
+func (e *Engine) Run(candles []strategy.Candle) (Result, error) {
+	if strings.TrimSpace(e.cfg.Symbol) == "" {
+		return Result{}, errors.New("symbol is required")
+	}
+	if len(candles) < 2 {
+		return Result{}, fmt.Errorf("at least 2 candles required, got %d", len(candles))
+	}
+	if e.cfg.WarmupBars >= len(candles)-1 {
+		return Result{}, fmt.Errorf("warmup of %d bars leaves nothing to trade in %d candles", e.cfg.WarmupBars, len(candles))
+	}
+	barLen, err := strategy.ParseTimeframe(e.cfg.Timeframe)
+	if err != nil {
+		return Result{}, err
+	}
+	var htf []strategy.Candle
+	var htfLen time.Duration
+	if e.cfg.HTFTimeframe != "" {
+		if htfLen, err = strategy.ParseTimeframe(e.cfg.HTFTimeframe); err != nil {
+			return Result{}, err
+		}
+		if htfLen <= barLen {
+			return Result{}, fmt.Errorf("higher timeframe %s must be longer than %s", e.cfg.HTFTimeframe, e.cfg.Timeframe)
+		}
+		htf = strategy.Resample(candles, htfLen)
+	}
+	htfClosed := 0
+
+	res := Result{
+		Config:      e.cfg,
+		Trades:      make([]Trade, 0, 64),
+		Equity:      make([]EquityPoint, 0, len(candles)),
+		RiskDenials: make(map[string]int),
+	}
+	balance := e.cfg.InitialEquity
+	peak := balance
+	open := make([]*position, 0, 4)
+	var pending []pendingEntry
+	dayKey := ""
+	dayStartEquity := balance
+	nextID := 1
+	var activity domain.TradeActivity
+	// Days roll at the default TRADING_DAY_BOUNDARY, as live trading does.
+	day, _ := e.risk.TradingDay(activity.AccountID)
+
+	for i, bar := range candles {
+		if i < e.cfg.WarmupBars {
+			continue
+		}
+		for _, p := range pending {
+			open = append(open, e.enter(p, bar, nextID))
+			nextID++
+			activity, _ = e.risk.RecordOpen(activity, bar.Time)
+		}
+		pending = pending[:0]
+
+		kept := open[:0]
+		for _, p := range open {
+			if price, reason, hit := e.exitHit(p, bar); hit {
+				trade := e.close(p, bar.Time, price, reason)
+				balance += trade.PnL
+				res.Trades = append(res.Trades, trade)
+				activity, _ = e.risk.RecordClose(activity, trade.PnL, bar.Time)
+				continue
+			}
+			kept = append(kept, p)
+		}
+		open = kept
+
+		equity := balance
+		for _, p := range open {
+			equity += e.markToMarket(p, bar.Close)
+		}
+		if key := day.Key(bar.Time); key != dayKey {
+			dayKey = key
+			dayStartEquity = equity
+		}
+		if equity > peak {
+			peak = equity
+		}
+		drawdownPct := 0.0
+		if peak > 0 {
+			drawdownPct = (peak - equity) / peak * 100
+		}
+		res.Equity = append(res.Equity, EquityPoint{
+			Time:        bar.Time,
+			Balance:     balance,
+			Equity:      equity,
+			DrawdownPct: drawdownPct,
+		})
+
+		if i == len(candles)-1 {
+			break
+		}
+		start := max(0, i+1-e.cfg.Lookback)
+		input := strategy.TrendInput{
+			Symbol:     e.cfg.Symbol,
+			Timeframe:  e.cfg.Timeframe,
+			Candles:    candles[start : i+1],
+			SpreadPips: e.cfg.SpreadPips,
+			PipSize:    e.cfg.PipSize,
+		}
+		if htf != nil {
+			// Only higher-timeframe bars that have closed by the end of this bar.
+			barEnd := bar.Time.Add(barLen)
+			for htfClosed < len(htf) && !htf[htfClosed].Time.Add(htfLen).After(barEnd) {
+				htfClosed++
+			}
+			input.HTFTimeframe = e.cfg.HTFTimeframe
+			input.HTFCandles = htf[max(0, htfClosed-e.cfg.Lookback):htfClosed]
+		}
+		sig, err := e.strategy.Evaluate(input)
+		if err != nil {
+			res.EvalErrors++
+			continue
+		}
+		if !sig.HasSignal {
+			continue
+		}
+		res.Signals++
+
+		dailyLossPct := 0.0
+		if dayStartEquity > 0 && equity < dayStartEquity {
+			dailyLossPct = (dayStartEquity - equity) / dayStartEquity * 100
+		}
+		volume := e.volume(equity, sig.StopLossPips)
+		positions := make([]domain.Position, 0, len(open)+len(pending))
+		for _, p := range open {
+			positions = append(positions, domain.Position{Symbol: p.trade.Symbol, Side: p.trade.Side, Volume: p.trade.Volume})
+		}
+		for _, p := range pending {
+			positions = append(positions, domain.Position{Symbol: e.cfg.Symbol, Side: p.signal.Side, Volume: p.volume})
+		}
+		decision := e.risk.Evaluate(domain.SignalInput{
+			Symbol:         e.cfg.Symbol,
+			Side:           sig.Side,
+			Confidence:     sig.Confidence,
+			Reason:         sig.Reason,
+			SpreadPips:     e.cfg.SpreadPips,
+			StopLossPips:   sig.StopLossPips,
+			TakeProfitPips: sig.TakeProfitPips,
+			Volume:         volume,
+		}, domain.StrategyState{
+			OpenPositions:     len(open) + len(pending),
+			DailyLossPct:      dailyLossPct,
+			Positions:         positions,
+			TradesToday:       day.Roll(activity, bar.Time).TradesToday,
+			LossCooldownUntil: activity.CooldownUntil,
+			Now:               bar.Time,
+		})
+		if !decision.Allowed {
+			res.RiskDenials[decision.DenyReason]++
+			continue
+		}
+		pending = append(pending, pendingEntry{
+			signal:     sig,
+			signalTime: bar.Time,
+			volume:     volume,
+		})
+	}
+
+	last := candles[len(candles)-1]
+	for _, p := range open {
+		exit := last.Close
+		if p.dir < 0 {
+			exit += e.cfg.SpreadPips * e.cfg.PipSize
+		}
+		trade := e.close(p, last.Time, exit, exitEndOfData)
+		balance += trade.PnL
+		res.Trades = append(res.Trades, trade)
+	}
+	if n := len(res.Equity); n > 0 && len(open) > 0 {
+		res.Equity[n-1].Balance = balance
+		res.Equity[n-1].Equity = balance
+	}
+
+	res.Stats = ComputeStats(res.Trades, res.Equity, e.cfg.InitialEquity)
+	return res, nil
+}
+
+func (e *Engine) enter(p pendingEntry, bar strategy.Candle, id int) *position {
+	pip := e.cfg.PipSize
+	dir := 1.0
+	entry := bar.Open + (e.cfg.SpreadPips+e.cfg.SlippagePips)*pip
+	if strings.EqualFold(p.signal.Side, "SELL") {
+		dir = -1
+		entry = bar.Open - e.cfg.SlippagePips*pip
+	}
+	sl := entry - dir*p.signal.StopLossPips*pip
+	tp := 0.0
+	if p.signal.TakeProfitPips > 0 {
+		tp = entry + dir*p.signal.TakeProfitPips*pip
+	}
+	return &position{
+		dir:      dir,
+		riskCash: p.signal.StopLossPips * e.cfg.PipValuePerLot * p.volume,
+		trade: Trade{
+			ID:           id,
+			Symbol:       e.cfg.Symbol,
+			Side:         strings.ToUpper(p.signal.Side),
+			Volume:       p.volume,
+			SignalTime:   p.signalTime,
+			EntryTime:    bar.Time,
+			EntryPrice:   entry,
+			StopLoss:     sl,
+			TakeProfit:   tp,
+			Confidence:   p.signal.Confidence,
+			SignalReason: p.signal.Reason,
+		},
+	}
+}
+
+// exitHit reports whether bar touches the position's SL or TP. Longs close on
+// the bid (candle prices) and shorts on the ask (candle prices plus spread).
+func (e *Engine) exitHit(p *position, bar strategy.Candle) (float64, string, bool) {
+	pip := e.cfg.PipSize
+	slip := e.cfg.SlippagePips * pip
+	t := p.trade
+	if p.dir > 0 {
+		if bar.Low <= t.StopLoss {
+			return math.Min(t.StopLoss, bar.Open) - slip, exitStopLoss, true
+		}
+		if t.TakeProfit > 0 && bar.High >= t.TakeProfit {
+			return math.Max(t.TakeProfit, bar.Open), exitTakeProfit, true
+		}
+		return 0, "", false
+	}
+	spread := e.cfg.SpreadPips * pip
+	if bar.High+spread >= t.StopLoss {
+		return math.Max(t.StopLoss, bar.Open+spread) + slip, exitStopLoss, true
+	}
+	if t.TakeProfit > 0 && bar.Low+spread <= t.TakeProfit {
+		return math.Min(t.TakeProfit, bar.Open+spread), exitTakeProfit, true
+	}
+	return 0, "", false
+}
+
+func (e *Engine) close(p *position, at time.Time, price float64, reason string) Trade {
+	t := p.trade
+	t.ExitTime = at
+	t.ExitPrice = price
+	t.ExitReason = reason
+	t.Pips = (price - t.EntryPrice) * p.dir / e.cfg.PipSize
+	t.Commission = e.cfg.CommissionPerLot * t.Volume
+	t.PnL = t.Pips*e.cfg.PipValuePerLot*t.Volume - t.Commission
+	if p.riskCash > 0 {
+		t.RMultiple = t.PnL / p.riskCash
+	}
+	return t
+}
+
+func (e *Engine) markToMarket(p *position, bid float64) float64 {
+	exit := bid
+	if p.dir < 0 {
+		exit += e.cfg.SpreadPips * e.cfg.PipSize
+	}
+	pips := (exit - p.trade.EntryPrice) * p.dir / e.cfg.PipSize
+	return pips * e.cfg.PipValuePerLot * p.trade.Volume
+}
+
+func (e *Engine) volume(equity, stopLossPips float64) float64 {
+	if e.cfg.RiskPct <= 0 || stopLossPips <= 0 {
+		return e.cfg.Volume
+	}
+	lots := equity * e.cfg.RiskPct / 100 / (stopLossPips * e.cfg.PipValuePerLot)
+	lots = math.Floor(lots*100) / 100
+	if lots < 0.01 {
+		lots = 0.01
+	}
+	return lots
+}
@@ -0,0 +1,238 @@
+package backtest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"mmbot/internal/service/risk"
+	"mmbot/internal/service/strategy"
+)
+
+// scriptedStrategy emits a fixed signal on the bars listed in fireAt.
+type scriptedStrategy struct {
+	fireAt map[int]bool
+	signal strategy.TrendSignal
+}
+
+func (s *scriptedStrategy) Evaluate(input strategy.TrendInput) (strategy.TrendSignal, error) {
+	if s.fireAt[len(input.Candles)-1] {
+		return s.signal, nil
+	}
+	return strategy.TrendSignal{}, nil
+}
+
+func flatCandles(n int, price float64) []strategy.Candle {
+	out := make([]strategy.Candle, 0, n)
+	for i := 0; i < n; i++ {
+		out = append(out, strategy.Candle{
+			Time:  time.Unix(int64(1700000000+i*900), 0).UTC(),
+			Open:  price,
+			High:  price + 0.0002,
+			Low:   price - 0.0002,
+			Close: price,
+		})
+	}
+	return out
+}
+
+func TestRun_TakeProfitHitIntrabar(t *testing.T) {
+	candles := flatCandles(6, 1.1000)
+	candles[3].High = 1.1030
+	strat := &scriptedStrategy{
+		fireAt: map[int]bool{1: true},
+		signal: strategy.TrendSignal{HasSignal: true, Side: "BUY", Confidence: 0.8, StopLossPips: 10, TakeProfitPips: 20},
+	}
+	engine := NewEngine(strat, risk.NewEngine(3, 2.0, 0.70, 2.0), Config{
+		Symbol:         "EURUSD",
+		InitialEquity:  10000,
+		SpreadPips:     1,
+		PipValuePerLot: 10,
+		Volume:         1,
+	})
+	res, err := engine.Run(candles)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Trades) != 1 {
+		t.Fatalf("expected 1 trade, got %d", len(res.Trades))
+	}
+	tr := res.Trades[0]
+	if tr.ExitReason != exitTakeProfit {
+		t.Fatalf("expected take_profit exit, got %s", tr.ExitReason)
+	}
+	if !tr.EntryTime.Equal(candles[2].Time) {
+		t.Fatalf("expected entry on the bar after the signal, got %s", tr.EntryTime)
+	}
+	// Entry 1.1001 (spread), exit 1.1021: 20 pips at $10/pip on 1 lot.
+	if tr.Pips < 19.99 || tr.Pips > 20.01 || tr.PnL < 199.9 || tr.PnL > 200.1 {
+		t.Fatalf("unexpected trade result pips=%.2f pnl=%.2f", tr.Pips, tr.PnL)
+	}
+	if res.Stats.WinRate != 1 || res.Stats.ProfitFactor != profitFactorCap {
+		t.Fatalf("unexpected stats %+v", res.Stats)
+	}
+}
+
+func TestRun_StopLossWinsWhenBothLevelsInSameBar(t *testing.T) {
+	candles := flatCandles(6, 1.1000)
+	candles[3].High = 1.1050
+	candles[3].Low = 1.0950
+	strat := &scriptedStrategy{
+		fireAt: map[int]bool{1: true},
+		signal: strategy.TrendSignal{HasSignal: true, Side: "SELL", Confidence: 0.8, StopLossPips: 10, TakeProfitPips: 20},
+	}
+	engine := NewEngine(strat, risk.NewEngine(3, 2.0, 0.70, 2.0), Config{
+		Symbol:           "EURUSD",
+		CommissionPerLot: 7,
+		Volume:           1,
+	})
+	res, err := engine.Run(candles)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Trades) != 1 || res.Trades[0].ExitReason != exitStopLoss {
+		t.Fatalf("expected one stop_loss exit, got %+v", res.Trades)
+	}
+	if r := res.Trades[0].RMultiple; r > -1.0 {
+		t.Fatalf("expected loss beyond -1R after commission, got %.3f", r)
+	}
+	if res.Stats.MaxDrawdown <= 0 {
+		t.Fatalf("expected drawdown to be recorded, got %+v", res.Stats)
+	}
+}
+
+func TestRun_CountsRiskDenials(t *testing.T) {
+	strat := &scriptedStrategy{
+		fireAt: map[int]bool{1: true, 2: true},
+		signal: strategy.TrendSignal{HasSignal: true, Side: "BUY", Confidence: 0.5, StopLossPips: 10, TakeProfitPips: 20},
+	}
+	engine := NewEngine(strat, risk.NewEngine(3, 2.0, 0.70, 2.0), Config{Symbol: "EURUSD"})
+	res, err := engine.Run(flatCandles(5, 1.1000))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Trades) != 0 || res.RiskDenials["ai_confidence_too_low"] != 2 {
+		t.Fatalf("expected two confidence denials and no trades, got trades=%d denials=%v", len(res.Trades), res.RiskDenials)
+	}
+}
+
+func TestReadCandlesCSV_MT5Export(t *testing.T) {
+	raw := "<DATE>\t<TIME>\t<OPEN>\t<HIGH>\t<LOW>\t<CLOSE>\t<TICKVOL>\n" +
+		"2026.02.27\t14:15:00\t1.0826\t1.0830\t1.0820\t1.0828\t120\n" +
+		"2026.02.27\t14:00:00\t1.0821\t1.0829\t1.0817\t1.0826\t98\n"
+	candles, err := ReadCandlesCSV(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(candles) != 2 {
+		t.Fatalf("expected 2 candles, got %d", len(candles))
+	}
+	if candles[0].Time.Format(time.RFC3339) != "2026-02-27T14:00:00Z" || candles[0].Volume != 98 {
+		t.Fatalf("expected rows sorted by time, got %+v", candles[0])
+	}
+}
+
+func trendingCandles(n int) []strategy.Candle {
+	out := make([]strategy.Candle, 0, n)
+	price := 1.1000
+	for i := 0; i < n; i++ {
+		// Alternating legs so both long and short setups appear.
+		step := 0.0004
+		if (i/150)%2 == 1 {
+			step = -0.0004
+		}
+		open := price
+		price += step
+		out = append(out, strategy.Candle{
+			Time:  time.Unix(int64(1700000000+i*900), 0).UTC(),
+			Open:  open,
+			High:  max(open, price) + 0.0003,
+			Low:   min(open, price) - 0.0003,
+			Close: price,
+		})
+	}
+	return out
+}
+
+func TestParamSets_GridCartesianProduct(t *testing.T) {
+	fast, err := ParseParamRange("fast_ema=10:20:5")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sets, err := paramSets(SweepConfig{Ranges: []ParamRange{fast, {Name: "sl_atr_mult", Min: 1, Max: 2, Step: 0.5}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sets) != 9 {
+		t.Fatalf("expected 3x3 grid, got %d sets", len(sets))
+	}
+	if _, err := paramSets(SweepConfig{Ranges: []ParamRange{{Name: "nope", Min: 1, Max: 2, Step: 1}}}); err == nil {
+		t.Fatal("expected unknown parameter to be rejected")
+	}
+}
+
+func TestSweep_RanksByObjectiveAndSkipsInvalidSets(t *testing.T) {
+	results, err := Sweep(trendingCandles(600), Config{Symbol: "EURUSD"}, risk.NewEngine(3, 5.0, 0.0, 2.0), SweepConfig{
+		Base:      *strategy.NewTrendEngine(),
+		Ranges:    []ParamRange{{Name: "fast_ema", Min: 10, Max: 60, Step: 25}},
+		Objective: "net_profit",
+		Workers:   2,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	// fast_ema=60 is not shorter than slow_ema=50 and must fail validation.
+	last := results[len(results)-1]
+	if last.Params["fast_ema"] != 60 || last.Error == "" || last.Eligible {
+		t.Fatalf("expected invalid set ranked last with error, got %+v", last)
+	}
+	if results[0].Score < results[1].Score {
+		t.Fatalf("expected results sorted best first, got %.2f then %.2f", results[0].Score, results[1].Score)
+	}
+}
+
+func TestWalkForward_ProducesOutOfSampleFolds(t *testing.T) {
+	report, err := WalkForward(trendingCandles(900), Config{Symbol: "EURUSD"}, risk.NewEngine(3, 5.0, 0.0, 2.0), SweepConfig{
+		Base:   *strategy.NewTrendEngine(),
+		Ranges: []ParamRange{{Name: "sl_atr_mult", Min: 1, Max: 2, Step: 1}},
+	}, WalkForwardConfig{InSampleBars: 400, OutSampleBars: 200})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Folds) != 2 {
+		t.Fatalf("expected 2 folds, got %d", len(report.Folds))
+	}
+	for _, f := range report.Folds {
+		if !f.OutSampleFrom.After(f.InSampleTo) {
+			t.Fatalf("fold %d out-of-sample window overlaps in-sample", f.Index)
+		}
+	}
+}
+
+func TestRun_CountsTradesPerTradingDay(t *testing.T) {
+	// 17:00 New York is 22:00 UTC in early March, so the signal at 22:00
+	// opens a new trading day although the UTC date is unchanged.
+	candles := flatCandles(8, 1.1000)
+	for i := range candles {
+		candles[i].Time = time.Date(2026, 3, 2, 18+i, 0, 0, 0, time.UTC)
+	}
+	days, err := risk.ParseTradingDays("17:00@America/New_York", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	strat := &scriptedStrategy{
+		fireAt: map[int]bool{1: true, 4: true},
+		signal: strategy.TrendSignal{HasSignal: true, Side: "BUY", Confidence: 0.8, StopLossPips: 10, TakeProfitPips: 20},
+	}
+	riskEngine := risk.NewEngine(3, 2.0, 0.70, 2.0).WithStreakLimits(risk.StreakLimits{MaxTradesPerDay: 1}).WithTradingDays(days)
+	res, err := NewEngine(strat, riskEngine, Config{Symbol: "EURUSD", Volume: 1}).Run(candles)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Trades) != 2 || len(res.RiskDenials) != 0 {
+		t.Fatalf("expected one trade on each trading day, got trades=%d denials=%v", len(res.Trades), res.RiskDenials)
+	}
+}
@@ -0,0 +1,149 @@
+package backtest
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"mmbot/internal/service/strategy"
+)
+
+var candleTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006.01.02 15:04:05",
+	"2006.01.02 15:04",
+	"2006-01-02",
+	"2006.01.02",
+}
+
+// ReadCandlesCSV parses OHLC rows with a header naming at least time (or
+// date + time, as in MT5 exports), open, high, low and close. Comma, tab and
+// semicolon delimiters are accepted. Rows are returned sorted by time.
+func ReadCandlesCSV(r io.Reader) ([]strategy.Candle, error) {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(4096)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	cr := csv.NewReader(br)
+	cr.Comma = detectDelimiter(string(head))
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+	cols := make(map[string]int, len(header))
+	for i, h := range header {
+		name := strings.ToLower(strings.Trim(strings.TrimSpace(h), "<>"))
+		switch name {
+		case "tickvol", "tick_volume", "vol":
+			name = "volume"
+		case "timestamp", "datetime":
+			name = "time"
+		}
+		cols[name] = i
+	}
+	for _, required := range []string{"open", "high", "low", "close"} {
+		if _, ok := cols[required]; !ok {
+			return nil, fmt.Errorf("csv header missing %q column", required)
+		}
+	}
+	_, hasTime := cols["time"]
+	_, hasDate := cols["date"]
+	if !hasTime && !hasDate {
+		return nil, errors.New(`csv header missing "time" column`)
+	}
+
+	out := make([]strategy.Candle, 0, 1024)
+	line := 1
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		field := func(name string) string {
+			idx, ok := cols[name]
+			if !ok || idx >= len(rec) {
+				return ""
+			}
+			return strings.TrimSpace(rec[idx])
+		}
+		rawTime := field("time")
+		if hasDate {
+			rawTime = strings.TrimSpace(field("date") + " " + rawTime)
+		}
+		ts, err := parseCandleTime(rawTime)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		c := strategy.Candle{Time: ts}
+		for _, f := range []struct {
+			name string
+			dst  *float64
+		}{
+			{"open", &c.Open},
+			{"high", &c.High},
+			{"low", &c.Low},
+			{"close", &c.Close},
+		} {
+			v, err := strconv.ParseFloat(field(f.name), 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid %s: %w", line, f.name, err)
+			}
+			*f.dst = v
+		}
+		if raw := field("volume"); raw != "" {
+			if v, err := strconv.ParseFloat(raw, 64); err == nil {
+				c.Volume = v
+			}
+		}
+		out = append(out, c)
+	}
+	slices.SortStableFunc(out, func(a, b strategy.Candle) int {
+		return a.Time.Compare(b.Time)
+	})
+	return out, nil
+}
+
+func parseCandleTime(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, errors.New("empty time")
+	}
+	if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		if n > 1e12 {
+			return time.UnixMilli(n).UTC(), nil
+		}
+		return time.Unix(n, 0).UTC(), nil
+	}
+	for _, layout := range candleTimeLayouts {
+		if ts, err := time.Parse(layout, raw); err == nil {
+			return ts.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised time %q", raw)
+}
+
+func detectDelimiter(sample string) rune {
+	first, _, _ := strings.Cut(sample, "\n")
+	best, bestCount := ',', strings.Count(first, ",")
+	for _, d := range []rune{'\t', ';'} {
+		if n := strings.Count(first, string(d)); n > bestCount {
+			best, bestCount = d, n
+		}
+	}
+	return best
+}
@@ -0,0 +1,178 @@
+package backtest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+)
+
+// profitFactorCap stands in for an infinite profit factor (no losing trades)
+// so results stay JSON-encodable and sortable.
+const profitFactorCap = 999.0
+
+type Stats struct {
+	Trades          int     `json:"trades"`
+	Wins            int     `json:"wins"`
+	Losses          int     `json:"losses"`
+	WinRate         float64 `json:"win_rate"`
+	GrossProfit     float64 `json:"gross_profit"`
+	GrossLoss       float64 `json:"gross_loss"`
+	NetProfit       float64 `json:"net_profit"`
+	ProfitFactor    float64 `json:"profit_factor"`
+	Expectancy      float64 `json:"expectancy"`
+	ExpectancyR     float64 `json:"expectancy_r"`
+	AvgWin          float64 `json:"avg_win"`
+	AvgLoss         float64 `json:"avg_loss"`
+	MaxDrawdown     float64 `json:"max_drawdown"`
+	MaxDrawdownPct  float64 `json:"max_drawdown_pct"`
+	InitialEquity   float64 `json:"initial_equity"`
+	FinalEquity     float64 `json:"final_equity"`
+	ReturnPct       float64 `json:"return_pct"`
+	MaxConsecLosses int     `json:"max_consecutive_losses"`
+}
+
+// ComputeStats summarises closed trades and the mark-to-market equity curve.
+func ComputeStats(trades []Trade, equity []EquityPoint, initialEquity float64) Stats {
+	st := Stats{
+		Trades:        len(trades),
+		InitialEquity: initialEquity,
+		FinalEquity:   initialEquity,
+	}
+	sumR := 0.0
+	streak := 0
+	for _, t := range trades {
+		st.NetProfit += t.PnL
+		sumR += t.RMultiple
+		if t.PnL > 0 {
+			st.Wins++
+			st.GrossProfit += t.PnL
+			streak = 0
+		} else {
+			st.Losses++
+			st.GrossLoss += -t.PnL
+			streak++
+			st.MaxConsecLosses = max(st.MaxConsecLosses, streak)
+		}
+	}
+	if st.Trades > 0 {
+		st.WinRate = float64(st.Wins) / float64(st.Trades)
+		st.Expectancy = st.NetProfit / float64(st.Trades)
+		st.ExpectancyR = sumR / float64(st.Trades)
+	}
+	if st.Wins > 0 {
+		st.AvgWin = st.GrossProfit / float64(st.Wins)
+	}
+	if st.Losses > 0 {
+		st.AvgLoss = st.GrossLoss / float64(st.Losses)
+	}
+	switch {
+	case st.GrossLoss > 0:
+		st.ProfitFactor = st.GrossProfit / st.GrossLoss
+	case st.GrossProfit > 0:
+		st.ProfitFactor = profitFactorCap
+	}
+
+	peak := initialEquity
+	for _, p := range equity {
+		if p.Equity > peak {
+			peak = p.Equity
+		}
+		if dd := peak - p.Equity; dd > st.MaxDrawdown {
+			st.MaxDrawdown = dd
+		}
+		if p.DrawdownPct > st.MaxDrawdownPct {
+			st.MaxDrawdownPct = p.DrawdownPct
+		}
+	}
+	st.FinalEquity = initialEquity + st.NetProfit
+	if initialEquity > 0 {
+		st.ReturnPct = st.NetProfit / initialEquity * 100
+	}
+	return st
+}
+
+func WriteJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func WriteTradesCSV(w io.Writer, trades []Trade) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{
+		"id", "symbol", "side", "volume", "signal_time", "entry_time", "entry_price", "stop_loss", "take_profit",
+		"exit_time", "exit_price", "exit_reason", "pips", "commission", "pnl", "r_multiple", "confidence", "signal_reason",
+	})
+	for _, t := range trades {
+		_ = cw.Write([]string{
+			strconv.Itoa(t.ID),
+			t.Symbol,
+			t.Side,
+			formatFloat(t.Volume),
+			t.SignalTime.UTC().Format(time.RFC3339),
+			t.EntryTime.UTC().Format(time.RFC3339),
+			formatFloat(t.EntryPrice),
+			formatFloat(t.StopLoss),
+			formatFloat(t.TakeProfit),
+			t.ExitTime.UTC().Format(time.RFC3339),
+			formatFloat(t.ExitPrice),
+			t.ExitReason,
+			formatFloat(t.Pips),
+			formatFloat(t.Commission),
+			formatFloat(t.PnL),
+			formatFloat(t.RMultiple),
+			formatFloat(t.Confidence),
+			t.SignalReason,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func WriteEquityCSV(w io.Writer, points []EquityPoint) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"time", "balance", "equity", "drawdown_pct"})
+	for _, p := range points {
+		_ = cw.Write([]string{
+			p.Time.UTC().Format(time.RFC3339),
+			formatFloat(p.Balance),
+			formatFloat(p.Equity),
+			formatFloat(p.DrawdownPct),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func WriteStatsCSV(w io.Writer, st Stats) error {
+	cw := csv.NewWriter(w)
+	rows := [][]string{
+		{"metric", "value"},
+		{"trades", strconv.Itoa(st.Trades)},
+		{"wins", strconv.Itoa(st.Wins)},
+		{"losses", strconv.Itoa(st.Losses)},
+		{"win_rate", formatFloat(st.WinRate)},
+		{"gross_profit", formatFloat(st.GrossProfit)},
+		{"gross_loss", formatFloat(st.GrossLoss)},
+		{"net_profit", formatFloat(st.NetProfit)},
+		{"profit_factor", formatFloat(st.ProfitFactor)},
+		{"expectancy", formatFloat(st.Expectancy)},
+		{"expectancy_r", formatFloat(st.ExpectancyR)},
+		{"avg_win", formatFloat(st.AvgWin)},
+		{"avg_loss", formatFloat(st.AvgLoss)},
+		{"max_drawdown", formatFloat(st.MaxDrawdown)},
+		{"max_drawdown_pct", formatFloat(st.MaxDrawdownPct)},
+		{"initial_equity", formatFloat(st.InitialEquity)},
+		{"final_equity", formatFloat(st.FinalEquity)},
+		{"return_pct", formatFloat(st.ReturnPct)},
+		{"max_consecutive_losses", strconv.Itoa(st.MaxConsecLosses)},
+	}
+	_ = cw.WriteAll(rows)
+	return cw.Error()
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,246 @@
+package backtest
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"mmbot/internal/service/risk"
+	"mmbot/internal/service/strategy"
+)
+
+type ParamRange struct {
+	Name string  `json:"name"`
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+	Step float64 `json:"step"`
+}
+
+// ParseParamRange reads "name=min:max:step" (step optional for random search).
+func ParseParamRange(raw string) (ParamRange, error) {
+	name, spec, ok := strings.Cut(raw, "=")
+	if !ok || strings.TrimSpace(name) == "" {
+		return ParamRange{}, fmt.Errorf("param %q: expected name=min:max[:step]", raw)
+	}
+	parts := strings.Split(spec, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return ParamRange{}, fmt.Errorf("param %q: expected name=min:max[:step]", raw)
+	}
+	vals := make([]float64, len(parts))
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return ParamRange{}, fmt.Errorf("param %q: %w", raw, err)
+		}
+		vals[i] = v
+	}
+	pr := ParamRange{Name: strings.TrimSpace(name), Min: vals[0], Max: vals[1]}
+	if len(vals) == 3 {
+		pr.Step = vals[2]
+	}
+	if pr.Max < pr.Min {
+		return ParamRange{}, fmt.Errorf("param %q: max below min", raw)
+	}
+	return pr, nil
+}
+
+const (
+	SearchGrid   = "grid"
+	SearchRandom = "random"
+)
+
+type SweepConfig struct {
+	Base      strategy.TrendEngine `json:"base"`
+	Ranges    []ParamRange         `json:"ranges"`
+	Search    string               `json:"search"`
+	Samples   int                  `json:"samples"`
+	Seed      int64                `json:"seed"`
+	Objective string               `json:"objective"`
+	MinTrades int                  `json:"min_trades"`
+	Workers   int                  `json:"workers"`
+}
+
+type SweepResult struct {
+	Params   map[string]float64 `json:"params"`
+	Score    float64            `json:"score"`
+	Eligible bool               `json:"eligible"`
+	Stats    Stats              `json:"stats"`
+	Error    string             `json:"error,omitempty"`
+}
+
+// Objectives maps objective names to a score where higher is better.
+var Objectives = map[string]func(Stats) float64{
+	"net_profit":    func(s Stats) float64 { return s.NetProfit },
+	"profit_factor": func(s Stats) float64 { return s.ProfitFactor },
+	"expectancy":    func(s Stats) float64 { return s.Expectancy },
+	"expectancy_r":  func(s Stats) float64 { return s.ExpectancyR },
+	"win_rate":      func(s Stats) float64 { return s.WinRate },
+	"max_drawdown":  func(s Stats) float64 { return -s.MaxDrawdownPct },
+	"return_dd": func(s Stats) float64 {
+		if s.MaxDrawdownPct <= 0 {
+			return s.ReturnPct
+		}
+		return s.ReturnPct / s.MaxDrawdownPct
+	},
+}
+
+func objective(name string) (func(Stats) float64, error) {
+	if name == "" {
+		name = "return_dd"
+	}
+	fn, ok := Objectives[name]
+	if !ok {
+		names := make([]string, 0, len(Objectives))
+		for k := range Objectives {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown objective %q (want one of %s)", name, strings.Join(names, ", "))
+	}
+	return fn, nil
+}
+
+// Sweep backtests every parameter set from the grid or random search across
+// Workers goroutines (default: one per CPU) and returns results best first.
+// Runs with fewer than MinTrades trades are ranked last as ineligible.
+func Sweep(candles []strategy.Candle, btCfg Config, riskEngine *risk.Engine, sc SweepConfig) ([]SweepResult, error) {
+	score, err := objective(sc.Objective)
+	if err != nil {
+		return nil, err
+	}
+	sets, err := paramSets(sc)
+	if err != nil {
+		return nil, err
+	}
+	workers := sc.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	results := make([]SweepResult, len(sets))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, len(sets)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				results[idx] = runParamSet(candles, btCfg, riskEngine, sc, sets[idx], score)
+			}
+		}()
+	}
+	for i := range sets {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Eligible != b.Eligible {
+			return a.Eligible
+		}
+		return a.Score > b.Score
+	})
+	return results, nil
+}
+
+func runParamSet(candles []strategy.Candle, btCfg Config, riskEngine *risk.Engine, sc SweepConfig, params map[string]float64, score func(Stats) float64) SweepResult {
+	out := SweepResult{Params: params}
+	engine := sc.Base
+	for name, v := range params {
+		if err := engine.SetParam(name, v); err != nil {
+			out.Error = err.Error()
+			return out
+		}
+	}
+	if err := engine.Validate(); err != nil {
+		out.Error = err.Error()
+		return out
+	}
+	res, err := NewEngine(&engine, riskEngine, btCfg).Run(candles)
+	if err != nil {
+		out.Error = err.Error()
+		return out
+	}
+	out.Stats = res.Stats
+	out.Score = score(res.Stats)
+	out.Eligible = res.Stats.Trades >= sc.MinTrades
+	return out
+}
+
+func paramSets(sc SweepConfig) ([]map[string]float64, error) {
+	if len(sc.Ranges) == 0 {
+		return nil, errors.New("at least one parameter range is required")
+	}
+	known := strategy.TrendParamNames()
+	for _, r := range sc.Ranges {
+		if !slices.Contains(known, r.Name) {
+			return nil, fmt.Errorf("unknown parameter %q (want one of %s)", r.Name, strings.Join(known, ", "))
+		}
+	}
+
+	switch sc.Search {
+	case "", SearchGrid:
+		axes := make([][]float64, len(sc.Ranges))
+		total := 1
+		for i, r := range sc.Ranges {
+			if r.Step <= 0 {
+				return nil, fmt.Errorf("grid search needs a positive step for %q", r.Name)
+			}
+			for v := r.Min; v <= r.Max+r.Step*1e-9; v += r.Step {
+				axes[i] = append(axes[i], math.Round(v*1e9)/1e9)
+			}
+			total *= len(axes[i])
+		}
+		sets := make([]map[string]float64, 0, total)
+		idx := make([]int, len(axes))
+		for {
+			set := make(map[string]float64, len(axes))
+			for i, r := range sc.Ranges {
+				set[r.Name] = axes[i][idx[i]]
+			}
+			sets = append(sets, set)
+			k := len(idx) - 1
+			for k >= 0 {
+				idx[k]++
+				if idx[k] < len(axes[k]) {
+					break
+				}
+				idx[k] = 0
+				k--
+			}
+			if k < 0 {
+				return sets, nil
+			}
+		}
+	case SearchRandom:
+		n := sc.Samples
+		if n <= 0 {
+			n = 100
+		}
+		rng := rand.New(rand.NewSource(sc.Seed))
+		sets := make([]map[string]float64, 0, n)
+		for i := 0; i < n; i++ {
+			set := make(map[string]float64, len(sc.Ranges))
+			for _, r := range sc.Ranges {
+				v := r.Min + rng.Float64()*(r.Max-r.Min)
+				if r.Step > 0 {
+					v = r.Min + math.Round((v-r.Min)/r.Step)*r.Step
+				}
+				set[r.Name] = math.Round(v*1e9) / 1e9
+			}
+			sets = append(sets, set)
+		}
+		return sets, nil
+	default:
+		return nil, fmt.Errorf("unknown search %q (want grid or random)", sc.Search)
+	}
+}
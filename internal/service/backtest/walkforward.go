@@ -0,0 +1,144 @@
+package backtest
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"mmbot/internal/service/risk"
+	"mmbot/internal/service/strategy"
+)
+
+type WalkForwardConfig struct {
+	InSampleBars  int  `json:"in_sample_bars"`
+	OutSampleBars int  `json:"out_sample_bars"`
+	Anchored      bool `json:"anchored"`
+}
+
+type WalkForwardFold struct {
+	Index          int                `json:"index"`
+	InSampleFrom   time.Time          `json:"in_sample_from"`
+	InSampleTo     time.Time          `json:"in_sample_to"`
+	OutSampleFrom  time.Time          `json:"out_sample_from"`
+	OutSampleTo    time.Time          `json:"out_sample_to"`
+	Params         map[string]float64 `json:"params,omitempty"`
+	InSample       Stats              `json:"in_sample"`
+	OutOfSample    Stats              `json:"out_of_sample"`
+	InSampleScore  float64            `json:"in_sample_score"`
+	OutSampleScore float64            `json:"out_sample_score"`
+	Error          string             `json:"error,omitempty"`
+}
+
+type WalkForwardReport struct {
+	Objective          string            `json:"objective"`
+	Folds              []WalkForwardFold `json:"folds"`
+	OutOfSample        Stats             `json:"out_of_sample"`
+	MeanInSampleScore  float64           `json:"mean_in_sample_score"`
+	MeanOutSampleScore float64           `json:"mean_out_sample_score"`
+	// Efficiency is mean out-of-sample score over mean in-sample score; values
+	// well below 1 suggest the optimiser is fitting noise.
+	Efficiency float64 `json:"efficiency"`
+}
+
+// WalkForward rolls an in-sample window across the candles, sweeps parameters
+// on it, then trades the winning set on the following out-of-sample window.
+// With Anchored the in-sample window always starts at the first candle.
+func WalkForward(candles []strategy.Candle, btCfg Config, riskEngine *risk.Engine, sc SweepConfig, wf WalkForwardConfig) (WalkForwardReport, error) {
+	if wf.InSampleBars <= 0 || wf.OutSampleBars <= 0 {
+		return WalkForwardReport{}, errors.New("in-sample and out-of-sample bar counts must be positive")
+	}
+	if len(candles) < wf.InSampleBars+wf.OutSampleBars {
+		return WalkForwardReport{}, fmt.Errorf("need at least %d candles, got %d", wf.InSampleBars+wf.OutSampleBars, len(candles))
+	}
+	score, err := objective(sc.Objective)
+	if err != nil {
+		return WalkForwardReport{}, err
+	}
+	btCfg = btCfg.withDefaults()
+
+	report := WalkForwardReport{Objective: sc.Objective, Folds: make([]WalkForwardFold, 0, 8)}
+	if report.Objective == "" {
+		report.Objective = "return_dd"
+	}
+	oosTrades := make([]Trade, 0, 64)
+	sumIS, sumOOS, scored := 0.0, 0.0, 0
+
+	for oosStart := wf.InSampleBars; oosStart+wf.OutSampleBars <= len(candles); oosStart += wf.OutSampleBars {
+		isStart := oosStart - wf.InSampleBars
+		if wf.Anchored {
+			isStart = 0
+		}
+		oosEnd := oosStart + wf.OutSampleBars
+		fold := WalkForwardFold{
+			Index:         len(report.Folds),
+			InSampleFrom:  candles[isStart].Time,
+			InSampleTo:    candles[oosStart-1].Time,
+			OutSampleFrom: candles[oosStart].Time,
+			OutSampleTo:   candles[oosEnd-1].Time,
+		}
+
+		ranked, err := Sweep(candles[isStart:oosStart], btCfg, riskEngine, sc)
+		if err != nil {
+			return WalkForwardReport{}, err
+		}
+		if len(ranked) == 0 || !ranked[0].Eligible {
+			fold.Error = "no eligible parameter set in sample"
+			report.Folds = append(report.Folds, fold)
+			continue
+		}
+		best := ranked[0]
+		fold.Params = best.Params
+		fold.InSample = best.Stats
+		fold.InSampleScore = best.Score
+
+		engine := sc.Base
+		for name, v := range best.Params {
+			_ = engine.SetParam(name, v)
+		}
+		warmup := min(btCfg.Lookback, oosStart)
+		oosCfg := btCfg
+		oosCfg.WarmupBars = warmup
+		res, err := NewEngine(&engine, riskEngine, oosCfg).Run(candles[oosStart-warmup : oosEnd])
+		if err != nil {
+			fold.Error = err.Error()
+			report.Folds = append(report.Folds, fold)
+			continue
+		}
+		fold.OutOfSample = res.Stats
+		fold.OutSampleScore = score(res.Stats)
+		oosTrades = append(oosTrades, res.Trades...)
+		sumIS += fold.InSampleScore
+		sumOOS += fold.OutSampleScore
+		scored++
+		report.Folds = append(report.Folds, fold)
+	}
+
+	if scored > 0 {
+		report.MeanInSampleScore = sumIS / float64(scored)
+		report.MeanOutSampleScore = sumOOS / float64(scored)
+		if report.MeanInSampleScore != 0 {
+			report.Efficiency = report.MeanOutSampleScore / report.MeanInSampleScore
+		}
+	}
+	report.OutOfSample = ComputeStats(oosTrades, realisedCurve(oosTrades, btCfg.InitialEquity), btCfg.InitialEquity)
+	return report, nil
+}
+
+// realisedCurve builds an equity curve from closed-trade PnL, for stitched
+// trade lists that have no bar-level mark-to-market history.
+func realisedCurve(trades []Trade, initial float64) []EquityPoint {
+	out := make([]EquityPoint, 0, len(trades))
+	equity, peak := initial, initial
+	for _, t := range trades {
+		equity += t.PnL
+		if equity > peak {
+			peak = equity
+		}
+		dd := 0.0
+		if peak > 0 {
+			dd = (peak - equity) / peak * 100
+		}
+		out = append(out, EquityPoint{Time: t.ExitTime, Balance: equity, Equity: equity, DrawdownPct: dd})
+	}
+	return out
+}
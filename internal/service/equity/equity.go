@@ -0,0 +1,137 @@
+// Package equity turns /ea/sync snapshots into a per-account equity curve
+// kept at three resolutions: raw syncs, 1-minute and 1-hour rollups.
+package equity
+
+import (
+	"time"
+
+	"mmbot/internal/domain"
+	"mmbot/internal/service/risk"
+)
+
+const (
+	Raw    = "raw"
+	Minute = "1m"
+	Hour   = "1h"
+)
+
+// Rollups are the bucketed resolutions, finest first.
+var Rollups = []string{Minute, Hour}
+
+// BucketSize is the width of a rollup bucket; zero for raw points.
+func BucketSize(resolution string) time.Duration {
+	switch resolution {
+	case Minute:
+		return time.Minute
+	case Hour:
+		return time.Hour
+	}
+	return 0
+}
+
+func ValidResolution(resolution string) bool {
+	return resolution == Raw || BucketSize(resolution) > 0
+}
+
+// Policy controls sampling and retention. Raw points closer than
+// SampleInterval to the previous one are dropped; rollups still see every
+// sync. A zero retention keeps that resolution forever.
+type Policy struct {
+	SampleInterval  time.Duration
+	RawRetention    time.Duration
+	MinuteRetention time.Duration
+	HourRetention   time.Duration
+}
+
+func (p Policy) Retention(resolution string) time.Duration {
+	switch resolution {
+	case Raw:
+		return p.RawRetention
+	case Minute:
+		return p.MinuteRetention
+	case Hour:
+		return p.HourRetention
+	}
+	return 0
+}
+
+// Auto picks the finest resolution that still holds data back to from. A
+// zero from asks for the whole history, which only the hourly rollup keeps.
+func (p Policy) Auto(from, now time.Time) string {
+	if from.IsZero() {
+		return Hour
+	}
+	for _, res := range []string{Raw, Minute} {
+		if keep := p.Retention(res); keep == 0 || !from.Before(now.Add(-keep)) {
+			return res
+		}
+	}
+	return Hour
+}
+
+// Sample reads a raw point from a sync snapshot. Floating PnL is equity less
+// balance unless the snapshot reports it.
+func Sample(accountID string, snapshot map[string]interface{}, at time.Time) domain.EquityPoint {
+	metrics := risk.DeriveSnapshotMetrics(snapshot)
+	eq := risk.SnapshotEquity(snapshot)
+	balance := risk.SnapshotBalance(snapshot)
+	floating, ok := snapshot["floating_pnl"].(float64)
+	if !ok && balance > 0 {
+		floating = eq - balance
+	}
+	return domain.EquityPoint{
+		AccountID:     accountID,
+		Resolution:    Raw,
+		Time:          at.UTC(),
+		Equity:        eq,
+		Balance:       balance,
+		FloatingPnL:   floating,
+		EquityHigh:    eq,
+		EquityLow:     eq,
+		OpenPositions: metrics.OpenPositions,
+		Samples:       1,
+	}
+}
+
+// Bucket returns the empty rollup point that p falls into.
+func Bucket(p domain.EquityPoint, resolution string) domain.EquityPoint {
+	return domain.EquityPoint{
+		AccountID:  p.AccountID,
+		Resolution: resolution,
+		Time:       p.Time.UTC().Truncate(BucketSize(resolution)),
+	}
+}
+
+// Fold adds a raw point to its rollup bucket.
+func Fold(bucket, p domain.EquityPoint) domain.EquityPoint {
+	if bucket.Samples == 0 || p.Equity > bucket.EquityHigh {
+		bucket.EquityHigh = p.Equity
+	}
+	if bucket.Samples == 0 || p.Equity < bucket.EquityLow {
+		bucket.EquityLow = p.Equity
+	}
+	bucket.Equity = p.Equity
+	bucket.Balance = p.Balance
+	bucket.FloatingPnL = p.FloatingPnL
+	bucket.OpenPositions = p.OpenPositions
+	bucket.Samples++
+	return bucket
+}
+
+// Drawdown is the largest fall from a running equity peak to a later low
+// along the curve, absolute and as a percent of that peak.
+func Drawdown(points []domain.EquityPoint) (float64, float64) {
+	var peak, maxDD, maxPct float64
+	for _, p := range points {
+		high, low := p.EquityHigh, p.EquityLow
+		if high == 0 && low == 0 {
+			high, low = p.Equity, p.Equity
+		}
+		if dd := peak - low; peak > 0 && dd > maxDD {
+			maxDD = dd
+			maxPct = dd / peak * 100
+		}
+		peak = max(peak, high)
+	}
+	return maxDD, maxPct
+}
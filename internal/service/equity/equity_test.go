@@ -0,0 +1,64 @@
+package equity
+
+import (
+	"testing"
+	"time"
+
+	"mmbot/internal/domain"
+)
+
+func TestSampleAndFold(t *testing.T) {
+	at := time.Date(2026, 3, 2, 9, 15, 42, 0, time.UTC)
+	p := Sample("paper-1", map[string]interface{}{
+		"equity":    10050.0,
+		"balance":   10000.0,
+		"positions": []interface{}{map[string]interface{}{"symbol": "EURUSD"}},
+	}, at)
+	if p.Equity != 10050 || p.Balance != 10000 || p.FloatingPnL != 50 || p.OpenPositions != 1 || p.Resolution != Raw {
+		t.Fatalf("unexpected sample %+v", p)
+	}
+
+	bucket := Bucket(p, Hour)
+	if !bucket.Time.Equal(time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the 09:00 bucket, got %v", bucket.Time)
+	}
+	bucket = Fold(bucket, p)
+	low := p
+	low.Equity = 9900
+	bucket = Fold(bucket, low)
+	last := p
+	last.Equity = 9950
+	bucket = Fold(bucket, last)
+	if bucket.Equity != 9950 || bucket.EquityHigh != 10050 || bucket.EquityLow != 9900 || bucket.Samples != 3 {
+		t.Fatalf("unexpected rollup %+v", bucket)
+	}
+}
+
+func TestPolicyAuto(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	p := Policy{RawRetention: 24 * time.Hour, MinuteRetention: 7 * 24 * time.Hour}
+	cases := map[time.Time]string{
+		{}:                            Hour,
+		now.Add(-time.Hour):           Raw,
+		now.Add(-48 * time.Hour):      Minute,
+		now.Add(-30 * 24 * time.Hour): Hour,
+	}
+	for from, want := range cases {
+		if got := p.Auto(from, now); got != want {
+			t.Fatalf("from %v: expected %s, got %s", from, want, got)
+		}
+	}
+}
+
+func TestDrawdown(t *testing.T) {
+	points := []domain.EquityPoint{
+		{Equity: 1000, EquityHigh: 1000, EquityLow: 1000},
+		{Equity: 1150, EquityHigh: 1200, EquityLow: 1100},
+		{Equity: 1000, EquityHigh: 1150, EquityLow: 900},
+		{Equity: 1300},
+	}
+	dd, pct := Drawdown(points)
+	if dd != 300 || pct != 25 {
+		t.Fatalf("expected 300 (25%%) from the 1200 peak, got %v (%v%%)", dd, pct)
+	}
+}
@@ -0,0 +1,156 @@
+// Package export encodes commands, command results, events and journal
+// trades as CSV rows or NDJSON lines for download.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"mmbot/internal/domain"
+)
+
+const (
+	CSV    = "csv"
+	NDJSON = "ndjson"
+)
+
+// Datasets lists the exportable record kinds.
+var Datasets = []string{"commands", "results", "events", "trades"}
+
+// Writer encodes one dataset. CSV output starts with the dataset's header
+// row; NDJSON output is one JSON object per line.
+type Writer struct {
+	format  string
+	header  []string
+	csv     *csv.Writer
+	json    *json.Encoder
+	started bool
+}
+
+// NewWriter returns a writer for format and dataset.
+func NewWriter(w io.Writer, format, dataset string) (*Writer, error) {
+	header, ok := headers[dataset]
+	if !ok {
+		return nil, fmt.Errorf("unknown dataset %q", dataset)
+	}
+	out := &Writer{format: format, header: header}
+	switch format {
+	case CSV:
+		out.csv = csv.NewWriter(w)
+	case NDJSON:
+		out.json = json.NewEncoder(w)
+	default:
+		return nil, fmt.Errorf("format must be %s or %s", CSV, NDJSON)
+	}
+	return out, nil
+}
+
+// ContentType is the MIME type of the writer's output.
+func (w *Writer) ContentType() string {
+	if w.format == CSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/x-ndjson"
+}
+
+// Header writes the CSV header row; NDJSON has none. Write calls it on
+// first use, so an empty export still carries the header.
+func (w *Writer) Header() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	if w.csv != nil {
+		return w.csv.Write(w.header)
+	}
+	return nil
+}
+
+// Command encodes a command.
+func (w *Writer) Command(c domain.Command) error {
+	return w.write(c, []string{
+		c.ID, c.AccountID, c.DeviceID, string(c.Type), c.Symbol, c.Side, num(c.Volume), num(c.SL), num(c.TP),
+		c.Reason, c.Strategy, string(c.Status), stamp(c.CreatedAt), stamp(c.ExpiresAt),
+		num(c.RequestedPrice), num(c.FillPrice), num(c.SlippagePips), strconv.FormatInt(c.LatencyMS, 10), stamp(c.FilledAt),
+	})
+}
+
+// Result encodes a command result.
+func (w *Writer) Result(r domain.CommandResult) error {
+	return w.write(r, []string{
+		r.CommandID, r.AccountID, r.Status, r.BrokerTicket, r.ErrorCode, r.ErrorMessage, r.ExecutedAt,
+		num(r.RequestedPrice), num(r.FillPrice), num(r.SlippagePips), strconv.FormatInt(r.LatencyMS, 10), stamp(r.ReceivedAt),
+	})
+}
+
+// Event encodes an event; its payload becomes a JSON column in CSV.
+func (w *Writer) Event(e domain.Event) error {
+	payload, err := json.Marshal(e.Payload)
+	if err != nil {
+		return err
+	}
+	return w.write(e, []string{e.ID, e.AccountID, string(e.Type), stamp(e.CreatedAt), string(payload)})
+}
+
+// Trade encodes a journal trade; CSV leaves out its modifications.
+func (w *Writer) Trade(t domain.Trade) error {
+	return w.write(t, []string{
+		t.ID, t.AccountID, t.Symbol, t.Side, num(t.Volume), string(t.Status), t.Ticket, t.Strategy,
+		num(t.EntryPrice), num(t.ExitPrice), num(t.SL), num(t.TP), num(t.Profit), num(t.RMultiple),
+		strconv.FormatInt(t.DurationSeconds, 10), stamp(t.OpenedAt), stamp(t.ClosedAt), t.OpenCommandID, t.CloseCommandID, t.Reason,
+		t.CloseReason,
+	})
+}
+
+// Flush writes buffered CSV rows and reports any write error.
+func (w *Writer) Flush() error {
+	if w.csv != nil {
+		w.csv.Flush()
+		return w.csv.Error()
+	}
+	return nil
+}
+
+func (w *Writer) write(record interface{}, row []string) error {
+	if err := w.Header(); err != nil {
+		return err
+	}
+	if w.csv != nil {
+		return w.csv.Write(row)
+	}
+	return w.json.Encode(record)
+}
+
+var headers = map[string][]string{
+	"commands": {
+		"command_id", "account_id", "device_id", "type", "symbol", "side", "volume", "sl", "tp",
+		"reason", "strategy", "status", "created_at", "expires_at",
+		"requested_price", "fill_price", "slippage_pips", "latency_ms", "filled_at",
+	},
+	"results": {
+		"command_id", "account_id", "status", "broker_ticket", "error_code", "error_message", "executed_at",
+		"requested_price", "fill_price", "slippage_pips", "latency_ms", "received_at",
+	},
+	"events": {"event_id", "account_id", "event_type", "created_at", "payload"},
+	"trades": {
+		"id", "account_id", "symbol", "side", "volume", "status", "ticket", "strategy",
+		"entry_price", "exit_price", "sl", "tp", "profit", "r_multiple",
+		"duration_seconds", "opened_at", "closed_at", "open_command_id", "close_command_id", "reason",
+		"close_reason",
+	},
+}
+
+func num(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func stamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
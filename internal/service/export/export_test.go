@@ -0,0 +1,82 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"mmbot/internal/domain"
+)
+
+func TestWriter_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, CSV, "events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC)
+	if err := w.Event(domain.Event{ID: "e1", AccountID: "paper-1", Type: domain.EventRiskTriggered, CreatedAt: at, Payload: map[string]interface{}{"reason": "a,b"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || strings.Join(rows[0], ",") != "event_id,account_id,event_type,created_at,payload" {
+		t.Fatalf("unexpected rows %v", rows)
+	}
+	if rows[1][3] != "2026-03-02T09:30:00Z" || rows[1][4] != `{"reason":"a,b"}` {
+		t.Fatalf("unexpected event row %v", rows[1])
+	}
+	if w.ContentType() != "text/csv; charset=utf-8" {
+		t.Fatalf("unexpected content type %s", w.ContentType())
+	}
+}
+
+func TestWriter_NDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, NDJSON, "trades")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"t1", "t2"} {
+		if err := w.Trade(domain.Trade{ID: id, Symbol: "EURUSD", Profit: 12.5}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one line per trade, got %q", buf.String())
+	}
+	var trade domain.Trade
+	if err := json.Unmarshal([]byte(lines[1]), &trade); err != nil || trade.ID != "t2" || trade.Profit != 12.5 {
+		t.Fatalf("unexpected line %q (%v)", lines[1], err)
+	}
+}
+
+func TestWriter_EmptyCSVKeepsHeader(t *testing.T) {
+	var buf bytes.Buffer
+	w, _ := NewWriter(&buf, CSV, "results")
+	if err := w.Header(); err != nil {
+		t.Fatal(err)
+	}
+	_ = w.Flush()
+	if !strings.HasPrefix(buf.String(), "command_id,account_id,status,broker_ticket") {
+		t.Fatalf("expected the header row, got %q", buf.String())
+	}
+}
+
+func TestNewWriter_Rejects(t *testing.T) {
+	if _, err := NewWriter(&bytes.Buffer{}, "xml", "trades"); err == nil {
+		t.Fatal("expected an unknown format to be rejected")
+	}
+	if _, err := NewWriter(&bytes.Buffer{}, CSV, "positions"); err == nil {
+		t.Fatal("expected an unknown dataset to be rejected")
+	}
+}
@@ -0,0 +1,185 @@
+// Package fx converts amounts between account currencies. Rates come from
+// quotes the EA reports and from a configured table; a fresh EA quote wins
+// over the table, and pairs without a rate are crossed through a shared
+// currency.
+package fx
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"mmbot/internal/domain"
+)
+
+const (
+	SourceEA    = "ea"
+	SourceTable = "table"
+)
+
+// ErrNoRate is returned when no direct, inverse or crossed rate links two
+// currencies.
+var ErrNoRate = errors.New("no fx rate")
+
+// Rate prices one unit of Base in Quote.
+type Rate struct {
+	Base   string    `json:"base"`
+	Quote  string    `json:"quote"`
+	Price  float64   `json:"price"`
+	Source string    `json:"source"`
+	At     time.Time `json:"at,omitempty"`
+}
+
+// Converter holds the configured table and the latest EA quotes. EA quotes
+// older than maxAge are ignored; zero keeps them forever.
+type Converter struct {
+	mu     sync.RWMutex
+	table  map[string]Rate
+	quotes map[string]Rate
+	maxAge time.Duration
+}
+
+// NewConverter returns a converter over the configured table.
+func NewConverter(table []Rate, maxAge time.Duration) *Converter {
+	c := &Converter{table: make(map[string]Rate), quotes: make(map[string]Rate), maxAge: maxAge}
+	for _, r := range table {
+		r.Source = SourceTable
+		c.table[r.Base+r.Quote] = r
+	}
+	return c
+}
+
+// Observe records an EA quote for base/quote at price. Non-positive prices
+// and malformed codes are ignored.
+func (c *Converter) Observe(base, quote string, price float64, at time.Time) {
+	base, quote = Normalize(base), Normalize(quote)
+	if price <= 0 || !ValidCode(base) || !ValidCode(quote) || base == quote {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.quotes[base+quote] = Rate{Base: base, Quote: quote, Price: price, Source: SourceEA, At: at.UTC()}
+}
+
+// Rates lists the rates usable at now, sorted by pair.
+func (c *Converter) Rates(now time.Time) []Rate {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	usable := make(map[string]Rate, len(c.table)+len(c.quotes))
+	for k, r := range c.table {
+		usable[k] = r
+	}
+	for k, r := range c.quotes {
+		if c.maxAge > 0 && now.Sub(r.At) > c.maxAge {
+			continue
+		}
+		usable[k] = r
+	}
+	out := make([]Rate, 0, len(usable))
+	for _, r := range usable {
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Base+out[i].Quote < out[j].Base+out[j].Quote })
+	return out
+}
+
+// Rate returns how many units of to one unit of from is worth at now.
+func (c *Converter) Rate(from, to string, now time.Time) (float64, error) {
+	from, to = Normalize(from), Normalize(to)
+	if from == to {
+		return 1, nil
+	}
+	graph := make(map[string]map[string]float64)
+	link := func(a, b string, price float64) {
+		if graph[a] == nil {
+			graph[a] = make(map[string]float64)
+		}
+		graph[a][b] = price
+	}
+	for _, r := range c.Rates(now) {
+		link(r.Base, r.Quote, r.Price)
+		link(r.Quote, r.Base, 1/r.Price)
+	}
+	if price, ok := graph[from][to]; ok {
+		return price, nil
+	}
+	pivots := make([]string, 0, len(graph[from]))
+	for pivot := range graph[from] {
+		pivots = append(pivots, pivot)
+	}
+	sort.Strings(pivots)
+	for _, pivot := range pivots {
+		if second, ok := graph[pivot][to]; ok {
+			return graph[from][pivot] * second, nil
+		}
+	}
+	return 0, fmt.Errorf("%w for %s/%s", ErrNoRate, from, to)
+}
+
+// Convert turns amount in from into to at now.
+func (c *Converter) Convert(amount float64, from, to string, now time.Time) (float64, error) {
+	rate, err := c.Rate(from, to, now)
+	if err != nil {
+		return 0, err
+	}
+	return amount * rate, nil
+}
+
+// Normalize upper-cases and trims a currency code.
+func Normalize(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
+
+// ValidCode reports whether currency is a three-letter upper-case code.
+func ValidCode(currency string) bool {
+	if len(currency) != 3 {
+		return false
+	}
+	for _, ch := range currency {
+		if ch < 'A' || ch > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// SplitPair returns a symbol's base and profit currencies. The registered
+// spec wins when it names both; otherwise the first six letters of the name
+// are read as a pair, ignoring a broker suffix such as "EURUSD.m" or
+// "EURUSDm".
+func SplitPair(symbol string, spec *domain.SymbolSpec) (string, string, bool) {
+	if spec != nil && spec.BaseCurrency != "" && spec.ProfitCurrency != "" {
+		return Normalize(spec.BaseCurrency), Normalize(spec.ProfitCurrency), true
+	}
+	symbol = Normalize(symbol)
+	if len(symbol) < 6 || !ValidCode(symbol[:3]) || !ValidCode(symbol[3:6]) {
+		return "", "", false
+	}
+	return symbol[:3], symbol[3:6], true
+}
+
+// ParseRates reads a rate table such as "EURUSD=1.08,USDJPY=151.2".
+func ParseRates(raw string) ([]Rate, error) {
+	var out []Rate
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		pair, value, ok := strings.Cut(part, "=")
+		base, quote, pairOK := SplitPair(strings.TrimSpace(pair), nil)
+		if !ok || !pairOK || len(strings.TrimSpace(pair)) != 6 {
+			return nil, fmt.Errorf("fx rate %q must be PAIR=price, e.g. EURUSD=1.08", part)
+		}
+		price, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || price <= 0 {
+			return nil, fmt.Errorf("fx rate %q must have a positive price", part)
+		}
+		out = append(out, Rate{Base: base, Quote: quote, Price: price, Source: SourceTable})
+	}
+	return out, nil
+}
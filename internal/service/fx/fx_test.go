@@ -0,0 +1,80 @@
+package fx
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"mmbot/internal/domain"
+)
+
+func TestConverterRate(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	table, err := ParseRates("EURUSD=1.10, USDJPY=150")
+	if err != nil {
+		t.Fatalf("parse rates: %v", err)
+	}
+	c := NewConverter(table, time.Hour)
+
+	cases := []struct {
+		from, to string
+		want     float64
+	}{
+		{"usd", "USD", 1},
+		{"EUR", "USD", 1.10},
+		{"USD", "EUR", 1 / 1.10},
+		{"EUR", "JPY", 165},
+		{"JPY", "EUR", 1 / 165.0},
+	}
+	for _, tc := range cases {
+		got, err := c.Rate(tc.from, tc.to, now)
+		if err != nil || math.Abs(got-tc.want) > 1e-9 {
+			t.Fatalf("Rate(%s, %s) = %v, %v; want %v", tc.from, tc.to, got, err, tc.want)
+		}
+	}
+	if _, err := c.Rate("GBP", "USD", now); !errors.Is(err, ErrNoRate) {
+		t.Fatalf("expected no GBP rate, got %v", err)
+	}
+
+	c.Observe("eur", "usd", 1.20, now.Add(-time.Minute))
+	if got, _ := c.Convert(100, "EUR", "USD", now); math.Abs(got-120) > 1e-9 {
+		t.Fatalf("expected a fresh EA quote to win over the table, got %v", got)
+	}
+	if got, _ := c.Convert(100, "EUR", "USD", now.Add(2*time.Hour)); math.Abs(got-110) > 1e-9 {
+		t.Fatalf("expected a stale EA quote to fall back to the table, got %v", got)
+	}
+}
+
+func TestSplitPair(t *testing.T) {
+	gold := &domain.SymbolSpec{Symbol: "GOLD", BaseCurrency: "xau", ProfitCurrency: "usd"}
+	cases := []struct {
+		symbol      string
+		spec        *domain.SymbolSpec
+		base, quote string
+		ok          bool
+	}{
+		{"EURUSD", nil, "EUR", "USD", true},
+		{"gbpjpy.m", nil, "GBP", "JPY", true},
+		{"EURUSDm", nil, "EUR", "USD", true},
+		{"XAUUSD", nil, "XAU", "USD", true},
+		{"GOLD", gold, "XAU", "USD", true},
+		{"EURUSD", &domain.SymbolSpec{ProfitCurrency: "USD"}, "EUR", "USD", true},
+		{"US30", nil, "", "", false},
+		{"EUR.USD", nil, "", "", false},
+	}
+	for _, tc := range cases {
+		base, quote, ok := SplitPair(tc.symbol, tc.spec)
+		if base != tc.base || quote != tc.quote || ok != tc.ok {
+			t.Fatalf("SplitPair(%q) = %q, %q, %v", tc.symbol, base, quote, ok)
+		}
+	}
+}
+
+func TestParseRatesRejectsBadEntries(t *testing.T) {
+	for _, raw := range []string{"EURUSD", "EURUSD=abc", "EURUSD=-1", "EUR=1.1"} {
+		if _, err := ParseRates(raw); err == nil {
+			t.Fatalf("expected %q to be rejected", raw)
+		}
+	}
+}
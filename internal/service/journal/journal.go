@@ -0,0 +1,155 @@
+// Package journal builds trade records from the command and sync stream:
+// an executed OPEN starts a trade, SL/TP results and snapshot changes are
+// recorded as modifications, and a ticket missing from a sync closes it.
+package journal
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"mmbot/internal/domain"
+)
+
+// Open starts a trade for a successful OPEN command. The entry price is the
+// reported fill price when known and is otherwise taken from the first sync
+// that lists the ticket. An empty or "0" ticket leaves the trade without one
+// until Adopt matches it to a synced position.
+func Open(cmd domain.Command, ticket string, at time.Time) domain.Trade {
+	ticket = strings.TrimSpace(ticket)
+	if ticket == "0" {
+		ticket = ""
+	}
+	at = at.UTC()
+	return domain.Trade{
+		AccountID:      cmd.AccountID,
+		Symbol:         strings.ToUpper(strings.TrimSpace(cmd.Symbol)),
+		Side:           strings.ToUpper(strings.TrimSpace(cmd.Side)),
+		Volume:         cmd.Volume,
+		Status:         domain.TradeStatusOpen,
+		Ticket:         ticket,
+		OpenCommandID:  cmd.ID,
+		Strategy:       cmd.Strategy,
+		Reason:         cmd.Reason,
+		EntryPrice:     cmd.FillPrice,
+		StopLossPips:   cmd.SL,
+		TakeProfitPips: cmd.TP,
+		Modifications:  []domain.TradeModification{},
+		OpenedAt:       at,
+		UpdatedAt:      at,
+	}
+}
+
+// Adopt finds the oldest open trade without a ticket on the position's
+// symbol and side and gives it the position's ticket. It is the fallback for
+// OPEN results that reported no ticket.
+func Adopt(open []domain.Trade, p domain.Position) (domain.Trade, bool) {
+	var match domain.Trade
+	found := false
+	for _, t := range open {
+		if t.Ticket != "" || t.Status != domain.TradeStatusOpen ||
+			!strings.EqualFold(t.Symbol, strings.TrimSpace(p.Symbol)) || !strings.EqualFold(t.Side, strings.TrimSpace(p.Side)) {
+			continue
+		}
+		if !found || t.OpenedAt.Before(match.OpenedAt) {
+			match, found = t, true
+		}
+	}
+	if !found || p.Ticket == "" {
+		return domain.Trade{}, false
+	}
+	match.Ticket = p.Ticket
+	return match, true
+}
+
+// Modify records an SL or TP change made by a command. field is "sl" or
+// "tp"; an unchanged value records nothing.
+func Modify(t domain.Trade, field string, value float64, commandID string, at time.Time) domain.Trade {
+	return modify(t, field, value, "command", commandID, at)
+}
+
+// ApplySnapshot folds a synced position into an open trade: it fills a
+// missing entry price and volume, and records SL/TP changes the journal has
+// not seen yet. A level the journal has no value for yet is taken as placed
+// at open, not as a modification. It reports whether the trade changed.
+func ApplySnapshot(t domain.Trade, p domain.Position, at time.Time) (domain.Trade, bool) {
+	changed := false
+	if t.EntryPrice <= 0 && p.PriceOpen > 0 {
+		t.EntryPrice = p.PriceOpen
+		changed = true
+	}
+	if p.Volume > 0 && p.Volume != t.Volume {
+		t.Volume = p.Volume
+		changed = true
+	}
+	sl, tp := t.SL, t.TP
+	t = modify(t, "sl", p.SL, "sync", "", at)
+	t = modify(t, "tp", p.TP, "sync", "", at)
+	if changed || t.SL != sl || t.TP != tp {
+		t.UpdatedAt = at.UTC()
+		return t, true
+	}
+	return t, false
+}
+
+// Close ends a trade with the last synced state of its position. The exit
+// price is the position's last current price and the profit includes swap
+// and commission.
+func Close(t domain.Trade, last domain.Position, pipSize float64, at time.Time) domain.Trade {
+	at = at.UTC()
+	t.Status = domain.TradeStatusClosed
+	if last.PriceCurrent > 0 {
+		t.ExitPrice = last.PriceCurrent
+	}
+	if t.EntryPrice <= 0 && last.PriceOpen > 0 {
+		t.EntryPrice = last.PriceOpen
+	}
+	t.Profit = last.Profit + last.Swap + last.Commission
+	t.ClosedAt = at
+	t.DurationSeconds = int64(at.Sub(t.OpenedAt).Seconds())
+	t.RMultiple = RMultiple(t, pipSize)
+	t.UpdatedAt = at
+	return t
+}
+
+// RMultiple is the price move from entry to exit in units of the initial
+// stop distance, positive for a winning trade. It is zero when the prices or
+// the initial stop are unknown.
+func RMultiple(t domain.Trade, pipSize float64) float64 {
+	risk := t.StopLossPips * pipSize
+	if risk <= 0 || t.EntryPrice <= 0 || t.ExitPrice <= 0 {
+		return 0
+	}
+	move := t.ExitPrice - t.EntryPrice
+	if t.Side == "SELL" {
+		move = -move
+	}
+	return math.Round(move/risk*100) / 100
+}
+
+func modify(t domain.Trade, field string, value float64, source, commandID string, at time.Time) domain.Trade {
+	current := &t.SL
+	if field == "tp" {
+		current = &t.TP
+	}
+	if value == *current {
+		return t
+	}
+	at = at.UTC()
+	if source == "sync" && *current == 0 {
+		*current = value
+		t.UpdatedAt = at
+		return t
+	}
+	t.Modifications = append(t.Modifications, domain.TradeModification{
+		Field:     field,
+		Before:    *current,
+		After:     value,
+		Source:    source,
+		CommandID: commandID,
+		At:        at,
+	})
+	*current = value
+	t.UpdatedAt = at
+	return t
+}
@@ -0,0 +1,90 @@
+package journal
+
+import (
+	"testing"
+	"time"
+
+	"mmbot/internal/domain"
+)
+
+func TestJournal_Lifecycle(t *testing.T) {
+	at := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	cmd := domain.Command{
+		ID:        "cmd-1",
+		AccountID: "paper-1",
+		Type:      domain.CommandOpen,
+		Symbol:    "eurusd",
+		Side:      "sell",
+		Volume:    0.5,
+		SL:        20,
+		TP:        40,
+		Reason:    "ema cross",
+		Strategy:  "trend",
+	}
+	trade := Open(cmd, " 9001 ", at)
+	if trade.Symbol != "EURUSD" || trade.Side != "SELL" || trade.Ticket != "9001" || trade.Status != domain.TradeStatusOpen || trade.Strategy != "trend" {
+		t.Fatalf("unexpected open trade %+v", trade)
+	}
+
+	// The first sync places the stops without recording modifications.
+	trade, changed := ApplySnapshot(trade, domain.Position{Ticket: "9001", Volume: 0.5, PriceOpen: 1.10000, SL: 1.10200, TP: 1.09600}, at.Add(time.Minute))
+	if !changed || trade.EntryPrice != 1.10000 || trade.SL != 1.10200 || len(trade.Modifications) != 0 {
+		t.Fatalf("expected entry and stops from the first sync, got %+v", trade)
+	}
+	if _, changed := ApplySnapshot(trade, domain.Position{Ticket: "9001", Volume: 0.5, PriceOpen: 1.10000, SL: 1.10200, TP: 1.09600}, at.Add(2*time.Minute)); changed {
+		t.Fatal("expected an identical sync to change nothing")
+	}
+
+	trade = Modify(trade, "sl", 1.10100, "cmd-2", at.Add(3*time.Minute))
+	trade, _ = ApplySnapshot(trade, domain.Position{Ticket: "9001", Volume: 0.5, SL: 1.10100, TP: 1.09500}, at.Add(4*time.Minute))
+	if len(trade.Modifications) != 2 {
+		t.Fatalf("expected a command and a sync modification, got %+v", trade.Modifications)
+	}
+	if m := trade.Modifications[0]; m.Field != "sl" || m.Before != 1.10200 || m.After != 1.10100 || m.Source != "command" || m.CommandID != "cmd-2" {
+		t.Fatalf("unexpected SL modification %+v", m)
+	}
+	if m := trade.Modifications[1]; m.Field != "tp" || m.After != 1.09500 || m.Source != "sync" {
+		t.Fatalf("unexpected TP modification %+v", m)
+	}
+
+	trade = Close(trade, domain.Position{Ticket: "9001", PriceCurrent: 1.09700, Profit: 150, Swap: -2, Commission: -3}, 0.0001, at.Add(90*time.Minute))
+	if trade.Status != domain.TradeStatusClosed || trade.ExitPrice != 1.09700 || trade.Profit != 145 {
+		t.Fatalf("unexpected closed trade %+v", trade)
+	}
+	if trade.DurationSeconds != 5400 || trade.RMultiple != 1.5 {
+		t.Fatalf("expected 90 minutes and 1.5R, got %ds and %vR", trade.DurationSeconds, trade.RMultiple)
+	}
+}
+
+func TestRMultiple_ZeroWithoutStop(t *testing.T) {
+	trade := domain.Trade{Side: "BUY", EntryPrice: 1.1, ExitPrice: 1.2}
+	if r := RMultiple(trade, 0.0001); r != 0 {
+		t.Fatalf("expected 0R without an initial stop, got %v", r)
+	}
+	trade.StopLossPips = 10
+	if r := RMultiple(trade, 0.0001); r != 100 {
+		t.Fatalf("expected 100R, got %v", r)
+	}
+}
+
+func TestAdopt_MatchesOldestUnticketedTrade(t *testing.T) {
+	at := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	cmd := domain.Command{AccountID: "paper-1", Type: domain.CommandOpen, Symbol: "EURUSD", Side: "BUY", Volume: 0.1}
+	first := Open(cmd, "0", at)
+	if first.Ticket != "" {
+		t.Fatalf("expected a zero ticket to be dropped, got %q", first.Ticket)
+	}
+	first.ID = "t-1"
+	second := Open(cmd, "", at.Add(time.Minute))
+	second.ID = "t-2"
+	ticketed := Open(cmd, "77", at.Add(-time.Minute))
+	open := []domain.Trade{second, ticketed, first}
+
+	if _, ok := Adopt(open, domain.Position{Ticket: "9001", Symbol: "EURUSD", Side: "SELL"}); ok {
+		t.Fatal("expected the side to be matched")
+	}
+	trade, ok := Adopt(open, domain.Position{Ticket: "9001", Symbol: "eurusd", Side: "buy"})
+	if !ok || trade.ID != "t-1" || trade.Ticket != "9001" {
+		t.Fatalf("expected the oldest unticketed trade to take the ticket, got %+v", trade)
+	}
+}
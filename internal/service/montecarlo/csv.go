@@ -0,0 +1,68 @@
+package montecarlo
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReadOutcomesCSV reads a trade list with an "r_multiple" column and an
+// optional "exit_time" (or "time") column, such as the backtester's
+// trades.csv.
+func ReadOutcomesCSV(r io.Reader) ([]Outcome, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+	rCol, timeCol := -1, -1
+	for i, h := range header {
+		switch strings.ToLower(strings.TrimSpace(h)) {
+		case "r_multiple", "r":
+			rCol = i
+		case "exit_time", "closed_at", "time":
+			if timeCol < 0 {
+				timeCol = i
+			}
+		}
+	}
+	if rCol < 0 {
+		return nil, errors.New(`csv header missing "r_multiple" column`)
+	}
+
+	out := make([]Outcome, 0, 256)
+	line := 1
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if rCol >= len(rec) {
+			return nil, fmt.Errorf("line %d: missing r_multiple", line)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(rec[rCol]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid r_multiple: %w", line, err)
+		}
+		o := Outcome{R: v}
+		if timeCol >= 0 && timeCol < len(rec) && strings.TrimSpace(rec[timeCol]) != "" {
+			ts, err := time.Parse(time.RFC3339, strings.TrimSpace(rec[timeCol]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid time: %w", line, err)
+			}
+			o.Time = ts.UTC()
+		}
+		out = append(out, o)
+	}
+	return out, nil
+}
@@ -0,0 +1,305 @@
+package montecarlo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"time"
+
+	"mmbot/internal/service/backtest"
+)
+
+const (
+	MethodShuffle   = "shuffle"
+	MethodBootstrap = "bootstrap"
+)
+
+// Outcome is one closed trade expressed in R (PnL over the cash risked at
+// entry), so the sequence can be replayed at any risk-per-trade level.
+type Outcome struct {
+	Time time.Time `json:"time"`
+	R    float64   `json:"r"`
+}
+
+type Config struct {
+	// RiskLevels are percent of equity risked per trade, e.g. 0.5, 1, 2.
+	RiskLevels []float64 `json:"risk_levels"`
+	Iterations int       `json:"iterations"`
+	// Method is shuffle (reorder the same trades) or bootstrap (draw trades
+	// with replacement, so runs can see more or fewer losers than history).
+	Method string `json:"method"`
+	Seed   int64  `json:"seed"`
+	// Horizon is the number of trades per simulated run (default: as many
+	// as the input).
+	Horizon int `json:"horizon"`
+	// TradesPerDay fixes how trades are grouped into days; zero replays the
+	// per-day trade counts observed in the outcome timestamps.
+	TradesPerDay int `json:"trades_per_day"`
+	// DailyLossLimitPct stops a simulated day once its loss reaches the
+	// limit, mirroring MAX_DAILY_LOSS_PCT in the live risk engine. Zero
+	// disables the halt.
+	DailyLossLimitPct float64 `json:"daily_loss_limit_pct"`
+	// RuinDrawdownPct is the peak-to-trough drawdown counted as ruin.
+	RuinDrawdownPct float64 `json:"ruin_drawdown_pct"`
+	// MaxRuinProbability is the tolerance used for the recommendation.
+	MaxRuinProbability float64 `json:"max_ruin_probability"`
+}
+
+type Percentiles struct {
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P95 float64 `json:"p95"`
+	P99 float64 `json:"p99"`
+	Max float64 `json:"max"`
+}
+
+type LevelResult struct {
+	RiskPct         float64     `json:"risk_pct"`
+	MaxDrawdownPct  Percentiles `json:"max_drawdown_pct"`
+	WorstDayLossPct Percentiles `json:"worst_day_loss_pct"`
+	// ReturnPct percentiles are of the losing tail: P90 is the return
+	// beaten by 90% of runs.
+	ReturnPct       Percentiles `json:"return_pct"`
+	RuinProbability float64     `json:"ruin_probability"`
+	// DailyLimitHitProbability is the share of runs with at least one day
+	// halted by the daily loss limit; DailyLimitDayRate is the share of all
+	// simulated days halted.
+	DailyLimitHitProbability float64 `json:"daily_limit_hit_probability"`
+	DailyLimitDayRate        float64 `json:"daily_limit_day_rate"`
+}
+
+type Recommendation struct {
+	RiskPct      float64 `json:"risk_pct"`
+	DailyLossPct float64 `json:"daily_loss_pct"`
+	Reason       string  `json:"reason"`
+}
+
+type Report struct {
+	Config         Config          `json:"config"`
+	Trades         int             `json:"trades"`
+	Days           int             `json:"days"`
+	ExpectancyR    float64         `json:"expectancy_r"`
+	Levels         []LevelResult   `json:"levels"`
+	Recommendation *Recommendation `json:"recommendation,omitempty"`
+}
+
+func (cfg Config) withDefaults() Config {
+	if len(cfg.RiskLevels) == 0 {
+		cfg.RiskLevels = []float64{0.25, 0.5, 1, 1.5, 2}
+	}
+	if cfg.Iterations <= 0 {
+		cfg.Iterations = 5000
+	}
+	if cfg.Method == "" {
+		cfg.Method = MethodShuffle
+	}
+	if cfg.RuinDrawdownPct <= 0 {
+		cfg.RuinDrawdownPct = 30
+	}
+	if cfg.MaxRuinProbability <= 0 {
+		cfg.MaxRuinProbability = 0.01
+	}
+	return cfg
+}
+
+// FromTrades converts backtest trades into outcomes keyed by exit time.
+func FromTrades(trades []backtest.Trade) []Outcome {
+	out := make([]Outcome, 0, len(trades))
+	for _, t := range trades {
+		out = append(out, Outcome{Time: t.ExitTime, R: t.RMultiple})
+	}
+	return out
+}
+
+// Run replays the outcomes Iterations times at every risk level with
+// compounded, fixed-fractional sizing and reports the drawdown, worst-day and
+// ruin distributions.
+func Run(outcomes []Outcome, cfg Config) (Report, error) {
+	cfg = cfg.withDefaults()
+	if len(outcomes) == 0 {
+		return Report{}, errors.New("no trades to simulate")
+	}
+	if cfg.Method != MethodShuffle && cfg.Method != MethodBootstrap {
+		return Report{}, fmt.Errorf("unknown method %q (want shuffle or bootstrap)", cfg.Method)
+	}
+	for _, lvl := range cfg.RiskLevels {
+		if lvl <= 0 || lvl >= 100 {
+			return Report{}, fmt.Errorf("risk level %.2f%% out of range", lvl)
+		}
+	}
+	horizon := cfg.Horizon
+	if horizon <= 0 {
+		horizon = len(outcomes)
+	}
+	if cfg.Method == MethodShuffle && horizon > len(outcomes) {
+		return Report{}, fmt.Errorf("shuffle cannot exceed %d trades; use bootstrap for a longer horizon", len(outcomes))
+	}
+
+	daySizes := observedDaySizes(outcomes)
+	days := len(daySizes)
+	if cfg.TradesPerDay > 0 {
+		daySizes = []int{cfg.TradesPerDay}
+	}
+	rs := make([]float64, len(outcomes))
+	sumR := 0.0
+	for i, o := range outcomes {
+		rs[i] = o.R
+		sumR += o.R
+	}
+
+	report := Report{
+		Config:      cfg,
+		Trades:      len(outcomes),
+		Days:        days,
+		ExpectancyR: sumR / float64(len(outcomes)),
+		Levels:      make([]LevelResult, 0, len(cfg.RiskLevels)),
+	}
+	for i, lvl := range cfg.RiskLevels {
+		// Each level gets its own stream so adding a level leaves the others'
+		// results unchanged for a given seed.
+		rng := rand.New(rand.NewSource(cfg.Seed + int64(i)))
+		report.Levels = append(report.Levels, simulateLevel(rs, daySizes, horizon, lvl, cfg, rng))
+	}
+	report.Recommendation = recommend(report.Levels, cfg)
+	return report, nil
+}
+
+func simulateLevel(rs []float64, daySizes []int, horizon int, riskPct float64, cfg Config, rng *rand.Rand) LevelResult {
+	maxDD := make([]float64, cfg.Iterations)
+	worstDay := make([]float64, cfg.Iterations)
+	returns := make([]float64, cfg.Iterations)
+	ruined, limitRuns, limitDays, totalDays := 0, 0, 0, 0
+	seq := make([]float64, horizon)
+	perm := slices.Clone(rs)
+
+	for it := 0; it < cfg.Iterations; it++ {
+		if cfg.Method == MethodShuffle {
+			rng.Shuffle(len(perm), func(a, b int) { perm[a], perm[b] = perm[b], perm[a] })
+			copy(seq, perm[:horizon])
+		} else {
+			for k := range seq {
+				seq[k] = rs[rng.Intn(len(rs))]
+			}
+		}
+
+		equity, peak := 100.0, 100.0
+		runDD, runWorstDay := 0.0, 0.0
+		hitLimit := false
+		for pos := 0; pos < horizon && equity > 0; {
+			size := daySizes[rng.Intn(len(daySizes))]
+			dayStart, dayLow := equity, equity
+			halted := false
+			for n := 0; n < size && pos < horizon; n++ {
+				r := seq[pos]
+				pos++
+				if halted {
+					continue
+				}
+				equity += equity * riskPct / 100 * r
+				if equity < 0 {
+					equity = 0
+				}
+				dayLow = math.Min(dayLow, equity)
+				peak = math.Max(peak, equity)
+				runDD = math.Max(runDD, (peak-equity)/peak*100)
+				if cfg.DailyLossLimitPct > 0 && (dayStart-equity)/dayStart*100 >= cfg.DailyLossLimitPct {
+					halted = true
+				}
+			}
+			totalDays++
+			if halted {
+				limitDays++
+				hitLimit = true
+			}
+			runWorstDay = math.Max(runWorstDay, (dayStart-dayLow)/dayStart*100)
+		}
+
+		maxDD[it] = runDD
+		worstDay[it] = runWorstDay
+		returns[it] = equity - 100
+		if runDD >= cfg.RuinDrawdownPct || equity <= 0 {
+			ruined++
+		}
+		if hitLimit {
+			limitRuns++
+		}
+	}
+
+	res := LevelResult{
+		RiskPct:                  riskPct,
+		MaxDrawdownPct:           percentiles(maxDD, false),
+		WorstDayLossPct:          percentiles(worstDay, false),
+		ReturnPct:                percentiles(returns, true),
+		RuinProbability:          float64(ruined) / float64(cfg.Iterations),
+		DailyLimitHitProbability: float64(limitRuns) / float64(cfg.Iterations),
+	}
+	if totalDays > 0 {
+		res.DailyLimitDayRate = float64(limitDays) / float64(totalDays)
+	}
+	return res
+}
+
+// recommend picks the largest risk level within the ruin tolerance and sizes
+// the daily loss limit at that level's 95th percentile worst day, so the halt
+// only fires on days worse than nineteen runs in twenty ever see.
+func recommend(levels []LevelResult, cfg Config) *Recommendation {
+	var best *LevelResult
+	for i := range levels {
+		l := &levels[i]
+		if l.RuinProbability <= cfg.MaxRuinProbability && (best == nil || l.RiskPct > best.RiskPct) {
+			best = l
+		}
+	}
+	if best == nil {
+		return &Recommendation{Reason: fmt.Sprintf("no risk level keeps ruin probability (drawdown >= %.1f%%) within %.2f%%", cfg.RuinDrawdownPct, cfg.MaxRuinProbability*100)}
+	}
+	daily := math.Ceil(best.WorstDayLossPct.P95*10) / 10
+	return &Recommendation{
+		RiskPct:      best.RiskPct,
+		DailyLossPct: daily,
+		Reason: fmt.Sprintf("largest tested risk with ruin probability %.2f%% <= %.2f%% (drawdown >= %.1f%%); p95 max drawdown %.1f%%",
+			best.RuinProbability*100, cfg.MaxRuinProbability*100, cfg.RuinDrawdownPct, best.MaxDrawdownPct.P95),
+	}
+}
+
+// observedDaySizes counts trades per UTC day, falling back to one trade per
+// day when outcomes carry no timestamps.
+func observedDaySizes(outcomes []Outcome) []int {
+	counts := make(map[string]int)
+	order := make([]string, 0, 64)
+	for _, o := range outcomes {
+		if o.Time.IsZero() {
+			continue
+		}
+		key := o.Time.UTC().Format("2006-01-02")
+		if counts[key] == 0 {
+			order = append(order, key)
+		}
+		counts[key]++
+	}
+	if len(order) == 0 {
+		return []int{1}
+	}
+	out := make([]int, 0, len(order))
+	for _, k := range order {
+		out = append(out, counts[k])
+	}
+	return out
+}
+
+// percentiles reports nearest-rank percentiles; with lowTail the ranks are
+// taken from the bottom so P95 is the value only 5% of runs fall below.
+func percentiles(values []float64, lowTail bool) Percentiles {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	if lowTail {
+		slices.Reverse(sorted)
+	}
+	at := func(p float64) float64 {
+		idx := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+		return sorted[max(0, min(idx, len(sorted)-1))]
+	}
+	return Percentiles{P50: at(50), P90: at(90), P95: at(95), P99: at(99), Max: sorted[len(sorted)-1]}
+}
@@ -0,0 +1,92 @@
+package montecarlo
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func outcomes(rs ...float64) []Outcome {
+	out := make([]Outcome, 0, len(rs))
+	for i, r := range rs {
+		out = append(out, Outcome{Time: time.Date(2026, 3, 2+i/2, 10, 0, 0, 0, time.UTC), R: r})
+	}
+	return out
+}
+
+func TestRun_ShufflePreservesFinalReturn(t *testing.T) {
+	report, err := Run(outcomes(2, -1, -1, 2, -1, 2, -1, -1), Config{RiskLevels: []float64{1}, Iterations: 200, Seed: 7})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Days != 4 || report.Trades != 8 {
+		t.Fatalf("expected 8 trades over 4 days, got %d over %d", report.Trades, report.Days)
+	}
+	ret := report.Levels[0].ReturnPct
+	// Multiplication commutes, so every ordering ends on the same equity.
+	if math.Abs(ret.P50-ret.Max) > 1e-9 {
+		t.Fatalf("expected identical returns across shuffles, got %+v", ret)
+	}
+	if report.Levels[0].MaxDrawdownPct.Max < report.Levels[0].MaxDrawdownPct.P50 {
+		t.Fatalf("expected max drawdown percentiles to be ordered, got %+v", report.Levels[0].MaxDrawdownPct)
+	}
+}
+
+func TestRun_RuinProbabilityScalesWithRisk(t *testing.T) {
+	report, err := Run(outcomes(-1, -1, -1, -1, -1, -1, 1.5, -1, -1, -1), Config{
+		RiskLevels:      []float64{0.5, 5},
+		Iterations:      100,
+		RuinDrawdownPct: 20,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	low, high := report.Levels[0], report.Levels[1]
+	if low.RuinProbability != 0 || high.RuinProbability != 1 {
+		t.Fatalf("expected no ruin at 0.5%% and certain ruin at 5%%, got %.2f and %.2f", low.RuinProbability, high.RuinProbability)
+	}
+	if report.Recommendation == nil || report.Recommendation.RiskPct != 0.5 {
+		t.Fatalf("expected 0.5%% recommended, got %+v", report.Recommendation)
+	}
+}
+
+func TestRun_DailyLimitHaltsTheDay(t *testing.T) {
+	report, err := Run(outcomes(-1, -1, -1, -1), Config{
+		RiskLevels:        []float64{1},
+		Iterations:        10,
+		TradesPerDay:      4,
+		DailyLossLimitPct: 1.5,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lvl := report.Levels[0]
+	if lvl.DailyLimitHitProbability != 1 || lvl.DailyLimitDayRate != 1 {
+		t.Fatalf("expected the limit to fire every run, got %+v", lvl)
+	}
+	// Two 1% losses reach the limit; the remaining trades are skipped.
+	if lvl.WorstDayLossPct.Max > 2.0 {
+		t.Fatalf("expected the day to stop after two losses, got worst day %.3f%%", lvl.WorstDayLossPct.Max)
+	}
+}
+
+func TestRun_RejectsShuffleBeyondInput(t *testing.T) {
+	if _, err := Run(outcomes(1, -1), Config{Horizon: 5}); err == nil {
+		t.Fatal("expected shuffle horizon beyond input to be rejected")
+	}
+	if _, err := Run(outcomes(1, -1), Config{Horizon: 5, Method: MethodBootstrap, Iterations: 10}); err != nil {
+		t.Fatalf("expected bootstrap to allow a longer horizon, got %v", err)
+	}
+}
+
+func TestReadOutcomesCSV(t *testing.T) {
+	raw := "id,exit_time,pnl,r_multiple\n1,2026-03-02T10:00:00Z,20,2\n2,2026-03-02T12:00:00Z,-10.5,-1.05\n"
+	got, err := ReadOutcomesCSV(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got[1].R != -1.05 || got[0].Time.Hour() != 10 {
+		t.Fatalf("unexpected outcomes %+v", got)
+	}
+}
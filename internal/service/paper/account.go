@@ -0,0 +1,407 @@
+// Package paper simulates a broker account so the command loop can run
+// without an MT5 terminal. An Account executes queued commands the way the EA
+// does, fills them at the market bid or ask with a fixed spread and slippage,
+// closes positions whose SL or TP the market crosses, and reports sync
+// snapshots and deals in the EA's shapes.
+package paper
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"mmbot/internal/domain"
+	"mmbot/internal/service/reconcile"
+)
+
+// Deal reasons the simulator records, as MT5 names them.
+const (
+	ReasonExpert = "EXPERT"
+	ReasonSL     = "SL"
+	ReasonTP     = "TP"
+)
+
+// Config sets up a simulated account. Prices are bids; the ask is the bid
+// plus SpreadPips, and market fills and stops slip SlippagePips against the
+// trade.
+type Config struct {
+	Balance      float64
+	Currency     string
+	SpreadPips   float64
+	SlippagePips float64
+}
+
+// Contract values a symbol: the price size of a pip, the units in one lot
+// and the rate from its profit currency to the account currency.
+type Contract struct {
+	PipSize      float64
+	ContractSize float64
+	Rate         float64
+}
+
+// Prices supplies the market an account trades against. Contract fails
+// when the symbol's profit cannot be converted to the account currency.
+type Prices interface {
+	Bid(symbol string) (float64, bool)
+	Contract(symbol string) (Contract, error)
+}
+
+// Tick is a symbol's market since the previous step: the latest bid and the
+// lowest and highest bids traded.
+type Tick struct {
+	Symbol string
+	Bid    float64
+	High   float64
+	Low    float64
+	At     time.Time
+}
+
+type position struct {
+	domain.Position
+	commandID string
+	contract  Contract
+}
+
+// dir is +1 for a buy and -1 for a sell.
+func (p *position) dir() float64 {
+	if p.Side == "SELL" {
+		return -1
+	}
+	return 1
+}
+
+// Account is one simulated broker account. It is safe for concurrent use.
+type Account struct {
+	mu        sync.Mutex
+	id        string
+	cfg       Config
+	balance   float64
+	positions []*position
+	deals     []domain.Deal
+	seq       int64
+	paused    bool
+	day       string
+	dayStart  float64
+	realized  float64
+}
+
+// NewAccount opens a flat account with the configured balance.
+func NewAccount(id string, cfg Config) *Account {
+	cfg.Currency = strings.ToUpper(strings.TrimSpace(cfg.Currency))
+	return &Account{id: id, cfg: cfg, balance: cfg.Balance, dayStart: cfg.Balance}
+}
+
+func (a *Account) ID() string { return a.id }
+
+// Currency is the account's configured currency.
+func (a *Account) Currency() string { return a.cfg.Currency }
+
+// Symbols lists the symbols the account holds positions in.
+func (a *Account) Symbols() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	seen := make(map[string]bool)
+	var out []string
+	for _, p := range a.positions {
+		if !seen[p.Symbol] {
+			seen[p.Symbol] = true
+			out = append(out, p.Symbol)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Execute runs a command as the EA would and returns the result it reports.
+func (a *Account) Execute(cmd domain.Command, prices Prices, now time.Time) domain.CommandResult {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now = now.UTC()
+	res := domain.CommandResult{CommandID: cmd.ID, Status: "SUCCESS", ExecutedAt: now.Format(time.RFC3339)}
+	fail := func(code, msg string) domain.CommandResult {
+		res.Status = "FAIL"
+		res.ErrorCode = code
+		res.ErrorMessage = msg
+		return res
+	}
+	symbol := strings.ToUpper(strings.TrimSpace(cmd.Symbol))
+	switch cmd.Type {
+	case domain.CommandOpen:
+		if a.paused {
+			return fail("REMOTE_PAUSED", "backend pause active, refusing OPEN")
+		}
+		side := strings.ToUpper(cmd.Side)
+		if side != "BUY" && side != "SELL" {
+			return fail("INVALID_SIDE", "side must be BUY or SELL")
+		}
+		bid, ok := prices.Bid(symbol)
+		if !ok || bid <= 0 {
+			return fail("NO_PRICE", "no candle or quote for "+symbol)
+		}
+		contract, err := prices.Contract(symbol)
+		if err != nil {
+			return fail("NO_RATE", err.Error())
+		}
+		p := &position{commandID: cmd.ID, contract: contract}
+		p.Symbol, p.Side, p.Volume = symbol, side, cmd.Volume
+		if p.Volume <= 0 {
+			p.Volume = 0.01
+		}
+		requested := a.ask(bid, contract)
+		p.PriceOpen = requested + a.slip(contract)
+		if side == "SELL" {
+			requested = bid
+			p.PriceOpen = bid - a.slip(contract)
+		}
+		// SL and TP are pips from the quote, as the EA places them.
+		if cmd.SL > 0 {
+			p.SL = requested - p.dir()*cmd.SL*contract.PipSize
+		}
+		if cmd.TP > 0 {
+			p.TP = requested + p.dir()*cmd.TP*contract.PipSize
+		}
+		p.Ticket = a.nextTicket()
+		p.PriceCurrent = p.PriceOpen
+		a.positions = append(a.positions, p)
+		a.addDeal(p, "IN", ReasonExpert, p.PriceOpen, 0, now)
+		res.BrokerTicket = p.Ticket
+		res.RequestedPrice = requested
+		res.FillPrice = p.PriceOpen
+		return res
+	case domain.CommandClose:
+		closed := 0
+		for _, p := range a.matching(symbol) {
+			bid, ok := prices.Bid(p.Symbol)
+			if !ok || bid <= 0 {
+				continue
+			}
+			a.close(p, a.exitPrice(p, bid)-p.dir()*a.slip(p.contract), ReasonExpert, now)
+			closed++
+		}
+		res.BrokerTicket = strconv.Itoa(closed)
+		if closed == 0 {
+			return fail("CLOSE_NONE", "no matching positions to close")
+		}
+		return res
+	case domain.CommandMoveSL, domain.CommandSetTP:
+		level, field := cmd.SL, "SL"
+		if cmd.Type == domain.CommandSetTP {
+			level, field = cmd.TP, "TP"
+		}
+		if level <= 0 {
+			return fail("INVALID_"+field, string(cmd.Type)+" requires a positive absolute "+strings.ToLower(field)+" price")
+		}
+		modified := a.matching(symbol)
+		for _, p := range modified {
+			if cmd.Type == domain.CommandMoveSL {
+				p.SL = level
+			} else {
+				p.TP = level
+			}
+		}
+		res.BrokerTicket = strconv.Itoa(len(modified))
+		if len(modified) == 0 {
+			return fail(string(cmd.Type)+"_NONE", "no positions modified")
+		}
+		return res
+	case domain.CommandPause, domain.CommandResume:
+		a.paused = cmd.Type == domain.CommandPause
+		res.BrokerTicket = "0"
+		return res
+	}
+	return fail("UNSUPPORTED_COMMAND", "unsupported command type: "+string(cmd.Type))
+}
+
+// Step marks positions to the ticks and closes those whose stop or target
+// the tick's range crossed. The stop is assumed to trigger first when both
+// fall inside the range. It returns the closing deals.
+func (a *Account) Step(ticks map[string]Tick, now time.Time) []domain.Deal {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now = now.UTC()
+	var closing []domain.Deal
+	for _, p := range append([]*position(nil), a.positions...) {
+		tick, ok := ticks[p.Symbol]
+		if !ok || tick.Bid <= 0 {
+			continue
+		}
+		low, high := a.exitPrice(p, min(tick.Low, tick.Bid)), a.exitPrice(p, max(tick.High, tick.Bid))
+		worst, best := low, high
+		if p.dir() < 0 {
+			worst, best = high, low
+		}
+		switch {
+		case p.SL > 0 && (worst-p.SL)*p.dir() <= 0:
+			closing = append(closing, a.close(p, p.SL-p.dir()*a.slip(p.contract), ReasonSL, now))
+		case p.TP > 0 && (best-p.TP)*p.dir() >= 0:
+			closing = append(closing, a.close(p, p.TP, ReasonTP, now))
+		default:
+			p.PriceCurrent = a.exitPrice(p, tick.Bid)
+			p.Profit = a.profit(p, p.PriceCurrent)
+		}
+	}
+	return closing
+}
+
+// RollDay starts the trading day labelled day: the day's start equity is
+// the current equity and its realized P&L is zero. Rolling to the current
+// day does nothing.
+func (a *Account) RollDay(day string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if day != a.day {
+		a.day, a.dayStart, a.realized = day, a.equity(), 0
+	}
+}
+
+// Snapshot is the account in the shape of an /ea/sync payload. It does not
+// change the account.
+func (a *Account) Snapshot() map[string]interface{} {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	equity := a.equity()
+	positions := make([]interface{}, 0, len(a.positions))
+	for _, p := range a.positions {
+		ticket, _ := strconv.ParseInt(p.Ticket, 10, 64)
+		positions = append(positions, map[string]interface{}{
+			"ticket":        float64(ticket),
+			"symbol":        p.Symbol,
+			"side":          p.Side,
+			"volume":        p.Volume,
+			"price_open":    p.PriceOpen,
+			"price_current": p.PriceCurrent,
+			"sl":            p.SL,
+			"tp":            p.TP,
+			"profit":        p.Profit,
+			"swap":          0.0,
+			"commission":    0.0,
+		})
+	}
+	return map[string]interface{}{
+		"account_id":           a.id,
+		"currency":             a.cfg.Currency,
+		"equity":               equity,
+		"balance":              a.balance,
+		"day_start_equity":     a.dayStart,
+		"realized_pnl_today":   a.realized,
+		"open_positions_count": float64(len(a.positions)),
+		"positions":            positions,
+	}
+}
+
+// Deals returns the account's deals at or after since, oldest first.
+func (a *Account) Deals(since time.Time) []domain.Deal {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	out := make([]domain.Deal, 0, len(a.deals))
+	for _, d := range a.deals {
+		if !d.Time.Before(since) {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+func (a *Account) equity() float64 {
+	equity := a.balance
+	for _, p := range a.positions {
+		equity += p.Profit
+	}
+	return equity
+}
+
+// matching returns the open positions on symbol, or all of them when symbol
+// is empty.
+func (a *Account) matching(symbol string) []*position {
+	var out []*position
+	for _, p := range a.positions {
+		if symbol == "" || p.Symbol == symbol {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func (a *Account) close(p *position, price float64, reason string, at time.Time) domain.Deal {
+	profit := a.profit(p, price)
+	a.balance += profit
+	a.realized += profit
+	for i, open := range a.positions {
+		if open == p {
+			a.positions = append(a.positions[:i], a.positions[i+1:]...)
+			break
+		}
+	}
+	return a.addDeal(p, "OUT", reason, price, profit, at)
+}
+
+func (a *Account) addDeal(p *position, entry, reason string, price, profit float64, at time.Time) domain.Deal {
+	side := p.Side
+	if entry == "OUT" {
+		side = "BUY"
+		if p.Side == "BUY" {
+			side = "SELL"
+		}
+	}
+	comment := reconcile.CommentPrefix + p.commandID
+	if len(p.commandID) > 24 {
+		comment = reconcile.CommentPrefix + p.commandID[:24]
+	}
+	d := domain.Deal{
+		AccountID:  a.id,
+		Ticket:     a.nextTicket(),
+		Order:      p.Ticket,
+		PositionID: p.Ticket,
+		Symbol:     p.Symbol,
+		Side:       side,
+		Entry:      entry,
+		Reason:     reason,
+		Volume:     p.Volume,
+		Price:      price,
+		Profit:     profit,
+		Comment:    comment,
+		Time:       at,
+	}
+	if entry == "OUT" {
+		d.Order = a.nextTicket()
+	}
+	a.deals = append(a.deals, d)
+	return d
+}
+
+func (a *Account) nextTicket() string {
+	a.seq++
+	return strconv.FormatInt(a.seq, 10)
+}
+
+func (a *Account) ask(bid float64, c Contract) float64 {
+	return bid + a.cfg.SpreadPips*c.PipSize
+}
+
+func (a *Account) slip(c Contract) float64 {
+	return a.cfg.SlippagePips * c.PipSize
+}
+
+// exitPrice is the price a position closes at for a bid: the bid for a buy
+// and the ask for a sell.
+func (a *Account) exitPrice(p *position, bid float64) float64 {
+	if p.dir() < 0 {
+		return a.ask(bid, p.contract)
+	}
+	return bid
+}
+
+func (a *Account) profit(p *position, price float64) float64 {
+	size := p.contract.ContractSize
+	if size <= 0 {
+		size = 100000
+	}
+	rate := p.contract.Rate
+	if rate <= 0 {
+		rate = 1
+	}
+	return math.Round((price-p.PriceOpen)*p.dir()*p.Volume*size*rate*100) / 100
+}
@@ -0,0 +1,146 @@
+package paper
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"mmbot/internal/domain"
+)
+
+type stubPrices map[string]float64
+
+func (s stubPrices) Bid(symbol string) (float64, bool) {
+	bid, ok := s[symbol]
+	return bid, ok
+}
+
+func (s stubPrices) Contract(symbol string) (Contract, error) {
+	if symbol == "USDJPY" {
+		return Contract{}, errors.New("no JPY rate")
+	}
+	return Contract{PipSize: 0.0001, ContractSize: 100000, Rate: 1}, nil
+}
+
+func near(a, b float64) bool { return math.Abs(a-b) < 1e-6 }
+
+func TestAccountOpenAndTakeProfit(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	acct := NewAccount("sim-1", Config{Balance: 10000, Currency: "usd", SpreadPips: 1, SlippagePips: 0.2})
+	prices := stubPrices{"EURUSD": 1.1000}
+
+	res := acct.Execute(domain.Command{ID: "cmd-1", Type: domain.CommandOpen, Symbol: "eurusd", Side: "BUY", Volume: 0.1, SL: 20, TP: 40}, prices, now)
+	if res.Status != "SUCCESS" || res.BrokerTicket != "1" || !near(res.RequestedPrice, 1.1001) || !near(res.FillPrice, 1.10012) {
+		t.Fatalf("expected a buy filled at the ask plus slippage, got %+v", res)
+	}
+
+	acct.Step(map[string]Tick{"EURUSD": {Bid: 1.1010, High: 1.1015, Low: 1.1005}}, now.Add(time.Minute))
+	snap := acct.Snapshot()
+	positions := snap["positions"].([]interface{})
+	pos := positions[0].(map[string]interface{})
+	if len(positions) != 1 || !near(pos["sl"].(float64), 1.0981) || !near(pos["tp"].(float64), 1.1041) || !near(pos["profit"].(float64), 8.8) {
+		t.Fatalf("expected the position marked to the bid, got %#v", snap)
+	}
+	if !near(snap["equity"].(float64), 10008.8) || snap["currency"] != "USD" {
+		t.Fatalf("unexpected snapshot %#v", snap)
+	}
+
+	closing := acct.Step(map[string]Tick{"EURUSD": {Bid: 1.1030, High: 1.1045, Low: 1.1020}}, now.Add(2*time.Minute))
+	if len(closing) != 1 || closing[0].Reason != ReasonTP || closing[0].Side != "SELL" || !near(closing[0].Price, 1.1041) || !near(closing[0].Profit, 39.8) {
+		t.Fatalf("expected a take profit close, got %+v", closing)
+	}
+	deals := acct.Deals(time.Time{})
+	if len(deals) != 2 || deals[0].Entry != "IN" || deals[0].Comment != "MMBot cmd-1" || deals[1].PositionID != "1" {
+		t.Fatalf("expected the opening and closing deals, got %+v", deals)
+	}
+	if snap := acct.Snapshot(); !near(snap["balance"].(float64), 10039.8) || len(snap["positions"].([]interface{})) != 0 {
+		t.Fatalf("expected the profit booked to the balance, got %#v", snap)
+	}
+}
+
+func TestAccountRollDay(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	acct := NewAccount("sim-1", Config{Balance: 10000, SpreadPips: 1})
+	if snap := acct.Snapshot(); !near(snap["day_start_equity"].(float64), 10000) {
+		t.Fatalf("expected the opening balance as the first day start, got %#v", snap)
+	}
+
+	acct.RollDay("2026-03-02")
+	acct.Execute(domain.Command{ID: "cmd-1", Type: domain.CommandOpen, Symbol: "EURUSD", Side: "BUY", Volume: 0.1}, stubPrices{"EURUSD": 1.1000}, now)
+	acct.Step(map[string]Tick{"EURUSD": {Bid: 1.1010, High: 1.1010, Low: 1.1010}}, now.Add(time.Minute))
+	acct.RollDay("2026-03-02")
+	if snap := acct.Snapshot(); !near(snap["day_start_equity"].(float64), 10000) {
+		t.Fatalf("expected the same day to keep its start equity, got %#v", snap)
+	}
+
+	acct.RollDay("2026-03-03")
+	if snap := acct.Snapshot(); !near(snap["day_start_equity"].(float64), 10009) || snap["realized_pnl_today"] != 0.0 {
+		t.Fatalf("expected the new day to start at the current equity, got %#v", snap)
+	}
+}
+
+func TestAccountRejectsUnconvertibleOpen(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	acct := NewAccount("sim-1", Config{Balance: 10000, Currency: "USD", SpreadPips: 1})
+
+	res := acct.Execute(domain.Command{ID: "cmd-1", Type: domain.CommandOpen, Symbol: "USDJPY", Side: "BUY", Volume: 0.1}, stubPrices{"USDJPY": 150}, now)
+	if res.Status != "FAIL" || res.ErrorCode != "NO_RATE" {
+		t.Fatalf("expected the open rejected without a rate, got %+v", res)
+	}
+	if snap := acct.Snapshot(); len(snap["positions"].([]interface{})) != 0 || len(acct.Deals(time.Time{})) != 0 {
+		t.Fatalf("expected no position or deal, got %#v", snap)
+	}
+}
+
+func TestAccountStopLossFirst(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	acct := NewAccount("sim-1", Config{Balance: 10000, SpreadPips: 1, SlippagePips: 0.2})
+	prices := stubPrices{"EURUSD": 1.1000}
+	res := acct.Execute(domain.Command{ID: "cmd-1", Type: domain.CommandOpen, Symbol: "EURUSD", Side: "SELL", Volume: 0.1, SL: 20, TP: 20}, prices, now)
+	if !near(res.FillPrice, 1.09998) {
+		t.Fatalf("expected a sell filled at the bid less slippage, got %+v", res)
+	}
+	// The range reaches both the stop (on the ask) and the target.
+	closing := acct.Step(map[string]Tick{"EURUSD": {Bid: 1.1000, High: 1.1020, Low: 1.0970}}, now)
+	if len(closing) != 1 || closing[0].Reason != ReasonSL || !near(closing[0].Price, 1.10202) || !near(closing[0].Profit, -20.4) {
+		t.Fatalf("expected the stop to fill first with slippage, got %+v", closing)
+	}
+}
+
+func TestAccountCommands(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	acct := NewAccount("sim-1", Config{Balance: 10000, SpreadPips: 1})
+	prices := stubPrices{"EURUSD": 1.1000, "GBPUSD": 1.2700}
+
+	if res := acct.Execute(domain.Command{ID: "a", Type: domain.CommandOpen, Symbol: "USDJPY", Side: "BUY"}, prices, now); res.ErrorCode != "NO_PRICE" {
+		t.Fatalf("expected no price for USDJPY, got %+v", res)
+	}
+	if res := acct.Execute(domain.Command{ID: "b", Type: domain.CommandClose, Symbol: "EURUSD"}, prices, now); res.ErrorCode != "CLOSE_NONE" {
+		t.Fatalf("expected nothing to close, got %+v", res)
+	}
+	acct.Execute(domain.Command{ID: "c", Type: domain.CommandOpen, Symbol: "EURUSD", Side: "BUY", Volume: 0.1}, prices, now)
+	acct.Execute(domain.Command{ID: "d", Type: domain.CommandOpen, Symbol: "GBPUSD", Side: "SELL", Volume: 0.1}, prices, now)
+
+	if res := acct.Execute(domain.Command{ID: "e", Type: domain.CommandMoveSL, Symbol: "EURUSD", SL: 1.0950}, prices, now); res.Status != "SUCCESS" || res.BrokerTicket != "1" {
+		t.Fatalf("expected one position modified, got %+v", res)
+	}
+	if res := acct.Execute(domain.Command{ID: "f", Type: domain.CommandSetTP, Symbol: "EURUSD"}, prices, now); res.ErrorCode != "INVALID_TP" {
+		t.Fatalf("expected SET_TP without a price to fail, got %+v", res)
+	}
+	acct.Execute(domain.Command{ID: "g", Type: domain.CommandPause}, prices, now)
+	if res := acct.Execute(domain.Command{ID: "h", Type: domain.CommandOpen, Symbol: "EURUSD", Side: "BUY"}, prices, now); res.ErrorCode != "REMOTE_PAUSED" {
+		t.Fatalf("expected OPEN to be refused while paused, got %+v", res)
+	}
+	acct.Execute(domain.Command{ID: "i", Type: domain.CommandResume}, prices, now)
+
+	if got := acct.Symbols(); len(got) != 2 || got[0] != "EURUSD" || got[1] != "GBPUSD" {
+		t.Fatalf("expected both symbols held, got %v", got)
+	}
+	if res := acct.Execute(domain.Command{ID: "j", Type: domain.CommandClose}, prices, now); res.Status != "SUCCESS" || res.BrokerTicket != "2" {
+		t.Fatalf("expected CLOSE without a symbol to close everything, got %+v", res)
+	}
+	if res := acct.Execute(domain.Command{ID: "k", Type: domain.CommandType("HEDGE")}, prices, now); res.ErrorCode != "UNSUPPORTED_COMMAND" {
+		t.Fatalf("expected an unsupported command, got %+v", res)
+	}
+}
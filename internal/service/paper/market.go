@@ -0,0 +1,131 @@
+package paper
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"mmbot/internal/domain"
+)
+
+// maxQuotes caps the quotes kept per symbol between steps.
+const maxQuotes = 1000
+
+// CandleSource reads stored candles, oldest first.
+type CandleSource interface {
+	ListCandles(symbol, timeframe string, from, to time.Time, limit int) []domain.Candle
+}
+
+// Quote is a pushed bid for a symbol.
+type Quote struct {
+	Symbol string    `json:"symbol"`
+	Bid    float64   `json:"bid"`
+	At     time.Time `json:"time"`
+}
+
+// Market prices symbols from the stored candles of one timeframe and from
+// pushed quotes, whichever is newer. Candle prices are taken as bids.
+type Market struct {
+	mu        sync.Mutex
+	candles   CandleSource
+	timeframe string
+	quotes    map[string][]Quote
+	seen      map[string]time.Time
+}
+
+// NewMarket reads candles of timeframe from candles.
+func NewMarket(candles CandleSource, timeframe string) *Market {
+	return &Market{
+		candles:   candles,
+		timeframe: timeframe,
+		quotes:    make(map[string][]Quote),
+		seen:      make(map[string]time.Time),
+	}
+}
+
+// Push records a quote. Quotes at or before the symbol's last step are
+// dropped.
+func (m *Market) Push(q Quote) bool {
+	q.Symbol = strings.ToUpper(strings.TrimSpace(q.Symbol))
+	if q.Symbol == "" || q.Bid <= 0 {
+		return false
+	}
+	q.At = q.At.UTC()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if seen, ok := m.seen[q.Symbol]; ok && !q.At.After(seen) {
+		return false
+	}
+	list := append(m.quotes[q.Symbol], q)
+	if len(list) > maxQuotes {
+		list = list[len(list)-maxQuotes:]
+	}
+	m.quotes[q.Symbol] = list
+	return true
+}
+
+// Bid is the latest price for symbol. The first price seen for a symbol
+// starts its step history, so older bars cannot trigger stops.
+func (m *Market) Bid(symbol string) (float64, bool) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	price, at, ok := m.latest(symbol)
+	if ok {
+		if _, started := m.seen[symbol]; !started {
+			m.seen[symbol] = at
+		}
+	}
+	return price, ok
+}
+
+// Advance returns the tick for symbol since the previous Advance: the
+// latest bid and the range of the candles and quotes that arrived since.
+func (m *Market) Advance(symbol string) (Tick, bool) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	price, at, ok := m.latest(symbol)
+	if !ok {
+		return Tick{}, false
+	}
+	tick := Tick{Symbol: symbol, Bid: price, High: price, Low: price, At: at}
+	seen, started := m.seen[symbol]
+	if started {
+		widen := func(high, low float64) {
+			tick.High = max(tick.High, high)
+			tick.Low = min(tick.Low, low)
+		}
+		for _, c := range m.candles.ListCandles(symbol, m.timeframe, seen, time.Time{}, 0) {
+			if c.Time.After(seen) {
+				widen(c.High, c.Low)
+			}
+		}
+		for _, q := range m.quotes[symbol] {
+			if q.At.After(seen) {
+				widen(q.Bid, q.Bid)
+			}
+		}
+	}
+	m.seen[symbol] = at
+	if list := m.quotes[symbol]; len(list) > 1 {
+		m.quotes[symbol] = list[len(list)-1:]
+	}
+	return tick, true
+}
+
+// latest returns the newest of the last candle's close and the last quote.
+func (m *Market) latest(symbol string) (float64, time.Time, bool) {
+	var price float64
+	var at time.Time
+	found := false
+	if bars := m.candles.ListCandles(symbol, m.timeframe, time.Time{}, time.Time{}, 1); len(bars) > 0 {
+		price, at, found = bars[0].Close, bars[0].Time, true
+	}
+	if list := m.quotes[symbol]; len(list) > 0 {
+		if q := list[len(list)-1]; !found || !q.At.Before(at) {
+			price, at, found = q.Bid, q.At, true
+		}
+	}
+	return price, at, found
+}
@@ -0,0 +1,47 @@
+package paper
+
+import (
+	"testing"
+	"time"
+
+	"mmbot/internal/domain"
+	"mmbot/internal/store/memory"
+)
+
+func TestMarketAdvance(t *testing.T) {
+	base := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	st := memory.NewStore(time.Hour)
+	st.SaveCandles("EURUSD", "M15", []domain.Candle{
+		{Time: base.Add(-15 * time.Minute), Open: 1.09, High: 1.20, Low: 1.00, Close: 1.0990},
+		{Time: base, Open: 1.0990, High: 1.1005, Low: 1.0985, Close: 1.1000},
+	})
+	m := NewMarket(st, "M15")
+
+	if bid, ok := m.Bid("eurusd"); !ok || bid != 1.1000 {
+		t.Fatalf("expected the last close as the bid, got %v %v", bid, ok)
+	}
+	// Bars up to the first price seen are history and do not widen the range.
+	if tick, ok := m.Advance("EURUSD"); !ok || tick.High != 1.1000 || tick.Low != 1.1000 {
+		t.Fatalf("expected an empty range before new prices, got %+v", tick)
+	}
+
+	if m.Push(Quote{Symbol: "EURUSD", Bid: 1.2, At: base}) {
+		t.Fatal("expected a quote at the last step to be dropped")
+	}
+	m.Push(Quote{Symbol: "EURUSD", Bid: 1.1030, At: base.Add(time.Minute)})
+	m.Push(Quote{Symbol: "EURUSD", Bid: 1.0970, At: base.Add(2 * time.Minute)})
+	m.Push(Quote{Symbol: "EURUSD", Bid: 1.1010, At: base.Add(3 * time.Minute)})
+	tick, _ := m.Advance("EURUSD")
+	if tick.Bid != 1.1010 || tick.High != 1.1030 || tick.Low != 1.0970 {
+		t.Fatalf("expected the range of the pushed quotes, got %+v", tick)
+	}
+
+	st.SaveCandles("EURUSD", "M15", []domain.Candle{{Time: base.Add(15 * time.Minute), Open: 1.1010, High: 1.1040, Low: 1.1000, Close: 1.1020}})
+	tick, _ = m.Advance("EURUSD")
+	if tick.Bid != 1.1020 || tick.High != 1.1040 || tick.Low != 1.1000 {
+		t.Fatalf("expected the new candle's range, got %+v", tick)
+	}
+	if _, ok := m.Advance("GBPUSD"); ok {
+		t.Fatal("expected no tick without prices")
+	}
+}
@@ -0,0 +1,295 @@
+// Package reconcile compares the commands the backend executed with the
+// broker's deal history and open positions: positions no command opened,
+// OPEN results the broker has no deal for, and commands that opened more
+// than one position are reported as issues.
+package reconcile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"mmbot/internal/domain"
+	"mmbot/internal/service/journal"
+)
+
+// CommentPrefix starts the comment the EA puts on the orders it opens; the
+// leading characters of the command ID follow it.
+const CommentPrefix = "MMBot "
+
+// Open is a successful OPEN command and the ticket its result reported.
+type Open struct {
+	CommandID string
+	Ticket    string
+	Symbol    string
+	At        time.Time
+}
+
+// Input is one reconciliation pass over an account. Deals cover the EA's
+// history window and Positions are the latest snapshot. Opens are the OPEN
+// results received over the same window; those received in [From, Until)
+// must have a deal by now. Journaled reports whether a position ticket
+// belongs to an open journal trade.
+type Input struct {
+	AccountID string
+	Opens     []Open
+	Deals     []domain.Deal
+	Positions []domain.Position
+	Journaled func(ticket string) bool
+	From      time.Time
+	Until     time.Time
+}
+
+// opened maps order and position tickets to the deal that opened the
+// position.
+func (in Input) opened() map[string]domain.Deal {
+	out := make(map[string]domain.Deal)
+	for _, d := range in.Deals {
+		if !d.Opens() {
+			continue
+		}
+		if d.Order != "" {
+			out[d.Order] = d
+		}
+		if d.PositionID != "" {
+			out[d.PositionID] = d
+		}
+	}
+	return out
+}
+
+// filled reports whether the broker shows a deal or an open position for
+// ticket.
+func (in Input) filled(opened map[string]domain.Deal, ticket string) bool {
+	if _, ok := opened[ticket]; ok {
+		return true
+	}
+	for _, p := range in.Positions {
+		if p.Ticket == ticket {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns the issues found in the input, stamped at now.
+func Check(in Input, now time.Time) []domain.ReconcileIssue {
+	now = now.UTC()
+	opened := in.opened()
+	position := func(ticket string) string {
+		if d, ok := opened[ticket]; ok && d.PositionID != "" {
+			return d.PositionID
+		}
+		return ticket
+	}
+
+	var commands []string
+	symbols := make(map[string]string)
+	positions := make(map[string][]string)
+	owners := make(map[string][]string)
+	claim := func(commandID, pos string) {
+		positions[commandID] = appendUnique(positions[commandID], pos)
+		owners[pos] = appendUnique(owners[pos], commandID)
+	}
+	for _, o := range in.Opens {
+		if _, ok := symbols[o.CommandID]; !ok {
+			commands = append(commands, o.CommandID)
+			symbols[o.CommandID] = o.Symbol
+		}
+		if o.Ticket != "" && o.Ticket != "0" {
+			claim(o.CommandID, position(o.Ticket))
+		}
+	}
+	// A retried order carries its command's comment but was never reported.
+	for _, d := range in.Deals {
+		if !d.Opens() || d.PositionID == "" {
+			continue
+		}
+		if id := commentCommand(d.Comment, commands); id != "" {
+			claim(id, d.PositionID)
+		}
+	}
+
+	issues := make([]domain.ReconcileIssue, 0)
+	seen := make(map[string]bool)
+	add := func(issue domain.ReconcileIssue, key string) {
+		issue.ID = string(issue.Kind) + ":" + key
+		if seen[issue.ID] {
+			return
+		}
+		seen[issue.ID] = true
+		issue.AccountID = in.AccountID
+		issue.DetectedAt = now
+		issues = append(issues, issue)
+	}
+
+	duplicated := make(map[string]bool)
+	for _, id := range commands {
+		if len(positions[id]) < 2 {
+			continue
+		}
+		for _, pos := range positions[id] {
+			duplicated[pos] = true
+		}
+		add(domain.ReconcileIssue{
+			Kind:      domain.ReconcileDuplicateExecution,
+			Ticket:    strings.Join(positions[id], ","),
+			CommandID: id,
+			Symbol:    symbols[id],
+			Detail:    fmt.Sprintf("OPEN %s opened %d positions: %s", id, len(positions[id]), strings.Join(positions[id], ", ")),
+		}, id)
+	}
+	claimed := make([]string, 0, len(owners))
+	for pos := range owners {
+		claimed = append(claimed, pos)
+	}
+	sort.Strings(claimed)
+	for _, pos := range claimed {
+		if len(owners[pos]) < 2 {
+			continue
+		}
+		duplicated[pos] = true
+		add(domain.ReconcileIssue{
+			Kind:      domain.ReconcileDuplicateExecution,
+			Ticket:    pos,
+			CommandID: strings.Join(owners[pos], ","),
+			Symbol:    symbols[owners[pos][0]],
+			Detail:    fmt.Sprintf("ticket %s was reported by %d OPEN commands", pos, len(owners[pos])),
+		}, "ticket:"+pos)
+	}
+
+	for _, o := range in.Opens {
+		if o.At.Before(in.From) || !o.At.Before(in.Until) || in.filled(opened, o.Ticket) {
+			continue
+		}
+		add(domain.ReconcileIssue{
+			Kind:      domain.ReconcileMissingFill,
+			Ticket:    o.Ticket,
+			CommandID: o.CommandID,
+			Symbol:    o.Symbol,
+			Detail:    fmt.Sprintf("OPEN %s reported ticket %q but the broker has no deal or position for it", o.CommandID, o.Ticket),
+		}, o.CommandID)
+	}
+
+	for _, p := range in.Positions {
+		if len(owners[p.Ticket]) > 0 || duplicated[p.Ticket] || (in.Journaled != nil && in.Journaled(p.Ticket)) {
+			continue
+		}
+		detail := fmt.Sprintf("%s %s %g was not opened by a backend command", p.Side, p.Symbol, p.Volume)
+		if d, ok := opened[p.Ticket]; ok && d.Reason != "" {
+			detail += fmt.Sprintf(" (deal reason %s)", d.Reason)
+		}
+		add(domain.ReconcileIssue{
+			Kind:   domain.ReconcileOrphanedPosition,
+			Ticket: p.Ticket,
+			Symbol: p.Symbol,
+			Detail: detail,
+		}, p.Ticket)
+	}
+	return issues
+}
+
+// Changed reports whether a pass found different evidence for a stored
+// issue: other tickets, commands or detail. A resolved issue is only raised
+// again when it changed.
+func Changed(prior, issue domain.ReconcileIssue) bool {
+	return prior.Ticket != issue.Ticket || prior.CommandID != issue.CommandID || prior.Detail != issue.Detail
+}
+
+// Resolved reports whether an open issue that a pass over in did not find
+// again is settled. A missing fill is settled once the broker shows its
+// ticket, and an orphaned position once it is closed or journaled. Duplicate
+// executions stay open until an admin resolves them.
+func Resolved(issue domain.ReconcileIssue, in Input) bool {
+	switch issue.Kind {
+	case domain.ReconcileMissingFill:
+		return in.filled(in.opened(), issue.Ticket)
+	case domain.ReconcileOrphanedPosition:
+		return true
+	}
+	return false
+}
+
+// Attribute completes a closed journal trade from the deals of its position:
+// the close reason of the last closing deal, the volume-weighted exit price
+// and the net profit of every deal. Trades that are still open, already
+// attributed or have no closing deal are returned unchanged with false.
+func Attribute(t domain.Trade, deals []domain.Deal, pipSize float64, at time.Time) (domain.Trade, bool) {
+	if t.Status != domain.TradeStatusClosed || t.CloseReason != "" || t.Ticket == "" {
+		return t, false
+	}
+	position := t.Ticket
+	for _, d := range deals {
+		if d.Opens() && d.Order == t.Ticket && d.PositionID != "" {
+			position = d.PositionID
+		}
+	}
+	var last domain.Deal
+	var profit, volume, notional float64
+	closed := false
+	for _, d := range deals {
+		if d.PositionID != position {
+			continue
+		}
+		profit += d.Profit + d.Swap + d.Commission
+		if !d.Closes() {
+			continue
+		}
+		if !closed || !d.Time.Before(last.Time) {
+			last = d
+		}
+		closed = true
+		volume += d.Volume
+		notional += d.Volume * d.Price
+	}
+	if !closed {
+		return t, false
+	}
+	t.CloseReason = last.Reason
+	if t.CloseReason == "" {
+		t.CloseReason = "UNKNOWN"
+	}
+	if volume > 0 {
+		t.ExitPrice = notional / volume
+	}
+	t.Profit = profit
+	t.RMultiple = journal.RMultiple(t, pipSize)
+	t.UpdatedAt = at.UTC()
+	return t, true
+}
+
+// ClosedPositions returns the positions the deals closed, in deal order.
+func ClosedPositions(deals []domain.Deal) []string {
+	var out []string
+	for _, d := range deals {
+		if d.Closes() && d.PositionID != "" {
+			out = appendUnique(out, d.PositionID)
+		}
+	}
+	return out
+}
+
+// commentCommand returns the command whose ID the EA's order comment
+// abbreviates, if any.
+func commentCommand(comment string, commands []string) string {
+	prefix, ok := strings.CutPrefix(comment, CommentPrefix)
+	if !ok || len(prefix) < 8 {
+		return ""
+	}
+	for _, id := range commands {
+		if strings.HasPrefix(id, prefix) {
+			return id
+		}
+	}
+	return ""
+}
+
+func appendUnique(list []string, v string) []string {
+	for _, existing := range list {
+		if existing == v {
+			return list
+		}
+	}
+	return append(list, v)
+}
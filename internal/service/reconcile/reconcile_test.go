@@ -0,0 +1,146 @@
+package reconcile
+
+import (
+	"testing"
+	"time"
+
+	"mmbot/internal/domain"
+)
+
+func TestCheck(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	in := Input{
+		AccountID: "paper-1",
+		Opens: []Open{
+			// Filled and still open.
+			{CommandID: "cmd-ok-0000000000", Ticket: "100", Symbol: "EURUSD", At: now.Add(-time.Hour)},
+			// Reported a ticket the broker never dealt.
+			{CommandID: "cmd-lost-00000000", Ticket: "200", Symbol: "GBPUSD", At: now.Add(-time.Hour)},
+			// Too recent to expect a deal yet.
+			{CommandID: "cmd-new-000000000", Ticket: "300", Symbol: "GBPUSD", At: now.Add(-30 * time.Second)},
+			// Retried: the broker holds a second position tagged with it.
+			{CommandID: "cmd-retry-0000000", Ticket: "400", Symbol: "USDJPY", At: now.Add(-time.Hour)},
+		},
+		Deals: []domain.Deal{
+			{Ticket: "1", Order: "100", PositionID: "100", Entry: "IN", Reason: "EXPERT"},
+			{Ticket: "2", Order: "400", PositionID: "400", Entry: "IN", Reason: "EXPERT", Comment: "MMBot cmd-retry-0000000"},
+			{Ticket: "3", Order: "401", PositionID: "401", Entry: "IN", Reason: "EXPERT", Comment: "MMBot cmd-retry-0000000"},
+			{Ticket: "4", Order: "500", PositionID: "500", Entry: "IN", Reason: "CLIENT"},
+		},
+		Positions: []domain.Position{
+			{Ticket: "100", Symbol: "EURUSD", Side: "BUY", Volume: 0.1},
+			{Ticket: "400", Symbol: "USDJPY", Side: "SELL", Volume: 0.1},
+			{Ticket: "401", Symbol: "USDJPY", Side: "SELL", Volume: 0.1},
+			{Ticket: "500", Symbol: "XAUUSD", Side: "BUY", Volume: 1},
+			{Ticket: "600", Symbol: "EURUSD", Side: "BUY", Volume: 0.2},
+		},
+		Journaled: func(ticket string) bool { return ticket == "600" },
+		From:      now.Add(-24 * time.Hour),
+		Until:     now.Add(-2 * time.Minute),
+	}
+	issues := Check(in, now)
+
+	byID := make(map[string]domain.ReconcileIssue)
+	for _, issue := range issues {
+		byID[issue.ID] = issue
+	}
+	if len(issues) != 3 {
+		t.Fatalf("expected three issues, got %+v", issues)
+	}
+	if issue, ok := byID["missing_fill:cmd-lost-00000000"]; !ok || issue.Ticket != "200" || issue.AccountID != "paper-1" {
+		t.Fatalf("expected a missing fill for ticket 200, got %+v", issues)
+	}
+	if issue, ok := byID["duplicate_execution:cmd-retry-0000000"]; !ok || issue.Ticket != "400,401" {
+		t.Fatalf("expected the retried command to be a duplicate, got %+v", issues)
+	}
+	if issue, ok := byID["orphaned_position:500"]; !ok || issue.Symbol != "XAUUSD" || !issue.DetectedAt.Equal(now) {
+		t.Fatalf("expected the manual position to be orphaned, got %+v", issues)
+	}
+}
+
+func TestCheckTicketClaimedTwice(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	issues := Check(Input{
+		Opens: []Open{
+			{CommandID: "a", Ticket: "100", At: now.Add(-time.Hour)},
+			{CommandID: "b", Ticket: "100", At: now.Add(-time.Hour)},
+		},
+		Deals:     []domain.Deal{{Ticket: "1", Order: "100", PositionID: "100", Entry: "IN"}},
+		Positions: []domain.Position{{Ticket: "100"}},
+		From:      now.Add(-24 * time.Hour),
+		Until:     now,
+	}, now)
+	if len(issues) != 1 || issues[0].ID != "duplicate_execution:ticket:100" || issues[0].CommandID != "a,b" {
+		t.Fatalf("expected one duplicate ticket issue, got %+v", issues)
+	}
+}
+
+func TestResolved(t *testing.T) {
+	in := Input{
+		Deals:     []domain.Deal{{Ticket: "1", Order: "200", PositionID: "200", Entry: "IN"}},
+		Positions: []domain.Position{{Ticket: "300"}},
+	}
+	cases := []struct {
+		issue domain.ReconcileIssue
+		want  bool
+	}{
+		{domain.ReconcileIssue{Kind: domain.ReconcileMissingFill, Ticket: "200"}, true},
+		{domain.ReconcileIssue{Kind: domain.ReconcileMissingFill, Ticket: "300"}, true},
+		{domain.ReconcileIssue{Kind: domain.ReconcileMissingFill, Ticket: "999"}, false},
+		{domain.ReconcileIssue{Kind: domain.ReconcileOrphanedPosition, Ticket: "300"}, true},
+		{domain.ReconcileIssue{Kind: domain.ReconcileDuplicateExecution, Ticket: "200"}, false},
+	}
+	for _, c := range cases {
+		if got := Resolved(c.issue, in); got != c.want {
+			t.Fatalf("Resolved(%+v) = %v, want %v", c.issue, got, c.want)
+		}
+	}
+}
+
+func TestChanged(t *testing.T) {
+	prior := domain.ReconcileIssue{Kind: domain.ReconcileDuplicateExecution, Ticket: "300,301", CommandID: "cmd-1", Detail: "OPEN cmd-1 opened 2 positions: 300, 301"}
+	again := prior
+	again.DetectedAt = prior.DetectedAt.Add(time.Hour)
+	if Changed(prior, again) {
+		t.Fatal("expected the same evidence to be unchanged")
+	}
+	again.Ticket = "300,301,302"
+	if !Changed(prior, again) {
+		t.Fatal("expected a new ticket to change the issue")
+	}
+}
+
+func TestAttribute(t *testing.T) {
+	at := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	trade := domain.Trade{
+		Status:       domain.TradeStatusClosed,
+		Ticket:       "100",
+		Side:         "BUY",
+		EntryPrice:   1.1000,
+		StopLossPips: 20,
+		Profit:       12,
+	}
+	deals := []domain.Deal{
+		{Ticket: "1", Order: "100", PositionID: "100", Entry: "IN", Volume: 0.2, Price: 1.1000, Commission: -1},
+		{Ticket: "2", Order: "101", PositionID: "100", Entry: "OUT", Reason: "CLIENT", Volume: 0.1, Price: 1.1010, Profit: 10, Time: at.Add(-time.Hour)},
+		{Ticket: "3", Order: "102", PositionID: "100", Entry: "OUT", Reason: "TP", Volume: 0.1, Price: 1.1030, Profit: 30, Commission: -1, Time: at},
+		{Ticket: "4", Order: "900", PositionID: "900", Entry: "OUT", Reason: "SL", Volume: 1, Price: 1.2},
+	}
+	got, ok := Attribute(trade, deals, 0.0001, at)
+	if !ok {
+		t.Fatal("expected the closed trade to be attributed")
+	}
+	if got.CloseReason != "TP" || got.Profit != 38 {
+		t.Fatalf("expected the TP close and net profit, got %+v", got)
+	}
+	if got.ExitPrice < 1.10199 || got.ExitPrice > 1.10201 || got.RMultiple != 1 {
+		t.Fatalf("expected the volume-weighted exit, got %+v", got)
+	}
+	if _, ok := Attribute(got, deals, 0.0001, at); ok {
+		t.Fatal("expected an attributed trade to be left alone")
+	}
+	trade.Status = domain.TradeStatusOpen
+	if _, ok := Attribute(trade, deals, 0.0001, at); ok {
+		t.Fatal("expected an open trade to be left alone")
+	}
+}
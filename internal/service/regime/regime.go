@@ -0,0 +1,314 @@
+package regime
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"mmbot/internal/domain"
+	"mmbot/internal/service/strategy"
+)
+
+const (
+	Trending = "trending"
+	Ranging  = "ranging"
+	Volatile = "volatile"
+	Quiet    = "quiet"
+)
+
+// Result is one classification of a symbol's series at its last bar.
+type Result struct {
+	Symbol        string    `json:"symbol"`
+	Timeframe     string    `json:"timeframe"`
+	Regime        string    `json:"regime"`
+	ADX           float64   `json:"adx"`
+	ATRPercentile float64   `json:"atr_percentile"`
+	EMASlopePct   float64   `json:"ema_slope_pct"`
+	BarTime       time.Time `json:"bar_time"`
+	Candles       int       `json:"candles"`
+}
+
+// Classifier labels a series trending when ADX and the EMA slope both show
+// direction; otherwise volatile or quiet when ATR sits in the top or bottom
+// of its recent range, and ranging in between.
+type Classifier struct {
+	ADXLen    int `json:"adx_len"`
+	ATRLen    int `json:"atr_len"`
+	ATRWindow int `json:"atr_window"`
+	EMALen    int `json:"ema_len"`
+	SlopeBars int `json:"slope_bars"`
+	// TrendADX is the ADX a trending market must reach.
+	TrendADX float64 `json:"trend_adx"`
+	// MinSlopePct is the per-bar EMA change (fraction of price) a trending
+	// market must show.
+	MinSlopePct float64 `json:"min_slope_pct"`
+	// VolatilePct and QuietPct are ATR percentile bounds in [0, 1].
+	VolatilePct float64 `json:"volatile_pct"`
+	QuietPct    float64 `json:"quiet_pct"`
+}
+
+func NewClassifier() Classifier {
+	return Classifier{
+		ADXLen:      14,
+		ATRLen:      14,
+		ATRWindow:   100,
+		EMALen:      50,
+		SlopeBars:   5,
+		TrendADX:    25,
+		MinSlopePct: 0.00005,
+		VolatilePct: 0.80,
+		QuietPct:    0.20,
+	}
+}
+
+// MinCandles is the shortest series Classify accepts. The ATR percentile
+// uses up to ATRWindow values but needs at least 20.
+func (c Classifier) MinCandles() int {
+	return max(2*c.ADXLen+1, c.EMALen+c.SlopeBars, c.ATRLen+20)
+}
+
+func (c Classifier) Classify(candles []domain.Candle) (Result, error) {
+	if n := c.MinCandles(); len(candles) < n {
+		return Result{}, fmt.Errorf("at least %d candles required for regime classification, got %d", n, len(candles))
+	}
+	adxNow := adx(candles, c.ADXLen)
+	pct := atrPercentile(candles, c.ATRLen, c.ATRWindow)
+
+	closes := make([]float64, len(candles))
+	for i, k := range candles {
+		closes[i] = k.Close
+	}
+	emaNow := ema(closes, c.EMALen)
+	emaPrev := ema(closes[:len(closes)-c.SlopeBars], c.EMALen)
+	slope := 0.0
+	if emaNow > 0 {
+		slope = (emaNow - emaPrev) / emaNow / float64(c.SlopeBars)
+	}
+
+	regime := Ranging
+	switch {
+	case adxNow >= c.TrendADX && math.Abs(slope) >= c.MinSlopePct:
+		regime = Trending
+	case pct >= c.VolatilePct:
+		regime = Volatile
+	case pct <= c.QuietPct:
+		regime = Quiet
+	}
+	return Result{
+		Regime:        regime,
+		ADX:           adxNow,
+		ATRPercentile: pct,
+		EMASlopePct:   slope,
+		BarTime:       candles[len(candles)-1].Time,
+		Candles:       len(candles),
+	}, nil
+}
+
+// CandleSource is the stored-candle lookup the service falls back to.
+type CandleSource interface {
+	ListCandles(symbol, timeframe string, from, to time.Time, limit int) []domain.Candle
+}
+
+// Service classifies symbols and remembers the last regime of each series
+// so callers can tell when it changed.
+type Service struct {
+	store      CandleSource
+	classifier Classifier
+	limit      int
+
+	mu   sync.Mutex
+	last map[string]Result
+}
+
+func NewService(store CandleSource, classifier Classifier, limit int) *Service {
+	return &Service{store: store, classifier: classifier, limit: limit, last: make(map[string]Result)}
+}
+
+// Observe classifies candles, or the stored series when too few are given,
+// and returns the previous regime with changed=true when it differs. The
+// first observation of a series is not a change.
+func (s *Service) Observe(symbol, timeframe string, candles []domain.Candle) (Result, string, bool, error) {
+	res, err := s.Classify(symbol, timeframe, candles)
+	if err != nil {
+		return Result{}, "", false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := res.Symbol + "|" + res.Timeframe
+	prev, seen := s.last[key]
+	s.last[key] = res
+	if seen && prev.Regime != res.Regime {
+		return res, prev.Regime, true, nil
+	}
+	return res, "", false, nil
+}
+
+// Classify is Observe without recording the result.
+func (s *Service) Classify(symbol, timeframe string, candles []domain.Candle) (Result, error) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	timeframe = strings.ToUpper(strings.TrimSpace(timeframe))
+	if symbol == "" {
+		return Result{}, errors.New("symbol is required")
+	}
+	if len(candles) < s.classifier.MinCandles() {
+		to := time.Time{}
+		if len(candles) > 0 {
+			to = candles[len(candles)-1].Time
+		}
+		candles = s.store.ListCandles(symbol, timeframe, time.Time{}, to, s.limit)
+	}
+	res, err := s.classifier.Classify(candles)
+	if err != nil {
+		return Result{}, err
+	}
+	res.Symbol = symbol
+	res.Timeframe = timeframe
+	return res, nil
+}
+
+// List returns the last observed regime of every series.
+func (s *Service) List() []Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Result, 0, len(s.last))
+	for _, r := range s.last {
+		out = append(out, r)
+	}
+	slices.SortFunc(out, func(a, b Result) int {
+		return strings.Compare(a.Symbol+"|"+a.Timeframe, b.Symbol+"|"+b.Timeframe)
+	})
+	return out
+}
+
+// Routes maps a regime to the strategy that trades it; a nil entry means
+// stand aside.
+type Routes map[string]strategy.Strategy
+
+// ParseRoutes reads "trending=trend,ranging=range,quiet=none". Regimes not
+// listed stand aside.
+func ParseRoutes(raw string, reg *strategy.Registry) (Routes, error) {
+	routes := make(Routes)
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, target, ok := strings.Cut(part, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		target = strings.TrimSpace(target)
+		if !ok {
+			return nil, fmt.Errorf("route %q must be regime=strategy", part)
+		}
+		switch name {
+		case Trending, Ranging, Volatile, Quiet:
+		default:
+			return nil, fmt.Errorf("unknown regime %q", name)
+		}
+		if strings.EqualFold(target, "none") || target == "" {
+			routes[name] = nil
+			continue
+		}
+		s, found := reg.Get(target)
+		if !found {
+			return nil, fmt.Errorf("unknown strategy %q for regime %s (have %s)", target, name, strings.Join(reg.Names(), ", "))
+		}
+		routes[name] = s
+	}
+	return routes, nil
+}
+
+// adx is Wilder's average directional index at the last bar.
+func adx(candles []domain.Candle, length int) float64 {
+	n := float64(length)
+	var trS, plusS, minusS, adxV float64
+	dxCount := 0
+	for i := 1; i < len(candles); i++ {
+		cur, prev := candles[i], candles[i-1]
+		up := cur.High - prev.High
+		down := prev.Low - cur.Low
+		plusDM, minusDM := 0.0, 0.0
+		if up > down && up > 0 {
+			plusDM = up
+		}
+		if down > up && down > 0 {
+			minusDM = down
+		}
+		tr := math.Max(cur.High-cur.Low, math.Max(math.Abs(cur.High-prev.Close), math.Abs(cur.Low-prev.Close)))
+		if i <= length {
+			trS += tr
+			plusS += plusDM
+			minusS += minusDM
+			if i < length {
+				continue
+			}
+		} else {
+			trS = trS - trS/n + tr
+			plusS = plusS - plusS/n + plusDM
+			minusS = minusS - minusS/n + minusDM
+		}
+		dx := 0.0
+		if trS > 0 {
+			plusDI := 100 * plusS / trS
+			minusDI := 100 * minusS / trS
+			if sum := plusDI + minusDI; sum > 0 {
+				dx = 100 * math.Abs(plusDI-minusDI) / sum
+			}
+		}
+		dxCount++
+		if dxCount <= length {
+			adxV += dx / n
+		} else {
+			adxV = (adxV*(n-1) + dx) / n
+		}
+	}
+	return adxV
+}
+
+// atrPercentile ranks the latest rolling ATR against the previous window.
+func atrPercentile(candles []domain.Candle, length, window int) float64 {
+	trs := make([]float64, 0, len(candles)-1)
+	for i := 1; i < len(candles); i++ {
+		cur, prev := candles[i], candles[i-1]
+		trs = append(trs, math.Max(cur.High-cur.Low, math.Max(math.Abs(cur.High-prev.Close), math.Abs(cur.Low-prev.Close))))
+	}
+	atrs := make([]float64, 0, len(trs))
+	sum := 0.0
+	for i, tr := range trs {
+		sum += tr
+		if i >= length {
+			sum -= trs[i-length]
+		}
+		if i >= length-1 {
+			atrs = append(atrs, sum/float64(length))
+		}
+	}
+	if len(atrs) > window {
+		atrs = atrs[len(atrs)-window:]
+	}
+	// Mid-rank, so a flat ATR history sits at 0.5 rather than the top.
+	current := atrs[len(atrs)-1]
+	rank := 0.0
+	for _, a := range atrs {
+		switch {
+		case a < current:
+			rank++
+		case a == current:
+			rank += 0.5
+		}
+	}
+	return rank / float64(len(atrs))
+}
+
+func ema(values []float64, period int) float64 {
+	k := 2.0 / float64(period+1)
+	v := values[0]
+	for _, x := range values[1:] {
+		v = x*k + v*(1-k)
+	}
+	return v
+}
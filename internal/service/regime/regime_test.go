@@ -0,0 +1,123 @@
+package regime
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"mmbot/internal/domain"
+	"mmbot/internal/service/strategy"
+	"mmbot/internal/store/memory"
+)
+
+var start = time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+// series builds bars from closes with a fixed half-range around each close.
+func series(closes []float64, halfRange func(i int) float64) []domain.Candle {
+	out := make([]domain.Candle, 0, len(closes))
+	prev := closes[0]
+	for i, c := range closes {
+		h := halfRange(i)
+		out = append(out, domain.Candle{
+			Time:  start.Add(time.Duration(i) * 15 * time.Minute),
+			Open:  prev,
+			High:  math.Max(prev, c) + h,
+			Low:   math.Min(prev, c) - h,
+			Close: c,
+		})
+		prev = c
+	}
+	return out
+}
+
+func constRange(v float64) func(int) float64 { return func(int) float64 { return v } }
+
+func trendCloses(n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = 1.1000 + float64(i)*0.0008
+	}
+	return out
+}
+
+func choppyCloses(n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = 1.1000 + 0.0005*float64(i%2*2-1)
+	}
+	return out
+}
+
+func TestClassify(t *testing.T) {
+	c := NewClassifier()
+	cases := []struct {
+		name    string
+		candles []domain.Candle
+		want    string
+	}{
+		{"trend", series(trendCloses(150), constRange(0.0003)), Trending},
+		{"range", series(choppyCloses(150), constRange(0.0003)), Ranging},
+		{"volatile", series(choppyCloses(150), func(i int) float64 {
+			if i >= 140 {
+				return 0.0040
+			}
+			return 0.0003
+		}), Volatile},
+		{"quiet", series(choppyCloses(150), func(i int) float64 {
+			if i >= 130 {
+				return 0.00001
+			}
+			return 0.0010
+		}), Quiet},
+	}
+	for _, tc := range cases {
+		res, err := c.Classify(tc.candles)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if res.Regime != tc.want {
+			t.Fatalf("%s: expected %s, got %+v", tc.name, tc.want, res)
+		}
+	}
+	if _, err := c.Classify(series(trendCloses(20), constRange(0.0003))); err == nil {
+		t.Fatal("expected short series to be rejected")
+	}
+}
+
+func TestService_ObserveReportsChangesAndFallsBackToStore(t *testing.T) {
+	store := memory.NewStore(time.Hour)
+	svc := NewService(store, NewClassifier(), 300)
+	store.SaveCandles("EURUSD", "M15", series(trendCloses(150), constRange(0.0003)))
+
+	if res, err := svc.Classify("EURUSD", "M15", nil); err != nil || res.Regime != Trending || len(svc.List()) != 0 {
+		t.Fatalf("expected Classify to read the stored series without recording it, got %+v err=%v", res, err)
+	}
+	res, _, changed, err := svc.Observe("eurusd", "m15", nil)
+	if err != nil || res.Regime != Trending || changed {
+		t.Fatalf("expected first stored observation to be trending without a change, got %+v changed=%v err=%v", res, changed, err)
+	}
+	res, prev, changed, err := svc.Observe("EURUSD", "M15", series(choppyCloses(150), constRange(0.0003)))
+	if err != nil || !changed || prev != Trending || res.Regime != Ranging {
+		t.Fatalf("expected trending -> ranging, got %+v prev=%s changed=%v err=%v", res, prev, changed, err)
+	}
+	if list := svc.List(); len(list) != 1 || list[0].Regime != Ranging {
+		t.Fatalf("unexpected last regimes %+v", list)
+	}
+}
+
+func TestParseRoutes(t *testing.T) {
+	reg := strategy.NewRegistry(strategy.NewTrendEngine(), strategy.NewRangeEngine())
+	routes, err := ParseRoutes("trending=trend, ranging=range, quiet=none", reg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if routes[Trending].Name() != "trend" || routes[Ranging].Name() != "range" || routes[Quiet] != nil || routes[Volatile] != nil {
+		t.Fatalf("unexpected routes %+v", routes)
+	}
+	if _, err := ParseRoutes("sideways=range", reg); err == nil {
+		t.Fatal("expected unknown regime to be rejected")
+	}
+	if _, err := ParseRoutes("volatile=breakout", reg); err == nil {
+		t.Fatal("expected unregistered strategy to be rejected")
+	}
+}
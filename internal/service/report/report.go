@@ -0,0 +1,157 @@
+// Package report builds the end-of-day and weekly account summaries that are
+// archived, sent to Telegram and published as events.
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"mmbot/internal/domain"
+	"mmbot/internal/service/analytics"
+	"mmbot/internal/service/equity"
+)
+
+// Input is what one report covers. Trades may hold the account's whole
+// journal; only trades opened or closed in [From, To) count. Points and
+// Events should already be limited to the period. A zero StartEquity or
+// EndEquity falls back to the first or last point of the curve.
+type Input struct {
+	AccountID   string
+	Kind        domain.ReportKind
+	Period      string
+	From        time.Time
+	To          time.Time
+	Trades      []domain.Trade
+	Points      []domain.EquityPoint
+	Events      []domain.Event
+	StartEquity float64
+	EndEquity   float64
+}
+
+func inPeriod(t, from, to time.Time) bool {
+	return !t.IsZero() && !t.Before(from) && t.Before(to)
+}
+
+// Build summarizes the input into a report stamped at now.
+func Build(in Input, now time.Time) domain.Report {
+	r := domain.Report{
+		AccountID:    in.AccountID,
+		Kind:         in.Kind,
+		Period:       in.Period,
+		From:         in.From.UTC(),
+		To:           in.To.UTC(),
+		StartEquity:  in.StartEquity,
+		EndEquity:    in.EndEquity,
+		RiskTriggers: make(map[string]int),
+		CreatedAt:    now.UTC(),
+	}
+	if n := len(in.Points); n > 0 {
+		if r.StartEquity <= 0 {
+			r.StartEquity = in.Points[0].Equity
+		}
+		if r.EndEquity <= 0 {
+			r.EndEquity = in.Points[n-1].Equity
+		}
+	}
+	if r.StartEquity > 0 && r.EndEquity > 0 {
+		r.ReturnPct = (r.EndEquity - r.StartEquity) / r.StartEquity * 100
+	}
+	r.MaxDrawdown, r.MaxDrawdownPct = equity.Drawdown(in.Points)
+
+	closed := make([]domain.Trade, 0, len(in.Trades))
+	for _, t := range in.Trades {
+		if inPeriod(t.OpenedAt, in.From, in.To) {
+			r.TradesOpened++
+		}
+		if t.Status == domain.TradeStatusClosed && inPeriod(t.ClosedAt, in.From, in.To) {
+			closed = append(closed, t)
+		}
+	}
+	perf := analytics.Compute(closed, r.StartEquity).Performance
+	r.TradesClosed = perf.Trades
+	r.Wins = perf.Wins
+	r.Losses = perf.Losses
+	r.WinRate = perf.WinRate
+	r.NetProfit = perf.NetProfit
+	r.ProfitFactor = perf.ProfitFactor
+
+	for _, e := range in.Events {
+		switch e.Type {
+		case domain.EventRiskTriggered:
+			reason, _ := e.Payload["reason"].(string)
+			if reason == "" {
+				reason = "unknown"
+			}
+			r.RiskTriggers[reason]++
+		case domain.EventSignalProposed:
+			// A denied signal also has a RiskTriggered event; this is the
+			// signal count, not a separate trigger.
+			r.SignalsProposed++
+			if allowed, ok := e.Payload["allowed"].(bool); ok && !allowed {
+				r.SignalsDenied++
+			}
+		case domain.EventOpenClawDeliveryFailed:
+			r.DeliveryFailures++
+		}
+	}
+	return r
+}
+
+// Text renders the report as a Telegram message.
+func Text(r domain.Report) string {
+	title := "Daily report"
+	if r.Kind == domain.ReportWeekly {
+		title = "Weekly report"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\nAccount: %s", title, r.Period, r.AccountID)
+	if r.Currency != "" {
+		fmt.Fprintf(&b, " (%s)", r.Currency)
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "Trades: %d opened, %d closed (%d won, %d lost)\n", r.TradesOpened, r.TradesClosed, r.Wins, r.Losses)
+	fmt.Fprintf(&b, "Net PnL: %.2f\n", r.NetProfit)
+	if r.StartEquity > 0 && r.EndEquity > 0 {
+		fmt.Fprintf(&b, "Equity: %.2f -> %.2f (%+.2f%%)\n", r.StartEquity, r.EndEquity, r.ReturnPct)
+	}
+	fmt.Fprintf(&b, "Max drawdown: %.2f (%.2f%%)\n", r.MaxDrawdown, r.MaxDrawdownPct)
+	fmt.Fprintf(&b, "Signals: %d proposed, %d denied\n", r.SignalsProposed, r.SignalsDenied)
+	if len(r.RiskTriggers) > 0 {
+		reasons := make([]string, 0, len(r.RiskTriggers))
+		for reason := range r.RiskTriggers {
+			reasons = append(reasons, reason)
+		}
+		sort.Strings(reasons)
+		parts := make([]string, len(reasons))
+		for i, reason := range reasons {
+			parts[i] = fmt.Sprintf("%s x%d", reason, r.RiskTriggers[reason])
+		}
+		fmt.Fprintf(&b, "Risk triggers: %s\n", strings.Join(parts, ", "))
+	}
+	if r.DeliveryFailures > 0 {
+		fmt.Fprintf(&b, "OpenClaw delivery failures: %d\n", r.DeliveryFailures)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// WeekKey is the ISO week ("2026-W03") of a trading day key.
+func WeekKey(day string) string {
+	t, err := time.Parse("2006-01-02", day)
+	if err != nil {
+		return ""
+	}
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// WeekStart is the Monday key of the ISO week a trading day key falls in.
+func WeekStart(day string) string {
+	t, err := time.Parse("2006-01-02", day)
+	if err != nil {
+		return ""
+	}
+	offset := (int(t.Weekday()) + 6) % 7
+	return t.AddDate(0, 0, -offset).Format("2006-01-02")
+}
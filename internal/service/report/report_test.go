@@ -0,0 +1,95 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"mmbot/internal/domain"
+)
+
+func TestBuild(t *testing.T) {
+	from := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	trade := func(profit float64, opened, closed time.Time) domain.Trade {
+		return domain.Trade{Status: domain.TradeStatusClosed, Profit: profit, OpenedAt: opened, ClosedAt: closed}
+	}
+	in := Input{
+		AccountID: "paper-1",
+		Kind:      domain.ReportDaily,
+		Period:    "2026-03-02",
+		From:      from,
+		To:        to,
+		Trades: []domain.Trade{
+			trade(60, from.Add(-2*time.Hour), from.Add(time.Hour)),
+			trade(-20, from.Add(2*time.Hour), from.Add(3*time.Hour)),
+			// Closed after the period: counts as opened only.
+			trade(-500, from.Add(4*time.Hour), to.Add(time.Hour)),
+			{Status: domain.TradeStatusOpen, OpenedAt: from.Add(5 * time.Hour)},
+		},
+		Points: []domain.EquityPoint{
+			{Equity: 10000, EquityHigh: 10000, EquityLow: 10000},
+			{Equity: 10100, EquityHigh: 10200, EquityLow: 9990},
+			{Equity: 10040, EquityHigh: 10100, EquityLow: 10040},
+		},
+		Events: []domain.Event{
+			{Type: domain.EventSignalProposed, Payload: map[string]interface{}{"allowed": true}},
+			{Type: domain.EventSignalProposed, Payload: map[string]interface{}{"allowed": false}},
+			{Type: domain.EventRiskTriggered, Payload: map[string]interface{}{"reason": "max_spread_exceeded"}},
+			{Type: domain.EventRiskTriggered, Payload: map[string]interface{}{"reason": "max_spread_exceeded"}},
+			{Type: domain.EventRiskTriggered, Payload: map[string]interface{}{}},
+			{Type: domain.EventOpenClawDeliveryFailed, Payload: map[string]interface{}{}},
+		},
+	}
+	r := Build(in, to)
+
+	if r.TradesOpened != 3 || r.TradesClosed != 2 || r.Wins != 1 || r.Losses != 1 || r.NetProfit != 40 {
+		t.Fatalf("unexpected trade figures %+v", r)
+	}
+	if r.ProfitFactor != 3 || r.WinRate != 0.5 {
+		t.Fatalf("unexpected ratios %+v", r)
+	}
+	if r.StartEquity != 10000 || r.EndEquity != 10040 || r.ReturnPct < 0.399 || r.ReturnPct > 0.401 {
+		t.Fatalf("expected equity from the curve, got %+v", r)
+	}
+	if r.MaxDrawdown != 160 {
+		t.Fatalf("expected drawdown from the bucket highs to the last low, got %+v", r)
+	}
+	if r.RiskTriggers["max_spread_exceeded"] != 2 || r.RiskTriggers["unknown"] != 1 {
+		t.Fatalf("unexpected risk triggers %v", r.RiskTriggers)
+	}
+	if r.SignalsProposed != 2 || r.SignalsDenied != 1 || r.DeliveryFailures != 1 {
+		t.Fatalf("unexpected event counts %+v", r)
+	}
+
+	in.StartEquity, in.EndEquity = 9000, 9900
+	if r := Build(in, to); r.StartEquity != 9000 || r.EndEquity != 9900 || r.ReturnPct != 10 {
+		t.Fatalf("expected explicit equity to win over the curve, got %+v", r)
+	}
+
+	text := Text(r)
+	for _, want := range []string{"Daily report 2026-03-02", "2 closed (1 won, 1 lost)", "max_spread_exceeded x2", "delivery failures: 1"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in %q", want, text)
+		}
+	}
+}
+
+func TestWeekKeyAndStart(t *testing.T) {
+	cases := []struct{ day, week, start string }{
+		{"2026-01-05", "2026-W02", "2026-01-05"},
+		{"2026-01-11", "2026-W02", "2026-01-05"},
+		{"2027-01-01", "2026-W53", "2026-12-28"},
+	}
+	for _, c := range cases {
+		if got := WeekKey(c.day); got != c.week {
+			t.Fatalf("WeekKey(%s) = %s, want %s", c.day, got, c.week)
+		}
+		if got := WeekStart(c.day); got != c.start {
+			t.Fatalf("WeekStart(%s) = %s, want %s", c.day, got, c.start)
+		}
+	}
+	if WeekKey("bad") != "" || WeekStart("bad") != "" {
+		t.Fatal("expected empty keys for an invalid day")
+	}
+}
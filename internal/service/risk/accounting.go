@@ -2,14 +2,27 @@ package risk
 
 import (
 	"math"
+	"strconv"
 	"strings"
+
+	"mmbot/internal/domain"
 )
 
+// SnapshotMetrics are the account figures a sync snapshot reports. Equity and
+// NetPnL are in Currency, the account currency, which is empty when the EA
+// does not send one.
 type SnapshotMetrics struct {
 	OpenPositions int     `json:"open_positions"`
 	DailyLossPct  float64 `json:"daily_loss_pct"`
 	Equity        float64 `json:"equity"`
 	NetPnL        float64 `json:"net_pnl"`
+	Currency      string  `json:"currency,omitempty"`
+	// Margin, FreeMargin and MarginLevel (percent) are zero unless
+	// MarginReported.
+	Margin         float64 `json:"margin"`
+	FreeMargin     float64 `json:"free_margin"`
+	MarginLevel    float64 `json:"margin_level"`
+	MarginReported bool    `json:"margin_reported"`
 }
 
 func DeriveSnapshotMetrics(snapshot map[string]interface{}) SnapshotMetrics {
@@ -32,12 +45,158 @@ func DeriveSnapshotMetrics(snapshot map[string]interface{}) SnapshotMetrics {
 	if dailyLossPct < 0 {
 		dailyLossPct = 0
 	}
-	return SnapshotMetrics{
+	metrics := SnapshotMetrics{
 		OpenPositions: openPositions,
 		DailyLossPct:  dailyLossPct,
 		Equity:        equity,
 		NetPnL:        netPnL,
+		Currency:      SnapshotCurrency(snapshot),
+	}
+	if m, ok := SnapshotMargin(snapshot); ok {
+		metrics.Margin = m.Margin
+		metrics.FreeMargin = m.FreeMargin
+		metrics.MarginLevel = m.Level
+		metrics.MarginReported = true
+	}
+	return metrics
+}
+
+// SnapshotMargin reads the account's used margin, free margin and margin
+// level from a sync snapshot. Free margin and level are derived from the live
+// equity when only the used margin is sent. It reports false when the
+// snapshot has no margin data.
+func SnapshotMargin(snapshot map[string]interface{}) (domain.MarginState, bool) {
+	margin, ok := lookupFloat(snapshot, "margin", "account.margin", "metrics.margin")
+	if !ok {
+		return domain.MarginState{}, false
+	}
+	state := domain.MarginState{Equity: SnapshotEquity(snapshot), Margin: margin}
+	if state.FreeMargin, ok = lookupFloat(snapshot, "margin_free", "free_margin", "account.margin_free", "metrics.free_margin"); !ok {
+		state.FreeMargin = state.Equity - margin
+	}
+	if state.Level, ok = lookupFloat(snapshot, "margin_level", "account.margin_level", "metrics.margin_level"); !ok && margin > 0 {
+		state.Level = state.Equity / margin * 100
+	}
+	return state, true
+}
+
+// SnapshotEquity is the live account equity in a sync snapshot. Unlike
+// SnapshotMetrics.Equity, the daily-loss base, it ignores day_start_equity.
+func SnapshotEquity(snapshot map[string]interface{}) float64 {
+	return firstFloat(snapshot,
+		"equity",
+		"account_equity",
+		"metrics.equity",
+		"account.equity",
+		"balance",
+		"account.balance",
+	)
+}
+
+// SnapshotBalance is the account balance in a sync snapshot, or zero.
+func SnapshotBalance(snapshot map[string]interface{}) float64 {
+	v, _ := lookupFloat(snapshot, "balance", "account.balance", "metrics.balance")
+	return v
+}
+
+// SnapshotCurrency is the upper-cased account currency in a sync snapshot, or
+// empty when it is not reported.
+func SnapshotCurrency(snapshot map[string]interface{}) string {
+	for _, key := range []string{"currency", "account_currency", "account.currency"} {
+		if v, ok := getByPath(snapshot, key); ok {
+			if currency, ok := v.(string); ok && strings.TrimSpace(currency) != "" {
+				return strings.ToUpper(strings.TrimSpace(currency))
+			}
+		}
 	}
+	return ""
+}
+
+// SnapshotQuotes returns the mid price of each entry in the snapshot's quotes
+// list, keyed by upper-cased symbol. Entries without a positive bid and ask
+// are skipped.
+func SnapshotQuotes(snapshot map[string]interface{}) map[string]float64 {
+	arr, ok := getArray(snapshot, "quotes")
+	if !ok {
+		return nil
+	}
+	out := make(map[string]float64, len(arr))
+	for _, item := range arr {
+		qm, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		symbol, _ := qm["symbol"].(string)
+		bid, ask := valueOrZero(qm, "bid"), valueOrZero(qm, "ask")
+		if strings.TrimSpace(symbol) == "" || bid <= 0 || ask <= 0 {
+			continue
+		}
+		out[strings.ToUpper(strings.TrimSpace(symbol))] = (bid + ask) / 2
+	}
+	return out
+}
+
+// SnapshotPositions extracts the open positions listed in a sync snapshot.
+// Entries without a symbol are skipped.
+func SnapshotPositions(snapshot map[string]interface{}) []domain.Position {
+	arr, ok := getArray(snapshot, "positions")
+	if !ok {
+		return nil
+	}
+	out := make([]domain.Position, 0, len(arr))
+	for _, item := range arr {
+		pm, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		symbol, _ := pm["symbol"].(string)
+		if strings.TrimSpace(symbol) == "" {
+			continue
+		}
+		side, _ := pm["side"].(string)
+		ticket := ""
+		switch t := pm["ticket"].(type) {
+		case string:
+			ticket = t
+		case float64:
+			ticket = strconv.FormatFloat(t, 'f', -1, 64)
+		}
+		out = append(out, domain.Position{
+			Ticket:       ticket,
+			Symbol:       strings.ToUpper(strings.TrimSpace(symbol)),
+			Side:         strings.ToUpper(strings.TrimSpace(side)),
+			Volume:       valueOrZero(pm, "volume"),
+			PriceOpen:    valueOrZero(pm, "price_open"),
+			PriceCurrent: valueOrZero(pm, "price_current"),
+			SL:           valueOrZero(pm, "sl"),
+			TP:           valueOrZero(pm, "tp"),
+			Profit:       valueOrZero(pm, "profit"),
+			Swap:         valueOrZero(pm, "swap"),
+			Commission:   valueOrZero(pm, "commission"),
+		})
+	}
+	return out
+}
+
+// ClosedPositions returns the positions of prev whose tickets are missing
+// from next, i.e. closed between the two syncs. Their last reported profit
+// stands in for the realised result. A next snapshot without a positions
+// list closes nothing.
+func ClosedPositions(prev, next map[string]interface{}) []domain.Position {
+	if _, ok := getArray(next, "positions"); !ok {
+		return nil
+	}
+	open := make(map[string]bool)
+	for _, p := range SnapshotPositions(next) {
+		open[p.Ticket] = true
+	}
+	var closed []domain.Position
+	for _, p := range SnapshotPositions(prev) {
+		if p.Ticket != "" && !open[p.Ticket] {
+			closed = append(closed, p)
+		}
+	}
+	return closed
 }
 
 func countPositions(snapshot map[string]interface{}) int {
@@ -86,12 +245,17 @@ func valueOrZero(m map[string]interface{}, key string) float64 {
 }
 
 func firstFloat(snapshot map[string]interface{}, keys ...string) float64 {
+	v, _ := lookupFloat(snapshot, keys...)
+	return v
+}
+
+func lookupFloat(snapshot map[string]interface{}, keys ...string) (float64, bool) {
 	for _, key := range keys {
 		if v, ok := getFloat(snapshot, key); ok {
-			return v
+			return v, true
 		}
 	}
-	return 0
+	return 0, false
 }
 
 func getArray(snapshot map[string]interface{}, path string) ([]interface{}, bool) {
@@ -41,3 +41,94 @@ func TestDeriveSnapshotMetrics_ComputesFromRealizedAndPositions(t *testing.T) {
 		t.Fatalf("expected daily loss ~1.46%%, got %.4f", metrics.DailyLossPct)
 	}
 }
+
+func TestSnapshotPositions(t *testing.T) {
+	snapshot := map[string]interface{}{
+		"positions": []interface{}{
+			map[string]interface{}{"ticket": 1201.0, "symbol": "eurusd", "side": "buy", "volume": 0.3, "price_open": 1.08},
+			map[string]interface{}{"ticket": "1202", "symbol": "XAUUSD", "side": "SELL", "volume": 0.1},
+			map[string]interface{}{"profit": 5.0},
+		},
+	}
+	positions := SnapshotPositions(snapshot)
+	if len(positions) != 2 {
+		t.Fatalf("expected 2 positions, got %+v", positions)
+	}
+	if p := positions[0]; p.Ticket != "1201" || p.Symbol != "EURUSD" || p.Side != "BUY" || p.Volume != 0.3 || p.PriceOpen != 1.08 {
+		t.Fatalf("unexpected first position %+v", p)
+	}
+	if p := positions[1]; p.Ticket != "1202" || p.Side != "SELL" {
+		t.Fatalf("unexpected second position %+v", p)
+	}
+}
+
+func TestSnapshotEquity_IgnoresDayStartEquity(t *testing.T) {
+	snapshot := map[string]interface{}{"day_start_equity": 10000.0, "balance": 10000.0, "equity": 9400.0}
+	if eq := SnapshotEquity(snapshot); eq != 9400 {
+		t.Fatalf("expected live equity 9400, got %.2f", eq)
+	}
+	if eq := SnapshotEquity(map[string]interface{}{"balance": 5000.0}); eq != 5000 {
+		t.Fatalf("expected balance fallback 5000, got %.2f", eq)
+	}
+}
+
+func TestSnapshotCurrencyAndQuotes(t *testing.T) {
+	snapshot := map[string]interface{}{
+		"account": map[string]interface{}{"currency": " eur "},
+		"quotes": []interface{}{
+			map[string]interface{}{"symbol": "eurusd", "bid": 1.0998, "ask": 1.1002},
+			map[string]interface{}{"symbol": "GBPUSD", "bid": 0.0, "ask": 1.27},
+			map[string]interface{}{"bid": 1.0, "ask": 1.0},
+		},
+	}
+	if got := SnapshotCurrency(snapshot); got != "EUR" {
+		t.Fatalf("expected EUR, got %q", got)
+	}
+	if got := DeriveSnapshotMetrics(snapshot).Currency; got != "EUR" {
+		t.Fatalf("expected the metrics to carry EUR, got %q", got)
+	}
+	quotes := SnapshotQuotes(snapshot)
+	if len(quotes) != 1 || quotes["EURUSD"] < 1.09999 || quotes["EURUSD"] > 1.10001 {
+		t.Fatalf("expected only the EURUSD mid, got %v", quotes)
+	}
+	if got := SnapshotCurrency(map[string]interface{}{"currency": 978.0}); got != "" {
+		t.Fatalf("expected a non-string currency to be ignored, got %q", got)
+	}
+}
+
+func TestClosedPositions(t *testing.T) {
+	prev := map[string]interface{}{
+		"positions": []interface{}{
+			map[string]interface{}{"ticket": "1", "symbol": "EURUSD", "side": "BUY", "profit": -12.0, "swap": -0.5},
+			map[string]interface{}{"ticket": "2", "symbol": "GBPUSD", "side": "SELL", "profit": 4.0},
+		},
+	}
+	next := map[string]interface{}{
+		"positions": []interface{}{
+			map[string]interface{}{"ticket": "2", "symbol": "GBPUSD", "side": "SELL", "profit": 6.0},
+		},
+	}
+	closed := ClosedPositions(prev, next)
+	if len(closed) != 1 || closed[0].Ticket != "1" || closed[0].Profit+closed[0].Swap != -12.5 {
+		t.Fatalf("expected ticket 1 closed at -12.5, got %+v", closed)
+	}
+	if closed := ClosedPositions(prev, map[string]interface{}{"equity": 1000.0}); len(closed) != 0 {
+		t.Fatalf("expected a snapshot without positions to close nothing, got %+v", closed)
+	}
+}
+
+func TestSnapshotMargin(t *testing.T) {
+	if _, ok := SnapshotMargin(map[string]interface{}{"equity": 10000.0}); ok {
+		t.Fatal("expected no margin state without margin data")
+	}
+	m, ok := SnapshotMargin(map[string]interface{}{"equity": 10000.0, "balance": 9800.0, "margin": 2500.0})
+	if !ok || m.FreeMargin != 7500 || m.Level != 400 {
+		t.Fatalf("expected free margin and level derived from equity, got %+v", m)
+	}
+	metrics := DeriveSnapshotMetrics(map[string]interface{}{
+		"equity": 10000.0, "margin": 2500.0, "margin_free": 7400.0, "margin_level": 399.5,
+	})
+	if !metrics.MarginReported || metrics.FreeMargin != 7400 || metrics.MarginLevel != 399.5 {
+		t.Fatalf("expected reported margin fields to win, got %+v", metrics)
+	}
+}
@@ -0,0 +1,101 @@
+package risk
+
+import (
+	"time"
+
+	"mmbot/internal/domain"
+)
+
+// DrawdownLimits are the account breakers evaluated on every sync. A zero
+// field disables its rule.
+type DrawdownLimits struct {
+	// MaxDrawdownPct is the largest allowed fall from the equity peak.
+	MaxDrawdownPct    float64
+	MaxWeeklyLossPct  float64
+	MaxMonthlyLossPct float64
+}
+
+// Breach is a tripped drawdown breaker.
+type Breach struct {
+	Reason       string
+	LossPct      float64
+	ThresholdPct float64
+}
+
+// TrackEquity folds a synced equity reading into the account's high-water
+// mark and period anchors. Weeks start with Monday's trading day and months
+// with the 1st's, at the account's trading day boundary; the first reading of
+// a period becomes its starting equity.
+func TrackEquity(state domain.AccountRiskState, equity float64, now time.Time, day TradingDay) domain.AccountRiskState {
+	now = now.UTC()
+	if equity <= 0 {
+		return state
+	}
+	if equity > state.PeakEquity {
+		state.PeakEquity = equity
+		state.PeakAt = now
+	}
+	if week := day.WeekStart(now); !week.Equal(state.WeekStart) {
+		state.WeekStart = week
+		state.WeekStartEquity = equity
+	}
+	if month := day.MonthStart(now); !month.Equal(state.MonthStart) {
+		state.MonthStart = month
+		state.MonthStartEquity = equity
+	}
+	state.LastEquity = equity
+	state.UpdatedAt = now
+	return state
+}
+
+// DrawdownPct is the current fall from the equity peak in percent.
+func DrawdownPct(state domain.AccountRiskState) float64 {
+	return lossPct(state.PeakEquity, state.LastEquity)
+}
+
+// Check returns the first breaker the last tracked equity trips: drawdown
+// from peak, then weekly, then monthly loss.
+func (l DrawdownLimits) Check(state domain.AccountRiskState) (Breach, bool) {
+	checks := []struct {
+		reason    string
+		loss      float64
+		threshold float64
+	}{
+		{"max_drawdown_limit_hit", DrawdownPct(state), l.MaxDrawdownPct},
+		{"weekly_loss_limit_hit", lossPct(state.WeekStartEquity, state.LastEquity), l.MaxWeeklyLossPct},
+		{"monthly_loss_limit_hit", lossPct(state.MonthStartEquity, state.LastEquity), l.MaxMonthlyLossPct},
+	}
+	for _, c := range checks {
+		if c.threshold > 0 && c.loss >= c.threshold {
+			return Breach{Reason: c.reason, LossPct: c.loss, ThresholdPct: c.threshold}, true
+		}
+	}
+	return Breach{}, false
+}
+
+// Rebase moves every reference a breaker is currently tripped on to the
+// last equity, so resuming an account does not re-trigger on the same loss.
+// Untripped references, including the peak, are kept.
+func (l DrawdownLimits) Rebase(state domain.AccountRiskState) domain.AccountRiskState {
+	if state.LastEquity <= 0 {
+		return state
+	}
+	if l.MaxDrawdownPct > 0 && DrawdownPct(state) >= l.MaxDrawdownPct {
+		state.PeakEquity = state.LastEquity
+		state.PeakAt = state.UpdatedAt
+	}
+	if l.MaxWeeklyLossPct > 0 && lossPct(state.WeekStartEquity, state.LastEquity) >= l.MaxWeeklyLossPct {
+		state.WeekStartEquity = state.LastEquity
+	}
+	if l.MaxMonthlyLossPct > 0 && lossPct(state.MonthStartEquity, state.LastEquity) >= l.MaxMonthlyLossPct {
+		state.MonthStartEquity = state.LastEquity
+	}
+	return state
+}
+
+func lossPct(reference, equity float64) float64 {
+	if reference <= 0 || equity >= reference {
+		return 0
+	}
+	return (reference - equity) / reference * 100
+}
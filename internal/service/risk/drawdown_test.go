@@ -0,0 +1,70 @@
+package risk
+
+import (
+	"testing"
+	"time"
+
+	"mmbot/internal/domain"
+)
+
+func TestTrackEquity_PeakAndPeriodAnchors(t *testing.T) {
+	// Wednesday 2026-01-14.
+	now := time.Date(2026, 1, 14, 10, 0, 0, 0, time.UTC)
+	state := TrackEquity(domain.AccountRiskState{AccountID: "paper-1"}, 10000, now, TradingDay{})
+	state = TrackEquity(state, 10500, now.Add(time.Hour), TradingDay{})
+	state = TrackEquity(state, 9800, now.Add(2*time.Hour), TradingDay{})
+
+	if state.PeakEquity != 10500 || !state.PeakAt.Equal(now.Add(time.Hour)) {
+		t.Fatalf("expected peak 10500 at second sync, got %+v", state)
+	}
+	if !state.WeekStart.Equal(time.Date(2026, 1, 12, 0, 0, 0, 0, time.UTC)) || state.WeekStartEquity != 10000 {
+		t.Fatalf("expected week anchored on Monday at 10000, got %+v", state)
+	}
+	if state.MonthStartEquity != 10000 {
+		t.Fatalf("expected month start equity 10000, got %.2f", state.MonthStartEquity)
+	}
+	if dd := DrawdownPct(state); dd < 6.66 || dd > 6.67 {
+		t.Fatalf("expected ~6.67%% drawdown, got %.4f", dd)
+	}
+
+	// Next Monday starts a new week but not a new month.
+	state = TrackEquity(state, 9700, time.Date(2026, 1, 19, 0, 5, 0, 0, time.UTC), TradingDay{})
+	if state.WeekStartEquity != 9700 || state.MonthStartEquity != 10000 {
+		t.Fatalf("expected new week anchor only, got %+v", state)
+	}
+	// Non-positive equity readings are ignored.
+	if next := TrackEquity(state, 0, time.Date(2026, 2, 2, 0, 0, 0, 0, time.UTC), TradingDay{}); next != state {
+		t.Fatalf("expected zero equity to leave state unchanged, got %+v", next)
+	}
+}
+
+func TestDrawdownLimits_CheckAndRebase(t *testing.T) {
+	limits := DrawdownLimits{MaxDrawdownPct: 10, MaxWeeklyLossPct: 5, MaxMonthlyLossPct: 8}
+	state := domain.AccountRiskState{
+		PeakEquity:       12000,
+		WeekStartEquity:  10200,
+		MonthStartEquity: 11000,
+		LastEquity:       10000,
+	}
+	// 16.7% from peak, 2% this week, 9.1% this month: drawdown is reported first.
+	breach, ok := limits.Check(state)
+	if !ok || breach.Reason != "max_drawdown_limit_hit" || breach.ThresholdPct != 10 {
+		t.Fatalf("expected drawdown breach, got %+v ok=%v", breach, ok)
+	}
+
+	rebased := limits.Rebase(state)
+	if rebased.PeakEquity != 10000 || rebased.MonthStartEquity != 10000 || rebased.WeekStartEquity != 10200 {
+		t.Fatalf("expected only tripped references rebased, got %+v", rebased)
+	}
+	if breach, ok := limits.Check(rebased); ok {
+		t.Fatalf("expected no breach after rebase, got %+v", breach)
+	}
+
+	state.PeakEquity = 10300
+	if breach, ok := (DrawdownLimits{MaxWeeklyLossPct: 1.5}).Check(state); !ok || breach.Reason != "weekly_loss_limit_hit" {
+		t.Fatalf("expected weekly breach, got %+v ok=%v", breach, ok)
+	}
+	if _, ok := (DrawdownLimits{}).Check(state); ok {
+		t.Fatal("expected zero limits to disable every breaker")
+	}
+}
@@ -2,15 +2,33 @@ package risk
 
 import (
 	"strings"
+	"sync"
+	"time"
 
 	"mmbot/internal/domain"
 )
 
+// TimeGate blocks opens by time of day, calendar or news; Check returns the
+// deny reason or "".
+type TimeGate interface {
+	Check(symbol string, spec *domain.SymbolSpec, at time.Time) string
+}
+
 type Engine struct {
 	maxOpenPositions int
 	maxDailyLossPct  float64
 	minConfidence    float64
 	maxSpreadPips    float64
+	// minMarginLevelPct is the margin level floor after a new OPEN.
+	minMarginLevelPct float64
+	exposure          ExposureLimits
+	streak            StreakLimits
+	slippage          SlippageLimits
+	gate              TimeGate
+	days              TradingDays
+
+	mu     sync.RWMutex
+	policy Policy
 }
 
 func NewEngine(maxOpenPositions int, maxDailyLossPct, minConfidence, maxSpreadPips float64) *Engine {
@@ -22,30 +40,131 @@ func NewEngine(maxOpenPositions int, maxDailyLossPct, minConfidence, maxSpreadPi
 	}
 }
 
-func (e *Engine) Evaluate(input domain.SignalInput, state domain.StrategyState) domain.RiskDecision {
-	if state.Paused {
-		return domain.RiskDecision{Allowed: false, DenyReason: "bot_paused"}
-	}
-	if strings.TrimSpace(input.Symbol) == "" {
-		return domain.RiskDecision{Allowed: false, DenyReason: "symbol_missing"}
+// WithTimeGate enables the trading-session and news-blackout rules.
+func (e *Engine) WithTimeGate(gate TimeGate) *Engine {
+	e.gate = gate
+	return e
+}
+
+// WithPolicy layers a risk policy over the constructor thresholds.
+func (e *Engine) WithPolicy(p Policy) *Engine {
+	e.SetPolicy(p)
+	return e
+}
+
+// SetPolicy swaps the policy in place and returns the one it replaced.
+func (e *Engine) SetPolicy(p Policy) Policy {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	prev := e.policy
+	e.policy = p
+	return prev
+}
+
+func (e *Engine) Policy() Policy {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.policy
+}
+
+// Thresholds returns the limits in force for an account and symbol.
+func (e *Engine) Thresholds(accountID, symbol string) Thresholds {
+	base := Thresholds{
+		MaxOpenPositions:       e.maxOpenPositions,
+		MaxDailyLossPct:        e.maxDailyLossPct,
+		MinConfidence:          e.minConfidence,
+		MaxSpreadPips:          e.maxSpreadPips,
+		MaxPositionsPerSymbol:  e.exposure.MaxPositionsPerSymbol,
+		MaxLotsPerSymbol:       e.exposure.MaxLotsPerSymbol,
+		MaxCurrencyLots:        e.exposure.MaxCurrencyLots,
+		MaxCorrelatedPositions: e.exposure.MaxCorrelatedPositions,
+		MaxTradesPerDay:        e.streak.MaxTradesPerDay,
+		MinMarginLevelPct:      e.minMarginLevelPct,
+		MaxAvgSlippagePips:     e.slippage.MaxAvgPips,
 	}
-	if strings.TrimSpace(input.Side) == "" {
-		return domain.RiskDecision{Allowed: false, DenyReason: "side_missing"}
+	return e.Policy().Resolve(base, accountID, symbol)
+}
+
+// Evaluate runs every rule and reports each result. The decision is denied
+// with the reason of the first failed rule, in the order listed here.
+func (e *Engine) Evaluate(input domain.SignalInput, state domain.StrategyState) domain.RiskDecision {
+	t := e.Thresholds(input.AccountID, input.Symbol)
+	var c checklist
+	c.flag("bot_paused", state.Paused, "bot_paused")
+	c.flag("account_paused", state.AccountPaused, "account_paused")
+	c.flag("symbol_paused", state.SymbolPaused, "symbol_slippage_paused")
+	c.flag("symbol_present", strings.TrimSpace(input.Symbol) == "", "symbol_missing")
+	c.flag("side_present", strings.TrimSpace(input.Side) == "", "side_missing")
+	if e.gate != nil {
+		now := state.Now
+		if now.IsZero() {
+			now = time.Now()
+		}
+		reason := e.gate.Check(input.Symbol, state.Spec, now)
+		c.add(domain.RiskCheck{Rule: "trading_schedule", Passed: reason == "", Reason: reason, Observed: now.UTC().Format(time.RFC3339)})
 	}
-	if input.StopLossPips <= 0 {
-		return domain.RiskDecision{Allowed: false, DenyReason: "stop_loss_required"}
+	c.limit("stop_loss", input.StopLossPips <= 0, "stop_loss_required", input.StopLossPips, 0)
+	if minPips := stopsLevelPips(state.Spec); minPips > 0 {
+		c.limit("stop_loss_stops_level", input.StopLossPips < minPips, "stop_loss_inside_stops_level", input.StopLossPips, minPips)
+		if input.TakeProfitPips > 0 {
+			c.limit("take_profit_stops_level", input.TakeProfitPips < minPips, "take_profit_inside_stops_level", input.TakeProfitPips, minPips)
+		}
 	}
-	if input.SpreadPips > e.maxSpreadPips {
-		return domain.RiskDecision{Allowed: false, DenyReason: "spread_too_high"}
+	c.limit("spread", input.SpreadPips > t.MaxSpreadPips, "spread_too_high", input.SpreadPips, t.MaxSpreadPips)
+	c.limit("ai_confidence", input.Confidence < t.MinConfidence, "ai_confidence_too_low", input.Confidence, t.MinConfidence)
+	c.limit("open_positions", state.OpenPositions >= t.MaxOpenPositions, "max_open_positions_reached", state.OpenPositions, t.MaxOpenPositions)
+	exposureChecks(&c, t.exposure(), input, state)
+	marginChecks(&c, t.MinMarginLevelPct, input, state)
+	streakChecks(&c, t.MaxTradesPerDay, state)
+	c.limit("daily_loss", state.DailyLossPct >= t.MaxDailyLossPct, "daily_loss_limit_hit", state.DailyLossPct, t.MaxDailyLossPct)
+
+	decision := domain.RiskDecision{Allowed: true, Checks: c}
+	for _, check := range c {
+		if !check.Passed {
+			decision.Allowed = false
+			decision.DenyReason = check.Reason
+			break
+		}
 	}
-	if input.Confidence < e.minConfidence {
-		return domain.RiskDecision{Allowed: false, DenyReason: "ai_confidence_too_low"}
+	return decision
+}
+
+// checklist collects rule results in evaluation order.
+type checklist []domain.RiskCheck
+
+func (c *checklist) add(check domain.RiskCheck) {
+	if check.Passed {
+		check.Reason = ""
 	}
-	if state.OpenPositions >= e.maxOpenPositions {
-		return domain.RiskDecision{Allowed: false, DenyReason: "max_open_positions_reached"}
+	*c = append(*c, check)
+}
+
+// flag records a rule that fails when a condition is set.
+func (c *checklist) flag(rule string, set bool, reason string) {
+	c.add(domain.RiskCheck{Rule: rule, Passed: !set, Reason: reason, Observed: set})
+}
+
+// limit records a rule comparing an observed value with its threshold.
+func (c *checklist) limit(rule string, failed bool, reason string, observed, threshold interface{}) {
+	c.add(domain.RiskCheck{Rule: rule, Passed: !failed, Reason: reason, Observed: observed, Threshold: threshold})
+}
+
+// FailedReasons lists the deny reasons of every failed check.
+func FailedReasons(decision domain.RiskDecision) []string {
+	var out []string
+	for _, c := range decision.Checks {
+		if !c.Passed {
+			out = append(out, c.Reason)
+		}
 	}
-	if state.DailyLossPct >= e.maxDailyLossPct {
-		return domain.RiskDecision{Allowed: false, DenyReason: "daily_loss_limit_hit"}
+	return out
+}
+
+// stopsLevelPips converts the broker's minimum SL/TP distance (in points) to
+// pips; zero when the symbol has no spec or no stops level.
+func stopsLevelPips(spec *domain.SymbolSpec) float64 {
+	if spec == nil || spec.StopsLevel <= 0 || spec.Point <= 0 || spec.PipSize <= 0 {
+		return 0
 	}
-	return domain.RiskDecision{Allowed: true}
+	return float64(spec.StopsLevel) * spec.Point / spec.PipSize
 }
@@ -2,6 +2,7 @@ package risk
 
 import (
 	"testing"
+	"time"
 
 	"mmbot/internal/domain"
 )
@@ -57,3 +58,182 @@ func TestEvaluate_RejectsPaused(t *testing.T) {
 		t.Fatalf("expected bot_paused, got %+v", decision)
 	}
 }
+
+func TestEvaluate_RejectsStopInsideBrokerStopsLevel(t *testing.T) {
+	engine := NewEngine(3, 2.0, 0.70, 2.0)
+	// XAUUSD: 2 digits, 30-point stops level = 0.30 = 30 pips of 0.01.
+	spec := &domain.SymbolSpec{Symbol: "XAUUSD", Digits: 2, Point: 0.01, PipSize: 0.01, StopsLevel: 30}
+	input := domain.SignalInput{
+		Symbol:         "XAUUSD",
+		Side:           "BUY",
+		Confidence:     0.9,
+		SpreadPips:     1.0,
+		StopLossPips:   25,
+		TakeProfitPips: 50,
+	}
+	decision := engine.Evaluate(input, domain.StrategyState{Spec: spec})
+	if decision.Allowed || decision.DenyReason != "stop_loss_inside_stops_level" {
+		t.Fatalf("expected stop_loss_inside_stops_level, got %+v", decision)
+	}
+	input.StopLossPips = 40
+	if decision := engine.Evaluate(input, domain.StrategyState{Spec: spec}); !decision.Allowed {
+		t.Fatalf("expected SL beyond stops level to pass, got %+v", decision)
+	}
+}
+
+func TestEvaluate_ExposureLimits(t *testing.T) {
+	engine := NewEngine(10, 2.0, 0.70, 2.0).WithExposureLimits(ExposureLimits{
+		MaxPositionsPerSymbol:  2,
+		MaxLotsPerSymbol:       1.0,
+		MaxCurrencyLots:        1.5,
+		MaxCorrelatedPositions: 2,
+	})
+	input := func(symbol, side string, volume float64) domain.SignalInput {
+		return domain.SignalInput{
+			Symbol:       symbol,
+			Side:         side,
+			Confidence:   0.9,
+			SpreadPips:   1.0,
+			StopLossPips: 10,
+			Volume:       volume,
+		}
+	}
+	eurLongs := []domain.Position{
+		{Symbol: "EURUSD", Side: "BUY", Volume: 0.5},
+		{Symbol: "EURJPY", Side: "BUY", Volume: 0.5},
+	}
+
+	cases := []struct {
+		name      string
+		input     domain.SignalInput
+		positions []domain.Position
+		want      string
+	}{
+		{"third EUR long is correlated", input("EURGBP", "BUY", 0.1), eurLongs, "correlated_positions_limit_reached"},
+		{"EUR short hedges", input("EURGBP", "SELL", 0.1), eurLongs, ""},
+		{"same symbol count", input("GBPUSD", "SELL", 0.1), []domain.Position{
+			{Symbol: "GBPUSD", Side: "BUY", Volume: 0.1},
+			{Symbol: "GBPUSD", Side: "SELL", Volume: 0.1},
+		}, "max_positions_per_symbol_reached"},
+		{"same symbol lots", input("usdjpy", "BUY", 0.6), []domain.Position{
+			{Symbol: "USDJPY", Side: "BUY", Volume: 0.5},
+		}, "max_lots_per_symbol_exceeded"},
+		{"net currency lots", input("EURCHF", "BUY", 0.6), []domain.Position{
+			{Symbol: "EURUSD", Side: "BUY", Volume: 1.0},
+		}, "currency_exposure_limit_exceeded"},
+		{"reducing exposure passes", input("EURUSD", "SELL", 0.5), []domain.Position{
+			{Symbol: "EURCHF", Side: "BUY", Volume: 1.0},
+			{Symbol: "EURGBP", Side: "BUY", Volume: 1.0},
+		}, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			decision := engine.Evaluate(tc.input, domain.StrategyState{OpenPositions: len(tc.positions), Positions: tc.positions})
+			if tc.want == "" && !decision.Allowed {
+				t.Fatalf("expected allowed, got %+v", decision)
+			}
+			if tc.want != "" && (decision.Allowed || decision.DenyReason != tc.want) {
+				t.Fatalf("expected %s, got %+v", tc.want, decision)
+			}
+		})
+	}
+}
+
+func TestEvaluate_RejectsAccountPaused(t *testing.T) {
+	engine := NewEngine(3, 2.0, 0.70, 2.0)
+	decision := engine.Evaluate(
+		domain.SignalInput{Symbol: "EURUSD", Side: "BUY", Confidence: 0.9, SpreadPips: 1.0, StopLossPips: 10},
+		domain.StrategyState{AccountPaused: true},
+	)
+	if decision.Allowed || decision.DenyReason != "account_paused" {
+		t.Fatalf("expected account_paused, got %+v", decision)
+	}
+}
+
+type gateFunc func(symbol string, spec *domain.SymbolSpec, at time.Time) string
+
+func (f gateFunc) Check(symbol string, spec *domain.SymbolSpec, at time.Time) string {
+	return f(symbol, spec, at)
+}
+
+func TestEvaluate_TimeGateUsesStateClock(t *testing.T) {
+	blackout := time.Date(2026, 3, 6, 13, 30, 0, 0, time.UTC)
+	engine := NewEngine(3, 2.0, 0.70, 2.0).WithTimeGate(gateFunc(func(symbol string, _ *domain.SymbolSpec, at time.Time) string {
+		if at.Equal(blackout) {
+			return "news_blackout"
+		}
+		return ""
+	}))
+	signal := domain.SignalInput{Symbol: "EURUSD", Side: "BUY", Confidence: 0.9, SpreadPips: 1.0, StopLossPips: 10}
+	if decision := engine.Evaluate(signal, domain.StrategyState{Now: blackout}); decision.Allowed || decision.DenyReason != "news_blackout" {
+		t.Fatalf("expected news_blackout, got %+v", decision)
+	}
+	if decision := engine.Evaluate(signal, domain.StrategyState{Now: blackout.Add(time.Hour)}); !decision.Allowed {
+		t.Fatalf("expected allowed outside the gate, got %+v", decision)
+	}
+}
+
+func TestEvaluate_ReportsEveryFailedRule(t *testing.T) {
+	engine := NewEngine(3, 2.0, 0.70, 2.0).WithStreakLimits(StreakLimits{MaxTradesPerDay: 5})
+	decision := engine.Evaluate(
+		domain.SignalInput{Symbol: "EURUSD", Side: "BUY", Confidence: 0.5, SpreadPips: 3.5, StopLossPips: 10},
+		domain.StrategyState{OpenPositions: 3, DailyLossPct: 0.5, TradesToday: 2},
+	)
+	if decision.Allowed || decision.DenyReason != "spread_too_high" {
+		t.Fatalf("expected the first failed rule as deny reason, got %+v", decision)
+	}
+	want := []string{"spread_too_high", "ai_confidence_too_low", "max_open_positions_reached"}
+	got := FailedReasons(decision)
+	if len(got) != len(want) {
+		t.Fatalf("expected failures %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected failures %v, got %v", want, got)
+		}
+	}
+
+	checks := make(map[string]domain.RiskCheck)
+	for _, c := range decision.Checks {
+		checks[c.Rule] = c
+	}
+	if c := checks["ai_confidence"]; c.Passed || c.Observed != 0.5 || c.Threshold != 0.70 {
+		t.Fatalf("unexpected confidence check %+v", c)
+	}
+	if c := checks["trades_per_day"]; !c.Passed || c.Reason != "" || c.Observed != 2 || c.Threshold != 5 {
+		t.Fatalf("unexpected trades_per_day check %+v", c)
+	}
+	if _, ok := checks["positions_per_symbol"]; ok {
+		t.Fatal("expected disabled rules to be left out")
+	}
+	if c := checks["daily_loss"]; !c.Passed {
+		t.Fatalf("expected daily_loss to be evaluated after earlier failures, got %+v", c)
+	}
+}
+
+func TestEvaluate_MarginRules(t *testing.T) {
+	engine := NewEngine(5, 2.0, 0.70, 2.0).WithMarginFloor(200)
+	spec := &domain.SymbolSpec{Symbol: "EURUSD", MarginPerLot: 1000}
+	margin := &domain.MarginState{Equity: 10000, Margin: 3000, FreeMargin: 7000, Level: 333.3}
+	evaluate := func(volume float64, spec *domain.SymbolSpec, margin *domain.MarginState) domain.RiskDecision {
+		return engine.Evaluate(
+			domain.SignalInput{Symbol: "EURUSD", Side: "BUY", Confidence: 0.9, SpreadPips: 1.0, StopLossPips: 10, Volume: volume},
+			domain.StrategyState{Spec: spec, Margin: margin},
+		)
+	}
+	if d := evaluate(1.0, spec, margin); !d.Allowed {
+		t.Fatalf("expected 250%% projected level to pass, got %+v", d)
+	}
+	if d := evaluate(2.5, spec, margin); d.Allowed || d.DenyReason != "margin_level_below_floor" {
+		t.Fatalf("expected margin_level_below_floor, got %+v", d)
+	}
+	if d := evaluate(8, spec, margin); d.Allowed || d.DenyReason != "insufficient_free_margin" {
+		t.Fatalf("expected insufficient_free_margin, got %+v", d)
+	}
+	if d := evaluate(8, &domain.SymbolSpec{Symbol: "EURUSD"}, margin); !d.Allowed {
+		t.Fatalf("expected the rule to be skipped without margin per lot, got %+v", d)
+	}
+	if d := evaluate(8, spec, nil); !d.Allowed {
+		t.Fatalf("expected the rule to be skipped without synced margin, got %+v", d)
+	}
+}
@@ -0,0 +1,111 @@
+package risk
+
+import (
+	"math"
+	"strings"
+
+	"mmbot/internal/domain"
+	"mmbot/internal/service/fx"
+)
+
+// ExposureLimits cap concentration across open positions. A zero field
+// disables its rule.
+type ExposureLimits struct {
+	MaxPositionsPerSymbol int
+	MaxLotsPerSymbol      float64
+	// MaxCurrencyLots caps the net lots long or short any single currency,
+	// counting both legs of every pair (BUY 1.0 EURUSD is EUR +1, USD -1).
+	MaxCurrencyLots float64
+	// MaxCorrelatedPositions caps positions, the new one included, that are
+	// on the same side of a shared currency.
+	MaxCorrelatedPositions int
+}
+
+// WithExposureLimits enables the per-symbol, per-currency and correlation
+// rules on top of the basic checks.
+func (e *Engine) WithExposureLimits(limits ExposureLimits) *Engine {
+	e.exposure = limits
+	return e
+}
+
+func exposureChecks(c *checklist, l ExposureLimits, input domain.SignalInput, state domain.StrategyState) {
+	symbol := strings.ToUpper(strings.TrimSpace(input.Symbol))
+	side := strings.ToUpper(strings.TrimSpace(input.Side))
+
+	symbolCount, symbolLots := 0, 0.0
+	for _, p := range state.Positions {
+		if strings.EqualFold(p.Symbol, symbol) {
+			symbolCount++
+			symbolLots += p.Volume
+		}
+	}
+	if l.MaxPositionsPerSymbol > 0 {
+		c.limit("positions_per_symbol", symbolCount >= l.MaxPositionsPerSymbol, "max_positions_per_symbol_reached", symbolCount, l.MaxPositionsPerSymbol)
+	}
+	if l.MaxLotsPerSymbol > 0 {
+		lots := symbolLots + input.Volume
+		c.limit("lots_per_symbol", lots > l.MaxLotsPerSymbol+1e-9, "max_lots_per_symbol_exceeded", lots, l.MaxLotsPerSymbol)
+	}
+
+	base, quote, ok := fx.SplitPair(symbol, state.Spec)
+	if !ok || (l.MaxCurrencyLots <= 0 && l.MaxCorrelatedPositions <= 0) {
+		return
+	}
+	dir := 1.0
+	if side == "SELL" {
+		dir = -1
+	}
+
+	if l.MaxCurrencyLots > 0 {
+		net := make(map[string]float64)
+		for _, p := range state.Positions {
+			pb, pq, ok := positionLegs(p)
+			if !ok {
+				continue
+			}
+			pd := sideDir(p.Side)
+			net[pb] += pd * p.Volume
+			net[pq] -= pd * p.Volume
+		}
+		// Only legs the trade grows count: trades that shrink an already
+		// large exposure stay allowed.
+		worst := 0.0
+		for cur, delta := range map[string]float64{base: dir * input.Volume, quote: -dir * input.Volume} {
+			before := net[cur]
+			after := math.Abs(before + delta)
+			if after > math.Abs(before) && after > worst {
+				worst = after
+			}
+		}
+		c.limit("currency_exposure", worst > l.MaxCurrencyLots+1e-9, "currency_exposure_limit_exceeded", worst, l.MaxCurrencyLots)
+	}
+
+	if l.MaxCorrelatedPositions > 0 {
+		correlated := 1
+		for _, p := range state.Positions {
+			pb, pq, ok := positionLegs(p)
+			if !ok {
+				continue
+			}
+			legs := map[string]float64{pb: sideDir(p.Side), pq: -sideDir(p.Side)}
+			if legs[base]*dir > 0 || legs[quote]*-dir > 0 {
+				correlated++
+			}
+		}
+		c.limit("correlated_positions", correlated > l.MaxCorrelatedPositions, "correlated_positions_limit_reached", correlated, l.MaxCorrelatedPositions)
+	}
+}
+
+func sideDir(side string) float64 {
+	if strings.EqualFold(side, "SELL") {
+		return -1
+	}
+	return 1
+}
+
+func positionLegs(p domain.Position) (string, string, bool) {
+	if p.BaseCurrency != "" && p.ProfitCurrency != "" {
+		return strings.ToUpper(p.BaseCurrency), strings.ToUpper(p.ProfitCurrency), true
+	}
+	return fx.SplitPair(p.Symbol, nil)
+}
@@ -0,0 +1,112 @@
+package risk
+
+import (
+	"strings"
+	"time"
+
+	"mmbot/internal/domain"
+)
+
+// SlippageLimits pause a symbol when the average slippage of its recent fills
+// exceeds MaxAvgPips. Only the last Window fills count, and no pause happens
+// before MinFills of them. PauseFor zero holds the pause until an admin
+// resumes the symbol. A zero MaxAvgPips disables the rule.
+type SlippageLimits struct {
+	MaxAvgPips float64
+	Window     int
+	MinFills   int
+	PauseFor   time.Duration
+}
+
+// WithSlippageLimits enables the per-symbol slippage pause.
+func (e *Engine) WithSlippageLimits(limits SlippageLimits) *Engine {
+	e.slippage = limits
+	return e
+}
+
+func (e *Engine) SlippageLimits() SlippageLimits {
+	return e.slippage
+}
+
+// FillStats summarizes execution quality over a set of fills.
+type FillStats struct {
+	Symbol          string    `json:"symbol,omitempty"`
+	Fills           int       `json:"fills"`
+	AvgSlippagePips float64   `json:"avg_slippage_pips"`
+	MaxSlippagePips float64   `json:"max_slippage_pips"`
+	AvgLatencyMS    float64   `json:"avg_latency_ms"`
+	MaxLatencyMS    int64     `json:"max_latency_ms"`
+	LastFillAt      time.Time `json:"last_fill_at,omitempty"`
+}
+
+// SlippagePips is the distance from the requested to the fill price in pips,
+// positive when the fill was worse for the trade. A CLOSE of a BUY sells, so
+// the direction follows the order actually sent.
+func SlippagePips(cmdType domain.CommandType, side string, requested, fill, pipSize float64) float64 {
+	if requested <= 0 || fill <= 0 || pipSize <= 0 {
+		return 0
+	}
+	buy := strings.EqualFold(side, "BUY")
+	if cmdType == domain.CommandClose {
+		buy = !buy
+	}
+	diff := fill - requested
+	if !buy {
+		diff = -diff
+	}
+	return diff / pipSize
+}
+
+// ComputeFillStats aggregates fills per symbol. Commands without a fill
+// price are skipped.
+func ComputeFillStats(fills []domain.Command) map[string]FillStats {
+	out := make(map[string]FillStats)
+	for _, f := range fills {
+		if f.FillPrice <= 0 {
+			continue
+		}
+		s := out[f.Symbol]
+		s.Symbol = f.Symbol
+		s.Fills++
+		s.AvgSlippagePips += f.SlippagePips
+		s.AvgLatencyMS += float64(f.LatencyMS)
+		if s.Fills == 1 || f.SlippagePips > s.MaxSlippagePips {
+			s.MaxSlippagePips = f.SlippagePips
+		}
+		if f.LatencyMS > s.MaxLatencyMS {
+			s.MaxLatencyMS = f.LatencyMS
+		}
+		if f.FilledAt.After(s.LastFillAt) {
+			s.LastFillAt = f.FilledAt
+		}
+		out[f.Symbol] = s
+	}
+	for symbol, s := range out {
+		s.AvgSlippagePips /= float64(s.Fills)
+		s.AvgLatencyMS /= float64(s.Fills)
+		out[symbol] = s
+	}
+	return out
+}
+
+// SlippageBreach reports whether a symbol's recent fill stats call for a
+// pause, and returns the pause to store when they do.
+func (e *Engine) SlippageBreach(stats FillStats, now time.Time) (domain.SymbolPause, bool) {
+	limit := e.Thresholds("", stats.Symbol).MaxAvgSlippagePips
+	if limit <= 0 || stats.Fills < e.slippage.MinFills || stats.AvgSlippagePips <= limit {
+		return domain.SymbolPause{}, false
+	}
+	now = now.UTC()
+	pause := domain.SymbolPause{
+		Symbol:          stats.Symbol,
+		Paused:          true,
+		Reason:          "symbol_slippage_paused",
+		AvgSlippagePips: stats.AvgSlippagePips,
+		Fills:           stats.Fills,
+		PausedAt:        now,
+	}
+	if e.slippage.PauseFor > 0 {
+		pause.Until = now.Add(e.slippage.PauseFor)
+	}
+	return pause, true
+}
@@ -0,0 +1,74 @@
+package risk
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"mmbot/internal/domain"
+)
+
+func TestSlippagePips_PositiveWhenAdverse(t *testing.T) {
+	cases := []struct {
+		cmdType   domain.CommandType
+		side      string
+		requested float64
+		fill      float64
+		want      float64
+	}{
+		{domain.CommandOpen, "BUY", 1.10000, 1.10020, 2},
+		{domain.CommandOpen, "SELL", 1.10000, 1.10020, -2},
+		{domain.CommandOpen, "SELL", 1.10000, 1.09990, 1},
+		{domain.CommandClose, "BUY", 1.10000, 1.09990, 1},
+		{domain.CommandOpen, "BUY", 0, 1.10020, 0},
+	}
+	for _, tc := range cases {
+		got := SlippagePips(tc.cmdType, tc.side, tc.requested, tc.fill, 0.0001)
+		if math.Abs(got-tc.want) > 1e-9 {
+			t.Fatalf("%s %s %v->%v: expected %v pips, got %v", tc.cmdType, tc.side, tc.requested, tc.fill, tc.want, got)
+		}
+	}
+}
+
+func TestSlippageBreach_PausesAfterMinFills(t *testing.T) {
+	at := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	engine := NewEngine(3, 2.0, 0.70, 2.0).WithSlippageLimits(SlippageLimits{MaxAvgPips: 1.5, Window: 10, MinFills: 3, PauseFor: time.Hour})
+	fills := []domain.Command{
+		{Symbol: "EURUSD", FillPrice: 1.1, SlippagePips: 1, LatencyMS: 100, FilledAt: at},
+		{Symbol: "EURUSD", FillPrice: 1.1, SlippagePips: 3, LatencyMS: 300, FilledAt: at.Add(time.Minute)},
+		{Symbol: "GBPUSD", FillPrice: 1.3, SlippagePips: 0.2, LatencyMS: 50, FilledAt: at},
+		{Symbol: "EURUSD", SlippagePips: 9},
+	}
+	stats := ComputeFillStats(fills)
+	eur := stats["EURUSD"]
+	if eur.Fills != 2 || eur.AvgSlippagePips != 2 || eur.MaxSlippagePips != 3 || eur.AvgLatencyMS != 200 || eur.MaxLatencyMS != 300 || !eur.LastFillAt.Equal(at.Add(time.Minute)) {
+		t.Fatalf("unexpected EURUSD stats %+v", eur)
+	}
+	if _, breached := engine.SlippageBreach(eur, at); breached {
+		t.Fatal("expected no pause below the minimum fill count")
+	}
+
+	fills = append(fills, domain.Command{Symbol: "EURUSD", FillPrice: 1.1, SlippagePips: 2, FilledAt: at.Add(2 * time.Minute)})
+	pause, breached := engine.SlippageBreach(ComputeFillStats(fills)["EURUSD"], at)
+	if !breached || !pause.Paused || pause.Fills != 3 || !pause.Until.Equal(at.Add(time.Hour)) {
+		t.Fatalf("expected a one hour pause, got %+v breached=%v", pause, breached)
+	}
+	if !pause.Active(at.Add(59*time.Minute)) || pause.Active(at.Add(time.Hour)) {
+		t.Fatalf("expected the pause to expire at %v, got %+v", pause.Until, pause)
+	}
+
+	policy, err := ParsePolicy(strings.NewReader(`{"symbols": {"eurusd": {"max_avg_slippage_pips": 2.5}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.SetPolicy(policy)
+	if _, breached := engine.SlippageBreach(ComputeFillStats(fills)["EURUSD"], at); breached {
+		t.Fatal("expected the symbol override to raise the slippage limit")
+	}
+
+	input := domain.SignalInput{Symbol: "EURUSD", Side: "BUY", Confidence: 0.9, SpreadPips: 1.0, StopLossPips: 10}
+	if d := engine.Evaluate(input, domain.StrategyState{SymbolPaused: true}); d.Allowed || d.DenyReason != "symbol_slippage_paused" {
+		t.Fatalf("expected symbol_slippage_paused, got %+v", d)
+	}
+}
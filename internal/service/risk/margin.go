@@ -0,0 +1,44 @@
+package risk
+
+import "mmbot/internal/domain"
+
+// WithMarginFloor enables the pre-trade margin rules: an OPEN is refused when
+// its estimated margin exceeds the free margin, or when it would leave the
+// margin level below floorPct. Zero keeps only the free-margin check.
+func (e *Engine) WithMarginFloor(floorPct float64) *Engine {
+	e.minMarginLevelPct = floorPct
+	return e
+}
+
+// RequiredMargin estimates the margin volume lots of the symbol would need
+// from its spec; zero when the spec has no margin per lot.
+func RequiredMargin(spec *domain.SymbolSpec, volume float64) float64 {
+	if spec == nil || spec.MarginPerLot <= 0 || volume <= 0 {
+		return 0
+	}
+	return spec.MarginPerLot * volume
+}
+
+// ProjectedMarginLevel is the margin level in percent after adding required
+// margin to the account's current usage.
+func ProjectedMarginLevel(m domain.MarginState, required float64) float64 {
+	total := m.Margin + required
+	if total <= 0 {
+		return 0
+	}
+	return m.Equity / total * 100
+}
+
+// marginChecks are skipped when the EA sent no margin data or the new
+// position's margin cannot be estimated.
+func marginChecks(c *checklist, floorPct float64, input domain.SignalInput, state domain.StrategyState) {
+	required := RequiredMargin(state.Spec, input.Volume)
+	if state.Margin == nil || required <= 0 || state.Margin.Equity <= 0 {
+		return
+	}
+	c.limit("free_margin", required > state.Margin.FreeMargin, "insufficient_free_margin", required, state.Margin.FreeMargin)
+	if floorPct > 0 {
+		level := ProjectedMarginLevel(*state.Margin, required)
+		c.limit("margin_level", level < floorPct, "margin_level_below_floor", level, floorPct)
+	}
+}
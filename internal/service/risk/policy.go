@@ -0,0 +1,265 @@
+package risk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PolicyLimits are the thresholds one policy layer sets. A nil field
+// inherits from the layer below.
+type PolicyLimits struct {
+	MaxOpenPositions       *int     `json:"max_open_positions,omitempty"`
+	MaxDailyLossPct        *float64 `json:"max_daily_loss_pct,omitempty"`
+	MinConfidence          *float64 `json:"min_confidence,omitempty"`
+	MaxSpreadPips          *float64 `json:"max_spread_pips,omitempty"`
+	MaxPositionsPerSymbol  *int     `json:"max_positions_per_symbol,omitempty"`
+	MaxLotsPerSymbol       *float64 `json:"max_lots_per_symbol,omitempty"`
+	MaxCurrencyLots        *float64 `json:"max_currency_exposure_lots,omitempty"`
+	MaxCorrelatedPositions *int     `json:"max_correlated_positions,omitempty"`
+	MaxTradesPerDay        *int     `json:"max_trades_per_day,omitempty"`
+	MinMarginLevelPct      *float64 `json:"min_margin_level_pct,omitempty"`
+	MaxAvgSlippagePips     *float64 `json:"max_avg_slippage_pips,omitempty"`
+}
+
+// Policy layers thresholds over the engine's constructor values: defaults,
+// then the account's overrides, then the symbol's.
+type Policy struct {
+	Defaults PolicyLimits            `json:"defaults"`
+	Accounts map[string]PolicyLimits `json:"accounts,omitempty"`
+	Symbols  map[string]PolicyLimits `json:"symbols,omitempty"`
+}
+
+// Thresholds are the limits in force for one account and symbol. A zero
+// exposure, trades-per-day, margin level or slippage field disables its
+// rule; max open positions and max daily loss are always enforced.
+type Thresholds struct {
+	MaxOpenPositions       int     `json:"max_open_positions"`
+	MaxDailyLossPct        float64 `json:"max_daily_loss_pct"`
+	MinConfidence          float64 `json:"min_confidence"`
+	MaxSpreadPips          float64 `json:"max_spread_pips"`
+	MaxPositionsPerSymbol  int     `json:"max_positions_per_symbol"`
+	MaxLotsPerSymbol       float64 `json:"max_lots_per_symbol"`
+	MaxCurrencyLots        float64 `json:"max_currency_exposure_lots"`
+	MaxCorrelatedPositions int     `json:"max_correlated_positions"`
+	MaxTradesPerDay        int     `json:"max_trades_per_day"`
+	MinMarginLevelPct      float64 `json:"min_margin_level_pct"`
+	MaxAvgSlippagePips     float64 `json:"max_avg_slippage_pips"`
+}
+
+func (t Thresholds) exposure() ExposureLimits {
+	return ExposureLimits{
+		MaxPositionsPerSymbol:  t.MaxPositionsPerSymbol,
+		MaxLotsPerSymbol:       t.MaxLotsPerSymbol,
+		MaxCurrencyLots:        t.MaxCurrencyLots,
+		MaxCorrelatedPositions: t.MaxCorrelatedPositions,
+	}
+}
+
+func (t Thresholds) apply(l PolicyLimits) Thresholds {
+	setInt := func(dst *int, v *int) {
+		if v != nil {
+			*dst = *v
+		}
+	}
+	setFloat := func(dst *float64, v *float64) {
+		if v != nil {
+			*dst = *v
+		}
+	}
+	setInt(&t.MaxOpenPositions, l.MaxOpenPositions)
+	setFloat(&t.MaxDailyLossPct, l.MaxDailyLossPct)
+	setFloat(&t.MinConfidence, l.MinConfidence)
+	setFloat(&t.MaxSpreadPips, l.MaxSpreadPips)
+	setInt(&t.MaxPositionsPerSymbol, l.MaxPositionsPerSymbol)
+	setFloat(&t.MaxLotsPerSymbol, l.MaxLotsPerSymbol)
+	setFloat(&t.MaxCurrencyLots, l.MaxCurrencyLots)
+	setInt(&t.MaxCorrelatedPositions, l.MaxCorrelatedPositions)
+	setInt(&t.MaxTradesPerDay, l.MaxTradesPerDay)
+	setFloat(&t.MinMarginLevelPct, l.MinMarginLevelPct)
+	setFloat(&t.MaxAvgSlippagePips, l.MaxAvgSlippagePips)
+	return t
+}
+
+// Resolve layers the policy over base for an account and symbol.
+func (p Policy) Resolve(base Thresholds, accountID, symbol string) Thresholds {
+	t := base.apply(p.Defaults)
+	if l, ok := p.Accounts[strings.TrimSpace(accountID)]; ok {
+		t = t.apply(l)
+	}
+	if l, ok := p.Symbols[strings.ToUpper(strings.TrimSpace(symbol))]; ok {
+		t = t.apply(l)
+	}
+	return t
+}
+
+// Validate rejects negative limits, a max open positions or max daily loss
+// that is not positive, a confidence outside [0, 1], empty keys
+// and trade counts on symbols, which are tracked per account.
+func (p Policy) Validate() error {
+	if err := p.Defaults.validate(); err != nil {
+		return fmt.Errorf("defaults: %w", err)
+	}
+	for id, l := range p.Accounts {
+		if strings.TrimSpace(id) == "" {
+			return errors.New("accounts: empty account id")
+		}
+		if err := l.validate(); err != nil {
+			return fmt.Errorf("accounts.%s: %w", id, err)
+		}
+	}
+	for symbol, l := range p.Symbols {
+		if strings.TrimSpace(symbol) == "" {
+			return errors.New("symbols: empty symbol")
+		}
+		if l.MaxTradesPerDay != nil {
+			return fmt.Errorf("symbols.%s: max_trades_per_day is counted per account", symbol)
+		}
+		if err := l.validate(); err != nil {
+			return fmt.Errorf("symbols.%s: %w", symbol, err)
+		}
+	}
+	return nil
+}
+
+func (l PolicyLimits) validate() error {
+	ints := map[string]*int{
+		"max_open_positions":       l.MaxOpenPositions,
+		"max_positions_per_symbol": l.MaxPositionsPerSymbol,
+		"max_correlated_positions": l.MaxCorrelatedPositions,
+		"max_trades_per_day":       l.MaxTradesPerDay,
+	}
+	for name, v := range ints {
+		if v != nil && *v < 0 {
+			return fmt.Errorf("%s must not be negative", name)
+		}
+	}
+	floats := map[string]*float64{
+		"max_daily_loss_pct":         l.MaxDailyLossPct,
+		"min_confidence":             l.MinConfidence,
+		"max_spread_pips":            l.MaxSpreadPips,
+		"max_lots_per_symbol":        l.MaxLotsPerSymbol,
+		"max_currency_exposure_lots": l.MaxCurrencyLots,
+		"min_margin_level_pct":       l.MinMarginLevelPct,
+		"max_avg_slippage_pips":      l.MaxAvgSlippagePips,
+	}
+	for name, v := range floats {
+		if v != nil && *v < 0 {
+			return fmt.Errorf("%s must not be negative", name)
+		}
+	}
+	if l.MaxOpenPositions != nil && *l.MaxOpenPositions == 0 {
+		return errors.New("max_open_positions must be positive")
+	}
+	if l.MaxDailyLossPct != nil && *l.MaxDailyLossPct == 0 {
+		return errors.New("max_daily_loss_pct must be positive")
+	}
+	if l.MinConfidence != nil && *l.MinConfidence > 1 {
+		return errors.New("min_confidence must be between 0 and 1")
+	}
+	return nil
+}
+
+// ParsePolicy reads a JSON policy, rejecting unknown fields, and normalizes
+// account IDs and symbols.
+func ParsePolicy(r io.Reader) (Policy, error) {
+	var p Policy
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return Policy{}, fmt.Errorf("decode risk policy: %w", err)
+	}
+	accounts := make(map[string]PolicyLimits, len(p.Accounts))
+	for id, l := range p.Accounts {
+		accounts[strings.TrimSpace(id)] = l
+	}
+	symbols := make(map[string]PolicyLimits, len(p.Symbols))
+	for symbol, l := range p.Symbols {
+		key := strings.ToUpper(strings.TrimSpace(symbol))
+		if _, dup := symbols[key]; dup {
+			return Policy{}, fmt.Errorf("symbols: %s is listed twice", key)
+		}
+		symbols[key] = l
+	}
+	p.Accounts, p.Symbols = accounts, symbols
+	if err := p.Validate(); err != nil {
+		return Policy{}, err
+	}
+	return p, nil
+}
+
+// LoadPolicy reads a policy file. Only JSON is supported; a .yaml or .yml
+// path is rejected up front instead of failing as malformed JSON.
+func LoadPolicy(path string) (Policy, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return Policy{}, fmt.Errorf("risk policy %s: YAML is not supported, use JSON", path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return Policy{}, err
+	}
+	defer f.Close()
+	return ParsePolicy(f)
+}
+
+// PolicyChange is one threshold that differs between two policies. Scope
+// is "defaults", "accounts.<id>" or "symbols.<symbol>"; a nil side means
+// the field was not set there.
+type PolicyChange struct {
+	Scope  string      `json:"scope"`
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// DiffPolicies lists every threshold that differs, sorted by scope and field.
+func DiffPolicies(before, after Policy) []PolicyChange {
+	var out []PolicyChange
+	diff := func(scope string, a, b PolicyLimits) {
+		am, bm := limitFields(a), limitFields(b)
+		for field := range unionKeys(am, bm) {
+			if av, bv := am[field], bm[field]; av != bv {
+				out = append(out, PolicyChange{Scope: scope, Field: field, Before: av, After: bv})
+			}
+		}
+	}
+	diff("defaults", before.Defaults, after.Defaults)
+	for id := range unionKeys(before.Accounts, after.Accounts) {
+		diff("accounts."+id, before.Accounts[id], after.Accounts[id])
+	}
+	for symbol := range unionKeys(before.Symbols, after.Symbols) {
+		diff("symbols."+symbol, before.Symbols[symbol], after.Symbols[symbol])
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Scope != out[j].Scope {
+			return out[i].Scope < out[j].Scope
+		}
+		return out[i].Field < out[j].Field
+	})
+	return out
+}
+
+// limitFields flattens the set fields of a layer by their JSON names.
+func limitFields(l PolicyLimits) map[string]interface{} {
+	raw, _ := json.Marshal(l)
+	out := make(map[string]interface{})
+	_ = json.Unmarshal(raw, &out)
+	return out
+}
+
+func unionKeys[V any](a, b map[string]V) map[string]struct{} {
+	out := make(map[string]struct{}, len(a)+len(b))
+	for k := range a {
+		out[k] = struct{}{}
+	}
+	for k := range b {
+		out[k] = struct{}{}
+	}
+	return out
+}
@@ -0,0 +1,126 @@
+package risk
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"mmbot/internal/domain"
+)
+
+const testPolicy = `{
+	"defaults": {"max_spread_pips": 1.5, "max_trades_per_day": 6},
+	"accounts": {" live-1 ": {"max_open_positions": 1, "max_spread_pips": 1.0}},
+	"symbols": {"xauusd": {"max_spread_pips": 30, "max_lots_per_symbol": 0.5}}
+}`
+
+func TestParsePolicy_ResolvesLayers(t *testing.T) {
+	p, err := ParsePolicy(strings.NewReader(testPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := Thresholds{MaxOpenPositions: 3, MaxDailyLossPct: 2, MinConfidence: 0.7, MaxSpreadPips: 2, MaxTradesPerDay: 10}
+
+	got := p.Resolve(base, "paper-1", "EURUSD")
+	if got.MaxSpreadPips != 1.5 || got.MaxOpenPositions != 3 || got.MaxTradesPerDay != 6 {
+		t.Fatalf("expected defaults over base, got %+v", got)
+	}
+	got = p.Resolve(base, "live-1", "EURUSD")
+	if got.MaxSpreadPips != 1.0 || got.MaxOpenPositions != 1 || got.MinConfidence != 0.7 {
+		t.Fatalf("expected account override, got %+v", got)
+	}
+	got = p.Resolve(base, "live-1", "xauusd")
+	if got.MaxSpreadPips != 30 || got.MaxLotsPerSymbol != 0.5 || got.MaxOpenPositions != 1 {
+		t.Fatalf("expected symbol override on top of account, got %+v", got)
+	}
+}
+
+func TestParsePolicy_Rejects(t *testing.T) {
+	cases := map[string]string{
+		"unknown field":         `{"defaults": {"max_spred_pips": 1}}`,
+		"negative":              `{"defaults": {"max_open_positions": -1}}`,
+		"zero open positions":   `{"defaults": {"max_open_positions": 0}}`,
+		"zero daily loss":       `{"accounts": {"a": {"max_daily_loss_pct": 0}}}`,
+		"confidence above one":  `{"accounts": {"a": {"min_confidence": 1.5}}}`,
+		"symbol trade count":    `{"symbols": {"EURUSD": {"max_trades_per_day": 2}}}`,
+		"duplicate symbol case": `{"symbols": {"EURUSD": {}, "eurusd": {}}}`,
+		"malformed":             `{"defaults": `,
+	}
+	for name, raw := range cases {
+		if _, err := ParsePolicy(strings.NewReader(raw)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLoadPolicy_RejectsYAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte("defaults:\n  max_open_positions: 2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err := LoadPolicy(path)
+	if err == nil || !strings.Contains(err.Error(), "YAML is not supported") {
+		t.Fatalf("expected YAML to be rejected, got %v", err)
+	}
+}
+
+func TestDiffPolicies(t *testing.T) {
+	before, err := ParsePolicy(strings.NewReader(testPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, err := ParsePolicy(strings.NewReader(`{
+		"defaults": {"max_spread_pips": 1.5, "max_trades_per_day": 4},
+		"symbols": {"XAUUSD": {"max_spread_pips": 30, "max_lots_per_symbol": 0.5}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := DiffPolicies(before, after)
+	want := []PolicyChange{
+		{Scope: "accounts.live-1", Field: "max_open_positions", Before: 1.0, After: nil},
+		{Scope: "accounts.live-1", Field: "max_spread_pips", Before: 1.0, After: nil},
+		{Scope: "defaults", Field: "max_trades_per_day", Before: 6.0, After: 4.0},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("expected %d changes, got %+v", len(want), changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Fatalf("change %d: expected %+v, got %+v", i, want[i], changes[i])
+		}
+	}
+	if len(DiffPolicies(after, after)) != 0 {
+		t.Fatal("expected no changes between identical policies")
+	}
+}
+
+func TestEngine_PolicyOverridesThresholds(t *testing.T) {
+	p, err := ParsePolicy(strings.NewReader(testPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := NewEngine(3, 2.0, 0.70, 2.0).WithPolicy(p)
+	signal := domain.SignalInput{AccountID: "paper-1", Symbol: "EURUSD", Side: "BUY", Confidence: 0.9, SpreadPips: 1.8, StopLossPips: 10}
+	if d := engine.Evaluate(signal, domain.StrategyState{}); d.Allowed || d.DenyReason != "spread_too_high" {
+		t.Fatalf("expected policy default spread cap, got %+v", d)
+	}
+	signal.Symbol = "XAUUSD"
+	signal.SpreadPips = 25
+	if d := engine.Evaluate(signal, domain.StrategyState{}); !d.Allowed {
+		t.Fatalf("expected symbol override to allow a wider spread, got %+v", d)
+	}
+
+	now := time.Now().UTC()
+	if _, reached := engine.RecordOpen(domain.TradeActivity{AccountID: "paper-1", Day: now.Format("2006-01-02"), TradesToday: 5}, now); !reached {
+		t.Fatal("expected the policy trades-per-day limit to be reported as reached")
+	}
+	if prev := engine.SetPolicy(Policy{}); prev.Defaults.MaxSpreadPips == nil {
+		t.Fatal("expected SetPolicy to return the replaced policy")
+	}
+	if got := engine.Thresholds("live-1", "EURUSD").MaxSpreadPips; got != 2.0 {
+		t.Fatalf("expected constructor spread cap after clearing the policy, got %v", got)
+	}
+}
@@ -0,0 +1,74 @@
+package risk
+
+import (
+	"time"
+
+	"mmbot/internal/domain"
+)
+
+// StreakLimits pause new opens after a run of losing trades and cap executed
+// opens per trading day. A zero field disables its rule.
+type StreakLimits struct {
+	MaxConsecutiveLosses int
+	LossCooldown         time.Duration
+	MaxTradesPerDay      int
+}
+
+// WithStreakLimits enables the loss-streak cooldown and trades-per-day rules.
+func (e *Engine) WithStreakLimits(limits StreakLimits) *Engine {
+	e.streak = limits
+	return e
+}
+
+// RecordOpen counts an executed OPEN. It reports true when this open uses up
+// the account's allowance for the day.
+func (e *Engine) RecordOpen(a domain.TradeActivity, at time.Time) (domain.TradeActivity, bool) {
+	day, _ := e.TradingDay(a.AccountID)
+	a = day.Roll(a, at)
+	a.TradesToday++
+	a.UpdatedAt = at.UTC()
+	limit := e.Thresholds(a.AccountID, "").MaxTradesPerDay
+	return a, limit > 0 && a.TradesToday == limit
+}
+
+// RecordClose folds a closed trade's net profit into the loss streak. A loss
+// extends the streak and a win or break-even resets it. Reaching
+// MaxConsecutiveLosses starts the cooldown and resets the streak; it reports
+// true when that happens.
+func (e *Engine) RecordClose(a domain.TradeActivity, netProfit float64, at time.Time) (domain.TradeActivity, bool) {
+	at = at.UTC()
+	day, _ := e.TradingDay(a.AccountID)
+	a = day.Roll(a, at)
+	a.LastCloseAt = at
+	a.UpdatedAt = at
+	if netProfit >= 0 {
+		a.LossStreak = 0
+		return a, false
+	}
+	a.LossStreak++
+	if e.streak.MaxConsecutiveLosses <= 0 || a.LossStreak < e.streak.MaxConsecutiveLosses || e.streak.LossCooldown <= 0 {
+		return a, false
+	}
+	a.CooldownUntil = at.Add(e.streak.LossCooldown)
+	a.LossStreak = 0
+	return a, true
+}
+
+func streakChecks(c *checklist, maxTradesPerDay int, state domain.StrategyState) {
+	now := state.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	if maxTradesPerDay > 0 {
+		c.limit("trades_per_day", state.TradesToday >= maxTradesPerDay, "max_trades_per_day_reached", state.TradesToday, maxTradesPerDay)
+	}
+	if !state.LossCooldownUntil.IsZero() {
+		c.add(domain.RiskCheck{
+			Rule:      "loss_streak_cooldown",
+			Passed:    !now.Before(state.LossCooldownUntil),
+			Reason:    "loss_streak_cooldown_active",
+			Observed:  now.UTC().Format(time.RFC3339),
+			Threshold: state.LossCooldownUntil.UTC().Format(time.RFC3339),
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package risk
+
+import (
+	"testing"
+	"time"
+
+	"mmbot/internal/domain"
+)
+
+func TestRecordClose_StartsCooldownAfterLossStreak(t *testing.T) {
+	engine := NewEngine(3, 2.0, 0.70, 2.0).WithStreakLimits(StreakLimits{MaxConsecutiveLosses: 3, LossCooldown: 2 * time.Hour})
+	at := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	var a domain.TradeActivity
+	var started bool
+
+	a, _ = engine.RecordClose(a, -10, at)
+	a, _ = engine.RecordClose(a, 5, at.Add(time.Minute))
+	if a.LossStreak != 0 {
+		t.Fatalf("expected a win to reset the streak, got %d", a.LossStreak)
+	}
+	for i := 0; i < 2; i++ {
+		if a, started = engine.RecordClose(a, -10, at.Add(time.Duration(i+2)*time.Minute)); started {
+			t.Fatalf("cooldown started after %d losses", i+1)
+		}
+	}
+	a, started = engine.RecordClose(a, -0.5, at.Add(10*time.Minute))
+	if !started || !a.CooldownUntil.Equal(at.Add(10*time.Minute+2*time.Hour)) || a.LossStreak != 0 {
+		t.Fatalf("expected cooldown after third loss, got %+v started=%v", a, started)
+	}
+
+	input := domain.SignalInput{Symbol: "EURUSD", Side: "BUY", Confidence: 0.9, SpreadPips: 1.0, StopLossPips: 10}
+	state := domain.StrategyState{LossCooldownUntil: a.CooldownUntil, Now: at.Add(time.Hour)}
+	if d := engine.Evaluate(input, state); d.Allowed || d.DenyReason != "loss_streak_cooldown_active" {
+		t.Fatalf("expected loss_streak_cooldown_active, got %+v", d)
+	}
+	state.Now = a.CooldownUntil
+	if d := engine.Evaluate(input, state); !d.Allowed {
+		t.Fatalf("expected cooldown to expire, got %+v", d)
+	}
+}
+
+func TestRecordOpen_CapsTradesPerDay(t *testing.T) {
+	engine := NewEngine(3, 2.0, 0.70, 2.0).WithStreakLimits(StreakLimits{MaxTradesPerDay: 2})
+	at := time.Date(2026, 3, 2, 22, 0, 0, 0, time.UTC)
+	a, reached := engine.RecordOpen(domain.TradeActivity{}, at)
+	if reached || a.TradesToday != 1 || a.Day != "2026-03-02" {
+		t.Fatalf("unexpected first open %+v reached=%v", a, reached)
+	}
+	if a, reached = engine.RecordOpen(a, at.Add(time.Hour)); !reached || a.TradesToday != 2 {
+		t.Fatalf("expected second open to reach the cap, got %+v reached=%v", a, reached)
+	}
+
+	input := domain.SignalInput{Symbol: "EURUSD", Side: "BUY", Confidence: 0.9, SpreadPips: 1.0, StopLossPips: 10}
+	if d := engine.Evaluate(input, domain.StrategyState{TradesToday: a.TradesToday}); d.Allowed || d.DenyReason != "max_trades_per_day_reached" {
+		t.Fatalf("expected max_trades_per_day_reached, got %+v", d)
+	}
+	if rolled := (TradingDay{}).Roll(a, at.Add(3*time.Hour)); rolled.TradesToday != 0 || rolled.Day != "2026-03-03" {
+		t.Fatalf("expected next UTC day to reset the count, got %+v", rolled)
+	}
+}
@@ -0,0 +1,187 @@
+package risk
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"mmbot/internal/domain"
+)
+
+// TradingDay is the wall-clock boundary at which an account's trading day
+// ends, Minute minutes after midnight in Location. The zero value rolls over
+// at 00:00 UTC.
+type TradingDay struct {
+	Minute   int
+	Location *time.Location
+}
+
+func (d TradingDay) location() *time.Location {
+	if d.Location == nil {
+		return time.UTC
+	}
+	return d.Location
+}
+
+func (d TradingDay) String() string {
+	return fmt.Sprintf("%02d:%02d@%s", d.Minute/60, d.Minute%60, d.location())
+}
+
+// Start returns the boundary that opened the trading day containing t.
+func (d TradingDay) Start(t time.Time) time.Time {
+	loc := d.location()
+	local := t.In(loc)
+	start := time.Date(local.Year(), local.Month(), local.Day(), d.Minute/60, d.Minute%60, 0, 0, loc)
+	if start.After(local) {
+		start = time.Date(local.Year(), local.Month(), local.Day()-1, d.Minute/60, d.Minute%60, 0, 0, loc)
+	}
+	return start
+}
+
+// Key labels the trading day containing t with the date it ends on, so the
+// day from 17:00 Monday to 17:00 Tuesday is Tuesday's. A midnight boundary
+// keeps the calendar date.
+func (d TradingDay) Key(t time.Time) string {
+	start := d.Start(t)
+	if d.Minute == 0 {
+		return start.Format("2006-01-02")
+	}
+	return start.AddDate(0, 0, 1).Format("2006-01-02")
+}
+
+// Bounds returns the start and end of the trading day labelled key.
+func (d TradingDay) Bounds(key string) (time.Time, time.Time, error) {
+	date, err := time.ParseInLocation("2006-01-02", key, d.location())
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("trading day %q must be YYYY-MM-DD", key)
+	}
+	end := time.Date(date.Year(), date.Month(), date.Day(), d.Minute/60, d.Minute%60, 0, 0, d.location())
+	if d.Minute == 0 {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end.AddDate(0, 0, -1), end, nil
+}
+
+// Next returns the key of the trading day after key.
+func (d TradingDay) Next(key string) (string, error) {
+	_, end, err := d.Bounds(key)
+	if err != nil {
+		return "", err
+	}
+	return d.Key(end), nil
+}
+
+// WeekStart returns the boundary that opened the trading week containing t,
+// i.e. the start of the trading day labelled with that week's Monday.
+func (d TradingDay) WeekStart(t time.Time) time.Time {
+	date, _ := time.Parse("2006-01-02", d.Key(t))
+	monday := date.AddDate(0, 0, -((int(date.Weekday()) + 6) % 7))
+	start, _, _ := d.Bounds(monday.Format("2006-01-02"))
+	return start.UTC()
+}
+
+// MonthStart returns the boundary that opened the trading month containing
+// t, i.e. the start of the trading day labelled with the month's 1st.
+func (d TradingDay) MonthStart(t time.Time) time.Time {
+	date, _ := time.Parse("2006-01-02", d.Key(t))
+	first := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+	start, _, _ := d.Bounds(first.Format("2006-01-02"))
+	return start.UTC()
+}
+
+// Roll starts a new day on the activity when at falls in a later trading
+// day: the trade count, the day's starting equity and the daily breaker
+// mark reset, and the loss streak carries over.
+func (d TradingDay) Roll(a domain.TradeActivity, at time.Time) domain.TradeActivity {
+	if day := d.Key(at); day != a.Day {
+		a.Day = day
+		a.TradesToday = 0
+		a.DayStartEquity = 0
+		a.DailyBreakerAt = time.Time{}
+	}
+	return a
+}
+
+// TradingDays maps accounts to their trading day. Accounts without their
+// own boundary use the default one, if set.
+type TradingDays struct {
+	Default    TradingDay
+	DefaultSet bool
+	Accounts   map[string]TradingDay
+}
+
+// Lookup returns the account's trading day and whether a boundary was
+// configured for it.
+func (d TradingDays) Lookup(accountID string) (TradingDay, bool) {
+	if day, ok := d.Accounts[strings.TrimSpace(accountID)]; ok {
+		return day, true
+	}
+	return d.Default, d.DefaultSet
+}
+
+// WithTradingDays sets the boundaries that trade counts roll over at.
+func (e *Engine) WithTradingDays(days TradingDays) *Engine {
+	e.days = days
+	return e
+}
+
+// TradingDay returns the account's trading day and whether a boundary was
+// configured for it; without one the day rolls over at 00:00 UTC.
+func (e *Engine) TradingDay(accountID string) (TradingDay, bool) {
+	return e.days.Lookup(accountID)
+}
+
+// ParseTradingDay reads "17:00@America/New_York". Without a zone the
+// boundary is read in UTC.
+func ParseTradingDay(raw string) (TradingDay, error) {
+	clock, zone, _ := strings.Cut(strings.TrimSpace(raw), "@")
+	var h, m int
+	if _, err := fmt.Sscanf(strings.TrimSpace(clock), "%d:%d", &h, &m); err != nil || h < 0 || h > 23 || m < 0 || m > 59 {
+		return TradingDay{}, fmt.Errorf("trading day boundary %q must be HH:MM[@zone]", raw)
+	}
+	day := TradingDay{Minute: h*60 + m, Location: time.UTC}
+	if zone = strings.TrimSpace(zone); zone != "" {
+		loc, err := time.LoadLocation(zone)
+		if err != nil {
+			return TradingDay{}, fmt.Errorf("trading day boundary %q: %w", raw, err)
+		}
+		day.Location = loc
+	}
+	return day, nil
+}
+
+// ParseTradingDays reads a default boundary and per-account overrides such
+// as "live-1=17:00@America/New_York,paper-1=00:00". An empty default leaves
+// unlisted accounts on 00:00 UTC.
+func ParseTradingDays(defaultRaw, accountsRaw string) (TradingDays, error) {
+	var days TradingDays
+	if strings.TrimSpace(defaultRaw) != "" {
+		day, err := ParseTradingDay(defaultRaw)
+		if err != nil {
+			return TradingDays{}, err
+		}
+		days.Default, days.DefaultSet = day, true
+	}
+	days.Accounts = make(map[string]TradingDay)
+	for _, part := range strings.Split(accountsRaw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, raw, ok := strings.Cut(part, "=")
+		if !ok || strings.TrimSpace(id) == "" {
+			return TradingDays{}, fmt.Errorf("trading day %q must be account=HH:MM[@zone]", part)
+		}
+		day, err := ParseTradingDay(raw)
+		if err != nil {
+			return TradingDays{}, fmt.Errorf("trading day for %s: %w", strings.TrimSpace(id), err)
+		}
+		days.Accounts[strings.TrimSpace(id)] = day
+	}
+	return days, nil
+}
+
+// DayLossPct is the fall from the trading day's starting equity in percent.
+func DayLossPct(a domain.TradeActivity, equity float64) float64 {
+	return lossPct(a.DayStartEquity, equity)
+}
@@ -0,0 +1,129 @@
+package risk
+
+import (
+	"testing"
+	"time"
+
+	"mmbot/internal/domain"
+)
+
+func TestTradingDay_KeyAndBounds(t *testing.T) {
+	day, err := ParseTradingDay("17:00@America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		at   time.Time
+		want string
+	}{
+		// 16:59 New York on Monday 2 March (EST) is still Monday's day.
+		{time.Date(2026, 3, 2, 21, 59, 0, 0, time.UTC), "2026-03-02"},
+		{time.Date(2026, 3, 2, 22, 0, 0, 0, time.UTC), "2026-03-03"},
+		// After the DST switch on 8 March, 17:00 New York is 21:00 UTC.
+		{time.Date(2026, 3, 9, 20, 59, 0, 0, time.UTC), "2026-03-09"},
+		{time.Date(2026, 3, 9, 21, 0, 0, 0, time.UTC), "2026-03-10"},
+	}
+	for _, c := range cases {
+		if got := day.Key(c.at); got != c.want {
+			t.Fatalf("Key(%s) = %s, want %s", c.at, got, c.want)
+		}
+	}
+
+	start, end, err := day.Bounds("2026-03-03")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !start.Equal(time.Date(2026, 3, 2, 22, 0, 0, 0, time.UTC)) || !end.Equal(time.Date(2026, 3, 3, 22, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected bounds %s..%s", start, end)
+	}
+	if got := (TradingDay{}).Key(time.Date(2026, 3, 2, 23, 59, 0, 0, time.UTC)); got != "2026-03-02" {
+		t.Fatalf("expected the zero value to use the UTC date, got %s", got)
+	}
+	start, end, _ = TradingDay{}.Bounds("2026-03-02")
+	if !start.Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)) || end.Sub(start) != 24*time.Hour {
+		t.Fatalf("unexpected midnight bounds %s..%s", start, end)
+	}
+}
+
+func TestTradingDay_WeekAndMonthStart(t *testing.T) {
+	day, err := ParseTradingDay("17:00@America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Sunday 18:00 New York already trades Monday 19 January.
+	if got := day.WeekStart(time.Date(2026, 1, 18, 23, 0, 0, 0, time.UTC)); !got.Equal(time.Date(2026, 1, 18, 22, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the week to open Sunday 17:00 New York, got %s", got)
+	}
+	if got := day.WeekStart(time.Date(2026, 1, 16, 20, 0, 0, 0, time.UTC)); !got.Equal(time.Date(2026, 1, 11, 22, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected Friday to belong to the week opened on the 11th, got %s", got)
+	}
+	// Saturday 28 February 18:00 New York is in the day labelled 1 March.
+	if got := day.MonthStart(time.Date(2026, 2, 28, 23, 0, 0, 0, time.UTC)); !got.Equal(time.Date(2026, 2, 28, 22, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected March to open 28 February 17:00 New York, got %s", got)
+	}
+	if got := (TradingDay{}).WeekStart(time.Date(2026, 1, 14, 10, 0, 0, 0, time.UTC)); !got.Equal(time.Date(2026, 1, 12, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the zero value to start weeks Monday 00:00 UTC, got %s", got)
+	}
+}
+
+func TestTradingDay_Next(t *testing.T) {
+	day, _ := ParseTradingDay("17:00@America/New_York")
+	// The day after 8 March 2026 spans the US switch to daylight time.
+	for key, want := range map[string]string{"2026-03-08": "2026-03-09", "2026-12-31": "2027-01-01"} {
+		if got, err := day.Next(key); err != nil || got != want {
+			t.Errorf("Next(%s) = %q, %v; want %s", key, got, err, want)
+		}
+	}
+	if got, _ := (TradingDay{}).Next("2026-02-28"); got != "2026-03-01" {
+		t.Errorf("expected the zero value to keep calendar dates, got %s", got)
+	}
+	if _, err := day.Next("soon"); err == nil {
+		t.Error("expected an invalid key to be rejected")
+	}
+}
+
+func TestTradingDay_RollResetsDailyFields(t *testing.T) {
+	day, _ := ParseTradingDay("17:00@America/New_York")
+	a := domain.TradeActivity{
+		Day:            "2026-03-02",
+		TradesToday:    4,
+		LossStreak:     2,
+		DayStartEquity: 10000,
+		DailyBreakerAt: time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC),
+	}
+	same := day.Roll(a, time.Date(2026, 3, 2, 21, 0, 0, 0, time.UTC))
+	if same.TradesToday != 4 || same.DayStartEquity != 10000 {
+		t.Fatalf("expected no rollover before the boundary, got %+v", same)
+	}
+	next := day.Roll(a, time.Date(2026, 3, 2, 22, 30, 0, 0, time.UTC))
+	if next.Day != "2026-03-03" || next.TradesToday != 0 || next.DayStartEquity != 0 || !next.DailyBreakerAt.IsZero() || next.LossStreak != 2 {
+		t.Fatalf("expected a fresh day with the streak carried over, got %+v", next)
+	}
+	if got := DayLossPct(domain.TradeActivity{DayStartEquity: 10000}, 9750); got != 2.5 {
+		t.Fatalf("expected 2.5%% day loss, got %v", got)
+	}
+}
+
+func TestParseTradingDays(t *testing.T) {
+	days, err := ParseTradingDays("", "live-1=17:00@America/New_York, paper-1=02:30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, ok := days.Lookup("live-1"); !ok || d.String() != "17:00@America/New_York" {
+		t.Fatalf("unexpected live-1 boundary %v %v", d, ok)
+	}
+	if d, ok := days.Lookup("paper-1"); !ok || d.Minute != 150 || d.location() != time.UTC {
+		t.Fatalf("unexpected paper-1 boundary %v %v", d, ok)
+	}
+	if _, ok := days.Lookup("other"); ok {
+		t.Fatal("expected no boundary without a default")
+	}
+	for _, raw := range []string{"24:00", "17:00@Mars/Olympus", "noon"} {
+		if _, err := ParseTradingDay(raw); err == nil {
+			t.Fatalf("expected %q to be rejected", raw)
+		}
+	}
+	if _, err := ParseTradingDays("", "=17:00"); err == nil {
+		t.Fatal("expected an empty account id to be rejected")
+	}
+}
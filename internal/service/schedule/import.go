@@ -0,0 +1,256 @@
+package schedule
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"mmbot/internal/domain"
+)
+
+const (
+	SourceAdmin = "admin"
+	SourceICS   = "ics"
+	SourceCSV   = "csv"
+)
+
+// BlackoutID derives a stable ID so re-importing a calendar updates entries
+// instead of duplicating them.
+func BlackoutID(source, key string) string {
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(source+":"+key)).String()
+}
+
+// ParseICS reads the VEVENTs of an iCalendar feed. The currency comes from
+// CATEGORIES or X-CURRENCY, the impact from X-IMPACT or PRIORITY. Floating
+// times are read in loc; an event without DTEND is a point in time.
+func ParseICS(r io.Reader, loc *time.Location) ([]domain.Blackout, error) {
+	lines, err := unfoldICS(r)
+	if err != nil {
+		return nil, err
+	}
+	var out []domain.Blackout
+	var cur map[string]icsProp
+	for i, line := range lines {
+		switch {
+		case line == "BEGIN:VEVENT":
+			cur = make(map[string]icsProp)
+		case line == "END:VEVENT":
+			if cur == nil {
+				return nil, fmt.Errorf("line %d: END:VEVENT without BEGIN", i+1)
+			}
+			b, err := icsEvent(cur, loc)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			out = append(out, b)
+			cur = nil
+		case cur != nil:
+			name, prop := parseICSLine(line)
+			if _, seen := cur[name]; !seen {
+				cur[name] = prop
+			}
+		}
+	}
+	if len(out) == 0 {
+		return nil, errors.New("no VEVENT entries found")
+	}
+	return out, nil
+}
+
+type icsProp struct {
+	params map[string]string
+	value  string
+}
+
+func unfoldICS(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+func parseICSLine(line string) (string, icsProp) {
+	head, value, _ := strings.Cut(line, ":")
+	parts := strings.Split(head, ";")
+	prop := icsProp{params: make(map[string]string), value: value}
+	for _, p := range parts[1:] {
+		k, v, _ := strings.Cut(p, "=")
+		prop.params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+	return strings.ToUpper(parts[0]), prop
+}
+
+func icsEvent(props map[string]icsProp, loc *time.Location) (domain.Blackout, error) {
+	startProp, ok := props["DTSTART"]
+	if !ok {
+		return domain.Blackout{}, errors.New("VEVENT without DTSTART")
+	}
+	start, err := icsTime(startProp, loc)
+	if err != nil {
+		return domain.Blackout{}, fmt.Errorf("DTSTART: %w", err)
+	}
+	end := start
+	if endProp, ok := props["DTEND"]; ok {
+		if end, err = icsTime(endProp, loc); err != nil {
+			return domain.Blackout{}, fmt.Errorf("DTEND: %w", err)
+		}
+	}
+	if end.Before(start) {
+		return domain.Blackout{}, errors.New("DTEND before DTSTART")
+	}
+	currency := props["X-CURRENCY"].value
+	if currency == "" {
+		currency, _, _ = strings.Cut(props["CATEGORIES"].value, ",")
+	}
+	impact := props["X-IMPACT"].value
+	if impact == "" {
+		impact = priorityImpact(props["PRIORITY"].value)
+	}
+	title := icsUnescape(props["SUMMARY"].value)
+	key := props["UID"].value
+	if key == "" {
+		key = start.Format(time.RFC3339) + "|" + currency + "|" + title
+	}
+	return domain.Blackout{
+		ID:       BlackoutID(SourceICS, key),
+		Title:    title,
+		Currency: strings.ToUpper(strings.TrimSpace(currency)),
+		Impact:   strings.ToLower(strings.TrimSpace(impact)),
+		Start:    start,
+		End:      end,
+		Source:   SourceICS,
+	}, nil
+}
+
+func icsTime(p icsProp, loc *time.Location) (time.Time, error) {
+	v := strings.TrimSpace(p.value)
+	if p.params["VALUE"] == "DATE" || len(v) == 8 {
+		return time.ParseInLocation("20060102", v, loc)
+	}
+	if strings.HasSuffix(v, "Z") {
+		return time.Parse("20060102T150405Z", v)
+	}
+	if tzid := p.params["TZID"]; tzid != "" {
+		zone, err := time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unknown TZID %q", tzid)
+		}
+		loc = zone
+	}
+	return time.ParseInLocation("20060102T150405", v, loc)
+}
+
+// priorityImpact maps iCalendar PRIORITY (1 highest, 9 lowest) to an impact.
+func priorityImpact(raw string) string {
+	switch strings.TrimSpace(raw) {
+	case "1", "2", "3":
+		return "high"
+	case "4", "5", "6":
+		return "medium"
+	case "7", "8", "9":
+		return "low"
+	}
+	return ""
+}
+
+func icsUnescape(s string) string {
+	return strings.NewReplacer(`\,`, ",", `\;`, ";", `\n`, " ", `\N`, " ", `\\`, `\`).Replace(s)
+}
+
+// ParseCSV reads a header row naming at least start (or time) and
+// optionally end, currency, impact and title. Times are RFC 3339 or
+// "2006-01-02 15:04" in loc.
+func ParseCSV(r io.Reader, loc *time.Location) ([]domain.Blackout, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	col := make(map[string]int, len(header))
+	for i, h := range header {
+		col[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	startCol, ok := col["start"]
+	if !ok {
+		if startCol, ok = col["time"]; !ok {
+			return nil, errors.New("csv header needs a start or time column")
+		}
+	}
+	field := func(rec []string, name string) string {
+		if i, ok := col[name]; ok && i < len(rec) {
+			return strings.TrimSpace(rec[i])
+		}
+		return ""
+	}
+
+	var out []domain.Blackout
+	for line := 2; ; line++ {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if startCol >= len(rec) || strings.TrimSpace(rec[startCol]) == "" {
+			continue
+		}
+		start, err := csvTime(rec[startCol], loc)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		end := start
+		if raw := field(rec, "end"); raw != "" {
+			if end, err = csvTime(raw, loc); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			if end.Before(start) {
+				return nil, fmt.Errorf("line %d: end before start", line)
+			}
+		}
+		currency := strings.ToUpper(field(rec, "currency"))
+		title := field(rec, "title")
+		out = append(out, domain.Blackout{
+			ID:       BlackoutID(SourceCSV, start.Format(time.RFC3339)+"|"+currency+"|"+title),
+			Title:    title,
+			Currency: currency,
+			Impact:   strings.ToLower(field(rec, "impact")),
+			Start:    start,
+			End:      end,
+			Source:   SourceCSV,
+		})
+	}
+	if len(out) == 0 {
+		return nil, errors.New("no blackout rows found")
+	}
+	return out, nil
+}
+
+func csvTime(raw string, loc *time.Location) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, raw, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", raw)
+}
@@ -0,0 +1,78 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseICS(t *testing.T) {
+	feed := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"UID:nfp-2026-03",
+		"DTSTART:20260306T133000Z",
+		"SUMMARY:Non-Farm Employment Change\\, Unemployment",
+		"  Rate",
+		"CATEGORIES:USD",
+		"PRIORITY:1",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART;TZID=Europe/London:20260319T120000",
+		"DTEND;TZID=Europe/London:20260319T123000",
+		"SUMMARY:BoE rate decision",
+		"X-CURRENCY:gbp",
+		"X-IMPACT:High",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+	got, err := ParseICS(strings.NewReader(feed), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 events, got %+v", got)
+	}
+	nfp := got[0]
+	if nfp.Title != "Non-Farm Employment Change, Unemployment Rate" || nfp.Currency != "USD" || nfp.Impact != "high" {
+		t.Fatalf("unexpected first event %+v", nfp)
+	}
+	if !nfp.Start.Equal(time.Date(2026, 3, 6, 13, 30, 0, 0, time.UTC)) || !nfp.End.Equal(nfp.Start) {
+		t.Fatalf("expected point event at 13:30Z, got %s-%s", nfp.Start, nfp.End)
+	}
+	if nfp.ID != BlackoutID(SourceICS, "nfp-2026-03") {
+		t.Fatalf("expected UID-derived id, got %s", nfp.ID)
+	}
+	boe := got[1]
+	if boe.Currency != "GBP" || !boe.Start.Equal(time.Date(2026, 3, 19, 12, 0, 0, 0, time.UTC)) || boe.End.Sub(boe.Start) != 30*time.Minute {
+		t.Fatalf("unexpected second event %+v", boe)
+	}
+
+	if _, err := ParseICS(strings.NewReader("BEGIN:VCALENDAR\nEND:VCALENDAR\n"), time.UTC); err == nil {
+		t.Fatal("expected an empty calendar to be rejected")
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	raw := "time,currency,impact,title\n" +
+		"2026-03-06T13:30:00Z,usd,High,NFP\n" +
+		"2026-03-19 12:00,JPY,high,BoJ\n" +
+		",,,\n"
+	got, err := ParseCSV(strings.NewReader(raw), tokyo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Currency != "USD" || got[0].Impact != "high" {
+		t.Fatalf("unexpected rows %+v", got)
+	}
+	if !got[1].Start.Equal(time.Date(2026, 3, 19, 3, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected local time in the configured zone, got %s", got[1].Start)
+	}
+	if _, err := ParseCSV(strings.NewReader("currency,title\nUSD,NFP\n"), time.UTC); err == nil {
+		t.Fatal("expected a header without a time column to be rejected")
+	}
+	if _, err := ParseCSV(strings.NewReader("start,end\n2026-03-06 13:30,2026-03-06 13:00\n"), time.UTC); err == nil {
+		t.Fatal("expected end before start to be rejected")
+	}
+}
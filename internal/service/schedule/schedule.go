@@ -0,0 +1,254 @@
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"mmbot/internal/domain"
+	"mmbot/internal/service/fx"
+)
+
+const (
+	ReasonOutsideSession = "outside_trading_session"
+	ReasonNewsBlackout   = "news_blackout"
+)
+
+// Window is a daily trading window in minutes since local midnight. A window
+// whose end is before its start runs over midnight.
+type Window struct {
+	From int
+	To   int
+}
+
+func (w Window) contains(minute int) bool {
+	if w.From <= w.To {
+		return minute >= w.From && minute < w.To
+	}
+	return minute >= w.From || minute < w.To
+}
+
+func (w Window) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", w.From/60, w.From%60, w.To/60, w.To%60)
+}
+
+// BlackoutSource is the calendar lookup the gate reads.
+type BlackoutSource interface {
+	ListBlackouts(from, to time.Time) []domain.Blackout
+}
+
+// Gate decides whether a symbol may be opened at a given time. Sessions,
+// weekends and holidays are evaluated in Location; blackout windows are
+// absolute and widened by Before/After.
+type Gate struct {
+	Location *time.Location
+	// Sessions maps a symbol, or "*" for the default, to its allowed
+	// windows. A symbol without windows trades all day.
+	Sessions      map[string][]Window
+	BlockWeekends bool
+	// Holidays are local dates ("2006-01-02") with no trading.
+	Holidays map[string]bool
+	Before   time.Duration
+	After    time.Duration
+	// MinImpact ignores calendar entries rated below it ("low", "medium"
+	// or "high"). Entries without an impact always block.
+	MinImpact string
+	Blackouts BlackoutSource
+}
+
+// Config is the environment-level description of a gate.
+type Config struct {
+	Timezone      string
+	Sessions      string
+	Holidays      string
+	BlockWeekends bool
+	Before        time.Duration
+	After         time.Duration
+	MinImpact     string
+}
+
+func New(cfg Config, blackouts BlackoutSource) (*Gate, error) {
+	loc, err := ParseLocation(cfg.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	minImpact, err := ParseImpact(cfg.MinImpact)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := ParseSessions(cfg.Sessions)
+	if err != nil {
+		return nil, err
+	}
+	holidays, err := ParseHolidays(cfg.Holidays)
+	if err != nil {
+		return nil, err
+	}
+	return &Gate{
+		Location:      loc,
+		Sessions:      sessions,
+		BlockWeekends: cfg.BlockWeekends,
+		Holidays:      holidays,
+		Before:        cfg.Before,
+		After:         cfg.After,
+		MinImpact:     minImpact,
+		Blackouts:     blackouts,
+	}, nil
+}
+
+// Check returns the deny reason for opening symbol at t, or "" when allowed.
+func (g *Gate) Check(symbol string, spec *domain.SymbolSpec, t time.Time) string {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	local := t.In(g.location())
+	if g.BlockWeekends && (local.Weekday() == time.Saturday || local.Weekday() == time.Sunday) {
+		return ReasonOutsideSession
+	}
+	if g.Holidays[local.Format("2006-01-02")] {
+		return ReasonOutsideSession
+	}
+	if windows := g.windows(symbol); len(windows) > 0 {
+		minute := local.Hour()*60 + local.Minute()
+		open := false
+		for _, w := range windows {
+			if w.contains(minute) {
+				open = true
+				break
+			}
+		}
+		if !open {
+			return ReasonOutsideSession
+		}
+	}
+	if _, ok := g.ActiveBlackout(symbol, spec, t); ok {
+		return ReasonNewsBlackout
+	}
+	return ""
+}
+
+// ActiveBlackout returns the calendar entry blocking symbol at t, if any.
+func (g *Gate) ActiveBlackout(symbol string, spec *domain.SymbolSpec, t time.Time) (domain.Blackout, bool) {
+	if g.Blackouts == nil {
+		return domain.Blackout{}, false
+	}
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	base, quote, _ := fx.SplitPair(symbol, spec)
+	for _, b := range g.Blackouts.ListBlackouts(t.Add(-g.After), t.Add(g.Before)) {
+		if t.Before(b.Start.Add(-g.Before)) || !t.Before(b.End.Add(g.After)) {
+			continue
+		}
+		if rank, ok := impactRank[strings.ToLower(strings.TrimSpace(b.Impact))]; ok && rank < impactRank[g.MinImpact] {
+			continue
+		}
+		switch strings.ToUpper(strings.TrimSpace(b.Currency)) {
+		case "", symbol, base, quote:
+			return b, true
+		}
+	}
+	return domain.Blackout{}, false
+}
+
+func (g *Gate) windows(symbol string) []Window {
+	if w, ok := g.Sessions[symbol]; ok {
+		return w
+	}
+	return g.Sessions["*"]
+}
+
+func (g *Gate) location() *time.Location {
+	if g.Location == nil {
+		return time.UTC
+	}
+	return g.Location
+}
+
+// ParseLocation resolves a TRADING_TIMEZONE value; an empty value is UTC.
+func ParseLocation(raw string) (*time.Location, error) {
+	tz := strings.TrimSpace(raw)
+	if tz == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q: %w", tz, err)
+	}
+	return loc, nil
+}
+
+var impactRank = map[string]int{"low": 1, "medium": 2, "high": 3}
+
+// ParseImpact reads a BLACKOUT_MIN_IMPACT value; an empty value keeps every
+// calendar entry.
+func ParseImpact(raw string) (string, error) {
+	impact := strings.ToLower(strings.TrimSpace(raw))
+	if _, ok := impactRank[impact]; impact != "" && !ok {
+		return "", fmt.Errorf("impact %q must be low, medium or high", raw)
+	}
+	return impact, nil
+}
+
+// ParseSessions reads "EURUSD=07:00-20:00,USDJPY=00:00-09:00|12:00-16:00,*=00:00-24:00".
+func ParseSessions(raw string) (map[string][]Window, error) {
+	out := make(map[string][]Window)
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		symbol, spans, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("session %q must be symbol=HH:MM-HH:MM", part)
+		}
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		for _, span := range strings.Split(spans, "|") {
+			w, err := parseWindow(span)
+			if err != nil {
+				return nil, fmt.Errorf("session for %s: %w", symbol, err)
+			}
+			out[symbol] = append(out[symbol], w)
+		}
+	}
+	return out, nil
+}
+
+// ParseHolidays reads a comma-separated list of 2006-01-02 dates.
+func ParseHolidays(raw string) (map[string]bool, error) {
+	out := make(map[string]bool)
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", part); err != nil {
+			return nil, fmt.Errorf("holiday %q must be YYYY-MM-DD", part)
+		}
+		out[part] = true
+	}
+	return out, nil
+}
+
+func parseWindow(raw string) (Window, error) {
+	from, to, ok := strings.Cut(strings.TrimSpace(raw), "-")
+	if !ok {
+		return Window{}, fmt.Errorf("window %q must be HH:MM-HH:MM", raw)
+	}
+	f, err := parseClock(from)
+	if err != nil {
+		return Window{}, err
+	}
+	t, err := parseClock(to)
+	if err != nil {
+		return Window{}, err
+	}
+	if f == t {
+		return Window{}, fmt.Errorf("window %q is empty", raw)
+	}
+	return Window{From: f, To: t}, nil
+}
+
+func parseClock(raw string) (int, error) {
+	var h, m int
+	if _, err := fmt.Sscanf(strings.TrimSpace(raw), "%d:%d", &h, &m); err != nil || h < 0 || m < 0 || m > 59 || h*60+m > 24*60 {
+		return 0, fmt.Errorf("invalid time %q", raw)
+	}
+	return h*60 + m, nil
+}
@@ -0,0 +1,126 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"mmbot/internal/domain"
+)
+
+type blackoutList []domain.Blackout
+
+func (l blackoutList) ListBlackouts(from, to time.Time) []domain.Blackout { return l }
+
+func TestGate_SessionsWeekendsAndHolidays(t *testing.T) {
+	gate, err := New(Config{
+		Timezone:      "America/New_York",
+		Sessions:      "EURUSD=03:00-12:00, USDJPY=19:00-04:00|08:00-10:00",
+		Holidays:      "2026-12-25",
+		BlockWeekends: true,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ny, _ := time.LoadLocation("America/New_York")
+	at := func(day, hour, minute int) time.Time { return time.Date(2026, 3, day, hour, minute, 0, 0, ny) }
+
+	cases := []struct {
+		name   string
+		symbol string
+		t      time.Time
+		want   string
+	}{
+		{"inside window", "EURUSD", at(4, 9, 30), ""},
+		{"window end is exclusive", "eurusd", at(4, 12, 0), ReasonOutsideSession},
+		{"before window", "EURUSD", at(4, 2, 59), ReasonOutsideSession},
+		{"overnight window late", "USDJPY", at(4, 23, 0), ""},
+		{"overnight window early", "USDJPY", at(4, 3, 0), ""},
+		{"second window", "USDJPY", at(4, 9, 0), ""},
+		{"between windows", "USDJPY", at(4, 6, 0), ReasonOutsideSession},
+		{"unconfigured symbol trades all day", "GBPUSD", at(4, 23, 59), ""},
+		{"saturday local", "GBPUSD", at(7, 10, 0), ReasonOutsideSession},
+		{"holiday", "GBPUSD", time.Date(2026, 12, 25, 10, 0, 0, 0, ny), ReasonOutsideSession},
+	}
+	for _, tc := range cases {
+		if got := gate.Check(tc.symbol, nil, tc.t); got != tc.want {
+			t.Errorf("%s: got %q want %q", tc.name, got, tc.want)
+		}
+	}
+
+	// Friday 20:00 New York is already Saturday in UTC but still a weekday locally.
+	if got := gate.Check("GBPUSD", nil, time.Date(2026, 3, 7, 1, 0, 0, 0, time.UTC)); got != "" {
+		t.Fatalf("expected local Friday evening to trade, got %q", got)
+	}
+}
+
+func TestGate_NewsBlackoutWithPaddingAndCurrency(t *testing.T) {
+	nfp := time.Date(2026, 3, 6, 13, 30, 0, 0, time.UTC)
+	gate := &Gate{
+		Before: 15 * time.Minute,
+		After:  30 * time.Minute,
+		Blackouts: blackoutList{
+			{ID: "nfp", Title: "Non-Farm Payrolls", Currency: "USD", Start: nfp, End: nfp},
+			{ID: "gold", Title: "Gold auction", Currency: "XAUUSD", Start: nfp.Add(4 * time.Hour), End: nfp.Add(5 * time.Hour)},
+		},
+	}
+	cases := []struct {
+		symbol string
+		spec   *domain.SymbolSpec
+		t      time.Time
+		want   string
+	}{
+		{"EURUSD", nil, nfp.Add(-15 * time.Minute), ReasonNewsBlackout},
+		{"EURUSD", nil, nfp.Add(-16 * time.Minute), ""},
+		{"USDJPY.m", nil, nfp.Add(29 * time.Minute), ReasonNewsBlackout},
+		{"EURUSD", nil, nfp.Add(30 * time.Minute), ""},
+		{"EURGBP", nil, nfp, ""},
+		{"US30", &domain.SymbolSpec{BaseCurrency: "USD", ProfitCurrency: "USD"}, nfp, ReasonNewsBlackout},
+		{"XAUUSD", nil, nfp.Add(4*time.Hour + 10*time.Minute), ReasonNewsBlackout},
+		{"EURUSD", nil, nfp.Add(4*time.Hour + 10*time.Minute), ""},
+	}
+	for _, tc := range cases {
+		if got := gate.Check(tc.symbol, tc.spec, tc.t); got != tc.want {
+			t.Errorf("%s at %s: got %q want %q", tc.symbol, tc.t.Format(time.Kitchen), got, tc.want)
+		}
+	}
+}
+
+func TestGate_NewsBlackoutMinImpact(t *testing.T) {
+	cpi := time.Date(2026, 3, 11, 12, 30, 0, 0, time.UTC)
+	gate := &Gate{
+		MinImpact: "high",
+		Blackouts: blackoutList{
+			{ID: "cpi", Currency: "USD", Impact: "high", Start: cpi, End: cpi.Add(time.Minute)},
+			{ID: "claims", Currency: "EUR", Impact: "medium", Start: cpi, End: cpi.Add(time.Minute)},
+			{ID: "speech", Currency: "JPY", Start: cpi, End: cpi.Add(time.Minute)},
+		},
+	}
+	cases := map[string]string{
+		"EURUSD": ReasonNewsBlackout,
+		"EURGBP": "",
+		"GBPJPY": ReasonNewsBlackout,
+	}
+	for symbol, want := range cases {
+		if got := gate.Check(symbol, nil, cpi); got != want {
+			t.Errorf("%s: got %q want %q", symbol, got, want)
+		}
+	}
+	gate.MinImpact = ""
+	if got := gate.Check("EURGBP", nil, cpi); got != ReasonNewsBlackout {
+		t.Fatalf("expected every impact to block without a minimum, got %q", got)
+	}
+}
+
+func TestParseSessions_RejectsBadWindows(t *testing.T) {
+	for _, raw := range []string{"EURUSD", "EURUSD=07:00", "EURUSD=25:00-26:00", "EURUSD=08:00-08:00", "EURUSD=07:60-08:00"} {
+		if _, err := ParseSessions(raw); err == nil {
+			t.Errorf("expected %q to be rejected", raw)
+		}
+	}
+	if _, err := New(Config{Timezone: "Mars/Olympus"}, nil); err == nil {
+		t.Error("expected unknown timezone to be rejected")
+	}
+	if _, err := New(Config{MinImpact: "severe"}, nil); err == nil {
+		t.Error("expected unknown impact to be rejected")
+	}
+}
@@ -0,0 +1,115 @@
+package strategy
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// BreakoutEngine trades closes beyond the Donchian channel of the previous
+// Lookback bars. It complements TrendEngine, which only fires once the EMAs
+// have already separated.
+type BreakoutEngine struct {
+	Lookback     int     `json:"lookback"`
+	ATRLen       int     `json:"atr_len"`
+	SLATRMult    float64 `json:"sl_atr_mult"`
+	TPRiskReward float64 `json:"tp_risk_reward"`
+	MinSLPips    float64 `json:"min_sl_pips"`
+	// MinBreakATR is how far past the channel, in ATRs, the close must be.
+	MinBreakATR float64 `json:"min_break_atr"`
+}
+
+func NewBreakoutEngine() *BreakoutEngine {
+	return &BreakoutEngine{
+		Lookback:     20,
+		ATRLen:       14,
+		SLATRMult:    1.0,
+		TPRiskReward: 2.0,
+		MinSLPips:    8,
+		MinBreakATR:  0.1,
+	}
+}
+
+func (e *BreakoutEngine) Name() string { return "breakout" }
+
+func (e *BreakoutEngine) Validate() error {
+	if e.Lookback < 2 || e.ATRLen < 1 {
+		return errors.New("lookback must be at least 2 and atr_len positive")
+	}
+	if e.SLATRMult <= 0 || e.TPRiskReward <= 0 {
+		return errors.New("sl_atr_mult and tp_risk_reward must be positive")
+	}
+	if e.MinSLPips < 0 || e.MinBreakATR < 0 {
+		return errors.New("thresholds must not be negative")
+	}
+	return nil
+}
+
+func (e *BreakoutEngine) Evaluate(input TrendInput) (TrendSignal, error) {
+	if strings.TrimSpace(input.Symbol) == "" {
+		return TrendSignal{}, errors.New("symbol is required")
+	}
+	minCandles := max(e.Lookback+1, e.ATRLen+2)
+	if len(input.Candles) < minCandles {
+		return TrendSignal{}, fmt.Errorf("at least %d candles required", minCandles)
+	}
+	for i, c := range input.Candles {
+		if msg := ohlcProblem(c); msg != "" {
+			return TrendSignal{}, fmt.Errorf("candle %d: %s", i, msg)
+		}
+		if i > 0 && !c.Time.After(input.Candles[i-1].Time) {
+			return TrendSignal{}, fmt.Errorf("candle %d: times must be strictly increasing", i)
+		}
+	}
+
+	n := len(input.Candles)
+	last := input.Candles[n-1]
+	// The channel excludes the signal bar itself.
+	upper, lower := channel(input.Candles[n-1-e.Lookback : n-1])
+	atrNow := atr(input.Candles, e.ATRLen)
+	if atrNow <= 0 {
+		return TrendSignal{HasSignal: false, Reason: "no breakout"}, nil
+	}
+
+	var side string
+	var depth float64
+	switch {
+	case last.Close > upper:
+		side, depth = "BUY", (last.Close-upper)/atrNow
+	case last.Close < lower:
+		side, depth = "SELL", (lower-last.Close)/atrNow
+	default:
+		return TrendSignal{HasSignal: false, Reason: "no breakout"}, nil
+	}
+	if depth < e.MinBreakATR {
+		return TrendSignal{HasSignal: false, Reason: "breakout too shallow"}, nil
+	}
+
+	pip := input.PipSize
+	if pip <= 0 {
+		pip = PipSize(input.Symbol)
+	}
+	sl := math.Max(atrNow/pip*e.SLATRMult, e.MinSLPips)
+	direction := "above"
+	if side == "SELL" {
+		direction = "below"
+	}
+	return TrendSignal{
+		HasSignal:      true,
+		Side:           side,
+		Confidence:     minFloat(0.90, 0.60+minFloat(0.30, depth*0.3)),
+		Reason:         fmt.Sprintf("%s breakout: close %s %d-bar range by %.2f ATR", timeframeLabel(input.Timeframe), direction, e.Lookback, depth),
+		StopLossPips:   sl,
+		TakeProfitPips: sl * e.TPRiskReward,
+	}, nil
+}
+
+func channel(candles []Candle) (float64, float64) {
+	upper, lower := candles[0].High, candles[0].Low
+	for _, c := range candles[1:] {
+		upper = math.Max(upper, c.High)
+		lower = math.Min(lower, c.Low)
+	}
+	return upper, lower
+}
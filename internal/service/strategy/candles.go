@@ -0,0 +1,138 @@
+package strategy
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	IssueUnsorted       = "unsorted"
+	IssueDuplicate      = "duplicate"
+	IssueGap            = "gap"
+	IssueInvalidOHLC    = "invalid_ohlc"
+	IssueFormingBarDrop = "forming_bar_dropped"
+)
+
+// CandleIssue is one problem found while normalising a series. Rejecting
+// issues make the series unusable; the rest are fixed up or informational.
+type CandleIssue struct {
+	Code      string    `json:"code"`
+	Time      time.Time `json:"time"`
+	Message   string    `json:"message"`
+	Rejecting bool      `json:"rejecting,omitempty"`
+}
+
+type NormalizeOptions struct {
+	// Timeframe is the bar length used for gap and forming-bar checks; zero
+	// skips both.
+	Timeframe time.Duration
+	// DropFormingBar removes a last bar whose period has not ended by Now.
+	DropFormingBar bool
+	Now            time.Time
+}
+
+// NormalizeCandles sorts candles by time, keeps the last copy of duplicated
+// bars, reports gaps outside the FX weekend and optionally drops the bar that
+// is still forming. It returns an error when any bar has inconsistent OHLC.
+func NormalizeCandles(candles []Candle, opts NormalizeOptions) ([]Candle, []CandleIssue, error) {
+	issues := make([]CandleIssue, 0)
+	out := make([]Candle, 0, len(candles))
+	rejected := 0
+	for _, c := range candles {
+		if msg := ohlcProblem(c); msg != "" {
+			issues = append(issues, CandleIssue{Code: IssueInvalidOHLC, Time: c.Time, Message: msg, Rejecting: true})
+			rejected++
+			continue
+		}
+		out = append(out, c)
+	}
+	if rejected > 0 {
+		return nil, issues, fmt.Errorf("%d candle(s) with inconsistent OHLC", rejected)
+	}
+
+	if !slices.IsSortedFunc(out, compareCandleTime) {
+		issues = append(issues, CandleIssue{Code: IssueUnsorted, Message: "candles were not in time order and have been sorted"})
+		slices.SortStableFunc(out, compareCandleTime)
+	}
+
+	deduped := out[:0]
+	for _, c := range out {
+		if n := len(deduped); n > 0 && deduped[n-1].Time.Equal(c.Time) {
+			issues = append(issues, CandleIssue{Code: IssueDuplicate, Time: c.Time, Message: "duplicate bar replaced by its later copy"})
+			deduped[n-1] = c
+			continue
+		}
+		deduped = append(deduped, c)
+	}
+	out = deduped
+
+	if opts.Timeframe > 0 {
+		for i := 1; i < len(out); i++ {
+			if missing := missingTradingBars(out[i-1].Time, out[i].Time, opts.Timeframe); missing > 0 {
+				issues = append(issues, CandleIssue{
+					Code:    IssueGap,
+					Time:    out[i].Time,
+					Message: fmt.Sprintf("%d bar(s) missing before this candle", missing),
+				})
+			}
+		}
+		if opts.DropFormingBar && len(out) > 0 && !opts.Now.IsZero() {
+			last := out[len(out)-1]
+			if last.Time.Add(opts.Timeframe).After(opts.Now) {
+				issues = append(issues, CandleIssue{Code: IssueFormingBarDrop, Time: last.Time, Message: "last bar is still forming and was dropped"})
+				out = out[:len(out)-1]
+			}
+		}
+	}
+	return out, issues, nil
+}
+
+func ohlcProblem(c Candle) string {
+	switch {
+	case c.Time.IsZero():
+		return "missing time"
+	case c.Open <= 0 || c.High <= 0 || c.Low <= 0 || c.Close <= 0:
+		return "prices must be positive"
+	case c.High < c.Low:
+		return "high below low"
+	case c.Open > c.High || c.Open < c.Low:
+		return "open outside high/low range"
+	case c.Close > c.High || c.Close < c.Low:
+		return "close outside high/low range"
+	}
+	return ""
+}
+
+func compareCandleTime(a, b Candle) int {
+	return a.Time.Compare(b.Time)
+}
+
+// missingTradingBars counts bar slots strictly between prev and next that
+// fall inside FX trading hours. The market is treated as closed from Friday
+// 21:00 to Sunday 21:00 UTC.
+func missingTradingBars(prev, next time.Time, tf time.Duration) int {
+	if slots := int(next.Sub(prev) / tf); slots > 100000 {
+		// Months of missing data; the weekend adjustment no longer matters.
+		return slots - 1
+	}
+	missing := 0
+	for t := prev.Add(tf); t.Before(next); t = t.Add(tf) {
+		if !fxWeekend(t.UTC()) {
+			missing++
+		}
+	}
+	return missing
+}
+
+func fxWeekend(t time.Time) bool {
+	switch t.Weekday() {
+	case time.Saturday:
+		return true
+	case time.Friday:
+		return t.Hour() >= 21
+	case time.Sunday:
+		return t.Hour() < 21
+	}
+	return false
+}
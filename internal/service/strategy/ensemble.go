@@ -0,0 +1,205 @@
+package strategy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"mmbot/internal/domain"
+)
+
+// Vote is one member's output as recorded on the ensemble signal.
+type Vote = domain.StrategyVote
+
+const (
+	// EnsembleMajority signals when more than half of the members agree on
+	// a side; abstentions and errors count against the majority.
+	EnsembleMajority = "majority"
+	// EnsembleWeighted nets weight x confidence per side and scales it by the
+	// total weight, so the combined confidence drops when members abstain.
+	EnsembleWeighted = "weighted"
+	// EnsembleVeto follows the first member; any other member signalling the
+	// opposite side cancels the trade.
+	EnsembleVeto = "veto"
+)
+
+type Member struct {
+	Strategy Strategy
+	Weight   float64
+}
+
+// Ensemble runs several strategies on the same input and combines their
+// signals. SL/TP come from the lead voter (the most confident member on the
+// winning side) so the pair stays internally consistent.
+type Ensemble struct {
+	Mode    string
+	Members []Member
+}
+
+func NewEnsemble(mode string, members ...Member) (*Ensemble, error) {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if mode == "" {
+		mode = EnsembleMajority
+	}
+	switch mode {
+	case EnsembleMajority, EnsembleWeighted, EnsembleVeto:
+	default:
+		return nil, fmt.Errorf("unknown ensemble mode %q (want majority, weighted or veto)", mode)
+	}
+	if len(members) == 0 {
+		return nil, errors.New("ensemble needs at least one member")
+	}
+	for i := range members {
+		if members[i].Weight <= 0 {
+			members[i].Weight = 1
+		}
+	}
+	return &Ensemble{Mode: mode, Members: members}, nil
+}
+
+func (e *Ensemble) Name() string { return "ensemble" }
+
+func (e *Ensemble) Evaluate(input TrendInput) (TrendSignal, error) {
+	votes := make([]Vote, 0, len(e.Members))
+	signals := make([]TrendSignal, 0, len(e.Members))
+	var firstErr error
+	failed := 0
+	for _, m := range e.Members {
+		sig, err := m.Strategy.Evaluate(input)
+		vote := Vote{Strategy: m.Strategy.Name(), Weight: m.Weight}
+		if err != nil {
+			// A member that cannot run abstains; only fail if all of them do.
+			vote.Error = err.Error()
+			failed++
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", vote.Strategy, err)
+			}
+		} else {
+			vote.HasSignal = sig.HasSignal
+			vote.Side = sig.Side
+			vote.Confidence = sig.Confidence
+			vote.StopLossPips = sig.StopLossPips
+			vote.TakeProfitPips = sig.TakeProfitPips
+			vote.Reason = sig.Reason
+		}
+		votes = append(votes, vote)
+		signals = append(signals, sig)
+	}
+	if failed == len(e.Members) {
+		return TrendSignal{}, firstErr
+	}
+
+	var out TrendSignal
+	switch e.Mode {
+	case EnsembleWeighted:
+		out = e.weighted(votes, signals)
+	case EnsembleVeto:
+		out = e.veto(votes, signals)
+	default:
+		out = e.majority(votes, signals)
+	}
+	if out.Alignment == nil {
+		for _, sig := range signals {
+			if sig.Alignment != nil {
+				out.Alignment = sig.Alignment
+				break
+			}
+		}
+	}
+	out.Votes = votes
+	return out, nil
+}
+
+func (e *Ensemble) majority(votes []Vote, signals []TrendSignal) TrendSignal {
+	for _, side := range []string{"BUY", "SELL"} {
+		count, confSum, lead := 0, 0.0, -1
+		for i, v := range votes {
+			if !v.HasSignal || v.Side != side {
+				continue
+			}
+			count++
+			confSum += v.Confidence
+			if lead < 0 || v.Confidence > votes[lead].Confidence {
+				lead = i
+			}
+		}
+		if count*2 > len(votes) {
+			sig := signals[lead]
+			sig.Confidence = confSum / float64(count)
+			sig.Reason = fmt.Sprintf("ensemble majority %d/%d %s; lead %s: %s", count, len(votes), strings.ToLower(side), votes[lead].Strategy, sig.Reason)
+			return sig
+		}
+	}
+	return TrendSignal{HasSignal: false, Reason: fmt.Sprintf("ensemble majority not reached (%s)", tally(votes))}
+}
+
+func (e *Ensemble) weighted(votes []Vote, signals []TrendSignal) TrendSignal {
+	total, net := 0.0, 0.0
+	lead := -1
+	for _, v := range votes {
+		total += v.Weight
+		switch {
+		case v.HasSignal && v.Side == "BUY":
+			net += v.Weight * v.Confidence
+		case v.HasSignal && v.Side == "SELL":
+			net -= v.Weight * v.Confidence
+		}
+	}
+	side := "BUY"
+	if net < 0 {
+		side = "SELL"
+	}
+	for i, v := range votes {
+		if v.HasSignal && v.Side == side && (lead < 0 || v.Weight*v.Confidence > votes[lead].Weight*votes[lead].Confidence) {
+			lead = i
+		}
+	}
+	if net == 0 || lead < 0 {
+		return TrendSignal{HasSignal: false, Reason: fmt.Sprintf("ensemble weighted score is flat (%s)", tally(votes))}
+	}
+	score := net / total
+	if score < 0 {
+		score = -score
+	}
+	sig := signals[lead]
+	sig.Confidence = score
+	sig.Reason = fmt.Sprintf("ensemble weighted %s score %.2f; lead %s: %s", strings.ToLower(side), score, votes[lead].Strategy, sig.Reason)
+	return sig
+}
+
+func (e *Ensemble) veto(votes []Vote, signals []TrendSignal) TrendSignal {
+	primary := votes[0]
+	if !primary.HasSignal {
+		reason := primary.Reason
+		if primary.Error != "" {
+			reason = primary.Error
+		}
+		return TrendSignal{HasSignal: false, Reason: fmt.Sprintf("ensemble primary %s: %s", primary.Strategy, reason)}
+	}
+	for _, v := range votes[1:] {
+		if v.HasSignal && v.Side != primary.Side {
+			return TrendSignal{
+				HasSignal: false,
+				Reason:    fmt.Sprintf("ensemble %s %s vetoed by %s %s", primary.Strategy, strings.ToLower(primary.Side), v.Strategy, strings.ToLower(v.Side)),
+			}
+		}
+	}
+	sig := signals[0]
+	sig.Reason = fmt.Sprintf("ensemble primary %s not vetoed: %s", primary.Strategy, sig.Reason)
+	return sig
+}
+
+func tally(votes []Vote) string {
+	parts := make([]string, 0, len(votes))
+	for _, v := range votes {
+		switch {
+		case v.Error != "":
+			parts = append(parts, v.Strategy+" error")
+		case v.HasSignal:
+			parts = append(parts, v.Strategy+" "+strings.ToLower(v.Side))
+		default:
+			parts = append(parts, v.Strategy+" none")
+		}
+	}
+	return strings.Join(parts, ", ")
+}
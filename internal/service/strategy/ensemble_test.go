@@ -0,0 +1,118 @@
+package strategy
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fixedStrategy struct {
+	name string
+	sig  TrendSignal
+	err  error
+}
+
+func (f fixedStrategy) Name() string { return f.name }
+
+func (f fixedStrategy) Evaluate(TrendInput) (TrendSignal, error) { return f.sig, f.err }
+
+func buy(name string, conf, sl float64) fixedStrategy {
+	return fixedStrategy{name: name, sig: TrendSignal{HasSignal: true, Side: "BUY", Confidence: conf, StopLossPips: sl, TakeProfitPips: sl * 2, Reason: name + " long"}}
+}
+
+func sell(name string, conf float64) fixedStrategy {
+	return fixedStrategy{name: name, sig: TrendSignal{HasSignal: true, Side: "SELL", Confidence: conf, StopLossPips: 10, TakeProfitPips: 20, Reason: name + " short"}}
+}
+
+func none(name string) fixedStrategy {
+	return fixedStrategy{name: name, sig: TrendSignal{Reason: "no setup"}}
+}
+
+func TestEnsemble_MajorityUsesLeadVoterStops(t *testing.T) {
+	ens, err := NewEnsemble(EnsembleMajority, Member{Strategy: buy("a", 0.70, 12)}, Member{Strategy: buy("b", 0.80, 15)}, Member{Strategy: sell("c", 0.90)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sig, err := ens.Evaluate(TrendInput{Symbol: "EURUSD"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !sig.HasSignal || sig.Side != "BUY" || sig.StopLossPips != 15 {
+		t.Fatalf("expected BUY led by b, got %+v", sig)
+	}
+	if sig.Confidence < 0.749 || sig.Confidence > 0.751 || len(sig.Votes) != 3 {
+		t.Fatalf("expected mean confidence 0.75 and three votes, got %+v", sig)
+	}
+
+	ens.Members = ens.Members[:1]
+	ens.Members = append(ens.Members, Member{Strategy: none("b"), Weight: 1})
+	sig, _ = ens.Evaluate(TrendInput{Symbol: "EURUSD"})
+	if sig.HasSignal {
+		t.Fatalf("expected one of two votes to fall short of a majority, got %+v", sig)
+	}
+}
+
+func TestEnsemble_WeightedNetsOpposingVotes(t *testing.T) {
+	ens, _ := NewEnsemble(EnsembleWeighted, Member{Strategy: buy("a", 0.80, 12), Weight: 3}, Member{Strategy: sell("b", 0.60), Weight: 1})
+	sig, err := ens.Evaluate(TrendInput{Symbol: "EURUSD"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// (3*0.80 - 1*0.60) / 4 = 0.45
+	if !sig.HasSignal || sig.Side != "BUY" || sig.Confidence < 0.449 || sig.Confidence > 0.451 {
+		t.Fatalf("expected BUY at 0.45, got %+v", sig)
+	}
+}
+
+func TestEnsemble_VetoAndErrors(t *testing.T) {
+	ens, _ := NewEnsemble(EnsembleVeto, Member{Strategy: buy("trend", 0.80, 12)}, Member{Strategy: sell("breakout", 0.60)})
+	sig, _ := ens.Evaluate(TrendInput{Symbol: "EURUSD"})
+	if sig.HasSignal || !strings.Contains(sig.Reason, "vetoed by breakout") {
+		t.Fatalf("expected veto, got %+v", sig)
+	}
+
+	ens.Members[1] = Member{Strategy: fixedStrategy{name: "breakout", err: errors.New("not enough candles")}, Weight: 1}
+	sig, err := ens.Evaluate(TrendInput{Symbol: "EURUSD"})
+	if err != nil || !sig.HasSignal || sig.Votes[1].Error == "" {
+		t.Fatalf("expected failing member to abstain, got %+v err=%v", sig, err)
+	}
+
+	ens.Members[0] = ens.Members[1]
+	if _, err := ens.Evaluate(TrendInput{Symbol: "EURUSD"}); err == nil {
+		t.Fatal("expected an error when every member fails")
+	}
+	if _, err := NewEnsemble("unanimous", Member{Strategy: none("a")}); err == nil {
+		t.Fatal("expected unknown mode to be rejected")
+	}
+}
+
+func TestRegistryParseMembersAndBreakout(t *testing.T) {
+	reg := NewRegistry(NewTrendEngine(), NewBreakoutEngine())
+	members, err := reg.ParseMembers("trend:2, breakout")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(members) != 2 || members[0].Weight != 2 || members[1].Strategy.Name() != "breakout" {
+		t.Fatalf("unexpected members %+v", members)
+	}
+	if _, err := reg.ParseMembers("trend,meanrev"); err == nil {
+		t.Fatal("expected unknown member to be rejected")
+	}
+
+	candles := make([]Candle, 0, 30)
+	for i := 0; i < 30; i++ {
+		c := 1.1000 + float64(i%2)*0.0005
+		if i == 29 {
+			c = 1.1030
+		}
+		candles = append(candles, Candle{Time: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC).Add(time.Duration(i) * 15 * time.Minute), Open: 1.1000, High: c + 0.0003, Low: 1.0997, Close: c})
+	}
+	sig, err := NewBreakoutEngine().Evaluate(TrendInput{Symbol: "EURUSD", Candles: candles})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !sig.HasSignal || sig.Side != "BUY" || sig.StopLossPips < 8 {
+		t.Fatalf("expected breakout long, got %+v", sig)
+	}
+}
@@ -0,0 +1,155 @@
+package strategy
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// RangeEngine fades closes outside the Bollinger band when RSI confirms the
+// stretch, targeting the middle band. It is meant for ranging markets where
+// TrendEngine stays silent.
+type RangeEngine struct {
+	BandLen   int     `json:"band_len"`
+	BandStdev float64 `json:"band_stdev"`
+	RSILen    int     `json:"rsi_len"`
+	// RSIExtreme is the distance from 50 RSI must reach (20 = 30/70).
+	RSIExtreme float64 `json:"rsi_extreme"`
+	ATRLen     int     `json:"atr_len"`
+	SLATRMult  float64 `json:"sl_atr_mult"`
+	MinSLPips  float64 `json:"min_sl_pips"`
+}
+
+func NewRangeEngine() *RangeEngine {
+	return &RangeEngine{
+		BandLen:    20,
+		BandStdev:  2.0,
+		RSILen:     14,
+		RSIExtreme: 20,
+		ATRLen:     14,
+		SLATRMult:  1.5,
+		MinSLPips:  8,
+	}
+}
+
+func (e *RangeEngine) Name() string { return "range" }
+
+func (e *RangeEngine) Validate() error {
+	if e.BandLen < 2 || e.RSILen < 1 || e.ATRLen < 1 {
+		return errors.New("band_len must be at least 2 and rsi/atr lengths positive")
+	}
+	if e.BandStdev <= 0 || e.SLATRMult <= 0 {
+		return errors.New("band_stdev and sl_atr_mult must be positive")
+	}
+	if e.RSIExtreme < 0 || e.RSIExtreme >= 50 || e.MinSLPips < 0 {
+		return errors.New("rsi_extreme must be within [0, 50) and min_sl_pips not negative")
+	}
+	return nil
+}
+
+func (e *RangeEngine) Evaluate(input TrendInput) (TrendSignal, error) {
+	if strings.TrimSpace(input.Symbol) == "" {
+		return TrendSignal{}, errors.New("symbol is required")
+	}
+	minCandles := max(e.BandLen, e.RSILen+1, e.ATRLen+2)
+	if len(input.Candles) < minCandles {
+		return TrendSignal{}, fmt.Errorf("at least %d candles required", minCandles)
+	}
+	closes := make([]float64, 0, len(input.Candles))
+	for i, c := range input.Candles {
+		if msg := ohlcProblem(c); msg != "" {
+			return TrendSignal{}, fmt.Errorf("candle %d: %s", i, msg)
+		}
+		if i > 0 && !c.Time.After(input.Candles[i-1].Time) {
+			return TrendSignal{}, fmt.Errorf("candle %d: times must be strictly increasing", i)
+		}
+		closes = append(closes, c.Close)
+	}
+
+	mid, dev := meanStdev(closes[len(closes)-e.BandLen:])
+	upper := mid + e.BandStdev*dev
+	lower := mid - e.BandStdev*dev
+	last := closes[len(closes)-1]
+	rsiNow := rsi(closes, e.RSILen)
+	atrNow := atr(input.Candles, e.ATRLen)
+
+	var side string
+	var stretch float64
+	switch {
+	case last < lower && rsiNow <= 50-e.RSIExtreme:
+		side, stretch = "BUY", (50-e.RSIExtreme-rsiNow)/100
+	case last > upper && rsiNow >= 50+e.RSIExtreme:
+		side, stretch = "SELL", (rsiNow-50-e.RSIExtreme)/100
+	default:
+		return TrendSignal{HasSignal: false, Reason: "no range extreme"}, nil
+	}
+
+	pip := input.PipSize
+	if pip <= 0 {
+		pip = PipSize(input.Symbol)
+	}
+	sl := math.Max(atrNow/pip*e.SLATRMult, e.MinSLPips)
+	tp := math.Max(math.Abs(mid-last)/pip, e.MinSLPips)
+	band := "lower"
+	if side == "SELL" {
+		band = "upper"
+	}
+	return TrendSignal{
+		HasSignal:      true,
+		Side:           side,
+		Confidence:     minFloat(0.85, 0.65+stretch*2),
+		Reason:         fmt.Sprintf("%s range fade: close beyond %s band, RSI%d %.1f", timeframeLabel(input.Timeframe), band, e.RSILen, rsiNow),
+		StopLossPips:   sl,
+		TakeProfitPips: tp,
+	}, nil
+}
+
+func meanStdev(values []float64) (float64, float64) {
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
+
+// rsi is Wilder's relative strength index over the whole series.
+func rsi(closes []float64, length int) float64 {
+	if len(closes) <= length {
+		return 50
+	}
+	gain, loss := 0.0, 0.0
+	for i := 1; i <= length; i++ {
+		d := closes[i] - closes[i-1]
+		if d > 0 {
+			gain += d
+		} else {
+			loss -= d
+		}
+	}
+	gain /= float64(length)
+	loss /= float64(length)
+	for i := length + 1; i < len(closes); i++ {
+		d := closes[i] - closes[i-1]
+		up, down := 0.0, 0.0
+		if d > 0 {
+			up = d
+		} else {
+			down = -d
+		}
+		gain = (gain*float64(length-1) + up) / float64(length)
+		loss = (loss*float64(length-1) + down) / float64(length)
+	}
+	if loss == 0 {
+		if gain == 0 {
+			return 50
+		}
+		return 100
+	}
+	return 100 - 100/(1+gain/loss)
+}
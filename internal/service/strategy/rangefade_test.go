@@ -0,0 +1,30 @@
+package strategy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRangeEngineFadesBandExtreme(t *testing.T) {
+	candles := make([]Candle, 0, 40)
+	for i := 0; i < 40; i++ {
+		c := 1.1000 + 0.0001*float64(i%2*2-1)
+		if i >= 37 {
+			// Three sharp down closes push price under the lower band and RSI low.
+			c = 1.0990 - float64(i-37)*0.0010
+		}
+		candles = append(candles, Candle{Time: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC).Add(time.Duration(i) * 15 * time.Minute), Open: c + 0.0002, High: c + 0.0004, Low: c - 0.0002, Close: c})
+	}
+	sig, err := NewRangeEngine().Evaluate(TrendInput{Symbol: "EURUSD", Candles: candles})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !sig.HasSignal || sig.Side != "BUY" || sig.TakeProfitPips <= 0 {
+		t.Fatalf("expected fade long, got %+v", sig)
+	}
+
+	sig, _ = NewRangeEngine().Evaluate(TrendInput{Symbol: "EURUSD", Candles: candles[:37]})
+	if sig.HasSignal {
+		t.Fatalf("expected no signal inside the band, got %+v", sig)
+	}
+}
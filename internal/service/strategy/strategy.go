@@ -0,0 +1,74 @@
+package strategy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Strategy is a signal engine that can run on its own or as an ensemble
+// member. Every strategy reports in the TrendSignal shape.
+type Strategy interface {
+	Name() string
+	Evaluate(input TrendInput) (TrendSignal, error)
+}
+
+// Registry maps strategy names to engines for ensemble configuration.
+type Registry struct {
+	strategies map[string]Strategy
+}
+
+func NewRegistry(strategies ...Strategy) *Registry {
+	r := &Registry{strategies: make(map[string]Strategy, len(strategies))}
+	for _, s := range strategies {
+		r.Register(s)
+	}
+	return r
+}
+
+// Register adds s under its lower-cased name, replacing any earlier entry.
+func (r *Registry) Register(s Strategy) {
+	r.strategies[strings.ToLower(s.Name())] = s
+}
+
+func (r *Registry) Get(name string) (Strategy, bool) {
+	s, ok := r.strategies[strings.ToLower(strings.TrimSpace(name))]
+	return s, ok
+}
+
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.strategies))
+	for name := range r.strategies {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ParseMembers reads an ensemble list such as "trend:2,breakout" into
+// members; a missing weight is 1.
+func (r *Registry) ParseMembers(raw string) ([]Member, error) {
+	members := make([]Member, 0, 4)
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, weightRaw, hasWeight := strings.Cut(part, ":")
+		s, ok := r.Get(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown strategy %q (have %s)", name, strings.Join(r.Names(), ", "))
+		}
+		weight := 1.0
+		if hasWeight {
+			if _, err := fmt.Sscanf(strings.TrimSpace(weightRaw), "%g", &weight); err != nil || weight <= 0 {
+				return nil, fmt.Errorf("invalid weight %q for strategy %s", weightRaw, name)
+			}
+		}
+		members = append(members, Member{Strategy: s, Weight: weight})
+	}
+	if len(members) == 0 {
+		return nil, fmt.Errorf("no ensemble members in %q", raw)
+	}
+	return members, nil
+}
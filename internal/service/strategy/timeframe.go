@@ -0,0 +1,45 @@
+package strategy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var timeframes = map[string]time.Duration{
+	"M1":  time.Minute,
+	"M5":  5 * time.Minute,
+	"M15": 15 * time.Minute,
+	"M30": 30 * time.Minute,
+	"H1":  time.Hour,
+	"H4":  4 * time.Hour,
+	"D1":  24 * time.Hour,
+}
+
+// ParseTimeframe returns the bar length of an MT5-style timeframe name.
+func ParseTimeframe(tf string) (time.Duration, error) {
+	d, ok := timeframes[strings.ToUpper(strings.TrimSpace(tf))]
+	if !ok {
+		return 0, fmt.Errorf("unsupported timeframe %q (want M1, M5, M15, M30, H1, H4 or D1)", tf)
+	}
+	return d, nil
+}
+
+// Resample aggregates time-sorted candles into bars of length d aligned to
+// UTC midnight. The last bar may be incomplete; callers decide whether to use it.
+func Resample(candles []Candle, d time.Duration) []Candle {
+	out := make([]Candle, 0, len(candles)/max(1, int(d/time.Minute))+1)
+	for _, c := range candles {
+		bucket := c.Time.UTC().Truncate(d)
+		if n := len(out); n > 0 && out[n-1].Time.Equal(bucket) {
+			bar := &out[n-1]
+			bar.High = max(bar.High, c.High)
+			bar.Low = min(bar.Low, c.Low)
+			bar.Close = c.Close
+			bar.Volume += c.Volume
+			continue
+		}
+		out = append(out, Candle{Time: bucket, Open: c.Open, High: c.High, Low: c.Low, Close: c.Close, Volume: c.Volume})
+	}
+	return out
+}
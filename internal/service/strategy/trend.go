@@ -5,48 +5,254 @@ import (
 	"fmt"
 	"math"
 	"strings"
-	"time"
+
+	"mmbot/internal/domain"
 )
 
-type Candle struct {
-	Time   time.Time `json:"time"`
-	Open   float64   `json:"open"`
-	High   float64   `json:"high"`
-	Low    float64   `json:"low"`
-	Close  float64   `json:"close"`
-	Volume float64   `json:"volume,omitempty"`
-}
+// Candle is an alias so strategy inputs and the candle store share one shape.
+type Candle = domain.Candle
 
 type TrendInput struct {
 	Symbol     string   `json:"symbol"`
+	Timeframe  string   `json:"timeframe,omitempty"`
 	Candles    []Candle `json:"candles"`
 	SpreadPips float64  `json:"spread_pips"`
+	// PipSize comes from the symbol registry; zero falls back to PipSize.
+	PipSize float64 `json:"pip_size,omitempty"`
+	// HTFCandles is an optional higher-timeframe series; when present (or
+	// when HTFTimeframe is set) a signal is only emitted if its EMA regime
+	// agrees with the setup.
+	HTFTimeframe string   `json:"htf_timeframe,omitempty"`
+	HTFCandles   []Candle `json:"htf_candles,omitempty"`
 }
 
 type TrendSignal struct {
-	HasSignal      bool    `json:"has_signal"`
-	Side           string  `json:"side,omitempty"`
-	Confidence     float64 `json:"confidence,omitempty"`
-	Reason         string  `json:"reason,omitempty"`
-	StopLossPips   float64 `json:"stop_loss_pips,omitempty"`
-	TakeProfitPips float64 `json:"take_profit_pips,omitempty"`
+	HasSignal      bool       `json:"has_signal"`
+	Side           string     `json:"side,omitempty"`
+	Confidence     float64    `json:"confidence,omitempty"`
+	Reason         string     `json:"reason,omitempty"`
+	StopLossPips   float64    `json:"stop_loss_pips,omitempty"`
+	TakeProfitPips float64    `json:"take_profit_pips,omitempty"`
+	Alignment      *Alignment `json:"alignment,omitempty"`
+	// Votes is set by Ensemble: one entry per member, in member order.
+	Votes []Vote `json:"votes,omitempty"`
+}
+
+// Alignment describes the higher-timeframe EMA regime a signal was checked
+// against and how much confidence it added.
+type Alignment struct {
+	Timeframe       string  `json:"timeframe"`
+	Regime          string  `json:"regime"`
+	Agrees          bool    `json:"agrees"`
+	FastEMA         float64 `json:"fast_ema"`
+	SlowEMA         float64 `json:"slow_ema"`
+	GapPct          float64 `json:"gap_pct"`
+	ConfidenceBoost float64 `json:"confidence_boost"`
 }
 
+const (
+	RegimeBullish = "bullish"
+	RegimeBearish = "bearish"
+	RegimeFlat    = "flat"
+)
+
+// ErrInsufficientHTF is returned when the higher-timeframe series is too
+// short to classify.
+var ErrInsufficientHTF = errors.New("insufficient HTF history")
+
+// maxHTFBoost caps the confidence a strongly trending higher timeframe adds.
+const maxHTFBoost = 0.05
+
 type TrendEngine struct {
-	FastEMA int
-	SlowEMA int
-	ATRLen  int
+	FastEMA int `json:"fast_ema"`
+	SlowEMA int `json:"slow_ema"`
+	ATRLen  int `json:"atr_len"`
+	// SLATRMult sets the stop distance as a multiple of ATR.
+	SLATRMult float64 `json:"sl_atr_mult"`
+	// TPRiskReward sets the take-profit distance as a multiple of the stop.
+	TPRiskReward float64 `json:"tp_risk_reward"`
+	MinSLPips    float64 `json:"min_sl_pips"`
+	// MinTrendGapPct and MinSlopePct are fractions (0.0002 = 0.02%) below
+	// which the EMA gap or fast-EMA slope is treated as a flat market.
+	MinTrendGapPct float64 `json:"min_trend_gap_pct"`
+	MinSlopePct    float64 `json:"min_slope_pct"`
 }
 
 func NewTrendEngine() *TrendEngine {
 	return &TrendEngine{
-		FastEMA: 20,
-		SlowEMA: 50,
-		ATRLen:  14,
+		FastEMA:        20,
+		SlowEMA:        50,
+		ATRLen:         14,
+		SLATRMult:      1.5,
+		TPRiskReward:   2.0,
+		MinSLPips:      8,
+		MinTrendGapPct: 0.0002,
+		MinSlopePct:    0.00005,
+	}
+}
+
+// TrendOverride is a partial TrendEngine parameter set. Nil fields keep the
+// engine's value, so an override can also set a threshold to zero.
+type TrendOverride struct {
+	FastEMA        *int
+	SlowEMA        *int
+	ATRLen         *int
+	SLATRMult      *float64
+	TPRiskReward   *float64
+	MinSLPips      *float64
+	MinTrendGapPct *float64
+	MinSlopePct    *float64
+}
+
+// Override copies every set field of o onto the engine, so callers can
+// apply partial parameter sets from config or an optimiser.
+func (e *TrendEngine) Override(o TrendOverride) *TrendEngine {
+	if o.FastEMA != nil {
+		e.FastEMA = *o.FastEMA
+	}
+	if o.SlowEMA != nil {
+		e.SlowEMA = *o.SlowEMA
+	}
+	if o.ATRLen != nil {
+		e.ATRLen = *o.ATRLen
+	}
+	if o.SLATRMult != nil {
+		e.SLATRMult = *o.SLATRMult
+	}
+	if o.TPRiskReward != nil {
+		e.TPRiskReward = *o.TPRiskReward
+	}
+	if o.MinSLPips != nil {
+		e.MinSLPips = *o.MinSLPips
+	}
+	if o.MinTrendGapPct != nil {
+		e.MinTrendGapPct = *o.MinTrendGapPct
+	}
+	if o.MinSlopePct != nil {
+		e.MinSlopePct = *o.MinSlopePct
+	}
+	return e
+}
+
+// TrendParamNames lists the parameter names accepted by SetParam.
+func TrendParamNames() []string {
+	return []string{
+		"fast_ema", "slow_ema", "atr_len", "sl_atr_mult", "tp_risk_reward",
+		"min_sl_pips", "min_trend_gap_pct", "min_slope_pct",
+	}
+}
+
+// SetParam sets one tunable by its JSON name. Integer parameters are rounded.
+func (e *TrendEngine) SetParam(name string, v float64) error {
+	switch name {
+	case "fast_ema":
+		e.FastEMA = int(math.Round(v))
+	case "slow_ema":
+		e.SlowEMA = int(math.Round(v))
+	case "atr_len":
+		e.ATRLen = int(math.Round(v))
+	case "sl_atr_mult":
+		e.SLATRMult = v
+	case "tp_risk_reward":
+		e.TPRiskReward = v
+	case "min_sl_pips":
+		e.MinSLPips = v
+	case "min_trend_gap_pct":
+		e.MinTrendGapPct = v
+	case "min_slope_pct":
+		e.MinSlopePct = v
+	default:
+		return fmt.Errorf("unknown trend parameter %q", name)
+	}
+	return nil
+}
+
+// Validate rejects parameter combinations Evaluate cannot work with.
+func (e *TrendEngine) Validate() error {
+	if e.FastEMA < 1 || e.SlowEMA < 1 || e.ATRLen < 1 {
+		return errors.New("ema and atr lengths must be positive")
 	}
+	if e.FastEMA >= e.SlowEMA {
+		return fmt.Errorf("fast_ema (%d) must be shorter than slow_ema (%d)", e.FastEMA, e.SlowEMA)
+	}
+	if e.SLATRMult <= 0 || e.TPRiskReward <= 0 {
+		return errors.New("sl_atr_mult and tp_risk_reward must be positive")
+	}
+	if e.MinSLPips < 0 || e.MinTrendGapPct < 0 || e.MinSlopePct < 0 {
+		return errors.New("thresholds must not be negative")
+	}
+	return nil
 }
 
+func (e *TrendEngine) Name() string { return "trend" }
+
 func (e *TrendEngine) Evaluate(input TrendInput) (TrendSignal, error) {
+	sig, err := e.evaluateSeries(input)
+	if err != nil {
+		return TrendSignal{}, err
+	}
+	if input.HTFTimeframe == "" && len(input.HTFCandles) == 0 {
+		return sig, nil
+	}
+
+	align, err := e.htfAlignment(input.HTFTimeframe, input.HTFCandles)
+	if err != nil {
+		return TrendSignal{}, err
+	}
+	sig.Alignment = &align
+	if !sig.HasSignal {
+		return sig, nil
+	}
+	align.Agrees = (sig.Side == "BUY" && align.Regime == RegimeBullish) ||
+		(sig.Side == "SELL" && align.Regime == RegimeBearish)
+	if !align.Agrees {
+		return TrendSignal{
+			HasSignal: false,
+			Reason:    fmt.Sprintf("%s %s setup rejected: %s EMA regime is %s", timeframeLabel(input.Timeframe), strings.ToLower(sig.Side), align.Timeframe, align.Regime),
+			Alignment: &align,
+		}, nil
+	}
+	boosted := minFloat(0.90, sig.Confidence+minFloat(maxHTFBoost, align.GapPct*10))
+	align.ConfidenceBoost = boosted - sig.Confidence
+	sig.Confidence = boosted
+	sig.Reason += fmt.Sprintf("; %s EMA regime %s", align.Timeframe, align.Regime)
+	return sig, nil
+}
+
+// htfAlignment classifies the higher-timeframe series as bullish (close and
+// fast EMA above the slow EMA), bearish (mirror) or flat.
+func (e *TrendEngine) htfAlignment(timeframe string, candles []Candle) (Alignment, error) {
+	label := strings.ToUpper(strings.TrimSpace(timeframe))
+	if label == "" {
+		label = "HTF"
+	}
+	if len(candles) < e.SlowEMA+1 {
+		return Alignment{}, fmt.Errorf("%w: at least %d %s candles required for higher-timeframe confirmation, got %d", ErrInsufficientHTF, e.SlowEMA+1, label, len(candles))
+	}
+	closes := make([]float64, 0, len(candles))
+	for i, c := range candles {
+		if msg := ohlcProblem(c); msg != "" {
+			return Alignment{}, fmt.Errorf("%s candle %d: %s", label, i, msg)
+		}
+		closes = append(closes, c.Close)
+	}
+	fast := ema(closes, e.FastEMA)
+	slow := ema(closes, e.SlowEMA)
+	last := closes[len(closes)-1]
+	gap := math.Abs(fast-slow) / slow
+
+	regime := RegimeFlat
+	switch {
+	case gap < e.MinTrendGapPct:
+	case fast > slow && last > slow:
+		regime = RegimeBullish
+	case fast < slow && last < slow:
+		regime = RegimeBearish
+	}
+	return Alignment{Timeframe: label, Regime: regime, FastEMA: fast, SlowEMA: slow, GapPct: gap}, nil
+}
+
+func (e *TrendEngine) evaluateSeries(input TrendInput) (TrendSignal, error) {
 	if strings.TrimSpace(input.Symbol) == "" {
 		return TrendSignal{}, errors.New("symbol is required")
 	}
@@ -56,9 +262,12 @@ func (e *TrendEngine) Evaluate(input TrendInput) (TrendSignal, error) {
 	}
 
 	closes := make([]float64, 0, len(input.Candles))
-	for _, c := range input.Candles {
-		if c.Close <= 0 || c.High <= 0 || c.Low <= 0 {
-			return TrendSignal{}, errors.New("candles must have positive prices")
+	for i, c := range input.Candles {
+		if msg := ohlcProblem(c); msg != "" {
+			return TrendSignal{}, fmt.Errorf("candle %d: %s", i, msg)
+		}
+		if i > 0 && !c.Time.After(input.Candles[i-1].Time) {
+			return TrendSignal{}, fmt.Errorf("candle %d: times must be strictly increasing", i)
 		}
 		closes = append(closes, c.Close)
 	}
@@ -73,7 +282,7 @@ func (e *TrendEngine) Evaluate(input TrendInput) (TrendSignal, error) {
 	fastSlopePct := math.Abs(fastNow-fastPrev) / fastNow
 
 	// Ignore weak/flat regimes to reduce false positives.
-	if trendGapPct < 0.0002 || fastSlopePct < 0.00005 {
+	if trendGapPct < e.MinTrendGapPct || fastSlopePct < e.MinSlopePct {
 		return TrendSignal{
 			HasSignal: false,
 			Reason:    "no clear trend setup",
@@ -83,12 +292,12 @@ func (e *TrendEngine) Evaluate(input TrendInput) (TrendSignal, error) {
 	// Buy trend regime: price above fast, fast above slow, and fast slope up.
 	if lastClose > fastNow && fastNow > slowNow && fastNow > fastPrev && slowNow >= slowPrev {
 		conf := confidence(lastClose, fastNow, slowNow, atrNow)
-		slPips, tpPips := sltpPips(input.Symbol, atrNow)
+		slPips, tpPips := e.sltpPips(input, atrNow)
 		return TrendSignal{
 			HasSignal:      true,
 			Side:           "BUY",
 			Confidence:     conf,
-			Reason:         "M15 trend-following long: close>EMA20>EMA50 with positive slope",
+			Reason:         fmt.Sprintf("%s trend-following long: close>EMA%d>EMA%d with positive slope", timeframeLabel(input.Timeframe), e.FastEMA, e.SlowEMA),
 			StopLossPips:   slPips,
 			TakeProfitPips: tpPips,
 		}, nil
@@ -97,12 +306,12 @@ func (e *TrendEngine) Evaluate(input TrendInput) (TrendSignal, error) {
 	// Sell trend regime: price below fast, fast below slow, and fast slope down.
 	if lastClose < fastNow && fastNow < slowNow && fastNow < fastPrev && slowNow <= slowPrev {
 		conf := confidence(lastClose, fastNow, slowNow, atrNow)
-		slPips, tpPips := sltpPips(input.Symbol, atrNow)
+		slPips, tpPips := e.sltpPips(input, atrNow)
 		return TrendSignal{
 			HasSignal:      true,
 			Side:           "SELL",
 			Confidence:     conf,
-			Reason:         "M15 trend-following short: close<EMA20<EMA50 with negative slope",
+			Reason:         fmt.Sprintf("%s trend-following short: close<EMA%d<EMA%d with negative slope", timeframeLabel(input.Timeframe), e.FastEMA, e.SlowEMA),
 			StopLossPips:   slPips,
 			TakeProfitPips: tpPips,
 		}, nil
@@ -177,17 +386,32 @@ func confidence(close, fast, slow, atrValue float64) float64 {
 	return score
 }
 
-func sltpPips(symbol string, atrValue float64) (float64, float64) {
-	pip := pipSize(symbol)
-	sl := (atrValue / pip) * 1.5
-	if sl < 8 {
-		sl = 8
+func (e *TrendEngine) sltpPips(input TrendInput, atrValue float64) (float64, float64) {
+	pip := input.PipSize
+	if pip <= 0 {
+		pip = PipSize(input.Symbol)
+	}
+	sl := (atrValue / pip) * e.SLATRMult
+	if sl < e.MinSLPips {
+		sl = e.MinSLPips
 	}
-	tp := sl * 2.0
+	tp := sl * e.TPRiskReward
 	return sl, tp
 }
 
-func pipSize(symbol string) float64 {
+// timeframeLabel names the signal series in reasons; inputs without a
+// timeframe are the M15 series the engine was built around.
+func timeframeLabel(tf string) string {
+	if tf = strings.ToUpper(strings.TrimSpace(tf)); tf != "" {
+		return tf
+	}
+	return "M15"
+}
+
+// PipSize guesses the pip size from the symbol name. It is only a fallback
+// for symbols without a registered spec: it is wrong for metals, indices and
+// crypto.
+func PipSize(symbol string) float64 {
 	upper := strings.ToUpper(symbol)
 	if strings.Contains(upper, "JPY") {
 		return 0.01
@@ -77,3 +77,164 @@ func TestTrendEngineNoSignalOnFlatSeries(t *testing.T) {
 		t.Fatalf("expected no signal, got %+v", sig)
 	}
 }
+
+func TestTrendEngineUsesConfiguredRiskReward(t *testing.T) {
+	riskReward, minSL := 3.0, 30.0
+	engine := NewTrendEngine().Override(TrendOverride{TPRiskReward: &riskReward, MinSLPips: &minSL})
+	candles := make([]Candle, 0, 120)
+	for i := 0; i < 120; i++ {
+		close := 1.0800 + float64(i)*0.00065
+		candles = append(candles, Candle{
+			Time:  time.Unix(int64(1700000000+i*900), 0).UTC(),
+			Open:  close - 0.0002,
+			High:  close + 0.0008,
+			Low:   close - 0.0010,
+			Close: close,
+		})
+	}
+	sig, err := engine.Evaluate(TrendInput{Symbol: "EURUSD", Candles: candles})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !sig.HasSignal || sig.StopLossPips < 30 {
+		t.Fatalf("expected signal with SL floored at 30 pips, got %+v", sig)
+	}
+	if ratio := sig.TakeProfitPips / sig.StopLossPips; ratio < 2.99 || ratio > 3.01 {
+		t.Fatalf("expected 3R take profit, got %.2fR", ratio)
+	}
+}
+
+func TestTrendEngineSetParamValidation(t *testing.T) {
+	engine := NewTrendEngine()
+	if err := engine.SetParam("fast_ema", 60); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := engine.Validate(); err == nil {
+		t.Fatal("expected fast_ema >= slow_ema to be rejected")
+	}
+	if err := engine.SetParam("unknown", 1); err == nil {
+		t.Fatal("expected unknown parameter error")
+	}
+}
+
+func linearCandles(n int, start, step float64, spacing time.Duration) []Candle {
+	candles := make([]Candle, 0, n)
+	for i := 0; i < n; i++ {
+		close := start + float64(i)*step
+		candles = append(candles, Candle{
+			Time:  time.Date(2026, 3, 2, 0, 45, 0, 0, time.UTC).Add(time.Duration(i) * spacing),
+			Open:  close - step/2,
+			High:  close + 0.0006,
+			Low:   close - 0.0006,
+			Close: close,
+		})
+	}
+	return candles
+}
+
+func TestTrendEngineHigherTimeframeConfirmation(t *testing.T) {
+	engine := NewTrendEngine()
+	m15 := linearCandles(120, 1.0800, 0.00035, 15*time.Minute)
+
+	sig, err := engine.Evaluate(TrendInput{
+		Symbol:       "EURUSD",
+		Timeframe:    "M15",
+		Candles:      m15,
+		HTFTimeframe: "H4",
+		HTFCandles:   linearCandles(80, 1.0500, 0.0010, 4*time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !sig.HasSignal || sig.Alignment == nil || !sig.Alignment.Agrees || sig.Alignment.Regime != RegimeBullish {
+		t.Fatalf("expected bullish H4 to confirm the long, got %+v (alignment %+v)", sig, sig.Alignment)
+	}
+	if sig.Alignment.ConfidenceBoost <= 0 {
+		t.Fatalf("expected a confidence boost from an aligned H4 trend, got %+v", sig.Alignment)
+	}
+
+	sig, err = engine.Evaluate(TrendInput{
+		Symbol:       "EURUSD",
+		Candles:      m15,
+		HTFTimeframe: "H4",
+		HTFCandles:   linearCandles(80, 1.1500, -0.0010, 4*time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sig.HasSignal || sig.Alignment == nil || sig.Alignment.Agrees || sig.Alignment.Regime != RegimeBearish {
+		t.Fatalf("expected bearish H4 to veto the long, got %+v", sig)
+	}
+
+	if _, err := engine.Evaluate(TrendInput{Symbol: "EURUSD", Candles: m15, HTFTimeframe: "H1"}); err == nil {
+		t.Fatal("expected missing higher-timeframe candles to be an error")
+	}
+}
+
+func TestResampleAggregatesIntoAlignedBars(t *testing.T) {
+	m15 := linearCandles(8, 1.1000, 0.0001, 15*time.Minute)
+	h1 := Resample(m15, time.Hour)
+	if len(h1) != 3 {
+		t.Fatalf("expected 3 hourly buckets for 8 M15 bars starting mid-hour, got %d", len(h1))
+	}
+	if h1[1].Open != m15[1].Open || h1[1].Close != m15[4].Close || h1[1].Time.Minute() != 0 {
+		t.Fatalf("unexpected hourly bar %+v", h1[1])
+	}
+}
+
+func TestNormalizeCandlesSortsDedupesAndFlagsGaps(t *testing.T) {
+	// Friday 2026-03-06 20:30 UTC onwards, M15.
+	at := func(day, hour, minute int) time.Time { return time.Date(2026, 3, day, hour, minute, 0, 0, time.UTC) }
+	bar := func(ts time.Time, close float64) Candle {
+		return Candle{Time: ts, Open: close, High: close + 0.0005, Low: close - 0.0005, Close: close}
+	}
+	in := []Candle{
+		bar(at(6, 20, 45), 1.1002),
+		bar(at(6, 20, 30), 1.1001),
+		bar(at(6, 20, 45), 1.1003), // duplicate, later copy wins
+		bar(at(8, 21, 0), 1.1010),  // weekend close is not a gap
+		bar(at(8, 21, 45), 1.1011), // two M15 bars missing
+		bar(at(8, 22, 0), 1.1012),  // still forming at now
+	}
+	out, issues, err := NormalizeCandles(in, NormalizeOptions{
+		Timeframe:      15 * time.Minute,
+		DropFormingBar: true,
+		Now:            at(8, 22, 10),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out) != 4 || out[1].Close != 1.1003 || !out[3].Time.Equal(at(8, 21, 45)) {
+		t.Fatalf("unexpected normalised series %+v", out)
+	}
+	codes := map[string]int{}
+	for _, is := range issues {
+		codes[is.Code]++
+	}
+	want := map[string]int{IssueUnsorted: 1, IssueDuplicate: 1, IssueGap: 1, IssueFormingBarDrop: 1}
+	for code, n := range want {
+		if codes[code] != n {
+			t.Fatalf("expected %d %s issue(s), got %v", n, code, issues)
+		}
+	}
+
+	bad := bar(at(9, 10, 0), 1.1000)
+	bad.Close = bad.High + 0.0001
+	if _, issues, err := NormalizeCandles([]Candle{bad}, NormalizeOptions{}); err == nil || len(issues) != 1 || !issues[0].Rejecting {
+		t.Fatalf("expected close outside range to be rejected, got err=%v issues=%v", err, issues)
+	}
+}
+
+func TestTrendOverrideCanZeroThresholds(t *testing.T) {
+	zero := 0.0
+	engine := NewTrendEngine().Override(TrendOverride{MinTrendGapPct: &zero, MinSlopePct: &zero})
+	if engine.MinTrendGapPct != 0 || engine.MinSlopePct != 0 {
+		t.Fatalf("expected the thresholds switched off, got %+v", engine)
+	}
+	if engine.FastEMA != 20 || engine.MinSLPips != 8 {
+		t.Fatalf("expected unset fields to keep their defaults, got %+v", engine)
+	}
+	if err := engine.Validate(); err != nil {
+		t.Fatalf("expected zero thresholds to be valid: %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+package symbols
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"mmbot/internal/domain"
+	"mmbot/internal/service/strategy"
+)
+
+// SpecStore is the persistence the registry reads from.
+type SpecStore interface {
+	ListSymbolSpecs(symbol string) []domain.SymbolSpec
+}
+
+// Entry shows both layers behind an effective spec.
+type Entry struct {
+	Effective domain.SymbolSpec  `json:"effective"`
+	Reported  *domain.SymbolSpec `json:"reported,omitempty"`
+	Override  *domain.SymbolSpec `json:"override,omitempty"`
+}
+
+type Registry struct {
+	store SpecStore
+}
+
+func NewRegistry(store SpecStore) *Registry {
+	return &Registry{store: store}
+}
+
+// Get returns the effective spec for symbol: the EA report with any admin
+// override applied on top.
+func (r *Registry) Get(symbol string) (domain.SymbolSpec, bool) {
+	e, ok := r.Entry(symbol)
+	return e.Effective, ok
+}
+
+func (r *Registry) Entry(symbol string) (Entry, bool) {
+	if strings.TrimSpace(symbol) == "" {
+		return Entry{}, false
+	}
+	entries := r.entries(symbol)
+	if len(entries) == 0 {
+		return Entry{}, false
+	}
+	return entries[0], true
+}
+
+func (r *Registry) List() []Entry {
+	return r.entries("")
+}
+
+// PipSize returns the registered pip size for symbol, falling back to the
+// name-based heuristic for symbols the EA has not reported yet.
+func (r *Registry) PipSize(symbol string) float64 {
+	if spec, ok := r.Get(symbol); ok {
+		if pip := PipSize(spec); pip > 0 {
+			return pip
+		}
+	}
+	return strategy.PipSize(symbol)
+}
+
+func (r *Registry) entries(symbol string) []Entry {
+	bySymbol := make(map[string]*Entry)
+	order := make([]string, 0, 16)
+	for _, spec := range r.store.ListSymbolSpecs(symbol) {
+		e, ok := bySymbol[spec.Symbol]
+		if !ok {
+			e = &Entry{}
+			bySymbol[spec.Symbol] = e
+			order = append(order, spec.Symbol)
+		}
+		spec := spec
+		if spec.Source == domain.SymbolSpecSourceAdmin {
+			e.Override = &spec
+		} else {
+			e.Reported = &spec
+		}
+	}
+	slices.Sort(order)
+	out := make([]Entry, 0, len(order))
+	for _, sym := range order {
+		e := bySymbol[sym]
+		switch {
+		case e.Reported != nil && e.Override != nil:
+			e.Effective = Merge(*e.Reported, *e.Override)
+		case e.Reported != nil:
+			e.Effective = *e.Reported
+		default:
+			e.Effective = *e.Override
+		}
+		e.Effective.PipSize = PipSize(e.Effective)
+		out = append(out, *e)
+	}
+	return out
+}
+
+// Merge applies the non-zero fields of override on top of base.
+func Merge(base, override domain.SymbolSpec) domain.SymbolSpec {
+	out := base
+	if override.Digits > 0 {
+		out.Digits = override.Digits
+	}
+	setFloat := func(dst *float64, v float64) {
+		if v > 0 {
+			*dst = v
+		}
+	}
+	setFloat(&out.Point, override.Point)
+	setFloat(&out.PipSize, override.PipSize)
+	setFloat(&out.TickSize, override.TickSize)
+	setFloat(&out.TickValue, override.TickValue)
+	setFloat(&out.ContractSize, override.ContractSize)
+	setFloat(&out.VolumeMin, override.VolumeMin)
+	setFloat(&out.VolumeMax, override.VolumeMax)
+	setFloat(&out.VolumeStep, override.VolumeStep)
+	setFloat(&out.MarginPerLot, override.MarginPerLot)
+	if override.StopsLevel > 0 {
+		out.StopsLevel = override.StopsLevel
+	}
+	if override.BaseCurrency != "" {
+		out.BaseCurrency = override.BaseCurrency
+	}
+	if override.ProfitCurrency != "" {
+		out.ProfitCurrency = override.ProfitCurrency
+	}
+	if len(override.Sessions) > 0 {
+		out.Sessions = override.Sessions
+	}
+	out.Source = domain.SymbolSpecSourceAdmin
+	if override.UpdatedAt.After(out.UpdatedAt) {
+		out.UpdatedAt = override.UpdatedAt
+	}
+	return out
+}
+
+// PipSize returns an explicit pip size, else derives it the way the EA always
+// has: ten points on 3- and 5-digit quotes, one point otherwise.
+func PipSize(spec domain.SymbolSpec) float64 {
+	if spec.PipSize > 0 {
+		return spec.PipSize
+	}
+	if spec.Point <= 0 {
+		return 0
+	}
+	if spec.Digits == 3 || spec.Digits == 5 {
+		return spec.Point * 10
+	}
+	return spec.Point
+}
+
+// PipValuePerLot is the account-currency value of one pip on 1.0 lot.
+func PipValuePerLot(spec domain.SymbolSpec) float64 {
+	pip := PipSize(spec)
+	if spec.TickSize <= 0 || spec.TickValue <= 0 || pip <= 0 {
+		return 0
+	}
+	return spec.TickValue * pip / spec.TickSize
+}
+
+// NormalizeVolume rounds lots down to the volume step and clamps them to the
+// symbol's min/max, mirroring the EA so the queued size is what gets filled.
+func NormalizeVolume(spec domain.SymbolSpec, lots float64) float64 {
+	step := spec.VolumeStep
+	if step <= 0 {
+		step = 0.01
+	}
+	minLot := spec.VolumeMin
+	if minLot <= 0 {
+		minLot = step
+	}
+	if lots < minLot {
+		lots = minLot
+	}
+	if spec.VolumeMax > 0 && lots > spec.VolumeMax {
+		lots = spec.VolumeMax
+	}
+	steps := math.Floor((lots-minLot)/step + 1e-9)
+	return math.Round((minLot+steps*step)*1e8) / 1e8
+}
+
+// Validate checks an EA-reported spec carries enough to derive pips.
+func Validate(spec domain.SymbolSpec) error {
+	if strings.TrimSpace(spec.Symbol) == "" {
+		return errors.New("symbol is required")
+	}
+	if spec.Point <= 0 {
+		return fmt.Errorf("%s: point must be positive", spec.Symbol)
+	}
+	if spec.Digits < 0 || spec.Digits > 10 {
+		return fmt.Errorf("%s: digits out of range", spec.Symbol)
+	}
+	if spec.VolumeMin < 0 || spec.VolumeMax < 0 || spec.VolumeStep < 0 || spec.StopsLevel < 0 {
+		return fmt.Errorf("%s: volume limits and stops level must not be negative", spec.Symbol)
+	}
+	if spec.MarginPerLot < 0 {
+		return fmt.Errorf("%s: margin_per_lot must not be negative", spec.Symbol)
+	}
+	if spec.VolumeMax > 0 && spec.VolumeMin > spec.VolumeMax {
+		return fmt.Errorf("%s: volume_min above volume_max", spec.Symbol)
+	}
+	return nil
+}
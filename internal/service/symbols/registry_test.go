@@ -0,0 +1,70 @@
+package symbols
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"mmbot/internal/domain"
+	"mmbot/internal/store/memory"
+)
+
+func TestPipSize_DerivesFromDigits(t *testing.T) {
+	cases := []struct {
+		spec domain.SymbolSpec
+		want float64
+	}{
+		{domain.SymbolSpec{Symbol: "EURUSD", Digits: 5, Point: 0.00001}, 0.0001},
+		{domain.SymbolSpec{Symbol: "USDJPY", Digits: 3, Point: 0.001}, 0.01},
+		{domain.SymbolSpec{Symbol: "XAUUSD", Digits: 2, Point: 0.01}, 0.01},
+		{domain.SymbolSpec{Symbol: "US500", Digits: 1, Point: 0.1, PipSize: 1}, 1},
+	}
+	for _, tc := range cases {
+		if got := PipSize(tc.spec); math.Abs(got-tc.want) > 1e-12 {
+			t.Fatalf("%s: expected pip %.5f, got %.5f", tc.spec.Symbol, tc.want, got)
+		}
+	}
+}
+
+func TestPipValueAndNormalizeVolume(t *testing.T) {
+	gold := domain.SymbolSpec{Symbol: "XAUUSD", Digits: 2, Point: 0.01, TickSize: 0.01, TickValue: 1, VolumeMin: 0.01, VolumeMax: 5, VolumeStep: 0.01}
+	if got := PipValuePerLot(gold); got != 1 {
+		t.Fatalf("expected $1 per pip per lot on gold, got %v", got)
+	}
+	if got := NormalizeVolume(gold, 0.237); got != 0.23 {
+		t.Fatalf("expected volume rounded down to 0.23, got %v", got)
+	}
+	if got := NormalizeVolume(gold, 12); got != 5 {
+		t.Fatalf("expected volume clamped to max, got %v", got)
+	}
+	if got := NormalizeVolume(domain.SymbolSpec{VolumeMin: 0.1, VolumeStep: 0.1}, 0.05); got != 0.1 {
+		t.Fatalf("expected volume raised to min, got %v", got)
+	}
+}
+
+func TestRegistry_OverrideMergesOnTopOfReport(t *testing.T) {
+	store := memory.NewStore(time.Hour)
+	reg := NewRegistry(store)
+	if got := reg.PipSize("USDJPY"); got != 0.01 {
+		t.Fatalf("expected heuristic fallback for unknown symbol, got %v", got)
+	}
+
+	store.SaveSymbolSpec(domain.SymbolSpec{Symbol: "xauusd", Digits: 2, Point: 0.01, VolumeStep: 0.01, StopsLevel: 20})
+	store.SaveSymbolSpec(domain.SymbolSpec{Symbol: "XAUUSD", PipSize: 0.1, Source: domain.SymbolSpecSourceAdmin})
+
+	entry, ok := reg.Entry("XAUUSD")
+	if !ok || entry.Reported == nil || entry.Override == nil {
+		t.Fatalf("expected both layers, got %+v", entry)
+	}
+	if entry.Effective.PipSize != 0.1 || entry.Effective.StopsLevel != 20 || entry.Effective.Source != domain.SymbolSpecSourceAdmin {
+		t.Fatalf("unexpected effective spec %+v", entry.Effective)
+	}
+	if got := reg.PipSize("XAUUSD"); got != 0.1 {
+		t.Fatalf("expected override pip size, got %v", got)
+	}
+
+	store.DeleteSymbolSpec("XAUUSD", domain.SymbolSpecSourceAdmin)
+	if got := reg.PipSize("XAUUSD"); got != 0.01 {
+		t.Fatalf("expected reported pip size after override removal, got %v", got)
+	}
+}
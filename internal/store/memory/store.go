@@ -2,7 +2,9 @@ package memory
 
 import (
 	"errors"
+	"maps"
 	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -19,21 +21,36 @@ type Store struct {
 	tokenTTL time.Duration
 
 	paused bool
+	// pauseOwner is set by SetPausedBy and cleared by SetPaused.
+	pauseOwner *domain.PauseOwner
 
 	eaSessions map[string]domain.EASession
 
 	commands     map[string]domain.Command
 	commandOrder []string
 
-	events []domain.Event
+	events  []domain.Event
+	results []domain.CommandResult
 
 	openPositionsByAccount map[string]int
 	dailyLossByAccount     map[string]float64
+	accountRisk            map[string]domain.AccountRiskState
+	tradeActivity          map[string]domain.TradeActivity
 	lastSeenByDevice       map[string]time.Time
 
 	openAIState       map[string]domain.OAuthState
 	openAIConnection  *domain.ProviderConnection
 	positionSnapshots map[string]map[string]interface{}
+	candles           map[string]map[int64]domain.Candle
+	symbolSpecs       map[string]map[string]domain.SymbolSpec
+	blackouts         map[string]domain.Blackout
+	symbolPauses      map[string]domain.SymbolPause
+	trades            map[string]domain.Trade
+	equityPoints      map[string]map[int64]domain.EquityPoint
+	reports           map[string]domain.Report
+	deals             map[string]domain.Deal
+	reconcileIssues   map[string]domain.ReconcileIssue
+	accounts          map[string]domain.Account
 }
 
 func NewStore(tokenTTL time.Duration) *Store {
@@ -45,9 +62,21 @@ func NewStore(tokenTTL time.Duration) *Store {
 		events:                 make([]domain.Event, 0, 256),
 		openPositionsByAccount: make(map[string]int),
 		dailyLossByAccount:     make(map[string]float64),
+		accountRisk:            make(map[string]domain.AccountRiskState),
+		tradeActivity:          make(map[string]domain.TradeActivity),
 		lastSeenByDevice:       make(map[string]time.Time),
 		openAIState:            make(map[string]domain.OAuthState),
 		positionSnapshots:      make(map[string]map[string]interface{}),
+		candles:                make(map[string]map[int64]domain.Candle),
+		symbolSpecs:            make(map[string]map[string]domain.SymbolSpec),
+		blackouts:              make(map[string]domain.Blackout),
+		symbolPauses:           make(map[string]domain.SymbolPause),
+		trades:                 make(map[string]domain.Trade),
+		equityPoints:           make(map[string]map[int64]domain.EquityPoint),
+		reports:                make(map[string]domain.Report),
+		deals:                  make(map[string]domain.Deal),
+		reconcileIssues:        make(map[string]domain.ReconcileIssue),
+		accounts:               make(map[string]domain.Account),
 	}
 }
 
@@ -55,6 +84,10 @@ func (s *Store) IssueEASession(accountID, deviceID string) domain.EASession {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if _, ok := s.accounts[accountID]; !ok {
+		now := time.Now().UTC()
+		s.accounts[accountID] = domain.Account{ID: accountID, CreatedAt: now, UpdatedAt: now}
+	}
 	token := uuid.NewString()
 	session := domain.EASession{
 		Token:     token,
@@ -67,6 +100,39 @@ func (s *Store) IssueEASession(accountID, deviceID string) domain.EASession {
 	return session
 }
 
+func (s *Store) SaveAccount(account domain.Account) domain.Account {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.accounts[account.ID]; ok {
+		account.CreatedAt = existing.CreatedAt
+	} else if account.CreatedAt.IsZero() {
+		account.CreatedAt = time.Now().UTC()
+	}
+	if account.UpdatedAt.IsZero() {
+		account.UpdatedAt = time.Now().UTC()
+	}
+	s.accounts[account.ID] = account
+	return account
+}
+
+func (s *Store) Account(id string) (domain.Account, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	account, ok := s.accounts[id]
+	return account, ok
+}
+
+func (s *Store) ListAccounts() []domain.Account {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]domain.Account, 0, len(s.accounts))
+	for _, account := range s.accounts {
+		out = append(out, account)
+	}
+	slices.SortFunc(out, func(a, b domain.Account) int { return strings.Compare(a.ID, b.ID) })
+	return out
+}
+
 func (s *Store) ValidateEASession(token string) (domain.EASession, error) {
 	s.mu.RLock()
 	session, ok := s.eaSessions[token]
@@ -92,6 +158,13 @@ func (s *Store) SavePositionSnapshot(accountID string, snapshot map[string]inter
 	s.positionSnapshots[accountID] = snapshot
 }
 
+func (s *Store) PositionSnapshot(accountID string) (map[string]interface{}, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	snapshot, ok := s.positionSnapshots[accountID]
+	return snapshot, ok
+}
+
 func (s *Store) EnqueueCommand(cmd domain.Command) domain.Command {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -144,17 +217,111 @@ func (s *Store) MarkCommandResult(result domain.CommandResult) (domain.Command,
 	}
 	if result.Status == "SUCCESS" {
 		cmd.Status = domain.CommandStatusSuccess
+		if result.FillPrice > 0 {
+			cmd.RequestedPrice = result.RequestedPrice
+			cmd.FillPrice = result.FillPrice
+			cmd.SlippagePips = result.SlippagePips
+			cmd.LatencyMS = result.LatencyMS
+			cmd.FilledAt = time.Now().UTC()
+		}
 	} else {
 		cmd.Status = domain.CommandStatusFailed
 	}
 	s.commands[result.CommandID] = cmd
+	result.AccountID = cmd.AccountID
+	result.ReceivedAt = time.Now().UTC()
+	s.results = append(s.results, result)
 	return cmd, nil
 }
 
+// The memory store copies matching records under the lock and calls fn
+// after releasing it, so a slow reader does not block writers.
+
+func (s *Store) StreamCommands(q domain.ExportQuery, fn func(domain.Command) error) error {
+	s.mu.RLock()
+	out := make([]domain.Command, 0, len(s.commandOrder))
+	for _, id := range s.commandOrder {
+		if cmd := s.commands[id]; q.Matches(cmd.AccountID, cmd.CreatedAt) {
+			out = append(out, cmd)
+		}
+	}
+	s.mu.RUnlock()
+	slices.SortStableFunc(out, func(a, b domain.Command) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	for _, cmd := range out {
+		if err := fn(cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) StreamCommandResults(q domain.ExportQuery, fn func(domain.CommandResult) error) error {
+	s.mu.RLock()
+	out := make([]domain.CommandResult, 0, len(s.results))
+	for _, r := range s.results {
+		if q.Matches(r.AccountID, r.ReceivedAt) {
+			out = append(out, r)
+		}
+	}
+	s.mu.RUnlock()
+	for _, r := range out {
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) Command(id string) (domain.Command, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	cmd, ok := s.commands[id]
+	return cmd, ok
+}
+
+func (s *Store) ListFills(symbol string, since time.Time, limit int) []domain.Command {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	out := make([]domain.Command, 0, 32)
+	for _, cmd := range s.commands {
+		if cmd.FilledAt.IsZero() || cmd.Status != domain.CommandStatusSuccess {
+			continue
+		}
+		if symbol != "" && !strings.EqualFold(cmd.Symbol, symbol) {
+			continue
+		}
+		if !since.IsZero() && cmd.FilledAt.Before(since) {
+			continue
+		}
+		out = append(out, cmd)
+	}
+	slices.SortFunc(out, func(a, b domain.Command) int {
+		if c := b.FilledAt.Compare(a.FilledAt); c != 0 {
+			return c
+		}
+		return strings.Compare(b.ID, a.ID)
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
 func (s *Store) SetPaused(paused bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.paused = paused
+	s.pauseOwner = nil
+}
+
+func (s *Store) SetPausedBy(owner domain.PauseOwner) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paused = true
+	s.pauseOwner = &owner
 }
 
 func (s *Store) IsPaused() bool {
@@ -163,6 +330,15 @@ func (s *Store) IsPaused() bool {
 	return s.paused
 }
 
+func (s *Store) PauseOwner() (domain.PauseOwner, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if !s.paused || s.pauseOwner == nil {
+		return domain.PauseOwner{}, false
+	}
+	return *s.pauseOwner, true
+}
+
 func (s *Store) AppendEvent(eventType domain.EventType, accountID string, payload map[string]interface{}) domain.Event {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -192,6 +368,35 @@ func (s *Store) ListEvents(limit int) []domain.Event {
 	return out
 }
 
+func (s *Store) ListAccountEvents(accountID string, from, to time.Time) []domain.Event {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]domain.Event, 0)
+	for _, e := range s.events {
+		if e.AccountID == accountID && !e.CreatedAt.Before(from) && e.CreatedAt.Before(to) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func (s *Store) StreamEvents(q domain.ExportQuery, fn func(domain.Event) error) error {
+	s.mu.RLock()
+	out := make([]domain.Event, 0, len(s.events))
+	for _, e := range s.events {
+		if q.Matches(e.AccountID, e.CreatedAt) {
+			out = append(out, e)
+		}
+	}
+	s.mu.RUnlock()
+	for _, e := range out {
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *Store) OpenPositions(accountID string) int {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -228,6 +433,487 @@ func (s *Store) SetDailyLoss(accountID string, lossPct float64) {
 	s.dailyLossByAccount[accountID] = lossPct
 }
 
+func (s *Store) AccountRiskState(accountID string) (domain.AccountRiskState, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	state, ok := s.accountRisk[accountID]
+	return state, ok
+}
+
+func (s *Store) SaveAccountRiskState(state domain.AccountRiskState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accountRisk[state.AccountID] = state
+}
+
+func (s *Store) TradeActivity(accountID string) (domain.TradeActivity, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	activity, ok := s.tradeActivity[accountID]
+	return activity, ok
+}
+
+func (s *Store) SaveTradeActivity(activity domain.TradeActivity) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tradeActivity[activity.AccountID] = activity
+}
+
+func (s *Store) SaveCandles(symbol, timeframe string, candles []domain.Candle) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := candleKey(symbol, timeframe)
+	series, ok := s.candles[key]
+	if !ok {
+		series = make(map[int64]domain.Candle, len(candles))
+		s.candles[key] = series
+	}
+	for _, c := range candles {
+		c.Time = c.Time.UTC()
+		series[c.Time.Unix()] = c
+	}
+}
+
+func (s *Store) ListCandles(symbol, timeframe string, from, to time.Time, limit int) []domain.Candle {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	series := s.candles[candleKey(symbol, timeframe)]
+	out := make([]domain.Candle, 0, len(series))
+	for _, c := range series {
+		if !from.IsZero() && c.Time.Before(from) {
+			continue
+		}
+		if !to.IsZero() && c.Time.After(to) {
+			continue
+		}
+		out = append(out, c)
+	}
+	slices.SortFunc(out, func(a, b domain.Candle) int {
+		return a.Time.Compare(b.Time)
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[len(out)-limit:]
+	}
+	return out
+}
+
+func candleKey(symbol, timeframe string) string {
+	return strings.ToUpper(strings.TrimSpace(symbol)) + "|" + strings.ToUpper(strings.TrimSpace(timeframe))
+}
+
+func (s *Store) SaveSymbolSpec(spec domain.SymbolSpec) domain.SymbolSpec {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	spec.Symbol = strings.ToUpper(strings.TrimSpace(spec.Symbol))
+	if spec.Source == "" {
+		spec.Source = domain.SymbolSpecSourceEA
+	}
+	spec.UpdatedAt = time.Now().UTC()
+	bySource, ok := s.symbolSpecs[spec.Symbol]
+	if !ok {
+		bySource = make(map[string]domain.SymbolSpec)
+		s.symbolSpecs[spec.Symbol] = bySource
+	}
+	bySource[spec.Source] = spec
+	return spec
+}
+
+func (s *Store) ListSymbolSpecs(symbol string) []domain.SymbolSpec {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	out := make([]domain.SymbolSpec, 0, len(s.symbolSpecs))
+	for sym, bySource := range s.symbolSpecs {
+		if symbol != "" && sym != symbol {
+			continue
+		}
+		for _, spec := range bySource {
+			out = append(out, spec)
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.SymbolSpec) int {
+		if c := strings.Compare(a.Symbol, b.Symbol); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Source, b.Source)
+	})
+	return out
+}
+
+func (s *Store) DeleteSymbolSpec(symbol, source string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	bySource, ok := s.symbolSpecs[symbol]
+	if !ok {
+		return false
+	}
+	if _, ok := bySource[source]; !ok {
+		return false
+	}
+	delete(bySource, source)
+	if len(bySource) == 0 {
+		delete(s.symbolSpecs, symbol)
+	}
+	return true
+}
+
+func (s *Store) SaveBlackout(b domain.Blackout) domain.Blackout {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if b.ID == "" {
+		b.ID = uuid.NewString()
+	}
+	if b.CreatedAt.IsZero() {
+		b.CreatedAt = time.Now().UTC()
+	}
+	s.blackouts[b.ID] = b
+	return b
+}
+
+func (s *Store) ListBlackouts(from, to time.Time) []domain.Blackout {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]domain.Blackout, 0, len(s.blackouts))
+	for _, b := range s.blackouts {
+		if (!from.IsZero() && b.End.Before(from)) || (!to.IsZero() && b.Start.After(to)) {
+			continue
+		}
+		out = append(out, b)
+	}
+	slices.SortFunc(out, func(a, b domain.Blackout) int {
+		if c := a.Start.Compare(b.Start); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return out
+}
+
+func (s *Store) DeleteBlackout(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.blackouts[id]; !ok {
+		return false
+	}
+	delete(s.blackouts, id)
+	return true
+}
+
+func (s *Store) SaveTrade(trade domain.Trade) domain.Trade {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if trade.ID == "" {
+		trade.ID = uuid.NewString()
+	}
+	if trade.Modifications == nil {
+		trade.Modifications = []domain.TradeModification{}
+	}
+	trade.Modifications = slices.Clone(trade.Modifications)
+	s.trades[trade.ID] = trade
+	return trade
+}
+
+func (s *Store) Trade(id string) (domain.Trade, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	trade, ok := s.trades[id]
+	trade.Modifications = slices.Clone(trade.Modifications)
+	return trade, ok
+}
+
+func (s *Store) OpenTradeByTicket(accountID, ticket string) (domain.Trade, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, trade := range s.trades {
+		if trade.AccountID == accountID && trade.Ticket == ticket && trade.Status == domain.TradeStatusOpen {
+			trade.Modifications = slices.Clone(trade.Modifications)
+			return trade, true
+		}
+	}
+	return domain.Trade{}, false
+}
+
+func (s *Store) ListTrades(q domain.TradeQuery) []domain.Trade {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]domain.Trade, 0, len(s.trades))
+	for _, trade := range s.trades {
+		if q.AccountID != "" && trade.AccountID != q.AccountID {
+			continue
+		}
+		if q.Symbol != "" && !strings.EqualFold(trade.Symbol, q.Symbol) {
+			continue
+		}
+		if q.Ticket != "" && trade.Ticket != q.Ticket {
+			continue
+		}
+		if q.Strategy != "" && trade.Strategy != q.Strategy {
+			continue
+		}
+		if q.Status != "" && trade.Status != q.Status {
+			continue
+		}
+		if (!q.From.IsZero() && trade.OpenedAt.Before(q.From)) || (!q.To.IsZero() && trade.OpenedAt.After(q.To)) {
+			continue
+		}
+		if (!q.ClosedFrom.IsZero() || !q.ClosedTo.IsZero()) && trade.ClosedAt.IsZero() {
+			continue
+		}
+		if (!q.ClosedFrom.IsZero() && trade.ClosedAt.Before(q.ClosedFrom)) || (!q.ClosedTo.IsZero() && trade.ClosedAt.After(q.ClosedTo)) {
+			continue
+		}
+		trade.Modifications = slices.Clone(trade.Modifications)
+		out = append(out, trade)
+	}
+	slices.SortFunc(out, func(a, b domain.Trade) int {
+		if c := b.OpenedAt.Compare(a.OpenedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(b.ID, a.ID)
+	})
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[:q.Limit]
+	}
+	return out
+}
+
+func (s *Store) SaveReport(r domain.Report) domain.Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.ID == "" {
+		r.ID = uuid.NewString()
+	}
+	r.RiskTriggers = maps.Clone(r.RiskTriggers)
+	s.reports[r.ID] = r
+	return r
+}
+
+func (s *Store) Report(id string) (domain.Report, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r, ok := s.reports[id]
+	r.RiskTriggers = maps.Clone(r.RiskTriggers)
+	return r, ok
+}
+
+func (s *Store) ListReports(q domain.ReportQuery) []domain.Report {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]domain.Report, 0, len(s.reports))
+	for _, r := range s.reports {
+		if q.AccountID != "" && r.AccountID != q.AccountID {
+			continue
+		}
+		if q.Kind != "" && r.Kind != q.Kind {
+			continue
+		}
+		if (!q.From.IsZero() && r.From.Before(q.From)) || (!q.To.IsZero() && r.From.After(q.To)) {
+			continue
+		}
+		r.RiskTriggers = maps.Clone(r.RiskTriggers)
+		out = append(out, r)
+	}
+	slices.SortFunc(out, func(a, b domain.Report) int {
+		if c := b.From.Compare(a.From); c != 0 {
+			return c
+		}
+		return strings.Compare(b.ID, a.ID)
+	})
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[:q.Limit]
+	}
+	return out
+}
+
+func (s *Store) StreamTrades(q domain.ExportQuery, fn func(domain.Trade) error) error {
+	s.mu.RLock()
+	out := make([]domain.Trade, 0, len(s.trades))
+	for _, trade := range s.trades {
+		if q.Matches(trade.AccountID, trade.OpenedAt) {
+			trade.Modifications = slices.Clone(trade.Modifications)
+			out = append(out, trade)
+		}
+	}
+	s.mu.RUnlock()
+	slices.SortFunc(out, func(a, b domain.Trade) int {
+		if c := a.OpenedAt.Compare(b.OpenedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	for _, trade := range out {
+		if err := fn(trade); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) SaveDeals(deals []domain.Deal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, d := range deals {
+		s.deals[d.AccountID+"|"+d.Ticket] = d
+	}
+}
+
+func (s *Store) ListDeals(q domain.DealQuery) []domain.Deal {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]domain.Deal, 0, len(s.deals))
+	for _, d := range s.deals {
+		if q.AccountID != "" && d.AccountID != q.AccountID {
+			continue
+		}
+		if q.Symbol != "" && !strings.EqualFold(d.Symbol, q.Symbol) {
+			continue
+		}
+		if (!q.From.IsZero() && d.Time.Before(q.From)) || (!q.To.IsZero() && d.Time.After(q.To)) {
+			continue
+		}
+		out = append(out, d)
+	}
+	slices.SortFunc(out, func(a, b domain.Deal) int {
+		if c := b.Time.Compare(a.Time); c != 0 {
+			return c
+		}
+		return strings.Compare(b.Ticket, a.Ticket)
+	})
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[:q.Limit]
+	}
+	return out
+}
+
+func (s *Store) SaveReconcileIssue(issue domain.ReconcileIssue) domain.ReconcileIssue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reconcileIssues[issue.AccountID+"|"+issue.ID] = issue
+	return issue
+}
+
+func (s *Store) ReconcileIssue(accountID, id string) (domain.ReconcileIssue, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	issue, ok := s.reconcileIssues[accountID+"|"+id]
+	return issue, ok
+}
+
+func (s *Store) ListReconcileIssues(q domain.ReconcileQuery) []domain.ReconcileIssue {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]domain.ReconcileIssue, 0, len(s.reconcileIssues))
+	for _, issue := range s.reconcileIssues {
+		if q.AccountID != "" && issue.AccountID != q.AccountID {
+			continue
+		}
+		if q.Kind != "" && issue.Kind != q.Kind {
+			continue
+		}
+		if (q.Status == "open" && !issue.Open()) || (q.Status == "resolved" && issue.Open()) {
+			continue
+		}
+		out = append(out, issue)
+	}
+	slices.SortFunc(out, func(a, b domain.ReconcileIssue) int {
+		if c := b.DetectedAt.Compare(a.DetectedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(b.ID, a.ID)
+	})
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[:q.Limit]
+	}
+	return out
+}
+
+func (s *Store) SaveEquityPoint(p domain.EquityPoint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := equityKey(p.AccountID, p.Resolution)
+	series, ok := s.equityPoints[key]
+	if !ok {
+		series = make(map[int64]domain.EquityPoint)
+		s.equityPoints[key] = series
+	}
+	p.Time = p.Time.UTC()
+	series[p.Time.UnixNano()] = p
+}
+
+func (s *Store) LatestEquityPoint(accountID, resolution string) (domain.EquityPoint, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var latest domain.EquityPoint
+	found := false
+	for _, p := range s.equityPoints[equityKey(accountID, resolution)] {
+		if !found || p.Time.After(latest.Time) {
+			latest, found = p, true
+		}
+	}
+	return latest, found
+}
+
+func (s *Store) ListEquityPoints(accountID, resolution string, from, to time.Time) []domain.EquityPoint {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	series := s.equityPoints[equityKey(accountID, resolution)]
+	out := make([]domain.EquityPoint, 0, len(series))
+	for _, p := range series {
+		if (!from.IsZero() && p.Time.Before(from)) || (!to.IsZero() && p.Time.After(to)) {
+			continue
+		}
+		out = append(out, p)
+	}
+	slices.SortFunc(out, func(a, b domain.EquityPoint) int { return a.Time.Compare(b.Time) })
+	return out
+}
+
+func (s *Store) PruneEquityPoints(accountID, resolution string, before time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	series := s.equityPoints[equityKey(accountID, resolution)]
+	n := 0
+	for k, p := range series {
+		if p.Time.Before(before) {
+			delete(series, k)
+			n++
+		}
+	}
+	return n
+}
+
+func equityKey(accountID, resolution string) string {
+	return accountID + "|" + resolution
+}
+
+func (s *Store) SymbolPause(symbol string) (domain.SymbolPause, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	pause, ok := s.symbolPauses[strings.ToUpper(strings.TrimSpace(symbol))]
+	return pause, ok
+}
+
+func (s *Store) SaveSymbolPause(pause domain.SymbolPause) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pause.Symbol = strings.ToUpper(strings.TrimSpace(pause.Symbol))
+	s.symbolPauses[pause.Symbol] = pause
+}
+
+func (s *Store) ListSymbolPauses() []domain.SymbolPause {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]domain.SymbolPause, 0, len(s.symbolPauses))
+	for _, p := range s.symbolPauses {
+		out = append(out, p)
+	}
+	slices.SortFunc(out, func(a, b domain.SymbolPause) int {
+		return strings.Compare(a.Symbol, b.Symbol)
+	})
+	return out
+}
+
 func (s *Store) SaveOAuthState(state domain.OAuthState) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
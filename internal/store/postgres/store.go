@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -92,6 +93,52 @@ func (s *Store) IssueEASession(accountID, deviceID string) domain.EASession {
 	}
 }
 
+func (s *Store) SaveAccount(account domain.Account) domain.Account {
+	if account.UpdatedAt.IsZero() {
+		account.UpdatedAt = time.Now().UTC()
+	}
+	_ = s.db.QueryRow(
+		`insert into broker_accounts(id, broker_name, mode, currency, updated_at) values ($1, 'mt5', 'paper', $2, $3)
+		 on conflict (id) do update
+		 set currency = excluded.currency,
+		     updated_at = excluded.updated_at
+		 returning created_at`,
+		account.ID, account.Currency, account.UpdatedAt,
+	).Scan(&account.CreatedAt)
+	return account
+}
+
+func (s *Store) Account(id string) (domain.Account, bool) {
+	account, err := scanAccount(s.db.QueryRow(`select `+accountColumns+` from broker_accounts where id = $1`, id))
+	return account, err == nil
+}
+
+func (s *Store) ListAccounts() []domain.Account {
+	rows, err := s.db.Query(`select ` + accountColumns + ` from broker_accounts order by id`)
+	if err != nil {
+		return []domain.Account{}
+	}
+	defer rows.Close()
+
+	out := make([]domain.Account, 0, 4)
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			continue
+		}
+		out = append(out, account)
+	}
+	return out
+}
+
+const accountColumns = `id, currency, created_at, updated_at`
+
+func scanAccount(row interface{ Scan(...interface{}) error }) (domain.Account, error) {
+	var account domain.Account
+	err := row.Scan(&account.ID, &account.Currency, &account.CreatedAt, &account.UpdatedAt)
+	return account, err
+}
+
 func (s *Store) ValidateEASession(token string) (domain.EASession, error) {
 	tokenHash := hashToken(token)
 	var accountID, deviceID string
@@ -140,6 +187,19 @@ func (s *Store) SavePositionSnapshot(accountID string, snapshot map[string]inter
 	)
 }
 
+func (s *Store) PositionSnapshot(accountID string) (map[string]interface{}, bool) {
+	var raw []byte
+	err := s.db.QueryRow(`select snapshot from position_snapshots where account_id = $1`, accountID).Scan(&raw)
+	if err != nil {
+		return nil, false
+	}
+	var snapshot map[string]interface{}
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		return nil, false
+	}
+	return snapshot, true
+}
+
 func (s *Store) EnqueueCommand(cmd domain.Command) domain.Command {
 	if cmd.ID == "" {
 		cmd.ID = uuid.NewString()
@@ -152,8 +212,8 @@ func (s *Store) EnqueueCommand(cmd domain.Command) domain.Command {
 	}
 	_, _ = s.db.Exec(
 		`insert into commands(
-			id, account_id, type, symbol, side, volume, sl, tp, reason, status, expires_at, created_at, updated_at
-		) values ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,now())`,
+			id, account_id, type, symbol, side, volume, sl, tp, reason, strategy, status, expires_at, created_at, updated_at
+		) values ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,now())`,
 		cmd.ID,
 		cmd.AccountID,
 		string(cmd.Type),
@@ -163,6 +223,7 @@ func (s *Store) EnqueueCommand(cmd domain.Command) domain.Command {
 		cmd.SL,
 		cmd.TP,
 		cmd.Reason,
+		cmd.Strategy,
 		string(cmd.Status),
 		cmd.ExpiresAt,
 		cmd.CreatedAt,
@@ -226,15 +287,35 @@ func (s *Store) NextQueuedCommand(accountID string) (domain.Command, error) {
 	return cmd, nil
 }
 
+// MarkCommandResult updates the command and records the result in one
+// transaction, so a command never changes status without its result row.
 func (s *Store) MarkCommandResult(result domain.CommandResult) (domain.Command, error) {
 	newStatus := domain.CommandStatusFailed
 	if result.Status == "SUCCESS" {
 		newStatus = domain.CommandStatusSuccess
 	}
-	res, err := s.db.Exec(
-		`update commands set status = $2, updated_at = now() where id = $1`,
-		result.CommandID, string(newStatus),
-	)
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return domain.Command{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var res sql.Result
+	if newStatus == domain.CommandStatusSuccess && result.FillPrice > 0 {
+		res, err = tx.Exec(
+			`update commands
+			 set status = $2, requested_price = $3, fill_price = $4, slippage_pips = $5,
+			     latency_ms = $6, filled_at = now(), updated_at = now()
+			 where id = $1`,
+			result.CommandID, string(newStatus),
+			result.RequestedPrice, result.FillPrice, result.SlippagePips, result.LatencyMS,
+		)
+	} else {
+		res, err = tx.Exec(
+			`update commands set status = $2, updated_at = now() where id = $1`,
+			result.CommandID, string(newStatus),
+		)
+	}
 	if err != nil {
 		return domain.Command{}, err
 	}
@@ -242,14 +323,144 @@ func (s *Store) MarkCommandResult(result domain.CommandResult) (domain.Command,
 	if affected == 0 {
 		return domain.Command{}, ErrNotFound
 	}
+	cmd, err := scanCommand(tx.QueryRow(`select `+commandColumns+` from commands where id = $1`, result.CommandID))
+	if err != nil {
+		return domain.Command{}, err
+	}
+	if _, err := tx.Exec(
+		`insert into command_results(command_id, account_id, status, broker_ticket, error_code, error_message,
+		                             executed_at, requested_price, fill_price, slippage_pips, latency_ms, received_at)
+		 values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, now())`,
+		result.CommandID, cmd.AccountID, result.Status, result.BrokerTicket, result.ErrorCode, result.ErrorMessage,
+		result.ExecutedAt, result.RequestedPrice, result.FillPrice, result.SlippagePips, result.LatencyMS,
+	); err != nil {
+		return domain.Command{}, fmt.Errorf("record command result: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.Command{}, err
+	}
+	return cmd, nil
+}
 
+// exportArgs turns an export query into the account, from and to arguments
+// of the stream queries.
+func exportArgs(q domain.ExportQuery) (string, interface{}, interface{}) {
+	var fromArg, toArg interface{}
+	if !q.From.IsZero() {
+		fromArg = q.From
+	}
+	if !q.To.IsZero() {
+		toArg = q.To
+	}
+	return q.AccountID, fromArg, toArg
+}
+
+func (s *Store) StreamCommands(q domain.ExportQuery, fn func(domain.Command) error) error {
+	accountID, fromArg, toArg := exportArgs(q)
+	rows, err := s.db.Query(
+		`select `+commandColumns+`
+		 from commands
+		 where ($1::text = '' or account_id = $1)
+		   and ($2::timestamptz is null or created_at >= $2)
+		   and ($3::timestamptz is null or created_at < $3)
+		 order by created_at asc, id asc`,
+		accountID, fromArg, toArg,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		cmd, err := scanCommand(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(cmd); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (s *Store) StreamCommandResults(q domain.ExportQuery, fn func(domain.CommandResult) error) error {
+	accountID, fromArg, toArg := exportArgs(q)
+	rows, err := s.db.Query(
+		`select command_id, account_id, status, broker_ticket, error_code, error_message, executed_at,
+		        requested_price, fill_price, slippage_pips, latency_ms, received_at
+		 from command_results
+		 where ($1::text = '' or account_id = $1)
+		   and ($2::timestamptz is null or received_at >= $2)
+		   and ($3::timestamptz is null or received_at < $3)
+		 order by received_at asc, id asc`,
+		accountID, fromArg, toArg,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var r domain.CommandResult
+		if err := rows.Scan(
+			&r.CommandID, &r.AccountID, &r.Status, &r.BrokerTicket, &r.ErrorCode, &r.ErrorMessage, &r.ExecutedAt,
+			&r.RequestedPrice, &r.FillPrice, &r.SlippagePips, &r.LatencyMS, &r.ReceivedAt,
+		); err != nil {
+			return err
+		}
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (s *Store) Command(id string) (domain.Command, bool) {
+	cmd, err := scanCommand(s.db.QueryRow(`select `+commandColumns+` from commands where id = $1`, id))
+	return cmd, err == nil
+}
+
+func (s *Store) ListFills(symbol string, since time.Time, limit int) []domain.Command {
+	var sinceArg interface{}
+	if !since.IsZero() {
+		sinceArg = since
+	}
+	if limit <= 0 {
+		limit = 1000
+	}
+	rows, err := s.db.Query(
+		`select `+commandColumns+`
+		 from commands
+		 where status = $1 and filled_at is not null
+		   and ($2::text = '' or upper(symbol) = $2)
+		   and ($3::timestamptz is null or filled_at >= $3)
+		 order by filled_at desc, id desc
+		 limit $4`,
+		string(domain.CommandStatusSuccess), strings.ToUpper(strings.TrimSpace(symbol)), sinceArg, limit,
+	)
+	if err != nil {
+		return []domain.Command{}
+	}
+	defer rows.Close()
+
+	out := make([]domain.Command, 0, 32)
+	for rows.Next() {
+		cmd, err := scanCommand(rows)
+		if err != nil {
+			continue
+		}
+		out = append(out, cmd)
+	}
+	return out
+}
+
+const commandColumns = `id, account_id, type, symbol, side, volume, sl, tp, reason, strategy, status, expires_at, created_at,
+	requested_price, fill_price, slippage_pips, latency_ms, filled_at`
+
+// scanCommand reads a row selected with commandColumns.
+func scanCommand(row interface{ Scan(...interface{}) error }) (domain.Command, error) {
 	var cmd domain.Command
 	var cmdType, status string
-	err = s.db.QueryRow(
-		`select id, account_id, type, symbol, side, volume, sl, tp, reason, status, expires_at, created_at
-		 from commands where id = $1`,
-		result.CommandID,
-	).Scan(
+	var filledAt sql.NullTime
+	err := row.Scan(
 		&cmd.ID,
 		&cmd.AccountID,
 		&cmdType,
@@ -259,20 +470,45 @@ func (s *Store) MarkCommandResult(result domain.CommandResult) (domain.Command,
 		&cmd.SL,
 		&cmd.TP,
 		&cmd.Reason,
+		&cmd.Strategy,
 		&status,
 		&cmd.ExpiresAt,
 		&cmd.CreatedAt,
+		&cmd.RequestedPrice,
+		&cmd.FillPrice,
+		&cmd.SlippagePips,
+		&cmd.LatencyMS,
+		&filledAt,
 	)
 	if err != nil {
 		return domain.Command{}, err
 	}
 	cmd.Type = domain.CommandType(cmdType)
 	cmd.Status = domain.CommandStatus(status)
+	if filledAt.Valid {
+		cmd.FilledAt = filledAt.Time
+	}
 	return cmd, nil
 }
 
 func (s *Store) SetPaused(paused bool) {
 	raw, _ := json.Marshal(map[string]bool{"paused": paused})
+	s.savePauseState(raw)
+}
+
+// SetPausedBy stores the owner in the global_paused row, so SetPaused
+// replacing the row also clears it.
+func (s *Store) SetPausedBy(owner domain.PauseOwner) {
+	raw, _ := json.Marshal(pauseState{Paused: true, Owner: &owner})
+	s.savePauseState(raw)
+}
+
+type pauseState struct {
+	Paused bool               `json:"paused"`
+	Owner  *domain.PauseOwner `json:"owner,omitempty"`
+}
+
+func (s *Store) savePauseState(raw []byte) {
 	_, _ = s.db.Exec(
 		`insert into app_state(key, value_json, updated_at)
 		 values ('global_paused', $1::jsonb, now())
@@ -283,16 +519,28 @@ func (s *Store) SetPaused(paused bool) {
 }
 
 func (s *Store) IsPaused() bool {
+	return s.pauseState().Paused
+}
+
+func (s *Store) PauseOwner() (domain.PauseOwner, bool) {
+	state := s.pauseState()
+	if !state.Paused || state.Owner == nil {
+		return domain.PauseOwner{}, false
+	}
+	return *state.Owner, true
+}
+
+func (s *Store) pauseState() pauseState {
 	var raw []byte
 	err := s.db.QueryRow(`select value_json from app_state where key = 'global_paused'`).Scan(&raw)
 	if err != nil {
-		return false
+		return pauseState{}
 	}
-	var payload map[string]bool
-	if err := json.Unmarshal(raw, &payload); err != nil {
-		return false
+	var state pauseState
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return pauseState{}
 	}
-	return payload["paused"]
+	return state
 }
 
 func (s *Store) AppendEvent(eventType domain.EventType, accountID string, payload map[string]interface{}) domain.Event {
@@ -344,6 +592,73 @@ func (s *Store) ListEvents(limit int) []domain.Event {
 	return out
 }
 
+func (s *Store) ListAccountEvents(accountID string, from, to time.Time) []domain.Event {
+	rows, err := s.db.Query(
+		`select id, account_id, event_type, payload, created_at
+		 from events
+		 where account_id = $1 and created_at >= $2 and created_at < $3
+		 order by created_at asc`,
+		accountID, from.UTC(), to.UTC(),
+	)
+	if err != nil {
+		return []domain.Event{}
+	}
+	defer rows.Close()
+
+	out := make([]domain.Event, 0, 64)
+	for rows.Next() {
+		e, err := scanEvent(rows)
+		if err != nil {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+func (s *Store) StreamEvents(q domain.ExportQuery, fn func(domain.Event) error) error {
+	accountID, fromArg, toArg := exportArgs(q)
+	rows, err := s.db.Query(
+		`select id, coalesce(account_id, ''), event_type, payload, created_at
+		 from events
+		 where ($1::text = '' or account_id = $1)
+		   and ($2::timestamptz is null or created_at >= $2)
+		   and ($3::timestamptz is null or created_at < $3)
+		 order by created_at asc, id asc`,
+		accountID, fromArg, toArg,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		e, err := scanEvent(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// scanEvent reads a row of id, account_id, event_type, payload, created_at.
+func scanEvent(row interface{ Scan(...interface{}) error }) (domain.Event, error) {
+	var e domain.Event
+	var eventType string
+	var payloadRaw []byte
+	if err := row.Scan(&e.ID, &e.AccountID, &eventType, &payloadRaw, &e.CreatedAt); err != nil {
+		return domain.Event{}, err
+	}
+	e.Type = domain.EventType(eventType)
+	_ = json.Unmarshal(payloadRaw, &e.Payload)
+	if e.Payload == nil {
+		e.Payload = map[string]interface{}{}
+	}
+	return e, nil
+}
+
 func (s *Store) OpenPositions(accountID string) int {
 	var n int
 	err := s.db.QueryRow(`select open_positions from daily_risk_state where account_id = $1`, accountID).Scan(&n)
@@ -398,6 +713,866 @@ func (s *Store) SetDailyLoss(accountID string, lossPct float64) {
 	)
 }
 
+func (s *Store) AccountRiskState(accountID string) (domain.AccountRiskState, bool) {
+	state := domain.AccountRiskState{AccountID: accountID}
+	var pausedAt sql.NullTime
+	err := s.db.QueryRow(
+		`select peak_equity, peak_at, week_start, week_start_equity, month_start, month_start_equity,
+		        last_equity, paused, pause_reason, paused_at, updated_at
+		 from account_risk_state where account_id = $1`,
+		accountID,
+	).Scan(
+		&state.PeakEquity, &state.PeakAt, &state.WeekStart, &state.WeekStartEquity,
+		&state.MonthStart, &state.MonthStartEquity, &state.LastEquity,
+		&state.Paused, &state.PauseReason, &pausedAt, &state.UpdatedAt,
+	)
+	if err != nil {
+		return domain.AccountRiskState{}, false
+	}
+	if pausedAt.Valid {
+		state.PausedAt = pausedAt.Time
+	}
+	return state, true
+}
+
+func (s *Store) SaveAccountRiskState(state domain.AccountRiskState) {
+	var pausedAt interface{}
+	if !state.PausedAt.IsZero() {
+		pausedAt = state.PausedAt
+	}
+	_, _ = s.db.Exec(
+		`insert into account_risk_state(account_id, peak_equity, peak_at, week_start, week_start_equity,
+		     month_start, month_start_equity, last_equity, paused, pause_reason, paused_at, updated_at)
+		 values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		 on conflict (account_id) do update
+		 set peak_equity = excluded.peak_equity,
+		     peak_at = excluded.peak_at,
+		     week_start = excluded.week_start,
+		     week_start_equity = excluded.week_start_equity,
+		     month_start = excluded.month_start,
+		     month_start_equity = excluded.month_start_equity,
+		     last_equity = excluded.last_equity,
+		     paused = excluded.paused,
+		     pause_reason = excluded.pause_reason,
+		     paused_at = excluded.paused_at,
+		     updated_at = excluded.updated_at`,
+		state.AccountID, state.PeakEquity, state.PeakAt, state.WeekStart, state.WeekStartEquity,
+		state.MonthStart, state.MonthStartEquity, state.LastEquity, state.Paused, state.PauseReason,
+		pausedAt, state.UpdatedAt,
+	)
+}
+
+func (s *Store) TradeActivity(accountID string) (domain.TradeActivity, bool) {
+	a := domain.TradeActivity{AccountID: accountID}
+	var cooldownUntil, lastCloseAt, dailyBreakerAt sql.NullTime
+	err := s.db.QueryRow(
+		`select day, trades_today, loss_streak, cooldown_until, last_close_at, day_start_equity, daily_breaker_at, updated_at
+		 from trade_activity where account_id = $1`,
+		accountID,
+	).Scan(&a.Day, &a.TradesToday, &a.LossStreak, &cooldownUntil, &lastCloseAt, &a.DayStartEquity, &dailyBreakerAt, &a.UpdatedAt)
+	if err != nil {
+		return domain.TradeActivity{}, false
+	}
+	if cooldownUntil.Valid {
+		a.CooldownUntil = cooldownUntil.Time
+	}
+	if lastCloseAt.Valid {
+		a.LastCloseAt = lastCloseAt.Time
+	}
+	if dailyBreakerAt.Valid {
+		a.DailyBreakerAt = dailyBreakerAt.Time
+	}
+	return a, true
+}
+
+func (s *Store) SaveTradeActivity(activity domain.TradeActivity) {
+	var cooldownUntil, lastCloseAt, dailyBreakerAt interface{}
+	if !activity.CooldownUntil.IsZero() {
+		cooldownUntil = activity.CooldownUntil
+	}
+	if !activity.LastCloseAt.IsZero() {
+		lastCloseAt = activity.LastCloseAt
+	}
+	if !activity.DailyBreakerAt.IsZero() {
+		dailyBreakerAt = activity.DailyBreakerAt
+	}
+	_, _ = s.db.Exec(
+		`insert into trade_activity(account_id, day, trades_today, loss_streak, cooldown_until, last_close_at,
+		                            day_start_equity, daily_breaker_at, updated_at)
+		 values ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		 on conflict (account_id) do update
+		 set day = excluded.day,
+		     trades_today = excluded.trades_today,
+		     loss_streak = excluded.loss_streak,
+		     cooldown_until = excluded.cooldown_until,
+		     last_close_at = excluded.last_close_at,
+		     day_start_equity = excluded.day_start_equity,
+		     daily_breaker_at = excluded.daily_breaker_at,
+		     updated_at = excluded.updated_at`,
+		activity.AccountID, activity.Day, activity.TradesToday, activity.LossStreak,
+		cooldownUntil, lastCloseAt, activity.DayStartEquity, dailyBreakerAt, activity.UpdatedAt,
+	)
+}
+
+func (s *Store) SaveCandles(symbol, timeframe string, candles []domain.Candle) {
+	if len(candles) == 0 {
+		return
+	}
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	timeframe = strings.ToUpper(strings.TrimSpace(timeframe))
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+	stmt, err := tx.Prepare(
+		`insert into candles(symbol, timeframe, ts, open, high, low, close, volume)
+		 values ($1, $2, $3, $4, $5, $6, $7, $8)
+		 on conflict (symbol, timeframe, ts) do update
+		 set open = excluded.open,
+		     high = excluded.high,
+		     low = excluded.low,
+		     close = excluded.close,
+		     volume = excluded.volume`,
+	)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+	for _, c := range candles {
+		if _, err := stmt.Exec(symbol, timeframe, c.Time.UTC(), c.Open, c.High, c.Low, c.Close, c.Volume); err != nil {
+			return
+		}
+	}
+	_ = tx.Commit()
+}
+
+func (s *Store) ListCandles(symbol, timeframe string, from, to time.Time, limit int) []domain.Candle {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	timeframe = strings.ToUpper(strings.TrimSpace(timeframe))
+	var fromArg, toArg interface{}
+	if !from.IsZero() {
+		fromArg = from.UTC()
+	}
+	if !to.IsZero() {
+		toArg = to.UTC()
+	}
+	var limitArg interface{}
+	if limit > 0 {
+		limitArg = limit
+	}
+	// Take the most recent rows first so limit keeps the tail, then restore time order.
+	rows, err := s.db.Query(
+		`select ts, open, high, low, close, volume from (
+			select ts, open, high, low, close, volume
+			from candles
+			where symbol = $1 and timeframe = $2
+			  and ($3::timestamptz is null or ts >= $3)
+			  and ($4::timestamptz is null or ts <= $4)
+			order by ts desc
+			limit $5
+		 ) recent order by ts asc`,
+		symbol, timeframe, fromArg, toArg, limitArg,
+	)
+	if err != nil {
+		return []domain.Candle{}
+	}
+	defer rows.Close()
+
+	out := make([]domain.Candle, 0, 256)
+	for rows.Next() {
+		var c domain.Candle
+		if err := rows.Scan(&c.Time, &c.Open, &c.High, &c.Low, &c.Close, &c.Volume); err != nil {
+			continue
+		}
+		c.Time = c.Time.UTC()
+		out = append(out, c)
+	}
+	return out
+}
+
+func (s *Store) SaveSymbolSpec(spec domain.SymbolSpec) domain.SymbolSpec {
+	spec.Symbol = strings.ToUpper(strings.TrimSpace(spec.Symbol))
+	if spec.Source == "" {
+		spec.Source = domain.SymbolSpecSourceEA
+	}
+	spec.UpdatedAt = time.Now().UTC()
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return spec
+	}
+	_, _ = s.db.Exec(
+		`insert into symbol_specs(symbol, source, spec, updated_at)
+		 values ($1, $2, $3::jsonb, $4)
+		 on conflict (symbol, source) do update
+		 set spec = excluded.spec,
+		     updated_at = excluded.updated_at`,
+		spec.Symbol, spec.Source, string(raw), spec.UpdatedAt,
+	)
+	return spec
+}
+
+func (s *Store) ListSymbolSpecs(symbol string) []domain.SymbolSpec {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	rows, err := s.db.Query(
+		`select spec from symbol_specs
+		 where $1 = '' or symbol = $1
+		 order by symbol asc, source asc`,
+		symbol,
+	)
+	if err != nil {
+		return []domain.SymbolSpec{}
+	}
+	defer rows.Close()
+
+	out := make([]domain.SymbolSpec, 0, 16)
+	for rows.Next() {
+		var raw []byte
+		if err := rows.Scan(&raw); err != nil {
+			continue
+		}
+		var spec domain.SymbolSpec
+		if err := json.Unmarshal(raw, &spec); err != nil {
+			continue
+		}
+		out = append(out, spec)
+	}
+	return out
+}
+
+func (s *Store) DeleteSymbolSpec(symbol, source string) bool {
+	res, err := s.db.Exec(
+		`delete from symbol_specs where symbol = $1 and source = $2`,
+		strings.ToUpper(strings.TrimSpace(symbol)), source,
+	)
+	if err != nil {
+		return false
+	}
+	n, _ := res.RowsAffected()
+	return n > 0
+}
+
+func (s *Store) SaveBlackout(b domain.Blackout) domain.Blackout {
+	if b.ID == "" {
+		b.ID = uuid.NewString()
+	}
+	if b.CreatedAt.IsZero() {
+		b.CreatedAt = time.Now().UTC()
+	}
+	_, _ = s.db.Exec(
+		`insert into news_blackouts(id, title, currency, impact, starts_at, ends_at, source, created_at)
+		 values ($1, $2, $3, $4, $5, $6, $7, $8)
+		 on conflict (id) do update
+		 set title = excluded.title,
+		     currency = excluded.currency,
+		     impact = excluded.impact,
+		     starts_at = excluded.starts_at,
+		     ends_at = excluded.ends_at,
+		     source = excluded.source`,
+		b.ID, b.Title, b.Currency, b.Impact, b.Start, b.End, b.Source, b.CreatedAt,
+	)
+	return b
+}
+
+func (s *Store) ListBlackouts(from, to time.Time) []domain.Blackout {
+	var fromArg, toArg interface{}
+	if !from.IsZero() {
+		fromArg = from
+	}
+	if !to.IsZero() {
+		toArg = to
+	}
+	rows, err := s.db.Query(
+		`select id, title, currency, impact, starts_at, ends_at, source, created_at
+		 from news_blackouts
+		 where ($1::timestamptz is null or ends_at >= $1)
+		   and ($2::timestamptz is null or starts_at <= $2)
+		 order by starts_at asc, id asc`,
+		fromArg, toArg,
+	)
+	if err != nil {
+		return []domain.Blackout{}
+	}
+	defer rows.Close()
+
+	out := make([]domain.Blackout, 0, 32)
+	for rows.Next() {
+		var b domain.Blackout
+		if err := rows.Scan(&b.ID, &b.Title, &b.Currency, &b.Impact, &b.Start, &b.End, &b.Source, &b.CreatedAt); err != nil {
+			continue
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+func (s *Store) DeleteBlackout(id string) bool {
+	res, err := s.db.Exec(`delete from news_blackouts where id = $1`, id)
+	if err != nil {
+		return false
+	}
+	n, _ := res.RowsAffected()
+	return n > 0
+}
+
+func (s *Store) SaveTrade(trade domain.Trade) domain.Trade {
+	if trade.ID == "" {
+		trade.ID = uuid.NewString()
+	}
+	if trade.Modifications == nil {
+		trade.Modifications = []domain.TradeModification{}
+	}
+	mods, _ := json.Marshal(trade.Modifications)
+	var closedAt interface{}
+	if !trade.ClosedAt.IsZero() {
+		closedAt = trade.ClosedAt
+	}
+	_, _ = s.db.Exec(
+		`insert into trades(
+			id, account_id, symbol, side, volume, status, ticket, open_command_id, close_command_id,
+			strategy, reason, entry_price, exit_price, stop_loss_pips, take_profit_pips, sl, tp,
+			profit, r_multiple, duration_seconds, modifications, opened_at, closed_at, updated_at, close_reason
+		) values ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25)
+		on conflict (id) do update
+		set volume = excluded.volume,
+		    status = excluded.status,
+		    ticket = excluded.ticket,
+		    close_command_id = excluded.close_command_id,
+		    entry_price = excluded.entry_price,
+		    exit_price = excluded.exit_price,
+		    sl = excluded.sl,
+		    tp = excluded.tp,
+		    profit = excluded.profit,
+		    r_multiple = excluded.r_multiple,
+		    duration_seconds = excluded.duration_seconds,
+		    modifications = excluded.modifications,
+		    closed_at = excluded.closed_at,
+		    updated_at = excluded.updated_at,
+		    close_reason = excluded.close_reason`,
+		trade.ID, trade.AccountID, trade.Symbol, trade.Side, trade.Volume, string(trade.Status), trade.Ticket,
+		trade.OpenCommandID, trade.CloseCommandID, trade.Strategy, trade.Reason, trade.EntryPrice, trade.ExitPrice,
+		trade.StopLossPips, trade.TakeProfitPips, trade.SL, trade.TP, trade.Profit, trade.RMultiple,
+		trade.DurationSeconds, mods, trade.OpenedAt, closedAt, trade.UpdatedAt, trade.CloseReason,
+	)
+	return trade
+}
+
+func (s *Store) Trade(id string) (domain.Trade, bool) {
+	trade, err := scanTrade(s.db.QueryRow(`select `+tradeColumns+` from trades where id = $1`, id))
+	return trade, err == nil
+}
+
+func (s *Store) OpenTradeByTicket(accountID, ticket string) (domain.Trade, bool) {
+	trade, err := scanTrade(s.db.QueryRow(
+		`select `+tradeColumns+` from trades
+		 where account_id = $1 and ticket = $2 and status = $3
+		 order by opened_at desc limit 1`,
+		accountID, ticket, string(domain.TradeStatusOpen),
+	))
+	return trade, err == nil
+}
+
+func (s *Store) ListTrades(q domain.TradeQuery) []domain.Trade {
+	var fromArg, toArg interface{}
+	if !q.From.IsZero() {
+		fromArg = q.From
+	}
+	if !q.To.IsZero() {
+		toArg = q.To
+	}
+	var closedFromArg, closedToArg interface{}
+	if !q.ClosedFrom.IsZero() {
+		closedFromArg = q.ClosedFrom
+	}
+	if !q.ClosedTo.IsZero() {
+		closedToArg = q.ClosedTo
+	}
+	var limit interface{}
+	if q.Limit > 0 {
+		limit = q.Limit
+	}
+	rows, err := s.db.Query(
+		`select `+tradeColumns+`
+		 from trades
+		 where ($1::text = '' or account_id = $1)
+		   and ($2::text = '' or symbol = $2)
+		   and ($3::text = '' or strategy = $3)
+		   and ($4::text = '' or status = $4)
+		   and ($5::timestamptz is null or opened_at >= $5)
+		   and ($6::timestamptz is null or opened_at <= $6)
+		   and ($8::text = '' or ticket = $8)
+		   and ($9::timestamptz is null or closed_at >= $9)
+		   and ($10::timestamptz is null or closed_at <= $10)
+		 order by opened_at desc, id desc
+		 limit $7`,
+		q.AccountID, strings.ToUpper(strings.TrimSpace(q.Symbol)), q.Strategy, string(q.Status), fromArg, toArg, limit, q.Ticket,
+		closedFromArg, closedToArg,
+	)
+	if err != nil {
+		return []domain.Trade{}
+	}
+	defer rows.Close()
+
+	out := make([]domain.Trade, 0, 32)
+	for rows.Next() {
+		trade, err := scanTrade(rows)
+		if err != nil {
+			continue
+		}
+		out = append(out, trade)
+	}
+	return out
+}
+
+func (s *Store) StreamTrades(q domain.ExportQuery, fn func(domain.Trade) error) error {
+	accountID, fromArg, toArg := exportArgs(q)
+	rows, err := s.db.Query(
+		`select `+tradeColumns+`
+		 from trades
+		 where ($1::text = '' or account_id = $1)
+		   and ($2::timestamptz is null or opened_at >= $2)
+		   and ($3::timestamptz is null or opened_at < $3)
+		 order by opened_at asc, id asc`,
+		accountID, fromArg, toArg,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		trade, err := scanTrade(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(trade); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+const tradeColumns = `id, account_id, symbol, side, volume, status, ticket, open_command_id, close_command_id,
+	strategy, reason, entry_price, exit_price, stop_loss_pips, take_profit_pips, sl, tp,
+	profit, r_multiple, duration_seconds, modifications, opened_at, closed_at, updated_at, close_reason`
+
+func scanTrade(row interface{ Scan(...interface{}) error }) (domain.Trade, error) {
+	var t domain.Trade
+	var status string
+	var mods []byte
+	var closedAt sql.NullTime
+	err := row.Scan(
+		&t.ID, &t.AccountID, &t.Symbol, &t.Side, &t.Volume, &status, &t.Ticket, &t.OpenCommandID, &t.CloseCommandID,
+		&t.Strategy, &t.Reason, &t.EntryPrice, &t.ExitPrice, &t.StopLossPips, &t.TakeProfitPips, &t.SL, &t.TP,
+		&t.Profit, &t.RMultiple, &t.DurationSeconds, &mods, &t.OpenedAt, &closedAt, &t.UpdatedAt, &t.CloseReason,
+	)
+	if err != nil {
+		return domain.Trade{}, err
+	}
+	t.Status = domain.TradeStatus(status)
+	t.ClosedAt = closedAt.Time
+	if err := json.Unmarshal(mods, &t.Modifications); err != nil || t.Modifications == nil {
+		t.Modifications = []domain.TradeModification{}
+	}
+	return t, nil
+}
+
+func (s *Store) SaveReport(r domain.Report) domain.Report {
+	if r.ID == "" {
+		r.ID = uuid.NewString()
+	}
+	if r.RiskTriggers == nil {
+		r.RiskTriggers = map[string]int{}
+	}
+	triggers, _ := json.Marshal(r.RiskTriggers)
+	_, _ = s.db.Exec(
+		`insert into reports(
+			id, account_id, kind, period, period_from, period_to, trades_opened, trades_closed, wins, losses,
+			win_rate, net_profit, profit_factor, start_equity, end_equity, return_pct, max_drawdown, max_drawdown_pct,
+			risk_triggers, signals_proposed, signals_denied, delivery_failures, created_at, currency
+		) values ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19::jsonb,$20,$21,$22,$23,$24)
+		on conflict (id) do nothing`,
+		r.ID, r.AccountID, string(r.Kind), r.Period, r.From, r.To, r.TradesOpened, r.TradesClosed, r.Wins, r.Losses,
+		r.WinRate, r.NetProfit, r.ProfitFactor, r.StartEquity, r.EndEquity, r.ReturnPct, r.MaxDrawdown, r.MaxDrawdownPct,
+		string(triggers), r.SignalsProposed, r.SignalsDenied, r.DeliveryFailures, r.CreatedAt, r.Currency,
+	)
+	return r
+}
+
+func (s *Store) Report(id string) (domain.Report, bool) {
+	r, err := scanReport(s.db.QueryRow(`select `+reportColumns+` from reports where id = $1`, id))
+	return r, err == nil
+}
+
+func (s *Store) ListReports(q domain.ReportQuery) []domain.Report {
+	var fromArg, toArg interface{}
+	if !q.From.IsZero() {
+		fromArg = q.From
+	}
+	if !q.To.IsZero() {
+		toArg = q.To
+	}
+	var limit interface{}
+	if q.Limit > 0 {
+		limit = q.Limit
+	}
+	rows, err := s.db.Query(
+		`select `+reportColumns+`
+		 from reports
+		 where ($1::text = '' or account_id = $1)
+		   and ($2::text = '' or kind = $2)
+		   and ($3::timestamptz is null or period_from >= $3)
+		   and ($4::timestamptz is null or period_from <= $4)
+		 order by period_from desc, id desc
+		 limit $5`,
+		q.AccountID, string(q.Kind), fromArg, toArg, limit,
+	)
+	if err != nil {
+		return []domain.Report{}
+	}
+	defer rows.Close()
+
+	out := make([]domain.Report, 0, 16)
+	for rows.Next() {
+		r, err := scanReport(rows)
+		if err != nil {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+const reportColumns = `id, account_id, kind, period, period_from, period_to, trades_opened, trades_closed, wins, losses,
+	win_rate, net_profit, profit_factor, start_equity, end_equity, return_pct, max_drawdown, max_drawdown_pct,
+	risk_triggers, signals_proposed, signals_denied, delivery_failures, created_at, currency`
+
+func scanReport(row interface{ Scan(...interface{}) error }) (domain.Report, error) {
+	var r domain.Report
+	var kind string
+	var triggers []byte
+	err := row.Scan(
+		&r.ID, &r.AccountID, &kind, &r.Period, &r.From, &r.To, &r.TradesOpened, &r.TradesClosed, &r.Wins, &r.Losses,
+		&r.WinRate, &r.NetProfit, &r.ProfitFactor, &r.StartEquity, &r.EndEquity, &r.ReturnPct, &r.MaxDrawdown, &r.MaxDrawdownPct,
+		&triggers, &r.SignalsProposed, &r.SignalsDenied, &r.DeliveryFailures, &r.CreatedAt, &r.Currency,
+	)
+	if err != nil {
+		return domain.Report{}, err
+	}
+	r.Kind = domain.ReportKind(kind)
+	if err := json.Unmarshal(triggers, &r.RiskTriggers); err != nil || r.RiskTriggers == nil {
+		r.RiskTriggers = map[string]int{}
+	}
+	return r, nil
+}
+
+func (s *Store) SaveDeals(deals []domain.Deal) {
+	if len(deals) == 0 {
+		return
+	}
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+	stmt, err := tx.Prepare(
+		`insert into deals(account_id, ticket, order_ticket, position_id, symbol, side, entry, reason,
+		                   volume, price, profit, swap, commission, magic, comment, deal_time, received_at)
+		 values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		 on conflict (account_id, ticket) do update
+		 set order_ticket = excluded.order_ticket,
+		     position_id = excluded.position_id,
+		     symbol = excluded.symbol,
+		     side = excluded.side,
+		     entry = excluded.entry,
+		     reason = excluded.reason,
+		     volume = excluded.volume,
+		     price = excluded.price,
+		     profit = excluded.profit,
+		     swap = excluded.swap,
+		     commission = excluded.commission,
+		     magic = excluded.magic,
+		     comment = excluded.comment,
+		     deal_time = excluded.deal_time,
+		     received_at = excluded.received_at`,
+	)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+	for _, d := range deals {
+		if _, err := stmt.Exec(
+			d.AccountID, d.Ticket, d.Order, d.PositionID, d.Symbol, d.Side, d.Entry, d.Reason,
+			d.Volume, d.Price, d.Profit, d.Swap, d.Commission, d.Magic, d.Comment, d.Time.UTC(), d.ReceivedAt.UTC(),
+		); err != nil {
+			return
+		}
+	}
+	_ = tx.Commit()
+}
+
+func (s *Store) ListDeals(q domain.DealQuery) []domain.Deal {
+	var fromArg, toArg interface{}
+	if !q.From.IsZero() {
+		fromArg = q.From
+	}
+	if !q.To.IsZero() {
+		toArg = q.To
+	}
+	var limit interface{}
+	if q.Limit > 0 {
+		limit = q.Limit
+	}
+	rows, err := s.db.Query(
+		`select account_id, ticket, order_ticket, position_id, symbol, side, entry, reason,
+		        volume, price, profit, swap, commission, magic, comment, deal_time, received_at
+		 from deals
+		 where ($1::text = '' or account_id = $1)
+		   and ($2::text = '' or symbol = $2)
+		   and ($3::timestamptz is null or deal_time >= $3)
+		   and ($4::timestamptz is null or deal_time <= $4)
+		 order by deal_time desc, ticket desc
+		 limit $5`,
+		q.AccountID, strings.ToUpper(strings.TrimSpace(q.Symbol)), fromArg, toArg, limit,
+	)
+	if err != nil {
+		return []domain.Deal{}
+	}
+	defer rows.Close()
+
+	out := make([]domain.Deal, 0, 32)
+	for rows.Next() {
+		var d domain.Deal
+		if err := rows.Scan(
+			&d.AccountID, &d.Ticket, &d.Order, &d.PositionID, &d.Symbol, &d.Side, &d.Entry, &d.Reason,
+			&d.Volume, &d.Price, &d.Profit, &d.Swap, &d.Commission, &d.Magic, &d.Comment, &d.Time, &d.ReceivedAt,
+		); err != nil {
+			continue
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+func (s *Store) SaveReconcileIssue(issue domain.ReconcileIssue) domain.ReconcileIssue {
+	var resolvedAt interface{}
+	if !issue.ResolvedAt.IsZero() {
+		resolvedAt = issue.ResolvedAt
+	}
+	_, _ = s.db.Exec(
+		`insert into reconcile_issues(account_id, id, kind, ticket, command_id, symbol, detail, detected_at, resolved_at)
+		 values ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		 on conflict (account_id, id) do update
+		 set ticket = excluded.ticket,
+		     command_id = excluded.command_id,
+		     symbol = excluded.symbol,
+		     detail = excluded.detail,
+		     detected_at = excluded.detected_at,
+		     resolved_at = excluded.resolved_at`,
+		issue.AccountID, issue.ID, string(issue.Kind), issue.Ticket, issue.CommandID, issue.Symbol, issue.Detail,
+		issue.DetectedAt, resolvedAt,
+	)
+	return issue
+}
+
+func (s *Store) ReconcileIssue(accountID, id string) (domain.ReconcileIssue, bool) {
+	issue, err := scanReconcileIssue(s.db.QueryRow(
+		`select `+reconcileIssueColumns+` from reconcile_issues where account_id = $1 and id = $2`,
+		accountID, id,
+	))
+	return issue, err == nil
+}
+
+func (s *Store) ListReconcileIssues(q domain.ReconcileQuery) []domain.ReconcileIssue {
+	var limit interface{}
+	if q.Limit > 0 {
+		limit = q.Limit
+	}
+	rows, err := s.db.Query(
+		`select `+reconcileIssueColumns+`
+		 from reconcile_issues
+		 where ($1::text = '' or account_id = $1)
+		   and ($2::text = '' or kind = $2)
+		   and ($3::text = '' or ($3 = 'open') = (resolved_at is null))
+		 order by detected_at desc, id desc
+		 limit $4`,
+		q.AccountID, string(q.Kind), q.Status, limit,
+	)
+	if err != nil {
+		return []domain.ReconcileIssue{}
+	}
+	defer rows.Close()
+
+	out := make([]domain.ReconcileIssue, 0, 16)
+	for rows.Next() {
+		issue, err := scanReconcileIssue(rows)
+		if err != nil {
+			continue
+		}
+		out = append(out, issue)
+	}
+	return out
+}
+
+const reconcileIssueColumns = `account_id, id, kind, ticket, command_id, symbol, detail, detected_at, resolved_at`
+
+func scanReconcileIssue(row interface{ Scan(...interface{}) error }) (domain.ReconcileIssue, error) {
+	var issue domain.ReconcileIssue
+	var kind string
+	var resolvedAt sql.NullTime
+	err := row.Scan(
+		&issue.AccountID, &issue.ID, &kind, &issue.Ticket, &issue.CommandID, &issue.Symbol, &issue.Detail,
+		&issue.DetectedAt, &resolvedAt,
+	)
+	if err != nil {
+		return domain.ReconcileIssue{}, err
+	}
+	issue.Kind = domain.ReconcileIssueKind(kind)
+	issue.ResolvedAt = resolvedAt.Time
+	return issue, nil
+}
+
+func (s *Store) SaveEquityPoint(p domain.EquityPoint) {
+	_, _ = s.db.Exec(
+		`insert into equity_points(account_id, resolution, ts, equity, balance, floating_pnl, equity_high, equity_low, open_positions, samples)
+		 values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		 on conflict (account_id, resolution, ts) do update
+		 set equity = excluded.equity,
+		     balance = excluded.balance,
+		     floating_pnl = excluded.floating_pnl,
+		     equity_high = excluded.equity_high,
+		     equity_low = excluded.equity_low,
+		     open_positions = excluded.open_positions,
+		     samples = excluded.samples`,
+		p.AccountID, p.Resolution, p.Time.UTC(), p.Equity, p.Balance, p.FloatingPnL,
+		p.EquityHigh, p.EquityLow, p.OpenPositions, p.Samples,
+	)
+}
+
+func (s *Store) LatestEquityPoint(accountID, resolution string) (domain.EquityPoint, bool) {
+	p, err := scanEquityPoint(s.db.QueryRow(
+		`select `+equityColumns+` from equity_points
+		 where account_id = $1 and resolution = $2
+		 order by ts desc limit 1`,
+		accountID, resolution,
+	))
+	return p, err == nil
+}
+
+func (s *Store) ListEquityPoints(accountID, resolution string, from, to time.Time) []domain.EquityPoint {
+	var fromArg, toArg interface{}
+	if !from.IsZero() {
+		fromArg = from
+	}
+	if !to.IsZero() {
+		toArg = to
+	}
+	rows, err := s.db.Query(
+		`select `+equityColumns+` from equity_points
+		 where account_id = $1 and resolution = $2
+		   and ($3::timestamptz is null or ts >= $3)
+		   and ($4::timestamptz is null or ts <= $4)
+		 order by ts asc`,
+		accountID, resolution, fromArg, toArg,
+	)
+	if err != nil {
+		return []domain.EquityPoint{}
+	}
+	defer rows.Close()
+
+	out := make([]domain.EquityPoint, 0, 256)
+	for rows.Next() {
+		p, err := scanEquityPoint(rows)
+		if err != nil {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+func (s *Store) PruneEquityPoints(accountID, resolution string, before time.Time) int {
+	res, err := s.db.Exec(
+		`delete from equity_points where account_id = $1 and resolution = $2 and ts < $3`,
+		accountID, resolution, before,
+	)
+	if err != nil {
+		return 0
+	}
+	n, _ := res.RowsAffected()
+	return int(n)
+}
+
+const equityColumns = `account_id, resolution, ts, equity, balance, floating_pnl, equity_high, equity_low, open_positions, samples`
+
+func scanEquityPoint(row interface{ Scan(...interface{}) error }) (domain.EquityPoint, error) {
+	var p domain.EquityPoint
+	err := row.Scan(&p.AccountID, &p.Resolution, &p.Time, &p.Equity, &p.Balance, &p.FloatingPnL,
+		&p.EquityHigh, &p.EquityLow, &p.OpenPositions, &p.Samples)
+	return p, err
+}
+
+func (s *Store) SymbolPause(symbol string) (domain.SymbolPause, bool) {
+	p, err := scanSymbolPause(s.db.QueryRow(
+		`select `+symbolPauseColumns+` from symbol_pauses where symbol = $1`,
+		strings.ToUpper(strings.TrimSpace(symbol)),
+	))
+	return p, err == nil
+}
+
+func (s *Store) SaveSymbolPause(pause domain.SymbolPause) {
+	nullTime := func(t time.Time) interface{} {
+		if t.IsZero() {
+			return nil
+		}
+		return t
+	}
+	_, _ = s.db.Exec(
+		`insert into symbol_pauses(symbol, paused, reason, avg_slippage_pips, fills, paused_at, until, resumed_at, updated_at)
+		 values ($1, $2, $3, $4, $5, $6, $7, $8, now())
+		 on conflict (symbol) do update
+		 set paused = excluded.paused,
+		     reason = excluded.reason,
+		     avg_slippage_pips = excluded.avg_slippage_pips,
+		     fills = excluded.fills,
+		     paused_at = excluded.paused_at,
+		     until = excluded.until,
+		     resumed_at = excluded.resumed_at,
+		     updated_at = excluded.updated_at`,
+		strings.ToUpper(strings.TrimSpace(pause.Symbol)), pause.Paused, pause.Reason, pause.AvgSlippagePips, pause.Fills,
+		nullTime(pause.PausedAt), nullTime(pause.Until), nullTime(pause.ResumedAt),
+	)
+}
+
+func (s *Store) ListSymbolPauses() []domain.SymbolPause {
+	rows, err := s.db.Query(`select ` + symbolPauseColumns + ` from symbol_pauses order by symbol asc`)
+	if err != nil {
+		return []domain.SymbolPause{}
+	}
+	defer rows.Close()
+
+	out := make([]domain.SymbolPause, 0, 8)
+	for rows.Next() {
+		p, err := scanSymbolPause(rows)
+		if err != nil {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+const symbolPauseColumns = `symbol, paused, reason, avg_slippage_pips, fills, paused_at, until, resumed_at`
+
+func scanSymbolPause(row interface{ Scan(...interface{}) error }) (domain.SymbolPause, error) {
+	var p domain.SymbolPause
+	var pausedAt, until, resumedAt sql.NullTime
+	if err := row.Scan(&p.Symbol, &p.Paused, &p.Reason, &p.AvgSlippagePips, &p.Fills, &pausedAt, &until, &resumedAt); err != nil {
+		return domain.SymbolPause{}, err
+	}
+	p.PausedAt, p.Until, p.ResumedAt = pausedAt.Time, until.Time, resumedAt.Time
+	return p, nil
+}
+
 func (s *Store) SaveOAuthState(state domain.OAuthState) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
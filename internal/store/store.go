@@ -1,6 +1,10 @@
 package store
 
-import "mmbot/internal/domain"
+import (
+	"time"
+
+	"mmbot/internal/domain"
+)
 
 // Store defines the runtime persistence contract used by the HTTP layer.
 type Store interface {
@@ -8,22 +12,98 @@ type Store interface {
 	ValidateEASession(token string) (domain.EASession, error)
 	TouchDevice(deviceID string)
 	SavePositionSnapshot(accountID string, snapshot map[string]interface{})
+	PositionSnapshot(accountID string) (map[string]interface{}, bool)
+	// IssueEASession registers the account. SaveAccount updates its currency
+	// and ListAccounts returns every account ordered by ID.
+	SaveAccount(account domain.Account) domain.Account
+	Account(id string) (domain.Account, bool)
+	ListAccounts() []domain.Account
 
 	EnqueueCommand(cmd domain.Command) domain.Command
 	NextQueuedCommand(accountID string) (domain.Command, error)
 	MarkCommandResult(result domain.CommandResult) (domain.Command, error)
+	Command(id string) (domain.Command, bool)
+	// StreamCommands and StreamCommandResults call fn for each record matching
+	// q, oldest first, and stop at the first error fn returns.
+	StreamCommands(q domain.ExportQuery, fn func(domain.Command) error) error
+	StreamCommandResults(q domain.ExportQuery, fn func(domain.CommandResult) error) error
+	// ListFills returns successful commands with a fill price, newest first,
+	// filled at or after since. An empty symbol lists every symbol.
+	ListFills(symbol string, since time.Time, limit int) []domain.Command
 
+	// SetPaused sets the global pause and clears its owner; SetPausedBy
+	// pauses and records the owner in the same write.
 	SetPaused(paused bool)
+	SetPausedBy(owner domain.PauseOwner)
 	IsPaused() bool
+	PauseOwner() (domain.PauseOwner, bool)
 
 	AppendEvent(eventType domain.EventType, accountID string, payload map[string]interface{}) domain.Event
 	ListEvents(limit int) []domain.Event
+	// ListAccountEvents returns an account's events created in [from, to),
+	// oldest first.
+	ListAccountEvents(accountID string, from, to time.Time) []domain.Event
+	StreamEvents(q domain.ExportQuery, fn func(domain.Event) error) error
 
 	OpenPositions(accountID string) int
 	SetOpenPositions(accountID string, count int)
 	AdjustOpenPositions(accountID string, delta int)
 	DailyLoss(accountID string) float64
 	SetDailyLoss(accountID string, lossPct float64)
+	AccountRiskState(accountID string) (domain.AccountRiskState, bool)
+	SaveAccountRiskState(state domain.AccountRiskState)
+	TradeActivity(accountID string) (domain.TradeActivity, bool)
+	SaveTradeActivity(activity domain.TradeActivity)
+
+	SaveCandles(symbol, timeframe string, candles []domain.Candle)
+	ListCandles(symbol, timeframe string, from, to time.Time, limit int) []domain.Candle
+
+	// Symbol specs are keyed by symbol and source ("ea" or "admin").
+	SaveSymbolSpec(spec domain.SymbolSpec) domain.SymbolSpec
+	ListSymbolSpecs(symbol string) []domain.SymbolSpec
+	DeleteSymbolSpec(symbol, source string) bool
+
+	// SaveBlackout upserts by ID, assigning one when empty. ListBlackouts
+	// returns windows overlapping [from, to]; a zero bound is open.
+	SaveBlackout(b domain.Blackout) domain.Blackout
+	ListBlackouts(from, to time.Time) []domain.Blackout
+	DeleteBlackout(id string) bool
+
+	// SaveTrade upserts a journal record by ID, assigning one when empty.
+	// ListTrades returns the newest opened first.
+	SaveTrade(trade domain.Trade) domain.Trade
+	Trade(id string) (domain.Trade, bool)
+	OpenTradeByTicket(accountID, ticket string) (domain.Trade, bool)
+	ListTrades(q domain.TradeQuery) []domain.Trade
+	StreamTrades(q domain.ExportQuery, fn func(domain.Trade) error) error
+
+	// SaveDeals upserts broker deals by account and ticket. ListDeals returns
+	// the newest deal first.
+	SaveDeals(deals []domain.Deal)
+	ListDeals(q domain.DealQuery) []domain.Deal
+
+	// Reconciliation issues are keyed by account and ID. ListReconcileIssues
+	// returns the most recently detected first.
+	SaveReconcileIssue(issue domain.ReconcileIssue) domain.ReconcileIssue
+	ReconcileIssue(accountID, id string) (domain.ReconcileIssue, bool)
+	ListReconcileIssues(q domain.ReconcileQuery) []domain.ReconcileIssue
+
+	// Equity points are keyed by account, resolution and time; lists are
+	// oldest first. PruneEquityPoints deletes points before a time.
+	SaveEquityPoint(p domain.EquityPoint)
+	LatestEquityPoint(accountID, resolution string) (domain.EquityPoint, bool)
+	ListEquityPoints(accountID, resolution string, from, to time.Time) []domain.EquityPoint
+	PruneEquityPoints(accountID, resolution string, before time.Time) int
+
+	// SaveReport archives a report, assigning an ID when empty. ListReports
+	// returns the newest period first.
+	SaveReport(r domain.Report) domain.Report
+	Report(id string) (domain.Report, bool)
+	ListReports(q domain.ReportQuery) []domain.Report
+
+	SymbolPause(symbol string) (domain.SymbolPause, bool)
+	SaveSymbolPause(pause domain.SymbolPause)
+	ListSymbolPauses() []domain.SymbolPause
 
 	SaveOAuthState(state domain.OAuthState)
 	ConsumeOAuthState(state string) (domain.OAuthState, error)
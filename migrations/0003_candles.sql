@@ -0,0 +1,11 @@
+create table if not exists candles (
+    symbol text not null,
+    timeframe text not null,
+    ts timestamptz not null,
+    open numeric(18,8) not null,
+    high numeric(18,8) not null,
+    low numeric(18,8) not null,
+    close numeric(18,8) not null,
+    volume numeric(20,4) not null default 0,
+    primary key (symbol, timeframe, ts)
+);
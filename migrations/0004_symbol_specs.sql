@@ -0,0 +1,7 @@
+create table if not exists symbol_specs (
+    symbol text not null,
+    source text not null,
+    spec jsonb not null,
+    updated_at timestamptz not null default now(),
+    primary key (symbol, source)
+);
@@ -0,0 +1,14 @@
+create table if not exists account_risk_state (
+    account_id text primary key references broker_accounts(id),
+    peak_equity numeric(18,2) not null default 0,
+    peak_at timestamptz not null default now(),
+    week_start timestamptz not null,
+    week_start_equity numeric(18,2) not null default 0,
+    month_start timestamptz not null,
+    month_start_equity numeric(18,2) not null default 0,
+    last_equity numeric(18,2) not null default 0,
+    paused boolean not null default false,
+    pause_reason text not null default '',
+    paused_at timestamptz,
+    updated_at timestamptz not null default now()
+);
@@ -0,0 +1,9 @@
+create table if not exists trade_activity (
+    account_id text primary key references broker_accounts(id),
+    day text not null default '',
+    trades_today int not null default 0,
+    loss_streak int not null default 0,
+    cooldown_until timestamptz,
+    last_close_at timestamptz,
+    updated_at timestamptz not null default now()
+);
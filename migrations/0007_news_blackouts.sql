@@ -0,0 +1,12 @@
+create table if not exists news_blackouts (
+    id text primary key,
+    title text not null default '',
+    currency text not null default '',
+    impact text not null default '',
+    starts_at timestamptz not null,
+    ends_at timestamptz not null,
+    source text not null default 'admin',
+    created_at timestamptz not null default now()
+);
+
+create index if not exists idx_news_blackouts_window on news_blackouts(starts_at, ends_at);
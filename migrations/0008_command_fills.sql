@@ -0,0 +1,19 @@
+alter table commands add column if not exists requested_price double precision not null default 0;
+alter table commands add column if not exists fill_price double precision not null default 0;
+alter table commands add column if not exists slippage_pips double precision not null default 0;
+alter table commands add column if not exists latency_ms bigint not null default 0;
+alter table commands add column if not exists filled_at timestamptz;
+
+create index if not exists idx_commands_fills on commands(symbol, filled_at) where filled_at is not null;
+
+create table if not exists symbol_pauses (
+    symbol text primary key,
+    paused boolean not null default false,
+    reason text not null default '',
+    avg_slippage_pips double precision not null default 0,
+    fills int not null default 0,
+    paused_at timestamptz,
+    until timestamptz,
+    resumed_at timestamptz,
+    updated_at timestamptz not null default now()
+);
@@ -0,0 +1,31 @@
+alter table commands add column if not exists strategy text not null default '';
+
+create table if not exists trades (
+    id text primary key,
+    account_id text not null references broker_accounts(id),
+    symbol text not null,
+    side text not null,
+    volume double precision not null default 0,
+    status text not null,
+    ticket text not null default '',
+    open_command_id text not null default '',
+    close_command_id text not null default '',
+    strategy text not null default '',
+    reason text not null default '',
+    entry_price double precision not null default 0,
+    exit_price double precision not null default 0,
+    stop_loss_pips double precision not null default 0,
+    take_profit_pips double precision not null default 0,
+    sl double precision not null default 0,
+    tp double precision not null default 0,
+    profit double precision not null default 0,
+    r_multiple double precision not null default 0,
+    duration_seconds bigint not null default 0,
+    modifications jsonb not null default '[]'::jsonb,
+    opened_at timestamptz not null,
+    closed_at timestamptz,
+    updated_at timestamptz not null default now()
+);
+
+create index if not exists idx_trades_account_opened on trades(account_id, opened_at desc);
+create index if not exists idx_trades_open_ticket on trades(account_id, ticket) where status = 'OPEN';
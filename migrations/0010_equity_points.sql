@@ -0,0 +1,13 @@
+create table if not exists equity_points (
+    account_id text not null references broker_accounts(id),
+    resolution text not null,
+    ts timestamptz not null,
+    equity double precision not null default 0,
+    balance double precision not null default 0,
+    floating_pnl double precision not null default 0,
+    equity_high double precision not null default 0,
+    equity_low double precision not null default 0,
+    open_positions int not null default 0,
+    samples int not null default 0,
+    primary key (account_id, resolution, ts)
+);
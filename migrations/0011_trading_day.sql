@@ -0,0 +1,2 @@
+alter table trade_activity add column if not exists day_start_equity double precision not null default 0;
+alter table trade_activity add column if not exists daily_breaker_at timestamptz;
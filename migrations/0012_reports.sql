@@ -0,0 +1,29 @@
+create table if not exists reports (
+    id text primary key,
+    account_id text not null references broker_accounts(id),
+    kind text not null,
+    period text not null,
+    period_from timestamptz not null,
+    period_to timestamptz not null,
+    trades_opened int not null default 0,
+    trades_closed int not null default 0,
+    wins int not null default 0,
+    losses int not null default 0,
+    win_rate double precision not null default 0,
+    net_profit double precision not null default 0,
+    profit_factor double precision not null default 0,
+    start_equity double precision not null default 0,
+    end_equity double precision not null default 0,
+    return_pct double precision not null default 0,
+    max_drawdown double precision not null default 0,
+    max_drawdown_pct double precision not null default 0,
+    risk_triggers jsonb not null default '{}'::jsonb,
+    signals_proposed int not null default 0,
+    signals_denied int not null default 0,
+    delivery_failures int not null default 0,
+    created_at timestamptz not null default now()
+);
+create index if not exists idx_reports_account_from on reports(account_id, period_from desc);
+
+create index if not exists idx_events_account_created on events(account_id, created_at);
+create index if not exists idx_trades_account_closed on trades(account_id, closed_at desc) where status = 'CLOSED';
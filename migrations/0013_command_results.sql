@@ -0,0 +1,17 @@
+create table if not exists command_results (
+    id bigserial primary key,
+    command_id text not null references commands(id),
+    account_id text not null references broker_accounts(id),
+    status text not null,
+    broker_ticket text not null default '',
+    error_code text not null default '',
+    error_message text not null default '',
+    executed_at text not null default '',
+    requested_price double precision not null default 0,
+    fill_price double precision not null default 0,
+    slippage_pips double precision not null default 0,
+    latency_ms bigint not null default 0,
+    received_at timestamptz not null default now()
+);
+create index if not exists idx_command_results_account_received on command_results(account_id, received_at);
+create index if not exists idx_commands_account_created on commands(account_id, created_at);
@@ -0,0 +1,37 @@
+alter table trades add column if not exists close_reason text not null default '';
+
+create table if not exists deals (
+    account_id text not null references broker_accounts(id),
+    ticket text not null,
+    order_ticket text not null default '',
+    position_id text not null default '',
+    symbol text not null default '',
+    side text not null default '',
+    entry text not null default '',
+    reason text not null default '',
+    volume double precision not null default 0,
+    price double precision not null default 0,
+    profit double precision not null default 0,
+    swap double precision not null default 0,
+    commission double precision not null default 0,
+    magic bigint not null default 0,
+    comment text not null default '',
+    deal_time timestamptz not null,
+    received_at timestamptz not null default now(),
+    primary key (account_id, ticket)
+);
+create index if not exists idx_deals_account_time on deals(account_id, deal_time desc);
+
+create table if not exists reconcile_issues (
+    account_id text not null references broker_accounts(id),
+    id text not null,
+    kind text not null,
+    ticket text not null default '',
+    command_id text not null default '',
+    symbol text not null default '',
+    detail text not null default '',
+    detected_at timestamptz not null,
+    resolved_at timestamptz,
+    primary key (account_id, id)
+);
+create index if not exists idx_reconcile_issues_detected on reconcile_issues(detected_at desc);
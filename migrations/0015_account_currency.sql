@@ -0,0 +1,4 @@
+alter table broker_accounts add column if not exists currency text not null default '';
+alter table broker_accounts add column if not exists updated_at timestamptz not null default now();
+
+alter table reports add column if not exists currency text not null default '';